@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+)
+
+// Derivative is the simplified symbolic derivative of an expression and, when asked for, its value at a point
+type Derivative struct {
+	Derivative string   `json:"derivative"`
+	Variable   string   `json:"variable"`
+	Order      int      `json:"order"`
+	Result     *float64 `json:"result,omitempty"`
+}
+
+// DerivativeOptions refine a derivative. Variable defaults to "x" and Order to 1; At evaluates the
+// derivative at a point when set.
+type DerivativeOptions struct {
+	Variable string   `json:"variable,omitempty"`
+	Order    int      `json:"order,omitempty"`
+	At       *float64 `json:"at,omitempty"`
+}
+
+// NumericOptions hold the settings shared by the numerical methods. Variable defaults to "x",
+// Tolerance to 1e-10 and MaxIterations to the method's default.
+type NumericOptions struct {
+	Variable      string  `json:"variable,omitempty"`
+	Tolerance     float64 `json:"tolerance,omitempty"`
+	MaxIterations int     `json:"max_iterations,omitempty"`
+}
+
+// IntegrateOptions refine an integral. Method is "gauss-kronrod" (default) or "simpson".
+type IntegrateOptions struct {
+	NumericOptions
+	Method string `json:"method,omitempty"`
+}
+
+// Integral is the value of a definite integral and its estimated absolute error
+type Integral struct {
+	Result        float64 `json:"result"`
+	ErrorEstimate float64 `json:"error_estimate"`
+	Evaluations   int     `json:"evaluations"`
+	Method        string  `json:"method"`
+}
+
+// RootOptions select the root finding method. Method is "brent" (default) or "bisection", which need
+// the bracketing interval [A, B], or "newton", which starts from X0 (default: the midpoint of [A, B]).
+type RootOptions struct {
+	NumericOptions
+	A      *float64 `json:"a,omitempty"`
+	B      *float64 `json:"b,omitempty"`
+	X0     *float64 `json:"x0,omitempty"`
+	Method string   `json:"method,omitempty"`
+}
+
+// Root is a root of an expression, the expression's value there and the iterations used
+type Root struct {
+	Root       float64 `json:"root"`
+	Value      float64 `json:"value"`
+	Iterations int     `json:"iterations"`
+	Method     string  `json:"method"`
+}
+
+// Extremum is the location and value of a local minimum or maximum
+type Extremum struct {
+	X          float64 `json:"x"`
+	Value      float64 `json:"value"`
+	Iterations int     `json:"iterations"`
+}
+
+// PlotOptions refine a plot. Variable defaults to "x"; zero Samples, MaxPoints, Width and Height take
+// the server's defaults. SVG also renders the curve as an SVG image.
+type PlotOptions struct {
+	Variable  string `json:"variable,omitempty"`
+	Samples   int    `json:"samples,omitempty"`
+	MaxPoints int    `json:"max_points,omitempty"`
+	SVG       bool   `json:"svg,omitempty"`
+	Width     int    `json:"width,omitempty"`
+	Height    int    `json:"height,omitempty"`
+}
+
+// Point is a sampled point of a plot
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Gap is an x range where the plotted expression is undefined or discontinuous
+type Gap struct {
+	From float64 `json:"from"`
+	To   float64 `json:"to"`
+}
+
+// Plot is a sampled curve as continuous segments separated by gaps
+type Plot struct {
+	Segments [][]Point `json:"segments"`
+	Gaps     []Gap     `json:"gaps"`
+	Points   int       `json:"points"`
+	YMin     float64   `json:"y_min"`
+	YMax     float64   `json:"y_max"`
+	SVG      string    `json:"svg,omitempty"`
+}
+
+// PolynomialRoot is a distinct root of a polynomial with its multiplicity
+type PolynomialRoot struct {
+	Real         float64 `json:"real"`
+	Imag         float64 `json:"imag"`
+	Multiplicity int     `json:"multiplicity"`
+}
+
+// PolynomialSolution holds the roots of a polynomial. Solutions is "finite", or "none" or "infinite"
+// for nonzero or zero constant polynomials.
+type PolynomialSolution struct {
+	Degree    int              `json:"degree"`
+	Solutions string           `json:"solutions"`
+	Roots     []PolynomialRoot `json:"roots"`
+	Method    string           `json:"method,omitempty"`
+}
+
+// LinearSystemSolution is the solution of a system of linear equations. Solutions is "unique", "none"
+// or "infinite"; for infinitely many solutions Solution sets every free variable to zero and General
+// expresses the other variables in terms of them.
+type LinearSystemSolution struct {
+	Solutions     string             `json:"solutions"`
+	Variables     []string           `json:"variables"`
+	Rank          int                `json:"rank"`
+	Solution      map[string]float64 `json:"solution,omitempty"`
+	FreeVariables []string           `json:"free_variables,omitempty"`
+	General       map[string]string  `json:"general,omitempty"`
+}
+
+// Differentiate returns the symbolic derivative of expression, evaluated at opts.At when set
+func (c *Client) Differentiate(ctx context.Context, expression string, opts DerivativeOptions) (Derivative, error) {
+	return postResponse[Derivative](ctx, c, "derivative", struct {
+		Expression string `json:"expression"`
+		DerivativeOptions
+	}{expression, opts})
+}
+
+// Integrate returns the definite integral of expression from a to b
+func (c *Client) Integrate(ctx context.Context, expression string, a, b float64, opts IntegrateOptions) (Integral, error) {
+	return postResponse[Integral](ctx, c, "integrate", struct {
+		Expression string  `json:"expression"`
+		A          float64 `json:"a"`
+		B          float64 `json:"b"`
+		IntegrateOptions
+	}{expression, a, b, opts})
+}
+
+// FindRoot returns a root of expression found by the method of opts
+func (c *Client) FindRoot(ctx context.Context, expression string, opts RootOptions) (Root, error) {
+	return postResponse[Root](ctx, c, "roots", struct {
+		Expression string `json:"expression"`
+		RootOptions
+	}{expression, opts})
+}
+
+// Minimize returns a local minimum of expression in [a, b]
+func (c *Client) Minimize(ctx context.Context, expression string, a, b float64, opts NumericOptions) (Extremum, error) {
+	return c.optimize(ctx, "minimize", expression, a, b, opts)
+}
+
+// Maximize returns a local maximum of expression in [a, b]
+func (c *Client) Maximize(ctx context.Context, expression string, a, b float64, opts NumericOptions) (Extremum, error) {
+	return c.optimize(ctx, "maximize", expression, a, b, opts)
+}
+
+// optimize searches expression for the extremum the endpoint at path finds in [a, b]
+func (c *Client) optimize(ctx context.Context, path, expression string, a, b float64, opts NumericOptions) (Extremum, error) {
+	return postResponse[Extremum](ctx, c, path, struct {
+		Expression string  `json:"expression"`
+		A          float64 `json:"a"`
+		B          float64 `json:"b"`
+		NumericOptions
+	}{expression, a, b, opts})
+}
+
+// Plot samples expression over [a, b]
+func (c *Client) Plot(ctx context.Context, expression string, a, b float64, opts PlotOptions) (Plot, error) {
+	return postResponse[Plot](ctx, c, "plot", struct {
+		Expression string  `json:"expression"`
+		A          float64 `json:"a"`
+		B          float64 `json:"b"`
+		PlotOptions
+	}{expression, a, b, opts})
+}
+
+// SolvePolynomial returns the roots of the polynomial with coefficients in descending order of power
+func (c *Client) SolvePolynomial(ctx context.Context, coefficients []float64) (PolynomialSolution, error) {
+	return postResponse[PolynomialSolution](ctx, c, "solve/polynomial", map[string]any{"coefficients": coefficients})
+}
+
+// SolveLinearSystem solves equations such as "2x + y = 3" and "x - y = 0"
+func (c *Client) SolveLinearSystem(ctx context.Context, equations []string) (LinearSystemSolution, error) {
+	return postResponse[LinearSystemSolution](ctx, c, "solve/linear", map[string]any{"equations": equations})
+}
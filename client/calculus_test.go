@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+
+	"calculator/core"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCalculus tests derivatives, integrals, roots and extrema
+func TestCalculus(t *testing.T) {
+	c := newTestClient(setupServer(t, nil, nil))
+	ctx := context.Background()
+
+	at := 2.0
+	derivative, err := c.Differentiate(ctx, "x^3", DerivativeOptions{At: &at})
+	require.NoError(t, err)
+	assert.Equal(t, "x", derivative.Variable)
+	assert.Equal(t, 1, derivative.Order)
+	require.NotNil(t, derivative.Result)
+	assert.InDelta(t, 12, *derivative.Result, 1e-9)
+
+	integral, err := c.Integrate(ctx, "t^2", 0, 3, IntegrateOptions{NumericOptions: NumericOptions{Variable: "t"}, Method: "simpson"})
+	require.NoError(t, err)
+	assert.InDelta(t, 9, integral.Result, 1e-6)
+	assert.Equal(t, "simpson", integral.Method)
+
+	a, b := 0.0, 2.0
+	root, err := c.FindRoot(ctx, "x^2 - 2", RootOptions{A: &a, B: &b})
+	require.NoError(t, err)
+	assert.InDelta(t, math.Sqrt2, root.Root, 1e-9)
+
+	minimum, err := c.Minimize(ctx, "(x - 1)^2", -3, 3, NumericOptions{})
+	require.NoError(t, err)
+	assert.InDelta(t, 1, minimum.X, 1e-6)
+	maximum, err := c.Maximize(ctx, "-(x + 1)^2 + 4", -3, 3, NumericOptions{})
+	require.NoError(t, err)
+	assert.InDelta(t, -1, maximum.X, 1e-6)
+	assert.InDelta(t, 4, maximum.Value, 1e-9)
+
+	_, err = c.Integrate(ctx, "x", 0, 1, IntegrateOptions{Method: "trapezoid"})
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, core.CodeInvalidInput, apiErr.Code)
+}
+
+// TestPlot tests sampling an expression with a gap
+func TestPlot(t *testing.T) {
+	c := newTestClient(setupServer(t, nil, nil))
+
+	plot, err := c.Plot(context.Background(), "1/x", -1, 1, PlotOptions{Samples: 101, SVG: true})
+	require.NoError(t, err)
+	assert.Len(t, plot.Segments, 2)
+	assert.NotEmpty(t, plot.Gaps)
+	assert.Contains(t, plot.SVG, "<svg")
+}
+
+// TestSolvers tests solving polynomials and linear systems
+func TestSolvers(t *testing.T) {
+	c := newTestClient(setupServer(t, nil, nil))
+	ctx := context.Background()
+
+	polynomial, err := c.SolvePolynomial(ctx, []float64{1, -3, 2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, polynomial.Degree)
+	assert.Equal(t, "finite", polynomial.Solutions)
+	require.Len(t, polynomial.Roots, 2)
+
+	system, err := c.SolveLinearSystem(ctx, []string{"2x + y = 3", "x - y = 0"})
+	require.NoError(t, err)
+	assert.Equal(t, "unique", system.Solutions)
+	assert.InDelta(t, 1, system.Solution["x"], 1e-9)
+	assert.InDelta(t, 1, system.Solution["y"], 1e-9)
+}
+
+// TestRational tests exact rational mode operations
+func TestRational(t *testing.T) {
+	c := newTestClient(setupServer(t, nil, nil))
+
+	result, err := c.Rational(context.Background(), "add", "1/3", "1/6")
+	require.NoError(t, err)
+	assert.Equal(t, "1/2", result.Fraction)
+	assert.Equal(t, "1", result.Numerator)
+	assert.Equal(t, "2", result.Denominator)
+	assert.False(t, result.Approximate)
+
+	result, err = c.Rational(context.Background(), "inverse", "-2 1/3")
+	require.NoError(t, err)
+	assert.Equal(t, "-3/7", result.Fraction)
+}
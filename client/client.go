@@ -0,0 +1,251 @@
+// Package client is a Go SDK for the calculator HTTP API. A Client calls the /api/v1 endpoints with an
+// optional API key and session, retries transient failures with exponential backoff and returns the
+// server's structured error responses as *Error values. Typed methods cover the operations, finance,
+// linear algebra, unit conversion, calculus, plotting, the solvers and rational mode as well as the
+// caller's variables, user-defined functions and sessions.
+package client
+
+import (
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with every request
+const (
+	// APIKeyHeader identifies the caller's API key, which scopes variables and history
+	APIKeyHeader = "X-API-Key"
+	// SessionHeader identifies the caller's session, which scopes variables when no API key is sent
+	SessionHeader = "X-Session-ID"
+	// ClientHeader names the caller in the server's calculation events feed
+	ClientHeader = "X-Client-ID"
+	// IdempotencyKeyHeader names a POST call; the server applies retries of it once
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// AuthorizationHeader carries the bearer token naming the caller's tenant
+	AuthorizationHeader = "Authorization"
+)
+
+const (
+	// DefaultTimeout bounds each attempt of a request
+	DefaultTimeout = 10 * time.Second
+	// DefaultRetries is the number of times a transient failure is retried
+	DefaultRetries = 2
+	// DefaultBackoff is the delay before the first retry; it doubles with each further retry
+	DefaultBackoff = 100 * time.Millisecond
+	// DefaultClientID names the SDK in the events feed
+	DefaultClientID = "calculator-go"
+	// maxBackoff bounds the delay between retries, including delays asked for with Retry-After
+	maxBackoff = 5 * time.Second
+)
+
+// Client calls the calculator API. Fields left zero use no API key or session, no timeout beyond the
+// context's, no retries and the default HTTP client; New sets the defaults instead. A Client is safe
+// for concurrent use once configured.
+type Client struct {
+	// BaseURL is the server address, such as "http://localhost:8080"
+	BaseURL string
+	// APIKey scopes variables and history to the key's owner
+	APIKey string
+	// Session sends requests within a session, which holds ANS
+	Session string
+	// Token is a bearer token (a JWT) whose tenant claim selects the caller's tenant
+	Token string
+	// ClientID names the caller in the events feed
+	ClientID string
+	// Timeout bounds each attempt of a request
+	Timeout time.Duration
+	// Retries is the number of times a transient failure is retried: a network error, a 429, 502, 503
+	// or 504 response, or a 409 reporting that an earlier attempt of the call is still being processed
+	Retries int
+	// Backoff is the delay before the first retry, doubling with each further retry
+	Backoff time.Duration
+	// HTTPClient sends the requests; nil uses http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// New returns a client of the server at baseURL with the default timeout, retries and backoff
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:  baseURL,
+		ClientID: DefaultClientID,
+		Timeout:  DefaultTimeout,
+		Retries:  DefaultRetries,
+		Backoff:  DefaultBackoff,
+	}
+}
+
+// httpClient returns a safe HTTP client (never nil)
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// do sends body as JSON to the API path below /api/v1 and decodes the JSON response into out, which
+// may be nil. Transient failures are retried; every attempt of a POST carries the same Idempotency-Key,
+// so a retry of a request the server already applied is answered with its first response. Error
+// responses are returned as *Error.
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	var idempotencyKey string
+	if method == http.MethodPost {
+		idempotencyKey = newIdempotencyKey()
+	}
+
+	for attempt := 0; ; attempt++ {
+		data, retryAfter, err := c.attempt(ctx, method, path, payload, idempotencyKey)
+		if err == nil {
+			if out == nil {
+				return nil
+			}
+			return json.Unmarshal(data, out)
+		}
+		if attempt >= c.Retries || !retryable(ctx, err) {
+			return err
+		}
+		delay := c.backoff(attempt, retryAfter)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// attempt sends one request, returning the response body, the delay the server asked for with
+// Retry-After and any error
+func (c *Client) attempt(ctx context.Context, method, path string, payload []byte, idempotencyKey string) ([]byte, time.Duration, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.BaseURL, "/")+"/api/v1/"+path, reader)
+	if err != nil {
+		return nil, 0, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.ClientID != "" {
+		req.Header.Set(ClientHeader, c.ClientID)
+	}
+	if c.APIKey != "" {
+		req.Header.Set(APIKeyHeader, c.APIKey)
+	}
+	if c.Session != "" {
+		req.Header.Set(SessionHeader, c.Session)
+	}
+	if c.Token != "" {
+		req.Header.Set(AuthorizationHeader, "Bearer "+c.Token)
+	}
+	if idempotencyKey != "" {
+		req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), decodeError(resp, data)
+	}
+	return data, 0, nil
+}
+
+// newIdempotencyKey returns a random key naming one call
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	_, _ = crand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// retryable reports whether err is a transient failure worth retrying. Failures caused by ctx ending
+// are not. A 409 naming the call's Idempotency-Key means an earlier attempt is still being processed;
+// retrying it is answered with that attempt's response once it completes.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		case http.StatusConflict:
+			_, inProgress := apiErr.Details["idempotency_key"]
+			return inProgress
+		}
+		return false
+	}
+	// Network errors, including an attempt exceeding Timeout
+	return true
+}
+
+// backoff returns the delay before retry number attempt+1: the server's Retry-After when given,
+// otherwise Backoff doubled per attempt with jitter, at most maxBackoff
+func (c *Client) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, maxBackoff)
+	}
+	if c.Backoff <= 0 {
+		return 0
+	}
+	delay := maxBackoff
+	if attempt < 32 && c.Backoff < maxBackoff>>attempt {
+		delay = c.Backoff << attempt
+	}
+	// Spread retries of concurrent callers over the second half of the delay
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds; other forms are ignored
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// decodeError converts an error response into an *Error
+func decodeError(resp *http.Response, data []byte) *Error {
+	var envelope struct {
+		Error   string         `json:"error"`
+		Code    string         `json:"code"`
+		Details map[string]any `json:"details"`
+	}
+	if json.Unmarshal(data, &envelope) != nil || envelope.Error == "" {
+		envelope.Error = fmt.Sprintf("server responded %s", resp.Status)
+	}
+	return &Error{StatusCode: resp.StatusCode, Code: envelope.Code, Message: envelope.Error, Details: envelope.Details}
+}
@@ -0,0 +1,304 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"calculator/core"
+	"calculator/internal/calculator"
+	"calculator/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupServer serves the real calculator routes, passing each request through wrap when it is not nil
+func setupServer(t *testing.T, store storage.Store, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	calculator.SetupRoutes(r, &calculator.Service{Store: store})
+	var handler http.Handler = r
+	if wrap != nil {
+		handler = wrap(r)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestClient returns a client of server that retries without waiting long
+func newTestClient(server *httptest.Server) *Client {
+	c := New(server.URL)
+	c.Backoff = time.Millisecond
+	return c
+}
+
+// failFirst answers the first n requests with status before passing requests on, counting every request
+func failFirst(n int64, status int, requests *atomic.Int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) <= n {
+				w.WriteHeader(status)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// TestErrors tests decoding error responses into typed errors
+func TestErrors(t *testing.T) {
+	c := newTestClient(setupServer(t, nil, nil))
+	ctx := context.Background()
+
+	_, err := c.Divide(ctx, 1, 0)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrDivisionByZero)
+	assert.NotErrorIs(t, err, ErrDomainError)
+	assert.EqualError(t, err, "cannot divide by zero (DIVISION_BY_ZERO)")
+
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+
+	var calcErr *core.Error
+	require.ErrorAs(t, err, &calcErr)
+	assert.Equal(t, core.CodeDivisionByZero, calcErr.Code)
+
+	_, err = c.MatrixAdd(ctx, [][]float64{{1, 2}}, [][]float64{{1}})
+	assert.ErrorIs(t, err, ErrDimensionMismatch)
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "1x2", apiErr.Details["a"])
+
+	_, err = c.Convert(ctx, 1, "m", "furlongs")
+	assert.ErrorIs(t, err, ErrUnknownUnit)
+}
+
+// TestRetries tests retrying transient failures with backoff
+func TestRetries(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		failures int64
+		status   int
+		retries  int
+		requests int64
+		err      bool
+	}{
+		{name: "recovers from unavailable server", failures: 2, status: http.StatusServiceUnavailable, retries: 2, requests: 3},
+		{name: "recovers from rate limit", failures: 1, status: http.StatusTooManyRequests, retries: 2, requests: 2},
+		{name: "gives up after retries", failures: 5, status: http.StatusBadGateway, retries: 2, requests: 3, err: true},
+		{name: "no retries", failures: 1, status: http.StatusGatewayTimeout, retries: 0, requests: 1, err: true},
+		{name: "client errors are not retried", failures: 1, status: http.StatusBadRequest, retries: 2, requests: 1, err: true},
+		{name: "other conflicts are not retried", failures: 1, status: http.StatusConflict, retries: 2, requests: 1, err: true},
+		{name: "server errors are not retried", failures: 1, status: http.StatusInternalServerError, retries: 2, requests: 1, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int64
+			c := newTestClient(setupServer(t, nil, failFirst(tt.failures, tt.status, &requests)))
+			c.Retries = tt.retries
+
+			result, err := c.Add(ctx, 2, 3)
+			assert.Equal(t, tt.requests, requests.Load())
+			if tt.err {
+				var apiErr *Error
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, tt.status, apiErr.StatusCode)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 5.0, result)
+		})
+	}
+}
+
+// TestTimeout tests that slow attempts time out and are retried, and that a canceled context stops retries
+func TestTimeout(t *testing.T) {
+	var requests atomic.Int64
+	slow := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	c := newTestClient(setupServer(t, nil, slow))
+	c.Timeout = 50 * time.Millisecond
+
+	result, err := c.Multiply(context.Background(), 4, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 20.0, result)
+	assert.Equal(t, int64(2), requests.Load())
+
+	requests.Store(0)
+	c = newTestClient(setupServer(t, nil, failFirst(10, http.StatusServiceUnavailable, &requests)))
+	c.Retries = 10
+	c.Backoff = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = c.Add(ctx, 1, 2)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int64(1), requests.Load())
+}
+
+// TestBackoff tests the delay between retries
+func TestBackoff(t *testing.T) {
+	c := &Client{Backoff: 100 * time.Millisecond}
+	for attempt := range 3 {
+		delay := c.backoff(attempt, 0)
+		full := 100 * time.Millisecond << attempt
+		assert.GreaterOrEqual(t, delay, full/2)
+		assert.LessOrEqual(t, delay, full)
+	}
+	assert.GreaterOrEqual(t, c.backoff(40, 0), maxBackoff/2)
+	assert.LessOrEqual(t, c.backoff(40, 0), maxBackoff)
+	assert.Equal(t, 2*time.Second, c.backoff(0, 2*time.Second))
+	assert.Equal(t, maxBackoff, c.backoff(0, time.Minute))
+	assert.Equal(t, time.Duration(0), (&Client{}).backoff(3, 0))
+
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("Wed, 21 Oct 2015 07:28:00 GMT"))
+}
+
+// TestIdentity tests that the API key, session, token and client ID reach the server
+func TestIdentity(t *testing.T) {
+	store := storage.NewMemory()
+	store.SetVariable("key:secret", "x", 41)
+	var clientID, authorization string
+	capture := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clientID = r.Header.Get(ClientHeader)
+			authorization = r.Header.Get(AuthorizationHeader)
+			next.ServeHTTP(w, r)
+		})
+	}
+	c := newTestClient(setupServer(t, store, capture))
+	ctx := context.Background()
+
+	_, err := c.Eval(ctx, "x + 1")
+	assert.ErrorIs(t, err, ErrUndefinedVariable)
+	assert.Equal(t, DefaultClientID, clientID)
+
+	c.APIKey = "secret"
+	result, err := c.Eval(ctx, "x + 1")
+	require.NoError(t, err)
+	assert.Equal(t, 42.0, result)
+	require.Len(t, store.History("key:secret", 0), 1)
+
+	// The test server accepts no bearer tokens
+	c.Token = "token"
+	_, err = c.Eval(ctx, "x + 1")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.Equal(t, "Bearer token", authorization)
+
+	assert.Equal(t, calculator.APIKeyHeader, APIKeyHeader)
+	assert.Equal(t, calculator.SessionHeader, SessionHeader)
+	assert.Equal(t, calculator.ClientHeader, ClientHeader)
+	assert.Equal(t, calculator.IdempotencyKeyHeader, IdempotencyKeyHeader)
+	assert.Equal(t, calculator.AuthorizationHeader, AuthorizationHeader)
+}
+
+// TestIdempotentRetries tests that retrying a call whose response was lost does not apply it twice
+func TestIdempotentRetries(t *testing.T) {
+	store := storage.NewMemory()
+	var requests atomic.Int64
+	keys := make(chan string, 2)
+	// The first request reaches the server but its response is replaced by a 502
+	loseFirstResponse := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keys <- r.Header.Get(IdempotencyKeyHeader)
+			if requests.Add(1) == 1 {
+				next.ServeHTTP(httptest.NewRecorder(), r)
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	c := newTestClient(setupServer(t, store, loseFirstResponse))
+	c.APIKey = "alice"
+
+	result, err := c.Add(context.Background(), 2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, result)
+	assert.Equal(t, int64(2), requests.Load())
+	first, second := <-keys, <-keys
+	assert.NotEmpty(t, first)
+	assert.Equal(t, first, second)
+	assert.Len(t, store.History("key:alice", 0), 1)
+}
+
+// blockingRecorder records a response whose first write waits until release is closed
+type blockingRecorder struct {
+	*httptest.ResponseRecorder
+	writing chan struct{}
+	release chan struct{}
+}
+
+func (w *blockingRecorder) Write(b []byte) (int, error) {
+	close(w.writing)
+	<-w.release
+	return w.ResponseRecorder.Write(b)
+}
+
+// TestInFlightRetries tests that a retry arriving while the first attempt is still processed waits
+// for its response
+func TestInFlightRetries(t *testing.T) {
+	store := storage.NewMemory()
+	var requests atomic.Int64
+	first := &blockingRecorder{ResponseRecorder: httptest.NewRecorder(), writing: make(chan struct{}), release: make(chan struct{})}
+	done := make(chan struct{})
+	// The first attempt is still being processed when the client gives up on it and retries; it
+	// completes after the retry was answered with a conflict
+	slowFirst := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch requests.Add(1) {
+			case 1:
+				go func() {
+					defer close(done)
+					next.ServeHTTP(first, r.WithContext(context.Background()))
+				}()
+				<-first.writing
+				w.WriteHeader(http.StatusBadGateway)
+			case 2:
+				next.ServeHTTP(w, r)
+				close(first.release)
+				<-done
+			default:
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+	c := newTestClient(setupServer(t, store, slowFirst))
+	c.APIKey = "alice"
+
+	result, err := c.Add(context.Background(), 2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, result)
+	assert.Equal(t, int64(3), requests.Load())
+	assert.Len(t, store.History("key:alice", 0), 1)
+
+}
+
+// TestErrorIs tests matching errors by code
+func TestErrorIs(t *testing.T) {
+	err := &Error{StatusCode: http.StatusBadRequest, Code: core.CodeDomainError, Message: "out of range"}
+	assert.True(t, errors.Is(err, ErrDomainError))
+	assert.False(t, errors.Is(err, ErrInvalidInput))
+	assert.False(t, errors.Is(&Error{Message: "server responded 502 Bad Gateway"}, &Error{}))
+	assert.EqualError(t, &Error{Message: "server responded 502 Bad Gateway"}, "server responded 502 Bad Gateway")
+}
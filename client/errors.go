@@ -0,0 +1,58 @@
+package client
+
+import (
+	"fmt"
+
+	"calculator/core"
+)
+
+// Error is an error response from the server. It matches the sentinel errors below with errors.Is and
+// unwraps to a *core.Error, so callers handle remote and in-process failures alike.
+type Error struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int
+	// Code is the stable error code, one of the core.Code constants; empty when the response had none
+	Code    string
+	Message string
+	Details map[string]any
+}
+
+// Sentinel errors for each error code, for use with errors.Is
+var (
+	ErrInvalidInput      = &Error{Code: core.CodeInvalidInput}
+	ErrDivisionByZero    = &Error{Code: core.CodeDivisionByZero}
+	ErrDomainError       = &Error{Code: core.CodeDomainError}
+	ErrDimensionMismatch = &Error{Code: core.CodeDimensionMismatch}
+	ErrSingularMatrix    = &Error{Code: core.CodeSingularMatrix}
+	ErrNoConvergence     = &Error{Code: core.CodeNoConvergence}
+	ErrUnknownUnit       = &Error{Code: core.CodeUnknownUnit}
+	ErrIncompatibleUnits = &Error{Code: core.CodeIncompatibleUnits}
+	ErrUndefinedVariable = &Error{Code: core.CodeUndefinedVariable}
+	ErrNotFound          = &Error{Code: core.CodeNotFound}
+	ErrConflict          = &Error{Code: core.CodeConflict}
+	ErrUnknownOperation  = &Error{Code: core.CodeUnknownOperation}
+	ErrRecursionLimit    = &Error{Code: core.CodeRecursionLimit}
+	ErrRateLimited       = &Error{Code: core.CodeRateLimited}
+	ErrUnauthorized      = &Error{Code: core.CodeUnauthorized}
+	ErrForbidden         = &Error{Code: core.CodeForbidden}
+	ErrInternal          = &Error{Code: core.CodeInternal}
+)
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, e.Code)
+}
+
+// Is reports whether target is an *Error with the same code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
+// Unwrap returns the error as a *core.Error
+func (e *Error) Unwrap() error {
+	return &core.Error{Code: e.Code, Message: e.Message, Details: e.Details}
+}
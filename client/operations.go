@@ -0,0 +1,337 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"calculator/core"
+)
+
+// result is the response of endpoints returning a single result
+type result[T any] struct {
+	Result T `json:"result"`
+}
+
+// Conversion is the result of a unit conversion
+type Conversion struct {
+	Result   float64 `json:"result"`
+	Unit     string  `json:"unit"`
+	Category string  `json:"category"`
+}
+
+// RationalResult is an exact rational mode result. Numerator and Denominator are decimal strings since
+// they may exceed float64 precision; Approximate is set when an operand or the operation was not exact.
+type RationalResult struct {
+	Result      float64 `json:"result"`
+	Numerator   string  `json:"numerator"`
+	Denominator string  `json:"denominator"`
+	Fraction    string  `json:"fraction"`
+	Mixed       string  `json:"mixed"`
+	Decimal     string  `json:"decimal"`
+	Approximate bool    `json:"approximate"`
+}
+
+// Calculation is one operation of a batch
+type Calculation struct {
+	// Operation is an operation name such as "add" or "sqrt", as listed by core.OperationNames
+	Operation string
+	Operands  []float64
+}
+
+// BatchResult is the outcome of one calculation of a batch; Err is nil on success
+type BatchResult struct {
+	Result float64
+	Err    error
+}
+
+// batchCalculation is one operation of a batch request
+type batchCalculation struct {
+	Operation string   `json:"operation"`
+	A         *float64 `json:"a,omitempty"`
+	B         *float64 `json:"b,omitempty"`
+}
+
+// batchResponse is the response of the batch endpoint: each result, or the error fields of the error envelope
+type batchResponse struct {
+	Results []struct {
+		Result  *float64       `json:"result"`
+		Error   string         `json:"error"`
+		Code    string         `json:"code"`
+		Details map[string]any `json:"details"`
+	} `json:"results"`
+}
+
+// post sends body to the API path and returns the "result" field of the response
+func post[T any](ctx context.Context, c *Client, path string, body any) (T, error) {
+	var response result[T]
+	err := c.do(ctx, http.MethodPost, path, body, &response)
+	return response.Result, err
+}
+
+// postResponse sends body to the API path and decodes the whole response
+func postResponse[T any](ctx context.Context, c *Client, path string, body any) (T, error) {
+	var response T
+	err := c.do(ctx, http.MethodPost, path, body, &response)
+	return response, err
+}
+
+// binary applies the binary operation at path to a and b
+func (c *Client) binary(ctx context.Context, path string, a, b float64) (float64, error) {
+	return post[float64](ctx, c, path, map[string]float64{"a": a, "b": b})
+}
+
+// unary applies the unary operation at path to a
+func (c *Client) unary(ctx context.Context, path string, a float64) (float64, error) {
+	return post[float64](ctx, c, path, map[string]float64{"a": a})
+}
+
+// Operation applies the named operation to operands. Unary operations take one operand and binary
+// operations two, except sqrt, whose second operand is optional.
+func (c *Client) Operation(ctx context.Context, name string, operands ...float64) (float64, error) {
+	if !core.IsOperation(name) {
+		return 0, &Error{StatusCode: http.StatusNotFound, Code: core.CodeUnknownOperation, Message: fmt.Sprintf("unknown operation '%s'", name)}
+	}
+	if err := core.CheckOperands(name, len(operands)); err != nil {
+		calcErr := core.AsError(err)
+		return 0, &Error{Code: calcErr.Code, Message: calcErr.Message, Details: calcErr.Details}
+	}
+	if len(operands) == 1 {
+		return c.unary(ctx, name, operands[0])
+	}
+	return c.binary(ctx, name, operands[0], operands[1])
+}
+
+// Batch applies calculations in order with a single request to the batch endpoint, reporting each
+// outcome. A calculation with the wrong number of operands fails without being sent; the error is
+// only for the request as a whole, such as a network error or a caller without the compute permission.
+func (c *Client) Batch(ctx context.Context, calculations []Calculation) ([]BatchResult, error) {
+	results := make([]BatchResult, len(calculations))
+	var sent []int
+	var items []batchCalculation
+	for i, calculation := range calculations {
+		// Unknown operations are left for the server to report
+		if core.IsOperation(calculation.Operation) {
+			if err := core.CheckOperands(calculation.Operation, len(calculation.Operands)); err != nil {
+				calcErr := core.AsError(err)
+				results[i].Err = &Error{Code: calcErr.Code, Message: calcErr.Message, Details: calcErr.Details}
+				continue
+			}
+		}
+		item := batchCalculation{Operation: calculation.Operation}
+		if len(calculation.Operands) > 0 {
+			item.A = &calculation.Operands[0]
+		}
+		if len(calculation.Operands) > 1 {
+			item.B = &calculation.Operands[1]
+		}
+		sent = append(sent, i)
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return results, nil
+	}
+
+	response, err := postResponse[batchResponse](ctx, c, "batch", map[string]any{"calculations": items})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Results) != len(items) {
+		return nil, fmt.Errorf("batch returned %d results for %d calculations", len(response.Results), len(items))
+	}
+	for j, i := range sent {
+		item := response.Results[j]
+		if item.Result == nil {
+			results[i].Err = &Error{Code: item.Code, Message: item.Error, Details: item.Details}
+			continue
+		}
+		results[i].Result = *item.Result
+	}
+	return results, nil
+}
+
+// Eval evaluates an arithmetic expression with the built-in functions and constants and the caller's
+// variables and functions
+func (c *Client) Eval(ctx context.Context, expression string) (float64, error) {
+	return post[float64](ctx, c, "eval", map[string]string{"expression": expression})
+}
+
+// Add returns a + b
+func (c *Client) Add(ctx context.Context, a, b float64) (float64, error) {
+	return c.binary(ctx, "add", a, b)
+}
+
+// Subtract returns a - b
+func (c *Client) Subtract(ctx context.Context, a, b float64) (float64, error) {
+	return c.binary(ctx, "subtract", a, b)
+}
+
+// Multiply returns a * b
+func (c *Client) Multiply(ctx context.Context, a, b float64) (float64, error) {
+	return c.binary(ctx, "multiply", a, b)
+}
+
+// Divide returns a / b
+func (c *Client) Divide(ctx context.Context, a, b float64) (float64, error) {
+	return c.binary(ctx, "divide", a, b)
+}
+
+// Percentage returns b percent of a
+func (c *Client) Percentage(ctx context.Context, a, b float64) (float64, error) {
+	return c.binary(ctx, "percentage", a, b)
+}
+
+// Power returns a raised to b
+func (c *Client) Power(ctx context.Context, a, b float64) (float64, error) {
+	return c.binary(ctx, "power", a, b)
+}
+
+// Sqrt returns the square root of a
+func (c *Client) Sqrt(ctx context.Context, a float64) (float64, error) {
+	return c.unary(ctx, "sqrt", a)
+}
+
+// Root returns the bth root of a
+func (c *Client) Root(ctx context.Context, a, b float64) (float64, error) {
+	return c.binary(ctx, "root", a, b)
+}
+
+// Inverse returns 1 / a
+func (c *Client) Inverse(ctx context.Context, a float64) (float64, error) {
+	return c.unary(ctx, "inverse", a)
+}
+
+// Negative returns -a
+func (c *Client) Negative(ctx context.Context, a float64) (float64, error) {
+	return c.unary(ctx, "negative", a)
+}
+
+// PercentChange returns the percentage change from a to b
+func (c *Client) PercentChange(ctx context.Context, a, b float64) (float64, error) {
+	return c.binary(ctx, "percent-change", a, b)
+}
+
+// Markup returns price a marked up by b percent
+func (c *Client) Markup(ctx context.Context, a, b float64) (float64, error) {
+	return c.binary(ctx, "markup", a, b)
+}
+
+// Discount returns price a discounted by b percent
+func (c *Client) Discount(ctx context.Context, a, b float64) (float64, error) {
+	return c.binary(ctx, "discount", a, b)
+}
+
+// Dot returns the dot product of vectors a and b
+func (c *Client) Dot(ctx context.Context, a, b []float64) (float64, error) {
+	return post[float64](ctx, c, "vector/dot", map[string][]float64{"a": a, "b": b})
+}
+
+// Cross returns the cross product of the 3-dimensional vectors a and b
+func (c *Client) Cross(ctx context.Context, a, b []float64) ([]float64, error) {
+	return post[[]float64](ctx, c, "vector/cross", map[string][]float64{"a": a, "b": b})
+}
+
+// Norm returns the Euclidean norm of vector a
+func (c *Client) Norm(ctx context.Context, a []float64) (float64, error) {
+	return post[float64](ctx, c, "vector/norm", map[string][]float64{"a": a})
+}
+
+// MatrixAdd returns the sum of matrices a and b
+func (c *Client) MatrixAdd(ctx context.Context, a, b [][]float64) ([][]float64, error) {
+	return post[[][]float64](ctx, c, "matrix/add", map[string][][]float64{"a": a, "b": b})
+}
+
+// MatrixMultiply returns the matrix product a*b
+func (c *Client) MatrixMultiply(ctx context.Context, a, b [][]float64) ([][]float64, error) {
+	return post[[][]float64](ctx, c, "matrix/multiply", map[string][][]float64{"a": a, "b": b})
+}
+
+// Transpose returns the transpose of matrix a
+func (c *Client) Transpose(ctx context.Context, a [][]float64) ([][]float64, error) {
+	return post[[][]float64](ctx, c, "matrix/transpose", map[string][][]float64{"a": a})
+}
+
+// Determinant returns the determinant of the square matrix a
+func (c *Client) Determinant(ctx context.Context, a [][]float64) (float64, error) {
+	return post[float64](ctx, c, "matrix/determinant", map[string][][]float64{"a": a})
+}
+
+// MatrixInverse returns the inverse of the square matrix a
+func (c *Client) MatrixInverse(ctx context.Context, a [][]float64) ([][]float64, error) {
+	return post[[][]float64](ctx, c, "matrix/inverse", map[string][][]float64{"a": a})
+}
+
+// Rank returns the rank of matrix a
+func (c *Client) Rank(ctx context.Context, a [][]float64) (int, error) {
+	rank, err := post[float64](ctx, c, "matrix/rank", map[string][][]float64{"a": a})
+	return int(rank), err
+}
+
+// Solve solves the linear system a*x = b for x
+func (c *Client) Solve(ctx context.Context, a [][]float64, b []float64) ([]float64, error) {
+	return post[[]float64](ctx, c, "matrix/solve", map[string]any{"a": a, "b": b})
+}
+
+// CompoundInterest compounds principal at the annual rate compoundsPerYear times a year for years
+func (c *Client) CompoundInterest(ctx context.Context, principal, rate, years float64, compoundsPerYear int) (core.CompoundInterestResult, error) {
+	var response core.CompoundInterestResult
+	err := c.do(ctx, http.MethodPost, "finance/compound-interest", map[string]any{
+		"principal": principal, "rate": rate, "years": years, "compounds_per_year": compoundsPerYear,
+	}, &response)
+	return response, err
+}
+
+// FutureValue returns the future value of tv
+func (c *Client) FutureValue(ctx context.Context, tv core.TimeValue) (float64, error) {
+	return post[float64](ctx, c, "finance/future-value", tv)
+}
+
+// PresentValue returns the present value of tv
+func (c *Client) PresentValue(ctx context.Context, tv core.TimeValue) (float64, error) {
+	return post[float64](ctx, c, "finance/present-value", tv)
+}
+
+// Payment returns the periodic payment (PMT) of tv
+func (c *Client) Payment(ctx context.Context, tv core.TimeValue) (float64, error) {
+	return post[float64](ctx, c, "finance/payment", tv)
+}
+
+// NPV returns the net present value of cashFlows discounted at rate.
+// cashFlows[0] occurs at time zero and is not discounted.
+func (c *Client) NPV(ctx context.Context, rate float64, cashFlows []float64) (float64, error) {
+	return post[float64](ctx, c, "finance/npv", map[string]any{"rate": rate, "cash_flows": cashFlows})
+}
+
+// IRR returns the internal rate of return of cashFlows, searching from guess
+func (c *Client) IRR(ctx context.Context, cashFlows []float64, guess float64) (float64, error) {
+	return post[float64](ctx, c, "finance/irr", map[string]any{"cash_flows": cashFlows, "guess": guess})
+}
+
+// Amortization returns the schedule repaying principal at rate per period over periods payments
+func (c *Client) Amortization(ctx context.Context, principal, rate float64, periods int) (core.AmortizationSchedule, error) {
+	var response core.AmortizationSchedule
+	err := c.do(ctx, http.MethodPost, "finance/amortization", map[string]any{
+		"principal": principal, "rate": rate, "periods": periods,
+	}, &response)
+	return response, err
+}
+
+// Convert converts value from one unit to another, such as "km/h" to "m/s"
+func (c *Client) Convert(ctx context.Context, value float64, from, to string) (Conversion, error) {
+	var response Conversion
+	err := c.do(ctx, http.MethodPost, "convert", map[string]any{"value": value, "from": from, "to": to}, &response)
+	return response, err
+}
+
+// Rational applies the rational mode operation to operands exactly. Operands are fractions ("1/3"),
+// mixed numbers ("-2 1/3"), decimals, constants, variables ("$x") or "ans"; unary operations take one.
+func (c *Client) Rational(ctx context.Context, operation string, operands ...string) (RationalResult, error) {
+	body := make(map[string]any, 2)
+	for i, name := range []string{"a", "b"} {
+		if i < len(operands) {
+			body[name] = operands[i]
+		}
+	}
+	return postResponse[RationalResult](ctx, c, "rational/"+url.PathEscape(operation), body)
+}
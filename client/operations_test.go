@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"calculator/core"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestScalarOperations tests the arithmetic and percentage operations
+func TestScalarOperations(t *testing.T) {
+	c := newTestClient(setupServer(t, nil, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		call   func() (float64, error)
+		result float64
+	}{
+		{"add", func() (float64, error) { return c.Add(ctx, 2, 3) }, 5},
+		{"subtract", func() (float64, error) { return c.Subtract(ctx, 2, 3) }, -1},
+		{"multiply", func() (float64, error) { return c.Multiply(ctx, 2, 3) }, 6},
+		{"divide", func() (float64, error) { return c.Divide(ctx, 3, 2) }, 1.5},
+		{"percentage", func() (float64, error) { return c.Percentage(ctx, 200, 15) }, 30},
+		{"power", func() (float64, error) { return c.Power(ctx, 2, 10) }, 1024},
+		{"sqrt", func() (float64, error) { return c.Sqrt(ctx, 16) }, 4},
+		{"root", func() (float64, error) { return c.Root(ctx, 27, 3) }, 3},
+		{"inverse", func() (float64, error) { return c.Inverse(ctx, 4) }, 0.25},
+		{"negative", func() (float64, error) { return c.Negative(ctx, 2) }, -2},
+		{"percent change", func() (float64, error) { return c.PercentChange(ctx, 50, 75) }, 50},
+		{"markup", func() (float64, error) { return c.Markup(ctx, 100, 20) }, 120},
+		{"discount", func() (float64, error) { return c.Discount(ctx, 80, 25) }, 60},
+		{"eval", func() (float64, error) { return c.Eval(ctx, "2*(3+4)") }, 14},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.call()
+			require.NoError(t, err)
+			assert.InDelta(t, tt.result, result, 1e-9)
+		})
+	}
+}
+
+// TestOperation tests applying operations by name
+func TestOperation(t *testing.T) {
+	c := newTestClient(setupServer(t, nil, nil))
+	ctx := context.Background()
+
+	for _, name := range core.OperationNames() {
+		t.Run(name, func(t *testing.T) {
+			operands := []float64{8, 2}
+			if _, isUnary := core.UnaryOperation(name); isUnary {
+				operands = operands[:1]
+			}
+			expected, err := core.Apply(ctx, name, operands...)
+			require.NoError(t, err)
+			result, err := c.Operation(ctx, name, operands...)
+			require.NoError(t, err)
+			assert.InDelta(t, expected, result, 1e-9)
+		})
+	}
+
+	_, err := c.Operation(ctx, "frobnicate", 1, 2)
+	assert.ErrorIs(t, err, ErrUnknownOperation)
+	_, err = c.Operation(ctx, "add", 1)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+// TestBatch tests applying several operations in order with one request
+func TestBatch(t *testing.T) {
+	var requests atomic.Int64
+	c := newTestClient(setupServer(t, nil, failFirst(0, http.StatusOK, &requests)))
+
+	results, err := c.Batch(context.Background(), []Calculation{
+		{Operation: "add", Operands: []float64{2, 3}},
+		{Operation: "divide", Operands: []float64{1, 0}},
+		{Operation: "negative", Operands: []float64{1, 2}},
+		{Operation: "modulo", Operands: []float64{1, 2}},
+		{Operation: "sqrt", Operands: []float64{9}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), requests.Load())
+	require.Len(t, results, 5)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, 5.0, results[0].Result)
+	assert.ErrorIs(t, results[1].Err, ErrDivisionByZero)
+	assert.ErrorIs(t, results[2].Err, ErrInvalidInput)
+	assert.ErrorIs(t, results[3].Err, ErrUnknownOperation)
+	assert.NoError(t, results[4].Err)
+	assert.Equal(t, 3.0, results[4].Result)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.Batch(ctx, []Calculation{{Operation: "add", Operands: []float64{1, 2}}})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestLinearAlgebra tests the vector and matrix operations
+func TestLinearAlgebra(t *testing.T) {
+	c := newTestClient(setupServer(t, nil, nil))
+	ctx := context.Background()
+	a := [][]float64{{4, 7}, {2, 6}}
+
+	dot, err := c.Dot(ctx, []float64{1, 2, 3}, []float64{4, 5, 6})
+	require.NoError(t, err)
+	assert.Equal(t, 32.0, dot)
+
+	cross, err := c.Cross(ctx, []float64{1, 0, 0}, []float64{0, 1, 0})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0, 0, 1}, cross)
+
+	norm, err := c.Norm(ctx, []float64{3, 4})
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, norm)
+
+	sum, err := c.MatrixAdd(ctx, a, [][]float64{{1, 1}, {1, 1}})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{5, 8}, {3, 7}}, sum)
+
+	product, err := c.MatrixMultiply(ctx, a, [][]float64{{1}, {0}})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{4}, {2}}, product)
+
+	transposed, err := c.Transpose(ctx, a)
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{4, 2}, {7, 6}}, transposed)
+
+	det, err := c.Determinant(ctx, a)
+	require.NoError(t, err)
+	assert.InDelta(t, 10, det, 1e-9)
+
+	inverse, err := c.MatrixInverse(ctx, a)
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0.6, -0.7}, inverse[0], 1e-9)
+
+	rank, err := c.Rank(ctx, [][]float64{{1, 2}, {2, 4}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, rank)
+
+	x, err := c.Solve(ctx, a, []float64{1, 2})
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{-0.8, 0.6}, x, 1e-9)
+
+	_, err = c.MatrixInverse(ctx, [][]float64{{1, 2}, {2, 4}})
+	assert.ErrorIs(t, err, ErrSingularMatrix)
+}
+
+// TestFinance tests the financial operations
+func TestFinance(t *testing.T) {
+	c := newTestClient(setupServer(t, nil, nil))
+	ctx := context.Background()
+
+	interest, err := c.CompoundInterest(ctx, 1000, 0.05, 10, 12)
+	require.NoError(t, err)
+	assert.InDelta(t, 1647.009498, interest.Amount, 1e-6)
+
+	fv, err := c.FutureValue(ctx, core.TimeValue{Rate: 0.05, Periods: 10, PresentValue: -1000})
+	require.NoError(t, err)
+	assert.InDelta(t, 1628.894627, fv, 1e-6)
+
+	pv, err := c.PresentValue(ctx, core.TimeValue{Rate: 0.05, Periods: 10, Payment: -100})
+	require.NoError(t, err)
+	assert.InDelta(t, 772.173493, pv, 1e-6)
+
+	pmt, err := c.Payment(ctx, core.TimeValue{Rate: 0.01, Periods: 12, PresentValue: 10000, Due: true})
+	require.NoError(t, err)
+	assert.InDelta(t, -879.690977, pmt, 1e-6)
+
+	npv, err := c.NPV(ctx, 0.1, []float64{-1000, 500, 500, 500})
+	require.NoError(t, err)
+	assert.InDelta(t, 243.425995, npv, 1e-6)
+
+	irr, err := c.IRR(ctx, []float64{-1000, 500, 500, 500}, 0.1)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.233752, irr, 1e-6)
+
+	schedule, err := c.Amortization(ctx, 10000, 0.01, 12)
+	require.NoError(t, err)
+	assert.Len(t, schedule.Schedule, 12)
+	assert.InDelta(t, 888.487887, schedule.Payment, 1e-6)
+
+	_, err = c.IRR(ctx, []float64{100, 200}, 0.1)
+	assert.ErrorIs(t, err, ErrDomainError)
+}
+
+// TestConvert tests unit conversion
+func TestConvert(t *testing.T) {
+	c := newTestClient(setupServer(t, nil, nil))
+
+	conversion, err := c.Convert(context.Background(), 36, "km/h", "m/s")
+	require.NoError(t, err)
+	assert.InDelta(t, 10, conversion.Result, 1e-9)
+	assert.Equal(t, "m/s", conversion.Unit)
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"calculator/core"
+)
+
+// Session is a server-side calculator session with an accumulator, the last answer and memory registers
+type Session struct {
+	ID          string             `json:"id"`
+	Accumulator float64            `json:"accumulator"`
+	Ans         float64            `json:"ans"`
+	AnsExact    string             `json:"ans_exact,omitempty"`
+	Memory      map[string]float64 `json:"memory"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// sessionPath returns the API path of the session id followed by elem
+func sessionPath(id string, elem ...string) string {
+	path := "sessions/" + url.PathEscape(id)
+	for _, e := range elem {
+		path += "/" + url.PathEscape(e)
+	}
+	return path
+}
+
+// CreateSession creates a session. Set the client's Session field to its ID to scope variables to it.
+func (c *Client) CreateSession(ctx context.Context) (Session, error) {
+	return postResponse[Session](ctx, c, "sessions", nil)
+}
+
+// GetSession returns the session id
+func (c *Client) GetSession(ctx context.Context, id string) (Session, error) {
+	var session Session
+	err := c.do(ctx, http.MethodGet, sessionPath(id), nil, &session)
+	return session, err
+}
+
+// DeleteSession deletes the session id
+func (c *Client) DeleteSession(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, sessionPath(id), nil, nil)
+}
+
+// ClearSession resets the accumulator of the session id (AC); memory and ANS are kept
+func (c *Client) ClearSession(ctx context.Context, id string) (Session, error) {
+	return postResponse[Session](ctx, c, sessionPath(id, "clear"), nil)
+}
+
+// SetAccumulator sets the accumulator of the session id to value
+func (c *Client) SetAccumulator(ctx context.Context, id string, value float64) (Session, error) {
+	var session Session
+	err := c.do(ctx, http.MethodPut, sessionPath(id, "accumulator"), map[string]any{"value": value}, &session)
+	return session, err
+}
+
+// Memory applies the memory action MC, MR, M+, M- or MS to register (default "M") of the session id.
+// A nil value stands for the accumulator in M+, M- and MS.
+func (c *Client) Memory(ctx context.Context, id, action, register string, value *float64) (Session, error) {
+	body := map[string]any{"action": action}
+	if register != "" {
+		body["register"] = register
+	}
+	if value != nil {
+		body["value"] = *value
+	}
+	return postResponse[Session](ctx, c, sessionPath(id, "memory"), body)
+}
+
+// SessionOperation applies the named operation to the accumulator of the session id, making the result
+// the new accumulator and answer. With no operands the operation applies to the accumulator alone. An
+// operation that takes a single operand, such as negative or sqrt, applies to one operand instead; any
+// other operation combines the accumulator with it. Two operands replace the accumulator entirely.
+func (c *Client) SessionOperation(ctx context.Context, id, operation string, operands ...float64) (float64, Session, error) {
+	body := make(map[string]any, 2)
+	switch {
+	case len(operands) == 1 && core.CheckOperands(operation, 1) != nil:
+		body["b"] = operands[0]
+	case len(operands) >= 1:
+		body["a"] = operands[0]
+		if len(operands) > 1 {
+			body["b"] = operands[1]
+		}
+	}
+	var response struct {
+		Result  float64 `json:"result"`
+		Session Session `json:"session"`
+	}
+	err := c.do(ctx, http.MethodPost, sessionPath(id, "operations", operation), body, &response)
+	return response.Result, response.Session, err
+}
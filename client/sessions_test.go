@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"calculator/core"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSessions tests the session accumulator, memory registers and operations
+func TestSessions(t *testing.T) {
+	c := newTestClient(setupServer(t, nil, nil))
+	ctx := context.Background()
+
+	session, err := c.CreateSession(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, session.ID)
+
+	session, err = c.SetAccumulator(ctx, session.ID, 10)
+	require.NoError(t, err)
+	assert.InDelta(t, 10, session.Accumulator, 1e-9)
+
+	result, session, err := c.SessionOperation(ctx, session.ID, "add", 5)
+	require.NoError(t, err)
+	assert.InDelta(t, 15, result, 1e-9)
+	assert.InDelta(t, 15, session.Ans, 1e-9)
+
+	result, _, err = c.SessionOperation(ctx, session.ID, "sqrt", 16)
+	require.NoError(t, err)
+	assert.InDelta(t, 4, result, 1e-9)
+
+	result, _, err = c.SessionOperation(ctx, session.ID, "negative")
+	require.NoError(t, err)
+	assert.InDelta(t, -4, result, 1e-9)
+
+	result, _, err = c.SessionOperation(ctx, session.ID, "multiply", 2, 3)
+	require.NoError(t, err)
+	assert.InDelta(t, 6, result, 1e-9)
+
+	session, err = c.Memory(ctx, session.ID, "MS", "", nil)
+	require.NoError(t, err)
+	assert.InDelta(t, 6, session.Memory["M"], 1e-9)
+	value := 4.0
+	session, err = c.Memory(ctx, session.ID, "M+", "R1", &value)
+	require.NoError(t, err)
+	assert.InDelta(t, 4, session.Memory["R1"], 1e-9)
+
+	fetched, err := c.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, session.Memory, fetched.Memory)
+
+	session, err = c.ClearSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Zero(t, session.Accumulator)
+	assert.InDelta(t, 6, session.Memory["M"], 1e-9)
+
+	require.NoError(t, c.DeleteSession(ctx, session.ID))
+	_, err = c.GetSession(ctx, session.ID)
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, core.CodeNotFound, apiErr.Code)
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Variable is a named value the caller's expressions and operands may reference as "$name"
+type Variable struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+// Function is a user-defined function such as "hyp(a, b) = sqrt(a^2 + b^2)"
+type Function struct {
+	Name       string    `json:"name"`
+	Params     []string  `json:"params"`
+	Body       string    `json:"body"`
+	Definition string    `json:"definition"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Variables returns the caller's variables
+func (c *Client) Variables(ctx context.Context) ([]Variable, error) {
+	var response struct {
+		Variables []Variable `json:"variables"`
+	}
+	err := c.do(ctx, http.MethodGet, "variables", nil, &response)
+	return response.Variables, err
+}
+
+// GetVariable returns the value of the caller's variable name
+func (c *Client) GetVariable(ctx context.Context, name string) (float64, error) {
+	var variable Variable
+	err := c.do(ctx, http.MethodGet, "variables/"+url.PathEscape(name), nil, &variable)
+	return variable.Value, err
+}
+
+// CreateVariable creates the variable name; it fails with CONFLICT when the variable exists
+func (c *Client) CreateVariable(ctx context.Context, name string, value float64) error {
+	return c.do(ctx, http.MethodPost, "variables", Variable{Name: name, Value: value}, nil)
+}
+
+// SetVariable creates or replaces the variable name
+func (c *Client) SetVariable(ctx context.Context, name string, value float64) error {
+	return c.do(ctx, http.MethodPut, "variables/"+url.PathEscape(name), map[string]any{"value": value}, nil)
+}
+
+// DeleteVariable deletes the variable name
+func (c *Client) DeleteVariable(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "variables/"+url.PathEscape(name), nil, nil)
+}
+
+// Functions returns the caller's user-defined functions
+func (c *Client) Functions(ctx context.Context) ([]Function, error) {
+	var response struct {
+		Functions []Function `json:"functions"`
+	}
+	err := c.do(ctx, http.MethodGet, "functions", nil, &response)
+	return response.Functions, err
+}
+
+// GetFunction returns the caller's function name
+func (c *Client) GetFunction(ctx context.Context, name string) (Function, error) {
+	var fn Function
+	err := c.do(ctx, http.MethodGet, "functions/"+url.PathEscape(name), nil, &fn)
+	return fn, err
+}
+
+// DefineFunction creates the function of definition, such as "hyp(a, b) = sqrt(a^2 + b^2)"; it fails
+// with CONFLICT when a function of that name exists
+func (c *Client) DefineFunction(ctx context.Context, definition string) (Function, error) {
+	return postResponse[Function](ctx, c, "functions", map[string]any{"definition": definition})
+}
+
+// SetFunction creates or replaces the function name with definition, whose name must be name
+func (c *Client) SetFunction(ctx context.Context, name, definition string) (Function, error) {
+	var fn Function
+	err := c.do(ctx, http.MethodPut, "functions/"+url.PathEscape(name), map[string]any{"definition": definition}, &fn)
+	return fn, err
+}
+
+// DeleteFunction deletes the function name
+func (c *Client) DeleteFunction(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "functions/"+url.PathEscape(name), nil, nil)
+}
+
+// CallFunction calls the function name with args
+func (c *Client) CallFunction(ctx context.Context, name string, args ...float64) (float64, error) {
+	if args == nil {
+		args = []float64{}
+	}
+	return post[float64](ctx, c, "functions/"+url.PathEscape(name)+"/call", map[string]any{"args": args})
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"calculator/core"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVariables tests creating, listing, updating and deleting variables
+func TestVariables(t *testing.T) {
+	c := newTestClient(setupServer(t, nil, nil))
+	c.APIKey = "variables-key"
+	ctx := context.Background()
+
+	require.NoError(t, c.CreateVariable(ctx, "rate", 0.05))
+	err := c.CreateVariable(ctx, "rate", 0.06)
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+	assert.Equal(t, core.CodeConflict, apiErr.Code)
+
+	require.NoError(t, c.SetVariable(ctx, "rate", 0.07))
+	value, err := c.GetVariable(ctx, "rate")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.07, value, 1e-12)
+
+	result, err := c.Eval(ctx, "100 * rate")
+	require.NoError(t, err)
+	assert.InDelta(t, 7, result, 1e-9)
+
+	variables, err := c.Variables(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Variable{{Name: "rate", Value: 0.07}}, variables)
+
+	require.NoError(t, c.DeleteVariable(ctx, "rate"))
+	_, err = c.GetVariable(ctx, "rate")
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
+
+// TestFunctions tests defining, calling, listing and deleting user-defined functions
+func TestFunctions(t *testing.T) {
+	c := newTestClient(setupServer(t, nil, nil))
+	c.APIKey = "functions-key"
+	ctx := context.Background()
+
+	fn, err := c.DefineFunction(ctx, "hyp(a, b) = sqrt(a^2 + b^2)")
+	require.NoError(t, err)
+	assert.Equal(t, "hyp", fn.Name)
+	assert.Equal(t, []string{"a", "b"}, fn.Params)
+
+	result, err := c.CallFunction(ctx, "hyp", 3, 4)
+	require.NoError(t, err)
+	assert.InDelta(t, 5, result, 1e-9)
+
+	fn, err = c.SetFunction(ctx, "hyp", "hyp(a, b) = a + b")
+	require.NoError(t, err)
+	assert.Equal(t, "a + b", fn.Body)
+	fetched, err := c.GetFunction(ctx, "hyp")
+	require.NoError(t, err)
+	assert.Equal(t, fn.Definition, fetched.Definition)
+
+	functions, err := c.Functions(ctx)
+	require.NoError(t, err)
+	require.Len(t, functions, 1)
+	assert.Equal(t, "hyp", functions[0].Name)
+
+	require.NoError(t, c.DeleteFunction(ctx, "hyp"))
+	_, err = c.CallFunction(ctx, "hyp", 1, 2)
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, core.CodeNotFound, apiErr.Code)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"calculator/core"
+	"calculator/internal/audit"
+	"calculator/internal/calculator"
+)
+
+// clientID names the CLI in the server's calculation events feed
+const clientID = "calc"
+
+// Exit codes. Every error code the server returns has its own exit code so scripts can tell them apart.
+const (
+	exitOK          = 0
+	exitError       = 1 // unexpected failure or unrecognised error code
+	exitUsage       = 2 // invalid command line
+	exitUnavailable = 3 // the server could not be reached
+	exitTampered    = 4 // an audit log failed verification
+)
+
+// exitCodes maps the server's error codes to exit codes
+var exitCodes = map[string]int{
+	core.CodeInvalidInput:      10,
+	core.CodeDivisionByZero:    11,
+	core.CodeDomainError:       12,
+	core.CodeDimensionMismatch: 13,
+	core.CodeSingularMatrix:    14,
+	core.CodeNoConvergence:     15,
+	core.CodeUnknownUnit:       16,
+	core.CodeIncompatibleUnits: 17,
+	core.CodeUndefinedVariable: 18,
+	core.CodeNotFound:          19,
+	core.CodeConflict:          20,
+	core.CodeUnknownOperation:  21,
+	core.CodeRecursionLimit:    22,
+	core.CodeRateLimited:       23,
+	core.CodeInternal:          24,
+	core.CodeUnauthorized:      25,
+	core.CodeForbidden:         26,
+}
+
+// apiError is an error response from the server
+type apiError struct {
+	Status  int
+	Code    string
+	Message string
+	Details map[string]any
+}
+
+func (e *apiError) Error() string {
+	if e.Code == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, e.Code)
+}
+
+// usageError reports an invalid command line
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// reportedError wraps an error that has already been reported, such as the first failed line of a batch
+type reportedError struct {
+	err error
+}
+
+func (e *reportedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reportedError) Unwrap() error {
+	return e.err
+}
+
+// exitCode returns the exit code for err
+func exitCode(err error) int {
+	var apiErr *apiError
+	var calcErr *core.Error
+	var usageErr *usageError
+	var urlErr *url.Error
+	var chainErr *audit.ChainError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &apiErr):
+		if code, ok := exitCodes[apiErr.Code]; ok {
+			return code
+		}
+		return exitError
+	case errors.As(err, &calcErr):
+		if code, ok := exitCodes[calcErr.Code]; ok {
+			return code
+		}
+		return exitError
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.As(err, &urlErr):
+		return exitUnavailable
+	case errors.As(err, &chainErr):
+		return exitTampered
+	}
+	return exitError
+}
+
+// client calls the calculator HTTP API
+type client struct {
+	server  string
+	apiKey  string
+	session string
+	http    *http.Client
+}
+
+// do sends body as JSON to the API path below /api/v1 and returns the JSON response body.
+// Error responses are returned as *apiError.
+func (cl *client) do(method, path string, body any) (json.RawMessage, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(cl.server, "/")+"/api/v1/"+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set(calculator.ClientHeader, clientID)
+	if cl.apiKey != "" {
+		req.Header.Set(calculator.APIKeyHeader, cl.apiKey)
+	}
+	if cl.session != "" {
+		req.Header.Set(calculator.SessionHeader, cl.session)
+	}
+
+	resp, err := cl.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		var errResp calculator.ErrorResponse
+		if json.Unmarshal(data, &errResp) != nil || errResp.Error == "" {
+			errResp.Error = fmt.Sprintf("server responded %s", resp.Status)
+		}
+		return nil, &apiError{Status: resp.StatusCode, Code: errResp.Code, Message: errResp.Error, Details: errResp.Details}
+	}
+	return data, nil
+}
+
+// calculate runs operation, the path of a calculation endpoint such as "add" or "matrix/inverse", with body
+func (cl *client) calculate(operation string, body any) (json.RawMessage, error) {
+	data, err := cl.do(http.MethodPost, operation, body)
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound && apiErr.Code == "" {
+		// No route matched the operation
+		return nil, &apiError{Status: apiErr.Status, Code: core.CodeUnknownOperation, Message: fmt.Sprintf("unknown operation '%s'", operation)}
+	}
+	return data, err
+}
+
+// history returns up to limit of the caller's recent calculations, newest first; zero returns all
+func (cl *client) history(limit int) (calculator.HistoryResponse, error) {
+	path := "history"
+	if limit > 0 {
+		path += fmt.Sprintf("?limit=%d", limit)
+	}
+	var history calculator.HistoryResponse
+	data, err := cl.do(http.MethodGet, path, nil)
+	if err != nil {
+		return history, err
+	}
+	err = json.Unmarshal(data, &history)
+	return history, err
+}
@@ -0,0 +1,282 @@
+// Command calc is a command-line client for the calculator HTTP API.
+//
+// Usage:
+//
+//	calc [flags] <operation> a [b]     run an operation such as add, divide or sqrt
+//	calc [flags] eval <expression>     evaluate an expression such as "2*(3+4)"
+//	calc [flags] batch <file.jsonl>    run one calculation per line of a file ("-" reads standard input)
+//	calc [flags] history [-limit n]    list the caller's recent calculations
+//	calc repl [-history-file path]     calculate offline, in-process, without a server
+//	calc audit-verify <audit.log>      check the hash chain of a server's audit log offline
+//
+// Operands are numbers, or names of constants, variables or "ans". Each batch line is a JSON object
+// naming the operation and its request fields, such as {"operation": "add", "a": 2, "b": 3}, or an
+// {"expression": "..."} to evaluate. Since a line may name any endpoint, each line is sent as its own
+// request, one after another, rather than through the /batch endpoint.
+//
+// The repl command evaluates expressions, operations and assignments in-process with the calculator
+// package, keeping ans and variables between lines. On a terminal it offers line editing and recalls
+// lines from ~/.calc_history; with piped input it prints one result per line, for shell pipelines.
+//
+// The audit-verify command reads an audit log written by the server and reports the record where its
+// hash chain breaks, if any, or the head of the chain to compare with one noted earlier.
+//
+// The exit status is 0 on success, 1 on unexpected failures, 2 for invalid command lines, 3 when the
+// server cannot be reached, 4 when an audit log fails verification, and from 10 on for the error codes
+// returned by the server (see exitCodes).
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"calculator/core"
+	"calculator/internal/audit"
+	"calculator/internal/calculator"
+)
+
+const (
+	// defaultServer is the calculator server used without --server or CALC_SERVER
+	defaultServer = "http://localhost:8080"
+	// maxBatchLineBytes bounds a line of a batch file
+	maxBatchLineBytes = 1 << 20
+)
+
+const usage = `Usage:
+  calc [flags] <operation> a [b]
+  calc [flags] eval <expression>
+  calc [flags] batch <file.jsonl>
+  calc [flags] history [-limit n]
+  calc repl [-history-file path]
+  calc audit-verify <audit.log>
+
+Flags:
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// envOr returns the environment variable key, or fallback when it is unset
+func envOr(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+// run executes the command line args and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("calc", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		_, _ = fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	server := flags.String("server", envOr("CALC_SERVER", defaultServer), "calculator server URL (env CALC_SERVER)")
+	apiKey := flags.String("api-key", os.Getenv("CALC_API_KEY"), "API key scoping variables, functions and history (env CALC_API_KEY)")
+	session := flags.String("session", "", "session whose ANS operands may use")
+	output := flags.String("output", formatPlain, "output format: plain, json or table")
+	flags.StringVar(output, "o", formatPlain, "shorthand for --output")
+	timeout := flags.Duration("timeout", 30*time.Second, "request timeout")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	p := &printer{format: *output, out: stdout, errOut: stderr}
+	if !slices.Contains([]string{formatPlain, formatJSON, formatTable}, *output) {
+		p.format = formatPlain
+		p.failure(&usageError{fmt.Sprintf("unknown output format '%s'", *output)})
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	cl := &client{server: *server, apiKey: *apiKey, session: *session, http: &http.Client{Timeout: *timeout}}
+	command, rest := flags.Arg(0), flags.Args()[1:]
+	var err error
+	switch command {
+	case "eval":
+		err = runEval(cl, p, rest)
+	case "batch":
+		err = runBatch(cl, p, rest, stdin)
+	case "history":
+		err = runHistory(cl, p, rest, stderr)
+	case "repl":
+		err = runREPL(rest, stdin, stdout, stderr)
+	case "audit-verify":
+		err = runAuditVerify(p, rest)
+	default:
+		err = runOperation(cl, p, command, rest)
+	}
+	if err != nil {
+		p.failure(err)
+	}
+	return exitCode(err)
+}
+
+// operand returns arg as a number, or as a string naming a constant, variable or ans
+func operand(arg string) any {
+	if value, err := strconv.ParseFloat(arg, 64); err == nil {
+		return value
+	}
+	return arg
+}
+
+// runOperation runs a unary or binary operation on the operands in args
+func runOperation(cl *client, p *printer, operation string, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return &usageError{fmt.Sprintf("%s expects one or two operands, got %d", operation, len(args))}
+	}
+	body := map[string]any{"a": operand(args[0])}
+	if len(args) == 2 {
+		body["b"] = operand(args[1])
+	}
+	data, err := cl.calculate(operation, body)
+	if err != nil {
+		return err
+	}
+	return p.calculation(operation, strings.Join(args, " "), data)
+}
+
+// runEval evaluates the expression in args; several arguments are joined with spaces
+func runEval(cl *client, p *printer, args []string) error {
+	if len(args) == 0 {
+		return &usageError{"eval expects an expression"}
+	}
+	expression := strings.Join(args, " ")
+	data, err := cl.calculate("eval", calculator.EvalRequest{Expression: expression})
+	if err != nil {
+		return err
+	}
+	return p.calculation("eval", expression, data)
+}
+
+// runBatch runs the calculation on each line of the file in args as a separate request, in order.
+// Every line is run; the error returned is that of the first failed line.
+func runBatch(cl *client, p *printer, args []string, stdin io.Reader) error {
+	if len(args) != 1 {
+		return &usageError{"batch expects a file"}
+	}
+	input := stdin
+	if args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return &usageError{err.Error()}
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		input = file
+	}
+
+	var results []batchResult
+	var first error
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(nil, maxBatchLineBytes)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		result := runBatchLine(cl, line, text)
+		if result.err != nil && first == nil {
+			first = result.err
+		}
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := p.batch(results); err != nil {
+		return err
+	}
+	if first != nil {
+		return &reportedError{first}
+	}
+	return nil
+}
+
+// runBatchLine runs the calculation described by one line of a batch file
+func runBatchLine(cl *client, line int, text string) batchResult {
+	result := batchResult{Line: line}
+	var body map[string]any
+	if err := json.Unmarshal([]byte(text), &body); err != nil {
+		result.err = &apiError{Code: core.CodeInvalidInput, Message: fmt.Sprintf("line %d is not a JSON object", line)}
+	} else if operation, ok := body["operation"].(string); ok && operation != "" {
+		result.Operation = operation
+		delete(body, "operation")
+		result.Response, result.err = cl.calculate(operation, body)
+	} else if _, ok := body["expression"]; ok {
+		result.Operation = "eval"
+		result.Response, result.err = cl.calculate("eval", body)
+	} else {
+		result.err = &apiError{Code: core.CodeInvalidInput, Message: fmt.Sprintf("line %d has no operation or expression", line)}
+	}
+	if result.err != nil {
+		result.Error = result.err.Error()
+		var apiErr *apiError
+		if errors.As(result.err, &apiErr) {
+			result.Error, result.Code = apiErr.Message, apiErr.Code
+		}
+	}
+	return result
+}
+
+// runHistory lists the caller's recent calculations
+func runHistory(cl *client, p *printer, args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("history", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	limit := flags.Int("limit", 20, "number of calculations to list; 0 lists all")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return &usageError{err.Error()}
+	}
+	if flags.NArg() > 0 {
+		return &usageError{"history takes no arguments"}
+	}
+	history, err := cl.history(*limit)
+	if err != nil {
+		return err
+	}
+	return p.history(history.History)
+}
+
+// runAuditVerify checks the hash chain of the audit log file in args
+func runAuditVerify(p *printer, args []string) error {
+	if len(args) != 1 {
+		return &usageError{"audit-verify expects an audit log file"}
+	}
+	file, err := os.Open(args[0])
+	if err != nil {
+		return &usageError{err.Error()}
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	records, err := audit.Read(file)
+	if err != nil {
+		return err
+	}
+	if err := audit.Verify(records); err != nil {
+		return err
+	}
+	return p.auditVerified(records)
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"calculator/core"
+	"calculator/internal/audit"
+	"calculator/internal/calculator"
+	"calculator/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupServer serves the calculator routes used by the CLI
+func setupServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	s := &calculator.Service{Store: storage.NewMemory()}
+	r := gin.New()
+	api := r.Group("/api/v1")
+	api.POST("/add", s.Add)
+	api.POST("/divide", s.Divide)
+	api.POST("/sqrt", s.Sqrt)
+	api.POST("/eval", s.Eval)
+	api.POST("/matrix/determinant", s.MatrixDeterminant)
+	api.GET("/history", s.History)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// runCLI runs the CLI against server with args and returns the exit code and outputs
+func runCLI(server string, stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(append([]string{"--server", server}, args...), strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestRun tests the operation and eval commands in each output format
+func TestRun(t *testing.T) {
+	server := setupServer(t)
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{name: "binary operation", args: []string{"add", "2", "3"}, stdout: "5\n"},
+		{name: "negative operand", args: []string{"add", "-2", "0.5"}, stdout: "-1.5\n"},
+		{name: "unary operation", args: []string{"sqrt", "16"}, stdout: "4\n"},
+		{name: "constant operand", args: []string{"add", "pi", "0"}, stdout: "3.141592653589793\n"},
+		{name: "eval", args: []string{"eval", "2*(3+4)"}, stdout: "14\n"},
+		{name: "eval joins arguments", args: []string{"eval", "2", "*", "3"}, stdout: "6\n"},
+		{name: "table", args: []string{"-o", "table", "add", "2", "3"}, stdout: "OPERATION  INPUT  RESULT\nadd        2 3    5\n"},
+		{name: "json", args: []string{"--output", "json", "add", "2", "3"}, stdout: "{\n  \"result\": 5\n}\n"},
+		{name: "division by zero", args: []string{"divide", "1", "0"}, code: 11, stderr: "calc: cannot divide by zero (DIVISION_BY_ZERO)\n"},
+		{name: "undefined identifier", args: []string{"eval", "y + 1"}, code: 18},
+		{name: "unknown operation", args: []string{"frobnicate", "1", "2"}, code: 21, stderr: "calc: unknown operation 'frobnicate' (UNKNOWN_OPERATION)\n"},
+		{name: "missing operands", args: []string{"add"}, code: exitUsage, stderr: "calc: add expects one or two operands, got 0\n"},
+		{name: "missing expression", args: []string{"eval"}, code: exitUsage},
+		{name: "unknown output format", args: []string{"-o", "xml", "add", "1", "2"}, code: exitUsage},
+		{name: "no command", code: exitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runCLI(server.URL, "", tt.args...)
+			assert.Equal(t, tt.code, code, stderr)
+			if tt.code == exitOK {
+				assert.Equal(t, tt.stdout, stdout)
+			}
+			if tt.stderr != "" {
+				assert.Equal(t, tt.stderr, stderr)
+			}
+		})
+	}
+
+	t.Run("json error", func(t *testing.T) {
+		code, _, stderr := runCLI(server.URL, "", "-o", "json", "divide", "1", "0")
+		assert.Equal(t, 11, code)
+		var response calculator.ErrorResponse
+		require.NoError(t, json.Unmarshal([]byte(stderr), &response))
+		assert.Equal(t, core.CodeDivisionByZero, response.Code)
+	})
+
+	t.Run("server unavailable", func(t *testing.T) {
+		code, _, _ := runCLI("http://127.0.0.1:1", "", "add", "1", "2")
+		assert.Equal(t, exitUnavailable, code)
+	})
+}
+
+// TestBatch tests running a batch file
+func TestBatch(t *testing.T) {
+	server := setupServer(t)
+	batch := strings.Join([]string{
+		`{"operation": "add", "a": 2, "b": 3}`,
+		``,
+		`{"expression": "2^10"}`,
+		`{"operation": "divide", "a": 1, "b": 0}`,
+		`{"operation": "matrix/determinant", "a": [[1, 2], [3, 4]]}`,
+		`not json`,
+	}, "\n")
+	path := filepath.Join(t.TempDir(), "batch.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(batch), 0o600))
+
+	t.Run("plain", func(t *testing.T) {
+		code, stdout, _ := runCLI(server.URL, "", "batch", path)
+		assert.Equal(t, 11, code, "exit code of the first failed line")
+		assert.Equal(t, strings.Join([]string{
+			"5",
+			"1024",
+			"error: cannot divide by zero (DIVISION_BY_ZERO)",
+			"-2",
+			"error: line 6 is not a JSON object (INVALID_INPUT)",
+		}, "\n")+"\n", stdout)
+	})
+
+	t.Run("json from stdin", func(t *testing.T) {
+		code, stdout, _ := runCLI(server.URL, batch, "-o", "json", "batch", "-")
+		assert.Equal(t, 11, code)
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		require.Len(t, lines, 5)
+		var results []batchResult
+		for _, line := range lines {
+			var result batchResult
+			require.NoError(t, json.Unmarshal([]byte(line), &result))
+			results = append(results, result)
+		}
+		assert.Equal(t, 3, results[1].Line)
+		assert.Equal(t, "eval", results[1].Operation)
+		assert.JSONEq(t, `{"result": 1024}`, string(results[1].Response))
+		assert.Equal(t, core.CodeDivisionByZero, results[2].Code)
+		assert.Nil(t, results[2].Response)
+	})
+
+	t.Run("table", func(t *testing.T) {
+		_, stdout, _ := runCLI(server.URL, `{"operation": "add", "a": 1, "b": 1}`, "-o", "table", "batch", "-")
+		assert.Equal(t, "LINE  OPERATION  RESULT  ERROR\n1     add        2       \n", stdout)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		code, _, _ := runCLI(server.URL, `{"operation": "add", "a": 1, "b": 1}`, "batch", "-")
+		assert.Equal(t, exitOK, code)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		code, _, _ := runCLI(server.URL, "", "batch", filepath.Join(t.TempDir(), "missing.jsonl"))
+		assert.Equal(t, exitUsage, code)
+	})
+}
+
+// TestHistory tests listing the caller's history
+func TestHistory(t *testing.T) {
+	server := setupServer(t)
+	for _, args := range [][]string{{"add", "2", "3"}, {"eval", "1+1"}, {"sqrt", "9"}} {
+		code, _, stderr := runCLI(server.URL, "", append([]string{"--api-key", "alice"}, args...)...)
+		require.Equal(t, exitOK, code, stderr)
+	}
+
+	code, stdout, _ := runCLI(server.URL, "", "--api-key", "alice", "history", "-limit", "2")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "sqrt 9 0 = 3\neval = 2\n", stdout)
+
+	code, stdout, _ = runCLI(server.URL, "", "--api-key", "alice", "-o", "json", "history")
+	assert.Equal(t, exitOK, code)
+	var response calculator.HistoryResponse
+	require.NoError(t, json.Unmarshal([]byte(stdout), &response))
+	assert.Len(t, response.History, 3)
+
+	code, stdout, _ = runCLI(server.URL, "", "--api-key", "bob", "history")
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, stdout)
+
+	code, _, _ = runCLI(server.URL, "", "history")
+	assert.Equal(t, 10, code, "history needs an API key or session")
+}
+
+// TestExitCode tests mapping errors to exit codes
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, exitOK, exitCode(nil))
+	assert.Equal(t, 11, exitCode(&apiError{Code: core.CodeDivisionByZero}))
+	assert.Equal(t, exitError, exitCode(&apiError{Code: "SOMETHING_NEW"}))
+	assert.Equal(t, exitUsage, exitCode(&usageError{"bad"}))
+
+	// Exit codes are distinct
+	seen := map[int]string{}
+	for code, exit := range exitCodes {
+		assert.NotContains(t, seen, exit, code)
+		seen[exit] = code
+	}
+}
+
+// TestAuditVerify tests checking an audit log offline
+func TestAuditVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	trail := audit.New(file, 0)
+	for _, actor := range []string{"key:alice", "key:bob", "key:alice"} {
+		_, err := trail.Append(audit.Record{Actor: actor, Action: audit.ActionCalculation, Resource: "add", Status: 200})
+		require.NoError(t, err)
+	}
+	require.NoError(t, file.Close())
+	_, head := trail.Head()
+
+	code, stdout, _ := runCLI("", "", "audit-verify", path)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "3 records verified, head 3 "+head+"\n", stdout)
+
+	code, stdout, _ = runCLI("", "", "-o", "json", "audit-verify", path)
+	assert.Equal(t, exitOK, code)
+	assert.JSONEq(t, `{"records": 3, "head_seq": 3, "head_hash": "`+head+`"}`, stdout)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, bytes.Replace(data, []byte("key:bob"), []byte("key:eve"), 1), 0600))
+	code, _, stderr := runCLI("", "", "audit-verify", path)
+	assert.Equal(t, exitTampered, code)
+	assert.Equal(t, "calc: audit record 2: hash does not match the record's contents\n", stderr)
+
+	code, _, _ = runCLI("", "", "audit-verify")
+	assert.Equal(t, exitUsage, code)
+}
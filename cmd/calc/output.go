@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"calculator/internal/audit"
+	"calculator/internal/storage"
+)
+
+// Output formats
+const (
+	formatPlain = "plain"
+	formatJSON  = "json"
+	formatTable = "table"
+)
+
+// batchResult is the outcome of one line of a batch file
+type batchResult struct {
+	Line      int             `json:"line"`
+	Operation string          `json:"operation"`
+	Response  json.RawMessage `json:"response,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Code      string          `json:"code,omitempty"`
+
+	err error
+}
+
+// formatNumber formats a result with the fewest digits that represent it exactly
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// resultText returns the text of a successful response: its formatted result, its result with the unit,
+// or the whole compact response for endpoints without a single result
+func resultText(data json.RawMessage) string {
+	var response struct {
+		Result    json.RawMessage `json:"result"`
+		Unit      string          `json:"unit"`
+		Formatted string          `json:"formatted"`
+	}
+	if err := json.Unmarshal(data, &response); err == nil {
+		if response.Formatted != "" {
+			return response.Formatted
+		}
+		var result float64
+		if json.Unmarshal(response.Result, &result) == nil {
+			return strings.TrimSpace(formatNumber(result) + " " + response.Unit)
+		}
+	}
+	var compact bytes.Buffer
+	if json.Compact(&compact, data) != nil {
+		return string(data)
+	}
+	return compact.String()
+}
+
+// printer writes results in the chosen output format
+type printer struct {
+	format string
+	out    io.Writer
+	errOut io.Writer
+}
+
+// writeJSON writes v as indented JSON
+func (p *printer) writeJSON(v any) error {
+	encoder := json.NewEncoder(p.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// calculation writes the response to a calculation of operation on input
+func (p *printer) calculation(operation, input string, data json.RawMessage) error {
+	switch p.format {
+	case formatJSON:
+		return p.writeJSON(data)
+	case formatTable:
+		w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "OPERATION\tINPUT\tRESULT")
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", operation, input, resultText(data))
+		return w.Flush()
+	}
+	_, err := fmt.Fprintln(p.out, resultText(data))
+	return err
+}
+
+// failure reports err on the error output, as a JSON error response in the JSON format
+func (p *printer) failure(err error) {
+	var reported *reportedError
+	if errors.As(err, &reported) {
+		return
+	}
+	var apiErr *apiError
+	if p.format == formatJSON && errors.As(err, &apiErr) {
+		encoder := json.NewEncoder(p.errOut)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(map[string]any{"error": apiErr.Message, "code": apiErr.Code, "details": apiErr.Details})
+		return
+	}
+	_, _ = fmt.Fprintf(p.errOut, "calc: %v\n", err)
+}
+
+// batch writes the outcome of every line of a batch file. The JSON format writes one object per line.
+func (p *printer) batch(results []batchResult) error {
+	switch p.format {
+	case formatJSON:
+		encoder := json.NewEncoder(p.out)
+		for _, result := range results {
+			if err := encoder.Encode(result); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatTable:
+		w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "LINE\tOPERATION\tRESULT\tERROR")
+		for _, result := range results {
+			text := ""
+			if result.Response != nil {
+				text = resultText(result.Response)
+			}
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", result.Line, result.Operation, text, result.Error)
+		}
+		return w.Flush()
+	}
+	for _, result := range results {
+		var err error
+		if result.Response != nil {
+			_, err = fmt.Fprintln(p.out, resultText(result.Response))
+		} else {
+			_, err = fmt.Fprintf(p.out, "error: %v\n", result.err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// operandsText returns operands separated by spaces
+func operandsText(operands []float64) string {
+	texts := make([]string, len(operands))
+	for i, operand := range operands {
+		texts[i] = formatNumber(operand)
+	}
+	return strings.Join(texts, " ")
+}
+
+// history writes calculation history entries
+func (p *printer) history(entries []storage.HistoryEntry) error {
+	switch p.format {
+	case formatJSON:
+		return p.writeJSON(map[string]any{"history": entries})
+	case formatTable:
+		w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "TIME\tOPERATION\tOPERANDS\tRESULT")
+		for _, entry := range entries {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.CreatedAt.Local().Format(time.DateTime), entry.Operation,
+				operandsText(entry.Operands), strings.TrimSpace(formatNumber(entry.Result)+" "+entry.Unit))
+		}
+		return w.Flush()
+	}
+	for _, entry := range entries {
+		line := strings.TrimSpace(entry.Operation + " " + operandsText(entry.Operands))
+		if _, err := fmt.Fprintf(p.out, "%s = %s\n", line, strings.TrimSpace(formatNumber(entry.Result)+" "+entry.Unit)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditVerified reports an audit log whose hash chain is intact, with the head of the chain
+func (p *printer) auditVerified(records []audit.Record) error {
+	var head audit.Record
+	if len(records) > 0 {
+		head = records[len(records)-1]
+	}
+	switch p.format {
+	case formatJSON:
+		return p.writeJSON(map[string]any{"records": len(records), "head_seq": head.Seq, "head_hash": head.Hash})
+	case formatTable:
+		w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "RECORDS\tHEAD SEQ\tHEAD HASH")
+		_, _ = fmt.Fprintf(w, "%d\t%d\t%s\n", len(records), head.Seq, head.Hash)
+		return w.Flush()
+	}
+	_, err := fmt.Fprintf(p.out, "%d records verified, head %d %s\n", len(records), head.Seq, head.Hash)
+	return err
+}
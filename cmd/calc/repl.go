@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"calculator/core"
+
+	"golang.org/x/term"
+)
+
+const (
+	// replPrompt is shown before each line of an interactive session
+	replPrompt = "> "
+	// historyFileName is the history file in the home directory used without -history-file
+	historyFileName = ".calc_history"
+	// maxHistoryLines bounds the lines the line editor recalls
+	maxHistoryLines = 1000
+	// ansName is the variable holding the last result
+	ansName = "ans"
+)
+
+const replHelp = `Enter an expression such as 2*(3+4), an operation such as "power 2 10" or an
+assignment such as "rate = 0.05". Every result becomes ans. Operation operands are
+separated by spaces and may be numbers, variables, constants or expressions without spaces.
+
+Commands:
+  :vars   list variables
+  :ops    list operations
+  :help   show this help
+  :quit   leave (also quit, exit or Ctrl-D)
+`
+
+// assignmentPattern matches "name = expression"
+var assignmentPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.+)$`)
+
+// repl evaluates lines in-process with the core package, keeping ANS and variables between lines
+type repl struct {
+	vars map[string]float64
+}
+
+// newREPL returns a REPL with ans set to 0 and no variables
+func newREPL() *repl {
+	return &repl{vars: map[string]float64{ansName: 0}}
+}
+
+// evaluate evaluates a line: an assignment, an operation with space-separated operands, or an expression.
+// The result becomes ans.
+func (r *repl) evaluate(line string) (float64, error) {
+	ctx := context.Background()
+	var result float64
+	var err error
+	fields := strings.Fields(line)
+	if match := assignmentPattern.FindStringSubmatch(line); match != nil {
+		name := match[1]
+		if name == ansName {
+			return 0, &usageError{"cannot assign to ans"}
+		}
+		if err := core.ValidateVariableName(name); err != nil {
+			return 0, err
+		}
+		if result, err = core.Eval(ctx, match[2], r.vars); err != nil {
+			return 0, err
+		}
+		r.vars[name] = result
+	} else if len(fields) > 1 && core.IsOperation(fields[0]) {
+		operands := make([]float64, len(fields)-1)
+		for i, field := range fields[1:] {
+			if operands[i], err = core.Eval(ctx, field, r.vars); err != nil {
+				return 0, err
+			}
+		}
+		if result, err = core.Apply(ctx, fields[0], operands...); err != nil {
+			return 0, err
+		}
+	} else if result, err = core.Eval(ctx, line, r.vars); err != nil {
+		return 0, err
+	}
+	r.vars[ansName] = result
+	return result, nil
+}
+
+// command runs a REPL command, returning its output and whether the session ends. ok is false for
+// lines that are not commands.
+func (r *repl) command(line string) (output string, quit, ok bool) {
+	switch line {
+	case ":quit", ":q", "quit", "exit":
+		return "", true, true
+	case ":help":
+		return replHelp, false, true
+	case ":ops":
+		return strings.Join(core.OperationNames(), " ") + "\n", false, true
+	case ":vars":
+		names := make([]string, 0, len(r.vars))
+		for name := range r.vars {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		var b strings.Builder
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s = %s\n", name, formatNumber(r.vars[name]))
+		}
+		return b.String(), false, true
+	}
+	if strings.HasPrefix(line, ":") {
+		return fmt.Sprintf("unknown command '%s'; try :help\n", line), false, true
+	}
+	return "", false, false
+}
+
+// errorText describes err with its error code
+func errorText(err error) string {
+	var calcErr *core.Error
+	if errors.As(err, &calcErr) {
+		return fmt.Sprintf("%s (%s)", calcErr.Message, calcErr.Code)
+	}
+	return err.Error()
+}
+
+// runREPL runs the calculator in-process. On a terminal it is an interactive session with line editing
+// and a history file; otherwise it reads lines from stdin, writes one result per line and returns the
+// error of the first failed line, for shell pipelines.
+func runREPL(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("repl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	historyFile := flags.String("history-file", defaultHistoryFile(), "file recalling lines between sessions; empty disables it")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return &usageError{err.Error()}
+	}
+	if flags.NArg() > 0 {
+		return &usageError{"repl takes no arguments"}
+	}
+
+	r := newREPL()
+	if file, ok := stdin.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		return r.interactive(file, stdout, *historyFile)
+	}
+	return r.pipe(stdin, stdout, stderr)
+}
+
+// defaultHistoryFile returns the history file in the home directory, or "" without one
+func defaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, historyFileName)
+}
+
+// pipe evaluates the lines of in, writing results to out and errors to errOut
+func (r *repl) pipe(in io.Reader, out, errOut io.Writer) error {
+	var first error
+	scanner := bufio.NewScanner(in)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if output, quit, ok := r.command(line); ok {
+			if quit {
+				break
+			}
+			_, _ = io.WriteString(out, output)
+			continue
+		}
+		result, err := r.evaluate(line)
+		if err != nil {
+			_, _ = fmt.Fprintf(errOut, "calc: line %d: %s\n", number, errorText(err))
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintln(out, formatNumber(result)); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if first != nil {
+		return &reportedError{first}
+	}
+	return nil
+}
+
+// interactive runs a line-editing session on the terminal in
+func (r *repl) interactive(in *os.File, out io.Writer, historyFile string) error {
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = term.Restore(int(in.Fd()), state)
+	}()
+
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{in, out}, replPrompt)
+	if historyFile != "" {
+		history, err := openHistory(historyFile)
+		if err != nil {
+			_, _ = fmt.Fprintf(t, "calc: history not kept: %v\n", err)
+		} else {
+			defer history.Close()
+			t.History = history
+		}
+	}
+
+	_, _ = fmt.Fprintln(t, "calc offline mode; :help lists commands")
+	for {
+		line, err := t.ReadLine()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if output, quit, ok := r.command(line); ok {
+			if quit {
+				return nil
+			}
+			_, _ = io.WriteString(t, output)
+			continue
+		}
+		if result, err := r.evaluate(line); err != nil {
+			_, _ = fmt.Fprintf(t, "error: %s\n", errorText(err))
+		} else {
+			_, _ = fmt.Fprintln(t, formatNumber(result))
+		}
+	}
+}
+
+// fileHistory is the line editor's history, also appending each line to a file so that it is recalled
+// in later sessions
+type fileHistory struct {
+	entries []string // oldest first
+	file    *os.File
+}
+
+// openHistory loads the last lines of the history file at path and opens it for appending
+func openHistory(path string) (*fileHistory, error) {
+	h := &fileHistory{}
+	if data, err := os.ReadFile(path); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				h.entries = append(h.entries, line)
+			}
+		}
+		h.trim()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	h.file = file
+	return h, nil
+}
+
+// trim drops the oldest entries beyond maxHistoryLines
+func (h *fileHistory) trim() {
+	if len(h.entries) > maxHistoryLines {
+		h.entries = slices.Clone(h.entries[len(h.entries)-maxHistoryLines:])
+	}
+}
+
+// Add records entry unless it repeats the previous line
+func (h *fileHistory) Add(entry string) {
+	if entry = strings.TrimSpace(entry); entry == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	h.trim()
+	_, _ = fmt.Fprintln(h.file, entry)
+}
+
+// Len returns the number of entries
+func (h *fileHistory) Len() int {
+	return len(h.entries)
+}
+
+// At returns the entry idx lines back; 0 is the most recent
+func (h *fileHistory) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
+
+// Close closes the history file
+func (h *fileHistory) Close() {
+	_ = h.file.Close()
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"calculator/core"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestREPLEvaluate tests evaluating assignments, operations and expressions with ans and variables
+func TestREPLEvaluate(t *testing.T) {
+	r := newREPL()
+
+	tests := []struct {
+		line   string
+		result float64
+		code   string
+	}{
+		{line: "2*(3+4)", result: 14},
+		{line: "ans + 1", result: 15},
+		{line: "rate = 0.5", result: 0.5},
+		{line: "ans * 4", result: 2},
+		{line: "power 2 10", result: 1024},
+		{line: "add ans rate*2", result: 1025},
+		{line: "sqrt 16", result: 4},
+		{line: "negative ans", result: -4},
+		{line: "sqrt(ans^2)", result: 4},
+		{line: "divide 1 0", code: core.CodeDivisionByZero},
+		{line: "ans", result: 4},
+		{line: "y + 1", code: core.CodeUndefinedVariable},
+		{line: "pi = 3", code: core.CodeInvalidInput},
+		{line: "add 1", code: core.CodeInvalidInput},
+		{line: "1 +", code: core.CodeInvalidInput},
+	}
+	for _, tt := range tests {
+		result, err := r.evaluate(tt.line)
+		if tt.code != "" {
+			var calcErr *core.Error
+			require.ErrorAs(t, err, &calcErr, tt.line)
+			assert.Equal(t, tt.code, calcErr.Code, tt.line)
+			continue
+		}
+		require.NoError(t, err, tt.line)
+		assert.InDelta(t, tt.result, result, 1e-9, tt.line)
+	}
+
+	_, err := r.evaluate("ans = 1")
+	assert.Equal(t, exitUsage, exitCode(err))
+}
+
+// TestREPLCommands tests the REPL commands
+func TestREPLCommands(t *testing.T) {
+	r := newREPL()
+	_, err := r.evaluate("k = 3")
+	require.NoError(t, err)
+
+	output, quit, ok := r.command(":vars")
+	assert.True(t, ok)
+	assert.False(t, quit)
+	assert.Equal(t, "ans = 3\nk = 3\n", output)
+
+	output, _, _ = r.command(":ops")
+	assert.Contains(t, output, "add")
+
+	output, _, ok = r.command(":nope")
+	assert.True(t, ok)
+	assert.Contains(t, output, "unknown command")
+
+	_, quit, _ = r.command("exit")
+	assert.True(t, quit)
+
+	_, _, ok = r.command("1 + 1")
+	assert.False(t, ok)
+}
+
+// TestREPLPipe tests the one-shot mode reading lines from stdin
+func TestREPLPipe(t *testing.T) {
+	input := strings.Join([]string{
+		"# comment",
+		"x = 2",
+		"",
+		"x * 3",
+		"divide ans 0",
+		"ans / 2",
+		":quit",
+		"1 + 1",
+	}, "\n")
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"repl"}, strings.NewReader(input), &stdout, &stderr)
+	assert.Equal(t, 11, code, "exit code of the first failed line")
+	assert.Equal(t, "2\n6\n3\n", stdout.String())
+	assert.Equal(t, "calc: line 5: cannot divide by zero (DIVISION_BY_ZERO)\n", stderr.String())
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"repl"}, strings.NewReader("1+1\n"), &stdout, &stderr)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "2\n", stdout.String())
+	assert.Empty(t, stderr.String())
+
+	code = run([]string{"repl", "extra"}, strings.NewReader(""), &stdout, &stderr)
+	assert.Equal(t, exitUsage, code)
+}
+
+// TestFileHistory tests the line editor history kept in a file
+func TestFileHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	require.NoError(t, os.WriteFile(path, []byte("1+1\n\n2+2\n"), 0o600))
+
+	h, err := openHistory(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, h.Len())
+	assert.Equal(t, "2+2", h.At(0))
+	assert.Equal(t, "1+1", h.At(1))
+
+	h.Add("3+3")
+	h.Add("3+3")
+	h.Add(" ")
+	assert.Equal(t, 3, h.Len())
+	assert.Equal(t, "3+3", h.At(0))
+	h.Close()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "1+1\n\n2+2\n3+3\n", string(data))
+
+	for i := range maxHistoryLines + 5 {
+		h.entries = append(h.entries, strings.Repeat("x", i%3+1))
+	}
+	h.trim()
+	assert.Equal(t, maxHistoryLines, h.Len())
+}
@@ -0,0 +1,237 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// BinaryFunc is an operation on two operands
+type BinaryFunc func(ctx context.Context, a, b float64) (float64, error)
+
+// UnaryFunc is an operation on one operand
+type UnaryFunc func(ctx context.Context, a float64) (float64, error)
+
+// binaryOperations are the binary operations keyed by their endpoint name
+var binaryOperations = map[string]BinaryFunc{
+	"add":            Add,
+	"subtract":       Subtract,
+	"multiply":       Multiply,
+	"divide":         Divide,
+	"percentage":     Percentage,
+	"power":          Power,
+	"sqrt":           Sqrt,
+	"root":           Root,
+	"percent-change": PercentChange,
+	"markup":         Markup,
+	"discount":       Discount,
+}
+
+// unaryOperations are the unary operations keyed by their endpoint name
+var unaryOperations = map[string]UnaryFunc{
+	"inverse":  Inverse,
+	"negative": Negative,
+}
+
+// BinaryOperation returns the named binary operation
+func BinaryOperation(name string) (BinaryFunc, bool) {
+	op, ok := binaryOperations[name]
+	return op, ok
+}
+
+// UnaryOperation returns the named unary operation
+func UnaryOperation(name string) (UnaryFunc, bool) {
+	op, ok := unaryOperations[name]
+	return op, ok
+}
+
+// IsOperation reports whether name is a built-in binary or unary operation
+func IsOperation(name string) bool {
+	_, isBinary := binaryOperations[name]
+	_, isUnary := unaryOperations[name]
+	return isBinary || isUnary
+}
+
+// OperationNames returns the sorted names of all binary and unary operations
+func OperationNames() []string {
+	names := make([]string, 0, len(binaryOperations)+len(unaryOperations))
+	for name := range binaryOperations {
+		names = append(names, name)
+	}
+	for name := range unaryOperations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UnknownOperationError returns the error reported for names that are not operations
+func UnknownOperationError(name string) *Error {
+	return NewError(CodeUnknownOperation, fmt.Sprintf("unknown operation '%s'", name)).
+		WithDetail("name", name).
+		WithDetail("available", OperationNames())
+}
+
+// Apply applies the named operation to operands. Unary operations take one operand and binary
+// operations two, except sqrt, whose second operand is optional.
+func Apply(ctx context.Context, name string, operands ...float64) (float64, error) {
+	binary, isBinary := binaryOperations[name]
+	unary, isUnary := unaryOperations[name]
+	if !isBinary && !isUnary {
+		logger(ctx).Error("Unknown operation", "name", name)
+		return 0, UnknownOperationError(name)
+	}
+	if err := CheckOperands(name, len(operands)); err != nil {
+		return 0, err
+	}
+	if isUnary {
+		return unary(ctx, operands[0])
+	}
+	b := 0.0
+	if len(operands) == 2 {
+		b = operands[1]
+	}
+	return binary(ctx, operands[0], b)
+}
+
+// CheckOperands checks that the named operation takes n operands: one for unary operations and two
+// for binary operations, except sqrt, whose second operand is optional
+func CheckOperands(name string, n int) error {
+	_, isUnary := unaryOperations[name]
+	if n == 0 || n > 2 || (isUnary && n == 2) || (!isUnary && name != "sqrt" && n == 1) {
+		return NewError(CodeInvalidInput, fmt.Sprintf("%s takes %s, got %d", name, operandCount(name, isUnary), n)).
+			WithDetail("name", name).
+			WithDetail("operands", n)
+	}
+	return nil
+}
+
+// operandCount describes the number of operands an operation takes
+func operandCount(name string, isUnary bool) string {
+	switch {
+	case isUnary:
+		return "one operand"
+	case name == "sqrt":
+		return "one or two operands"
+	}
+	return "two operands"
+}
+
+// Add returns a + b
+func Add(ctx context.Context, a, b float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing addition", "a", a, "b", b)
+	result := a + b
+	log.Debug("Addition result", "result", result)
+	return result, nil
+}
+
+// Subtract returns a - b
+func Subtract(ctx context.Context, a, b float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing subtraction", "a", a, "b", b)
+	result := a - b
+	log.Debug("Subtraction result", "result", result)
+	return result, nil
+}
+
+// Multiply returns a * b
+func Multiply(ctx context.Context, a, b float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing multiplication", "a", a, "b", b)
+	result := a * b
+	log.Debug("Multiplication result", "result", result)
+	return result, nil
+}
+
+// Divide returns a / b, failing with CodeDivisionByZero when b is zero
+func Divide(ctx context.Context, a, b float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing division", "a", a, "b", b)
+	if b == 0 {
+		log.Error("Division by zero attempted", "a", a, "b", b)
+		return 0, NewError(CodeDivisionByZero, "cannot divide by zero")
+	}
+	result := a / b
+	log.Debug("Division result", "result", result)
+	return result, nil
+}
+
+// Percentage returns b percent of a
+func Percentage(ctx context.Context, a, b float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing percentage", "a", a, "b", b)
+	result := a * (b / 100)
+	log.Debug("Percentage result", "result", result)
+	return result, nil
+}
+
+// Power returns a raised to b
+func Power(ctx context.Context, a, b float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing power operation", "a", a, "b", b)
+	result := math.Pow(a, b)
+	log.Debug("Power result", "result", result)
+	return result, nil
+}
+
+// Sqrt returns the square root of a; b is ignored so that it fits the binary operations
+func Sqrt(ctx context.Context, a, _ float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing square root", "a", a)
+	if a < 0 {
+		log.Error("Square root of negative number attempted", "a", a)
+		return 0, NewError(CodeDomainError, "cannot calculate square root of negative number")
+	}
+	result := math.Sqrt(a)
+	log.Debug("Square root result", "result", result)
+	return result, nil
+}
+
+// Root returns the bth root of a. Negative a has only odd roots.
+func Root(ctx context.Context, a, b float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing nth root", "a", a, "b", b)
+	if b == 0 {
+		log.Error("Zeroth root attempted", "a", a, "b", b)
+		return 0, NewError(CodeDomainError, "cannot calculate 0th root")
+	}
+	if a < 0 {
+		// Check if the root is odd (can handle negative numbers)
+		if math.Mod(b, 2) == 1 {
+			// Odd root of negative number
+			log.Debug("Performing odd root of negative number", "a", a, "b", b)
+			result := -math.Pow(-a, 1/b)
+			log.Debug("Odd root of negative result", "result", result)
+			return result, nil
+		}
+		log.Error("Even root of negative number attempted", "a", a, "b", b)
+		return 0, NewError(CodeDomainError, "cannot calculate even root of negative number")
+	}
+	result := math.Pow(a, 1/b)
+	log.Debug("Nth root result", "result", result)
+	return result, nil
+}
+
+// Inverse returns 1 / a, failing with CodeDivisionByZero when a is zero
+func Inverse(ctx context.Context, a float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing inverse", "a", a)
+	if a == 0 {
+		log.Error("Inverse of zero attempted", "a", a)
+		return 0, NewError(CodeDivisionByZero, "cannot calculate inverse of zero")
+	}
+	result := 1 / a
+	log.Debug("Inverse result", "result", result)
+	return result, nil
+}
+
+// Negative returns -a
+func Negative(ctx context.Context, a float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing negation", "a", a)
+	result := -a
+	log.Debug("Negation result", "result", result)
+	return result, nil
+}
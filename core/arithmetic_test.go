@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOperations tests the arithmetic operations
+func TestOperations(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		op     BinaryFunc
+		a, b   float64
+		result float64
+		code   string
+	}{
+		{"add", Add, 2, 3, 5, ""},
+		{"subtract", Subtract, 2, 3, -1, ""},
+		{"multiply", Multiply, 2, 3, 6, ""},
+		{"divide", Divide, 3, 2, 1.5, ""},
+		{"divide by zero", Divide, 1, 0, 0, CodeDivisionByZero},
+		{"percentage", Percentage, 200, 15, 30, ""},
+		{"power", Power, 2, 10, 1024, ""},
+		{"sqrt", Sqrt, 16, 0, 4, ""},
+		{"sqrt of negative", Sqrt, -4, 0, 0, CodeDomainError},
+		{"root", Root, 27, 3, 3, ""},
+		{"odd root of negative", Root, -8, 3, -2, ""},
+		{"even root of negative", Root, -16, 4, 0, CodeDomainError},
+		{"zeroth root", Root, 8, 0, 0, CodeDomainError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.op(ctx, tt.a, tt.b)
+			if tt.code != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.code, AsError(err).Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.result, result, 1e-9)
+		})
+	}
+
+	result, err := Negative(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, -2.0, result)
+	_, err = Inverse(ctx, 0)
+	assert.Equal(t, CodeDivisionByZero, AsError(err).Code)
+}
+
+// TestApply tests applying operations by name
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		operation string
+		operands  []float64
+		result    float64
+		code      string
+	}{
+		{name: "binary", operation: "add", operands: []float64{2, 3}, result: 5},
+		{name: "unary", operation: "negative", operands: []float64{2}, result: -2},
+		{name: "sqrt with one operand", operation: "sqrt", operands: []float64{16}, result: 4},
+		{name: "finance", operation: "markup", operands: []float64{100, 20}, result: 120},
+		{name: "operation error", operation: "divide", operands: []float64{1, 0}, code: CodeDivisionByZero},
+		{name: "unknown operation", operation: "frobnicate", operands: []float64{1, 2}, code: CodeUnknownOperation},
+		{name: "missing operand", operation: "add", operands: []float64{1}, code: CodeInvalidInput},
+		{name: "no operands", operation: "add", code: CodeInvalidInput},
+		{name: "extra operand", operation: "inverse", operands: []float64{1, 2}, code: CodeInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Apply(ctx, tt.operation, tt.operands...)
+			if tt.code != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.code, AsError(err).Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.result, result, 1e-9)
+		})
+	}
+}
+
+// TestOperationNames tests the operation registry
+func TestOperationNames(t *testing.T) {
+	names := OperationNames()
+	assert.IsIncreasing(t, names)
+	assert.Len(t, names, len(binaryOperations)+len(unaryOperations))
+	assert.True(t, IsOperation("percent-change"))
+	assert.False(t, IsOperation("frobnicate"))
+
+	_, ok := UnaryOperation("inverse")
+	assert.True(t, ok)
+	_, ok = BinaryOperation("inverse")
+	assert.False(t, ok)
+
+	err := UnknownOperationError("frobnicate")
+	assert.Equal(t, "frobnicate", err.Details["name"])
+	assert.Equal(t, names, err.Details["available"])
+}
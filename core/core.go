@@ -0,0 +1,35 @@
+// Package core is the calculator's math, independent of any transport: arithmetic, financial and
+// linear-algebra operations, built-in constants, expression evaluation, unit conversion, exact
+// rational arithmetic, numeric calculus, plotting and the polynomial and linear-system solvers. The
+// HTTP, gRPC, GraphQL and WebSocket APIs, the calc command-line client and batch jobs all call these
+// functions.
+//
+// Every function takes a context. Long-running calculations stop when it is done and return its
+// error. Debug logs go to the logger attached with WithLogger. Failures are *Error values carrying
+// a stable code.
+package core
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// loggerKey is the context key of the logger attached by WithLogger
+type loggerKey struct{}
+
+// discard is the logger used when the context carries none
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// WithLogger returns a copy of ctx whose calculations log to logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// logger returns the logger attached to ctx, or a no-op logger
+func logger(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return discard
+}
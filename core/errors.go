@@ -0,0 +1,68 @@
+package core
+
+import "errors"
+
+// Stable error codes carried by every error the calculator returns
+const (
+	CodeInvalidInput      = "INVALID_INPUT"
+	CodeDivisionByZero    = "DIVISION_BY_ZERO"
+	CodeDomainError       = "DOMAIN_ERROR"
+	CodeDimensionMismatch = "DIMENSION_MISMATCH"
+	CodeSingularMatrix    = "SINGULAR_MATRIX"
+	CodeNoConvergence     = "NO_CONVERGENCE"
+	CodeUnknownUnit       = "UNKNOWN_UNIT"
+	CodeIncompatibleUnits = "INCOMPATIBLE_UNITS"
+	CodeUndefinedVariable = "UNDEFINED_VARIABLE"
+	CodeNotFound          = "NOT_FOUND"
+	CodeConflict          = "CONFLICT"
+	CodeUnknownOperation  = "UNKNOWN_OPERATION"
+	CodeRecursionLimit    = "RECURSION_LIMIT"
+	CodeRateLimited       = "RATE_LIMITED"
+	CodeUnauthorized      = "UNAUTHORIZED"
+	CodeForbidden         = "FORBIDDEN"
+	CodeInternal          = "INTERNAL_ERROR"
+)
+
+// Error is a calculator error carrying a stable, machine-readable code
+type Error struct {
+	Code    string
+	Message string
+	Details map[string]any
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewError creates a calculator error with the given code and message
+func NewError(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// WithDetail attaches a detail key/value to the error and returns it
+func (e *Error) WithDetail(key string, value any) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]any)
+	}
+	e.Details[key] = value
+	return e
+}
+
+// Extensions returns the code and details in the form GraphQL servers expose as error extensions
+func (e *Error) Extensions() map[string]any {
+	extensions := map[string]any{"code": e.Code}
+	if len(e.Details) > 0 {
+		extensions["details"] = e.Details
+	}
+	return extensions
+}
+
+// AsError converts any error into a calculator error, defaulting to CodeInvalidInput
+func AsError(err error) *Error {
+	var calcErr *Error
+	if errors.As(err, &calcErr) {
+		return calcErr
+	}
+	return NewError(CodeInvalidInput, err.Error())
+}
@@ -0,0 +1,21 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAsError tests converting errors into calculator errors
+func TestAsError(t *testing.T) {
+	calcErr := NewError(CodeDomainError, "out of range").WithDetail("param", "a")
+	assert.Same(t, calcErr, AsError(fmt.Errorf("wrapped: %w", calcErr)))
+	assert.Equal(t, map[string]any{"code": CodeDomainError, "details": map[string]any{"param": "a"}}, calcErr.Extensions())
+
+	plain := AsError(errors.New("bad request"))
+	assert.Equal(t, CodeInvalidInput, plain.Code)
+	assert.Equal(t, "bad request", plain.Error())
+	assert.Equal(t, map[string]any{"code": CodeInvalidInput}, plain.Extensions())
+}
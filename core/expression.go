@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+
+	"calculator/internal/expr"
+)
+
+// Constant is a built-in named value
+type Constant struct {
+	Name        string  `json:"name"`
+	Value       float64 `json:"value"`
+	Description string  `json:"description"`
+}
+
+// constants lists the built-in constants; names are case-sensitive
+var constants = map[string]Constant{
+	"pi":    {"pi", math.Pi, "ratio of a circle's circumference to its diameter"},
+	"tau":   {"tau", 2 * math.Pi, "2*pi"},
+	"e":     {"e", math.E, "Euler's number"},
+	"phi":   {"phi", math.Phi, "golden ratio"},
+	"sqrt2": {"sqrt2", math.Sqrt2, "square root of 2"},
+	"ln2":   {"ln2", math.Ln2, "natural logarithm of 2"},
+	"ln10":  {"ln10", math.Ln10, "natural logarithm of 10"},
+	"c":     {"c", 299792458, "speed of light in vacuum (m/s)"},
+	"G":     {"G", 6.67430e-11, "Newtonian constant of gravitation (m^3/(kg*s^2))"},
+	"g":     {"g", 9.80665, "standard acceleration of gravity (m/s^2)"},
+	"h":     {"h", 6.62607015e-34, "Planck constant (J*s)"},
+	"k_B":   {"k_B", 1.380649e-23, "Boltzmann constant (J/K)"},
+	"N_A":   {"N_A", 6.02214076e23, "Avogadro constant (1/mol)"},
+	"e_c":   {"e_c", 1.602176634e-19, "elementary charge (C)"},
+}
+
+// identifierPattern matches valid variable and constant names
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LookupConstant returns the named built-in constant
+func LookupConstant(name string) (Constant, bool) {
+	constant, ok := constants[name]
+	return constant, ok
+}
+
+// Constants returns the built-in constants sorted by name
+func Constants() []Constant {
+	list := make([]Constant, 0, len(constants))
+	for _, constant := range constants {
+		list = append(list, constant)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// ValidateVariableName checks that name is an identifier that does not shadow a constant
+func ValidateVariableName(name string) error {
+	if !identifierPattern.MatchString(name) {
+		return NewError(CodeInvalidInput, fmt.Sprintf("invalid variable name '%s'", name)).WithDetail("name", name)
+	}
+	if _, ok := constants[name]; ok {
+		return NewError(CodeInvalidInput, fmt.Sprintf("cannot redefine constant '%s'", name)).WithDetail("name", name)
+	}
+	return nil
+}
+
+// ExpressionError converts an expression parse or evaluation error into a calculator error
+func ExpressionError(err error) error {
+	var e *expr.Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	code := CodeInvalidInput
+	switch e.Kind {
+	case expr.ErrUndefined:
+		code = CodeUndefinedVariable
+	case expr.ErrDivisionByZero:
+		code = CodeDivisionByZero
+	case expr.ErrDomain:
+		code = CodeDomainError
+	case expr.ErrRecursion:
+		code = CodeRecursionLimit
+	}
+	calcErr := NewError(code, e.Error())
+	if e.Pos >= 0 {
+		calcErr.WithDetail("position", e.Pos)
+	}
+	return calcErr
+}
+
+// variableEnv resolves identifiers to variables, then to constants. It has no functions besides the builtins.
+type variableEnv map[string]float64
+
+// Lookup returns the value of a variable or constant
+func (env variableEnv) Lookup(name string) (float64, bool) {
+	if value, ok := env[name]; ok {
+		return value, true
+	}
+	if constant, ok := constants[name]; ok {
+		return constant.Value, true
+	}
+	return 0, false
+}
+
+// Call reports every function that is not a builtin as undefined
+func (env variableEnv) Call(name string, _ []float64) (float64, error) {
+	return 0, &expr.Error{Kind: expr.ErrUndefined, Pos: -1, Msg: fmt.Sprintf("undefined function '%s'", name)}
+}
+
+// Eval evaluates an arithmetic expression such as "2*(3+x)" using the built-in functions. Identifiers
+// resolve to vars, then to constants.
+func Eval(ctx context.Context, expression string, vars map[string]float64) (float64, error) {
+	node, err := expr.Parse(expression)
+	if err != nil {
+		return 0, ExpressionError(err)
+	}
+	logger(ctx).Debug("Evaluating expression", "expression", expression)
+	result, err := expr.Eval(node, variableEnv(vars))
+	if err != nil {
+		return 0, ExpressionError(err)
+	}
+	return result, nil
+}
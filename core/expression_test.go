@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEval tests evaluating expressions
+func TestEval(t *testing.T) {
+	vars := map[string]float64{"ans": 10, "k": 3}
+
+	tests := []struct {
+		name       string
+		expression string
+		result     float64
+		code       string
+	}{
+		{name: "arithmetic", expression: "2*(3+4)", result: 14},
+		{name: "variables", expression: "ans / 2 + k", result: 8},
+		{name: "constants and builtins", expression: "2 * cos(pi)", result: -2},
+		{name: "undefined", expression: "y + 1", code: CodeUndefinedVariable},
+		{name: "undefined function", expression: "f(1)", code: CodeUndefinedVariable},
+		{name: "syntax error", expression: "(1", code: CodeInvalidInput},
+		{name: "domain error", expression: "sqrt(-1)", code: CodeDomainError},
+		{name: "division by zero", expression: "1/0", code: CodeDivisionByZero},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Eval(context.Background(), tt.expression, vars)
+			if tt.code != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.code, AsError(err).Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.result, result, 1e-9)
+		})
+	}
+}
+
+// TestConstants tests the built-in constants and variable names
+func TestConstants(t *testing.T) {
+	list := Constants()
+	require.NotEmpty(t, list)
+	assert.IsIncreasing(t, []string{list[0].Name, list[len(list)-1].Name})
+
+	pi, ok := LookupConstant("pi")
+	assert.True(t, ok)
+	assert.InDelta(t, 3.14159265, pi.Value, 1e-8)
+	_, ok = LookupConstant("PI")
+	assert.False(t, ok, "names are case-sensitive")
+
+	assert.NoError(t, ValidateVariableName("rate_2"))
+	assert.Equal(t, CodeInvalidInput, AsError(ValidateVariableName("2x")).Code)
+	assert.Equal(t, CodeInvalidInput, AsError(ValidateVariableName("pi")).Code)
+}
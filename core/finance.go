@@ -0,0 +1,501 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// financePrecision is the number of decimal places kept for intermediate financial results
+	financePrecision = 20
+	// irrMaxIterations bounds the IRR solver
+	irrMaxIterations = 100
+	// irrTolerance is the NPV magnitude at which the IRR solver considers a rate converged
+	irrTolerance = 1e-10
+	// growthDigits is the number of significant digits kept beyond the rate's leading zeros while raising
+	// a growth factor to a power, and in discount factors
+	growthDigits = 40
+	// maxGrowthDigits bounds the decimal exponent of a growth factor, well beyond the range of float64
+	maxGrowthDigits = 400
+	// MaxPeriods bounds the periods of a time-value problem, amortization schedule or compounding
+	MaxPeriods = 100000
+	// MaxCompoundsPerYear bounds the compounding frequency: hourly
+	MaxCompoundsPerYear = 8760
+)
+
+// CompoundInterestResult is the outcome of compounding a principal
+type CompoundInterestResult struct {
+	Amount   float64 `json:"amount"`
+	Interest float64 `json:"interest"`
+}
+
+// TimeValue describes a time-value-of-money problem (present value, future value, payment).
+// Rate is the interest rate per period as a fraction (0.05 = 5%). Cash paid out is negative and cash
+// received is positive, following spreadsheet conventions. Due selects payments at the start of each period.
+type TimeValue struct {
+	Rate         float64 `json:"rate"`
+	Periods      float64 `json:"periods"`
+	Payment      float64 `json:"payment"`
+	PresentValue float64 `json:"present_value"`
+	FutureValue  float64 `json:"future_value"`
+	Due          bool    `json:"due"`
+}
+
+// AmortizationPayment is a single row of an amortization schedule
+type AmortizationPayment struct {
+	Period    int     `json:"period"`
+	Payment   float64 `json:"payment"`
+	Principal float64 `json:"principal"`
+	Interest  float64 `json:"interest"`
+	Balance   float64 `json:"balance"`
+}
+
+// AmortizationSchedule is the repayment schedule of a loan
+type AmortizationSchedule struct {
+	Payment       float64               `json:"payment"`
+	TotalInterest float64               `json:"total_interest"`
+	Schedule      []AmortizationPayment `json:"schedule"`
+}
+
+// Decimal helpers
+
+// dec converts a float64 into a decimal
+func dec(f float64) decimal.Decimal {
+	return decimal.NewFromFloat(f)
+}
+
+// decFloat rounds d to financePrecision and converts it back to float64
+func decFloat(d decimal.Decimal) float64 {
+	if d.Exponent() < -financePrecision {
+		d = d.Round(financePrecision)
+	}
+	return d.InexactFloat64()
+}
+
+// finiteResult converts d with decFloat, failing when it is beyond the range of float64
+func finiteResult(name string, d decimal.Decimal) (float64, error) {
+	result := decFloat(d)
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		return 0, NewError(CodeDomainError, fmt.Sprintf("%s is too large to represent", name)).
+			WithDetail("result", name)
+	}
+	return result, nil
+}
+
+// quotient returns a / b, failing with CodeDomainError when b is zero, as it may become for extreme rates
+func quotient(name string, a, b decimal.Decimal) (decimal.Decimal, error) {
+	if b.IsZero() {
+		return decimal.Zero, NewError(CodeDomainError, fmt.Sprintf("%s is undefined for these inputs", name)).
+			WithDetail("result", name)
+	}
+	return a.Div(b), nil
+}
+
+// checkPeriods fails when periods exceeds MaxPeriods in magnitude
+func checkPeriods(name string, periods float64) error {
+	if math.Abs(periods) > MaxPeriods {
+		return NewError(CodeInvalidInput, fmt.Sprintf("%s must be at most %d", name, MaxPeriods)).
+			WithDetail(name, periods).
+			WithDetail("max", MaxPeriods)
+	}
+	return nil
+}
+
+// significant rounds d to digits significant digits
+func significant(d decimal.Decimal, digits int32) decimal.Decimal {
+	if d.IsZero() {
+		return d
+	}
+	return d.Round(digits - int32(d.NumDigits()) - d.Exponent())
+}
+
+// growthPrecision returns the significant digits growth keeps for rate: growthDigits beyond the leading
+// zeros of a small rate, so that (1+rate)^periods - 1 keeps growthDigits digits rather than cancelling
+func growthPrecision(rate decimal.Decimal) int32 {
+	if rate.IsZero() {
+		return growthDigits
+	}
+	return growthDigits + max(0, -int32(rate.NumDigits())-rate.Exponent())
+}
+
+// growth returns (1+rate)^periods to growthPrecision(rate) significant digits, raising the whole periods
+// by repeated squaring so that the cost grows with the logarithm of periods
+func growth(ctx context.Context, rate, periods decimal.Decimal) (decimal.Decimal, error) {
+	base := decimal.NewFromInt(1).Add(rate)
+	if !base.IsPositive() {
+		return decimal.Zero, NewError(CodeDomainError, "rate must be greater than -100%").
+			WithDetail("rate", rate.InexactFloat64())
+	}
+	digits := growthPrecision(rate)
+	whole := periods.Floor()
+	result, err := base.PowWithPrecision(periods.Sub(whole), digits)
+	if err != nil {
+		return decimal.Zero, NewError(CodeDomainError, err.Error())
+	}
+	n := whole.IntPart()
+	if n < 0 {
+		base = significant(decimal.NewFromInt(1).DivRound(base, digits-int32(base.NumDigits())-base.Exponent()), digits)
+		n = -n
+	}
+	for ; n > 0; n >>= 1 {
+		if err := ctx.Err(); err != nil {
+			return decimal.Zero, err
+		}
+		if n&1 == 1 {
+			result = significant(result.Mul(base), digits)
+		}
+		base = significant(base.Mul(base), digits)
+	}
+	if digits := int64(result.NumDigits()) + int64(result.Exponent()); result.IsZero() || digits > maxGrowthDigits || digits < -maxGrowthDigits {
+		return decimal.Zero, NewError(CodeDomainError, "growth over the periods is beyond the representable range").
+			WithDetail("rate", rate.InexactFloat64()).
+			WithDetail("periods", periods.InexactFloat64())
+	}
+	return result, nil
+}
+
+// annuityFactor returns (1+rate*due)*((1+rate)^periods-1)/rate, or periods when the growth g is one
+func annuityFactor(rate, periods, g decimal.Decimal, due bool) decimal.Decimal {
+	growth := g.Sub(decimal.NewFromInt(1))
+	if rate.IsZero() || growth.IsZero() {
+		return periods
+	}
+	factor := growth.Div(rate)
+	if due {
+		factor = factor.Mul(decimal.NewFromInt(1).Add(rate))
+	}
+	return factor
+}
+
+// CompoundInterest compounds principal at the annual rate compoundsPerYear times a year for years
+func CompoundInterest(ctx context.Context, principal, rate, years float64, compoundsPerYear int) (CompoundInterestResult, error) {
+	log := logger(ctx)
+	log.Debug("Performing compound interest", "principal", principal, "rate", rate, "years", years, "compounds_per_year", compoundsPerYear)
+	if compoundsPerYear <= 0 {
+		log.Error("Invalid compounding frequency", "compounds_per_year", compoundsPerYear)
+		return CompoundInterestResult{}, NewError(CodeInvalidInput, "compounds_per_year must be positive").
+			WithDetail("compounds_per_year", compoundsPerYear)
+	}
+	if compoundsPerYear > MaxCompoundsPerYear {
+		log.Error("Compounding frequency too high", "compounds_per_year", compoundsPerYear)
+		return CompoundInterestResult{}, NewError(CodeInvalidInput, fmt.Sprintf("compounds_per_year must be at most %d", MaxCompoundsPerYear)).
+			WithDetail("compounds_per_year", compoundsPerYear).
+			WithDetail("max", MaxCompoundsPerYear)
+	}
+	if err := checkPeriods("compounding periods", years*float64(compoundsPerYear)); err != nil {
+		log.Error("Too many compounding periods", "years", years, "compounds_per_year", compoundsPerYear)
+		return CompoundInterestResult{}, err
+	}
+	n := decimal.NewFromInt(int64(compoundsPerYear))
+	g, err := growth(ctx, dec(rate).Div(n), dec(years).Mul(n))
+	if err != nil {
+		return CompoundInterestResult{}, err
+	}
+	p := dec(principal)
+	amount := p.Mul(g)
+	var result CompoundInterestResult
+	if result.Amount, err = finiteResult("amount", amount); err != nil {
+		return CompoundInterestResult{}, err
+	}
+	if result.Interest, err = finiteResult("interest", amount.Sub(p)); err != nil {
+		return CompoundInterestResult{}, err
+	}
+	log.Debug("Compound interest result", "result", result)
+	return result, nil
+}
+
+// FutureValue returns the future value of tv
+func FutureValue(ctx context.Context, tv TimeValue) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing future value", "request", tv)
+	if err := checkPeriods("periods", tv.Periods); err != nil {
+		log.Error("Future value with too many periods attempted", "periods", tv.Periods)
+		return 0, err
+	}
+	rate, periods := dec(tv.Rate), dec(tv.Periods)
+	g, err := growth(ctx, rate, periods)
+	if err != nil {
+		return 0, err
+	}
+	// FV = -(PV*(1+r)^n + PMT*annuityFactor)
+	fv := dec(tv.PresentValue).Mul(g).Add(dec(tv.Payment).Mul(annuityFactor(rate, periods, g, tv.Due))).Neg()
+	result, err := finiteResult("future value", fv)
+	if err != nil {
+		log.Error("Future value out of range", "request", tv)
+		return 0, err
+	}
+	log.Debug("Future value result", "result", result)
+	return result, nil
+}
+
+// PresentValue returns the present value of tv
+func PresentValue(ctx context.Context, tv TimeValue) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing present value", "request", tv)
+	if err := checkPeriods("periods", tv.Periods); err != nil {
+		log.Error("Present value with too many periods attempted", "periods", tv.Periods)
+		return 0, err
+	}
+	rate, periods := dec(tv.Rate), dec(tv.Periods)
+	g, err := growth(ctx, rate, periods)
+	if err != nil {
+		return 0, err
+	}
+	// PV = -(FV + PMT*annuityFactor) / (1+r)^n
+	pv, err := quotient("present value", dec(tv.FutureValue).Add(dec(tv.Payment).Mul(annuityFactor(rate, periods, g, tv.Due))), g)
+	if err != nil {
+		log.Error("Present value undefined", "request", tv)
+		return 0, err
+	}
+	result, err := finiteResult("present value", pv.Neg())
+	if err != nil {
+		log.Error("Present value out of range", "request", tv)
+		return 0, err
+	}
+	log.Debug("Present value result", "result", result)
+	return result, nil
+}
+
+// Payment returns the periodic payment (PMT) of tv
+func Payment(ctx context.Context, tv TimeValue) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing payment", "request", tv)
+	if tv.Periods <= 0 {
+		log.Error("Payment with non-positive periods attempted", "periods", tv.Periods)
+		return 0, NewError(CodeInvalidInput, "periods must be positive").WithDetail("periods", tv.Periods)
+	}
+	if err := checkPeriods("periods", tv.Periods); err != nil {
+		log.Error("Payment with too many periods attempted", "periods", tv.Periods)
+		return 0, err
+	}
+	rate, periods := dec(tv.Rate), dec(tv.Periods)
+	g, err := growth(ctx, rate, periods)
+	if err != nil {
+		return 0, err
+	}
+	// PMT = -(PV*(1+r)^n + FV) / annuityFactor
+	pmt, err := quotient("payment", dec(tv.PresentValue).Mul(g).Add(dec(tv.FutureValue)), annuityFactor(rate, periods, g, tv.Due))
+	if err != nil {
+		log.Error("Payment undefined", "request", tv)
+		return 0, err
+	}
+	result, err := finiteResult("payment", pmt.Neg())
+	if err != nil {
+		log.Error("Payment out of range", "request", tv)
+		return 0, err
+	}
+	log.Debug("Payment result", "result", result)
+	return result, nil
+}
+
+// npvDecimal discounts cashFlows at rate, returning the net present value. Discount factors keep
+// significant digits rather than decimal places, so that they never round to zero for rates near -100%.
+func npvDecimal(ctx context.Context, rate decimal.Decimal, cashFlows []float64) (decimal.Decimal, error) {
+	base := decimal.NewFromInt(1).Add(rate)
+	if !base.IsPositive() {
+		return decimal.Zero, NewError(CodeDomainError, "rate must be greater than -100%").
+			WithDetail("rate", rate.InexactFloat64())
+	}
+	digits := growthPrecision(rate)
+	total := decimal.Zero
+	discount := decimal.NewFromInt(1)
+	for _, cf := range cashFlows {
+		if err := ctx.Err(); err != nil {
+			return decimal.Zero, err
+		}
+		value, err := quotient("net present value", dec(cf), discount)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		total = total.Add(value)
+		discount = significant(discount.Mul(base), digits)
+	}
+	return total, nil
+}
+
+// NPV returns the net present value of cashFlows discounted at rate.
+// cashFlows[0] occurs at time zero and is not discounted.
+func NPV(ctx context.Context, rate float64, cashFlows []float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing NPV", "rate", rate, "cash_flows", cashFlows)
+	if len(cashFlows) == 0 {
+		log.Error("NPV with no cash flows attempted")
+		return 0, NewError(CodeInvalidInput, "at least one cash flow is required")
+	}
+	total, err := npvDecimal(ctx, dec(rate), cashFlows)
+	if err != nil {
+		return 0, err
+	}
+	result, err := finiteResult("net present value", total)
+	if err != nil {
+		log.Error("NPV out of range", "rate", rate)
+		return 0, err
+	}
+	log.Debug("NPV result", "result", result)
+	return result, nil
+}
+
+// IRR returns the internal rate of return of cashFlows, searching from guess
+func IRR(ctx context.Context, cashFlows []float64, guess float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing IRR", "cash_flows", cashFlows, "guess", guess)
+	hasPositive, hasNegative := false, false
+	for _, cf := range cashFlows {
+		hasPositive = hasPositive || cf > 0
+		hasNegative = hasNegative || cf < 0
+	}
+	if !hasPositive || !hasNegative {
+		log.Error("IRR without sign change attempted", "cash_flows", cashFlows)
+		return 0, NewError(CodeDomainError, "cash flows must contain at least one positive and one negative value")
+	}
+
+	// Newton-Raphson on NPV(r) using the analytic derivative
+	tolerance := dec(irrTolerance)
+	rate := dec(guess)
+	for i := 0; i < irrMaxIterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		value, err := npvDecimal(ctx, rate, cashFlows)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 0, ctxErr
+		}
+		if err != nil {
+			break
+		}
+		if value.Abs().LessThan(tolerance) {
+			result := decFloat(rate)
+			log.Debug("IRR result", "result", result, "iterations", i)
+			return result, nil
+		}
+		base := decimal.NewFromInt(1).Add(rate)
+		digits := growthPrecision(rate)
+		derivative := decimal.Zero
+		discount := base
+		for t, cf := range cashFlows {
+			if t > 0 {
+				derivative = derivative.Sub(dec(cf).Mul(decimal.NewFromInt(int64(t))).Div(discount))
+				discount = significant(discount.Mul(base), digits)
+			}
+		}
+		if derivative.IsZero() {
+			break
+		}
+		rate = rate.Sub(value.Div(derivative)).Round(financePrecision)
+	}
+
+	log.Error("IRR did not converge", "cash_flows", cashFlows, "guess", guess)
+	return 0, NewError(CodeNoConvergence, fmt.Sprintf("IRR did not converge within %d iterations", irrMaxIterations)).
+		WithDetail("iterations", irrMaxIterations).
+		WithDetail("guess", guess)
+}
+
+// Amortization returns the schedule repaying principal at rate per period over periods payments
+func Amortization(ctx context.Context, principal, rate float64, periods int) (AmortizationSchedule, error) {
+	log := logger(ctx)
+	log.Debug("Performing amortization", "principal", principal, "rate", rate, "periods", periods)
+	if periods <= 0 {
+		log.Error("Amortization with non-positive periods attempted", "periods", periods)
+		return AmortizationSchedule{}, NewError(CodeInvalidInput, "periods must be positive").WithDetail("periods", periods)
+	}
+	if err := checkPeriods("periods", float64(periods)); err != nil {
+		log.Error("Amortization with too many periods attempted", "periods", periods)
+		return AmortizationSchedule{}, err
+	}
+	r := dec(rate)
+	n := decimal.NewFromInt(int64(periods))
+	g, err := growth(ctx, r, n)
+	if err != nil {
+		return AmortizationSchedule{}, err
+	}
+	balance := dec(principal)
+	payment, err := quotient("payment", balance.Mul(g), annuityFactor(r, n, g, false))
+	if err != nil {
+		log.Error("Amortization payment undefined", "principal", principal, "rate", rate, "periods", periods)
+		return AmortizationSchedule{}, err
+	}
+	payment = payment.Round(financePrecision)
+	paymentFloat, err := finiteResult("payment", payment)
+	if err != nil {
+		log.Error("Amortization payment out of range", "principal", principal, "rate", rate, "periods", periods)
+		return AmortizationSchedule{}, err
+	}
+
+	schedule := AmortizationSchedule{
+		Payment:  paymentFloat,
+		Schedule: make([]AmortizationPayment, 0, periods),
+	}
+	totalInterest := decimal.Zero
+	for period := 1; period <= periods; period++ {
+		if err := ctx.Err(); err != nil {
+			return AmortizationSchedule{}, err
+		}
+		interest := balance.Mul(r).Round(financePrecision)
+		repaid := payment.Sub(interest)
+		if period == periods {
+			// Absorb accumulated rounding in the final payment so the loan is fully repaid
+			repaid = balance
+		}
+		balance = balance.Sub(repaid)
+		totalInterest = totalInterest.Add(interest)
+		schedule.Schedule = append(schedule.Schedule, AmortizationPayment{
+			Period:    period,
+			Payment:   decFloat(repaid.Add(interest)),
+			Principal: decFloat(repaid),
+			Interest:  decFloat(interest),
+			Balance:   decFloat(balance),
+		})
+	}
+	if schedule.TotalInterest, err = finiteResult("total interest", totalInterest); err != nil {
+		log.Error("Amortization interest out of range", "principal", principal, "rate", rate, "periods", periods)
+		return AmortizationSchedule{}, err
+	}
+	log.Debug("Amortization result", "payment", schedule.Payment, "total_interest", schedule.TotalInterest)
+	return schedule, nil
+}
+
+// PercentChange returns the percentage change from a to b
+func PercentChange(ctx context.Context, a, b float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing percentage change", "a", a, "b", b)
+	if a == 0 {
+		log.Error("Percentage change from zero attempted", "a", a, "b", b)
+		return 0, NewError(CodeDivisionByZero, "cannot calculate percentage change from zero")
+	}
+	result, err := finiteResult("percentage change", dec(b).Sub(dec(a)).Div(dec(a)).Mul(decimal.NewFromInt(100)))
+	if err != nil {
+		log.Error("Percentage change out of range", "a", a, "b", b)
+		return 0, err
+	}
+	log.Debug("Percentage change result", "result", result)
+	return result, nil
+}
+
+// Markup returns price a marked up by b percent
+func Markup(ctx context.Context, a, b float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing markup", "a", a, "b", b)
+	result, err := finiteResult("markup", dec(a).Mul(decimal.NewFromInt(100).Add(dec(b))).Div(decimal.NewFromInt(100)))
+	if err != nil {
+		log.Error("Markup out of range", "a", a, "b", b)
+		return 0, err
+	}
+	log.Debug("Markup result", "result", result)
+	return result, nil
+}
+
+// Discount returns price a discounted by b percent
+func Discount(ctx context.Context, a, b float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing discount", "a", a, "b", b)
+	result, err := finiteResult("discount", dec(a).Mul(decimal.NewFromInt(100).Sub(dec(b))).Div(decimal.NewFromInt(100)))
+	if err != nil {
+		log.Error("Discount out of range", "a", a, "b", b)
+		return 0, err
+	}
+	log.Debug("Discount result", "result", result)
+	return result, nil
+}
@@ -0,0 +1,190 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTimeValue tests the time-value-of-money functions
+func TestTimeValue(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		fn     func(context.Context, TimeValue) (float64, error)
+		tv     TimeValue
+		result float64
+		code   string
+	}{
+		{"future value of deposit", FutureValue, TimeValue{Rate: 0.05, Periods: 10, PresentValue: -1000}, 1628.894627, ""},
+		{"future value zero rate", FutureValue, TimeValue{Periods: 10, Payment: -100}, 1000, ""},
+		{"future value invalid rate", FutureValue, TimeValue{Rate: -1, Periods: 10, PresentValue: -1000}, 0, CodeDomainError},
+		{"present value of annuity", PresentValue, TimeValue{Rate: 0.05, Periods: 10, Payment: -100}, 772.173493, ""},
+		{"payment for loan", Payment, TimeValue{Rate: 0.01, Periods: 12, PresentValue: 10000}, -888.487887, ""},
+		{"payment annuity due", Payment, TimeValue{Rate: 0.01, Periods: 12, PresentValue: 10000, Due: true}, -879.690977, ""},
+		{"payment zero periods", Payment, TimeValue{Rate: 0.01, PresentValue: 10000}, 0, CodeInvalidInput},
+		{"present value negative periods", PresentValue, TimeValue{Rate: 0.05, Periods: -10, FutureValue: -1000}, 1628.894627, ""},
+		{"future value too many periods", FutureValue, TimeValue{Rate: 0.05, Periods: 1e9, PresentValue: -1}, 0, CodeInvalidInput},
+		{"future value too large", FutureValue, TimeValue{Rate: 1e300, Periods: 2, PresentValue: -1}, 0, CodeDomainError},
+		{"future value overflows float64", FutureValue, TimeValue{Rate: 1, Periods: 1030, PresentValue: -1}, 0, CodeDomainError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.fn(ctx, tt.tv)
+			if tt.code != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.code, AsError(err).Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.result, result, 1e-6)
+		})
+	}
+}
+
+// TestCashFlows tests net present value and internal rate of return
+func TestCashFlows(t *testing.T) {
+	ctx := context.Background()
+	flows := []float64{-1000, 500, 500, 500}
+
+	npv, err := NPV(ctx, 0.1, flows)
+	require.NoError(t, err)
+	assert.InDelta(t, 243.425995, npv, 1e-6)
+
+	_, err = NPV(ctx, 0.1, nil)
+	assert.Equal(t, CodeInvalidInput, AsError(err).Code)
+
+	irr, err := IRR(ctx, flows, 0.1)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.233752, irr, 1e-6)
+
+	_, err = IRR(ctx, []float64{100, 200}, 0.1)
+	assert.Equal(t, CodeDomainError, AsError(err).Code)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = IRR(canceled, flows, 0.1)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestExtremeRates tests rates so small that (1+rate)^periods rounds to one at a fixed precision, and
+// rates near -100% whose discount factors round to zero at a fixed number of decimal places
+func TestExtremeRates(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		fn     func(context.Context) (float64, error)
+		result float64
+	}{
+		{"future value tiny rate", func(ctx context.Context) (float64, error) {
+			return FutureValue(ctx, TimeValue{Rate: 1e-50, Periods: 10, Payment: 1})
+		}, -10},
+		{"future value smallest rate", func(ctx context.Context) (float64, error) {
+			return FutureValue(ctx, TimeValue{Rate: 5e-324, Periods: 10, Payment: 1, Due: true})
+		}, -10},
+		{"present value tiny rate", func(ctx context.Context) (float64, error) {
+			return PresentValue(ctx, TimeValue{Rate: 1e-50, Periods: 10, Payment: -100})
+		}, 1000},
+		{"payment tiny rate", func(ctx context.Context) (float64, error) {
+			return Payment(ctx, TimeValue{Rate: 1e-50, Periods: 10, PresentValue: 1000})
+		}, -100},
+		{"payment tiny negative rate", func(ctx context.Context) (float64, error) {
+			return Payment(ctx, TimeValue{Rate: -1e-50, Periods: 10, PresentValue: 1000})
+		}, -100},
+		{"amortization tiny rate", func(ctx context.Context) (float64, error) {
+			schedule, err := Amortization(ctx, 1000, 1e-50, 10)
+			return schedule.Payment, err
+		}, 100},
+		{"npv near -100%", func(ctx context.Context) (float64, error) {
+			return NPV(ctx, -0.9999999, []float64{1, 1, 1, 1, 1})
+		}, 1.0000001000000100000001e28},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.fn(ctx)
+			require.NoError(t, err)
+			assert.InEpsilon(t, tt.result, result, 1e-12)
+		})
+	}
+}
+
+// TestCompoundInterest tests compounding a principal
+func TestCompoundInterest(t *testing.T) {
+	result, err := CompoundInterest(context.Background(), 1000, 0.05, 10, 12)
+	require.NoError(t, err)
+	assert.InDelta(t, 1647.009498, result.Amount, 1e-6)
+	assert.InDelta(t, 647.009498, result.Interest, 1e-6)
+
+	_, err = CompoundInterest(context.Background(), 1000, 0.05, 10, 0)
+	assert.Equal(t, CodeInvalidInput, AsError(err).Code)
+
+	_, err = CompoundInterest(context.Background(), 1000, 0.05, 1, 1e6)
+	require.Error(t, err)
+	assert.Equal(t, CodeInvalidInput, AsError(err).Code)
+	assert.Equal(t, MaxCompoundsPerYear, AsError(err).Details["max"])
+	_, err = CompoundInterest(context.Background(), 1000, 0.05, 1e6, 12)
+	assert.Equal(t, CodeInvalidInput, AsError(err).Code)
+}
+
+// TestFinanceLimits tests that the largest accepted inputs stay fast and honour cancellation
+func TestFinanceLimits(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now()
+	_, err := FutureValue(ctx, TimeValue{Rate: 0.0004166666666666666667, Periods: MaxPeriods - 0.5, PresentValue: -1})
+	require.NoError(t, err)
+	_, err = FutureValue(ctx, TimeValue{Rate: 0.000000000123456789, Periods: MaxPeriods, PresentValue: -1})
+	require.NoError(t, err)
+	_, err = CompoundInterest(ctx, 1000, 0.05, 10, MaxCompoundsPerYear)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+
+	_, err = Amortization(ctx, 10000, 0.01, MaxPeriods+1)
+	assert.Equal(t, CodeInvalidInput, AsError(err).Code)
+	assert.Equal(t, MaxPeriods, AsError(err).Details["max"])
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = Amortization(canceled, 10000, 0.01, MaxPeriods)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = FutureValue(canceled, TimeValue{Rate: 0.05, Periods: 10, PresentValue: -1})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestAmortization tests loan amortization schedules
+func TestAmortization(t *testing.T) {
+	schedule, err := Amortization(context.Background(), 10000, 0.01, 12)
+	require.NoError(t, err)
+	require.Len(t, schedule.Schedule, 12)
+	assert.InDelta(t, 888.487887, schedule.Payment, 1e-6)
+	assert.Equal(t, 0.0, schedule.Schedule[11].Balance)
+
+	_, err = Amortization(context.Background(), 10000, 0.01, 0)
+	assert.Equal(t, CodeInvalidInput, AsError(err).Code)
+}
+
+// TestPriceChanges tests percentage change, markup and discount
+func TestPriceChanges(t *testing.T) {
+	ctx := context.Background()
+
+	result, err := PercentChange(ctx, 50, 75)
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, result)
+	_, err = PercentChange(ctx, 0, 75)
+	assert.Equal(t, CodeDivisionByZero, AsError(err).Code)
+
+	result, err = Markup(ctx, 19.99, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 21.989, result)
+
+	result, err = Discount(ctx, 80, 25)
+	require.NoError(t, err)
+	assert.Equal(t, 60.0, result)
+
+	_, err = Markup(ctx, 1.5e308, 50)
+	assert.Equal(t, CodeDomainError, AsError(err).Code)
+}
@@ -0,0 +1,384 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// epsilon is the machine epsilon of float64, the relative rounding error of elimination steps
+const epsilon = 0x1p-52
+
+// singularTolerance returns the pivot magnitude at or below which the matrix m is treated as singular:
+// machine epsilon scaled by the matrix size and its largest entry, so that matrices of any magnitude are
+// judged alike
+func singularTolerance(m [][]float64) float64 {
+	var largest float64
+	size := len(m)
+	for _, row := range m {
+		size = max(size, len(row))
+		for _, v := range row {
+			largest = max(largest, math.Abs(v))
+		}
+	}
+	return float64(size) * epsilon * largest
+}
+
+// Dimension helpers
+
+// shape returns the number of rows and columns of m, or an error if m is empty or ragged
+func shape(name string, m [][]float64) (int, int, error) {
+	if len(m) == 0 || len(m[0]) == 0 {
+		return 0, 0, NewError(CodeDimensionMismatch, fmt.Sprintf("matrix '%s' must not be empty", name)).
+			WithDetail("matrix", name)
+	}
+	cols := len(m[0])
+	for i, row := range m {
+		if len(row) != cols {
+			return 0, 0, NewError(CodeDimensionMismatch, fmt.Sprintf("matrix '%s' has rows of different lengths", name)).
+				WithDetail("matrix", name).
+				WithDetail("row", i).
+				WithDetail("expected", cols).
+				WithDetail("actual", len(row))
+		}
+	}
+	return len(m), cols, nil
+}
+
+// squareSize returns the size of the square matrix m, or an error if m is not square
+func squareSize(name string, m [][]float64) (int, error) {
+	rows, cols, err := shape(name, m)
+	if err != nil {
+		return 0, err
+	}
+	if rows != cols {
+		return 0, NewError(CodeDimensionMismatch, fmt.Sprintf("matrix '%s' must be square", name)).
+			WithDetail("matrix", name).
+			WithDetail(name, dims(rows, cols))
+	}
+	return rows, nil
+}
+
+// dims formats matrix dimensions as "rows x cols"
+func dims(rows, cols int) string {
+	return fmt.Sprintf("%dx%d", rows, cols)
+}
+
+// cloneMatrix returns a deep copy of m so elimination does not modify caller data
+func cloneMatrix(m [][]float64) [][]float64 {
+	out := make([][]float64, len(m))
+	for i, row := range m {
+		out[i] = append([]float64(nil), row...)
+	}
+	return out
+}
+
+// Dot returns the dot product of vectors a and b
+func Dot(ctx context.Context, a, b []float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing dot product", "a", a, "b", b)
+	if len(a) == 0 || len(a) != len(b) {
+		log.Error("Dot product dimension mismatch", "a", len(a), "b", len(b))
+		return 0, NewError(CodeDimensionMismatch, "vectors must be non-empty and of equal length").
+			WithDetail("a", len(a)).
+			WithDetail("b", len(b))
+	}
+	var result float64
+	for i := range a {
+		result += a[i] * b[i]
+	}
+	log.Debug("Dot product result", "result", result)
+	return result, nil
+}
+
+// Cross returns the cross product of the 3-dimensional vectors a and b
+func Cross(ctx context.Context, a, b []float64) ([]float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing cross product", "a", a, "b", b)
+	if len(a) != 3 || len(b) != 3 {
+		log.Error("Cross product dimension mismatch", "a", len(a), "b", len(b))
+		return nil, NewError(CodeDimensionMismatch, "cross product requires two 3-dimensional vectors").
+			WithDetail("a", len(a)).
+			WithDetail("b", len(b))
+	}
+	result := []float64{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+	log.Debug("Cross product result", "result", result)
+	return result, nil
+}
+
+// Norm returns the Euclidean norm of vector a
+func Norm(ctx context.Context, a []float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing vector norm", "a", a)
+	if len(a) == 0 {
+		log.Error("Norm of empty vector attempted")
+		return 0, NewError(CodeDimensionMismatch, "vector must not be empty").WithDetail("a", 0)
+	}
+	var sum float64
+	for _, v := range a {
+		sum += v * v
+	}
+	result := math.Sqrt(sum)
+	log.Debug("Vector norm result", "result", result)
+	return result, nil
+}
+
+// MatrixAdd returns the sum of matrices a and b
+func MatrixAdd(ctx context.Context, a, b [][]float64) ([][]float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing matrix addition", "a", a, "b", b)
+	aRows, aCols, err := shape("a", a)
+	if err != nil {
+		return nil, err
+	}
+	bRows, bCols, err := shape("b", b)
+	if err != nil {
+		return nil, err
+	}
+	if aRows != bRows || aCols != bCols {
+		log.Error("Matrix addition dimension mismatch", "a", dims(aRows, aCols), "b", dims(bRows, bCols))
+		return nil, NewError(CodeDimensionMismatch, "matrices must have the same dimensions").
+			WithDetail("a", dims(aRows, aCols)).
+			WithDetail("b", dims(bRows, bCols))
+	}
+	result := make([][]float64, aRows)
+	for i := range a {
+		result[i] = make([]float64, aCols)
+		for j := range a[i] {
+			result[i][j] = a[i][j] + b[i][j]
+		}
+	}
+	log.Debug("Matrix addition result", "result", result)
+	return result, nil
+}
+
+// MatrixMultiply returns the matrix product a*b
+func MatrixMultiply(ctx context.Context, a, b [][]float64) ([][]float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing matrix multiplication", "a", a, "b", b)
+	aRows, aCols, err := shape("a", a)
+	if err != nil {
+		return nil, err
+	}
+	bRows, bCols, err := shape("b", b)
+	if err != nil {
+		return nil, err
+	}
+	if aCols != bRows {
+		log.Error("Matrix multiplication dimension mismatch", "a", dims(aRows, aCols), "b", dims(bRows, bCols))
+		return nil, NewError(CodeDimensionMismatch, "number of columns of 'a' must equal number of rows of 'b'").
+			WithDetail("a", dims(aRows, aCols)).
+			WithDetail("b", dims(bRows, bCols))
+	}
+	result := make([][]float64, aRows)
+	for i := 0; i < aRows; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result[i] = make([]float64, bCols)
+		for j := 0; j < bCols; j++ {
+			for k := 0; k < aCols; k++ {
+				result[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	log.Debug("Matrix multiplication result", "result", result)
+	return result, nil
+}
+
+// Transpose returns the transpose of matrix a
+func Transpose(ctx context.Context, a [][]float64) ([][]float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing matrix transpose", "a", a)
+	rows, cols, err := shape("a", a)
+	if err != nil {
+		return nil, err
+	}
+	result := make([][]float64, cols)
+	for j := 0; j < cols; j++ {
+		result[j] = make([]float64, rows)
+		for i := 0; i < rows; i++ {
+			result[j][i] = a[i][j]
+		}
+	}
+	log.Debug("Matrix transpose result", "result", result)
+	return result, nil
+}
+
+// Determinant returns the determinant of the square matrix a
+func Determinant(ctx context.Context, a [][]float64) (float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing determinant", "a", a)
+	n, err := squareSize("a", a)
+	if err != nil {
+		return 0, err
+	}
+	m := cloneMatrix(a)
+	tolerance := singularTolerance(m)
+	result := 1.0
+	for col := 0; col < n; col++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		pivot := partialPivot(m, col, col)
+		if math.Abs(m[pivot][col]) <= tolerance {
+			log.Debug("Determinant result", "result", 0)
+			return 0, nil
+		}
+		if pivot != col {
+			m[pivot], m[col] = m[col], m[pivot]
+			result = -result
+		}
+		result *= m[col][col]
+		for row := col + 1; row < n; row++ {
+			factor := m[row][col] / m[col][col]
+			for k := col; k < n; k++ {
+				m[row][k] -= factor * m[col][k]
+			}
+		}
+	}
+	log.Debug("Determinant result", "result", result)
+	return result, nil
+}
+
+// MatrixInverse returns the inverse of the square matrix a
+func MatrixInverse(ctx context.Context, a [][]float64) ([][]float64, error) {
+	log := logger(ctx)
+	log.Debug("Performing matrix inverse", "a", a)
+	n, err := squareSize("a", a)
+	if err != nil {
+		return nil, err
+	}
+	// Gauss-Jordan elimination on the augmented matrix [A | I]
+	m := make([][]float64, n)
+	for i := range a {
+		m[i] = make([]float64, 2*n)
+		copy(m[i], a[i])
+		m[i][n+i] = 1
+	}
+	tolerance := singularTolerance(a)
+	for col := 0; col < n; col++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pivot := partialPivot(m, col, col)
+		if math.Abs(m[pivot][col]) <= tolerance {
+			log.Error("Inverse of singular matrix attempted", "a", a)
+			return nil, NewError(CodeSingularMatrix, "cannot invert singular matrix")
+		}
+		m[pivot], m[col] = m[col], m[pivot]
+		p := m[col][col]
+		for k := range m[col] {
+			m[col][k] /= p
+		}
+		for row := 0; row < n; row++ {
+			if row == col || m[row][col] == 0 {
+				continue
+			}
+			factor := m[row][col]
+			for k := range m[row] {
+				m[row][k] -= factor * m[col][k]
+			}
+		}
+	}
+	result := make([][]float64, n)
+	for i := range m {
+		result[i] = m[i][n:]
+	}
+	log.Debug("Matrix inverse result", "result", result)
+	return result, nil
+}
+
+// Rank returns the rank of matrix a
+func Rank(ctx context.Context, a [][]float64) (int, error) {
+	log := logger(ctx)
+	log.Debug("Performing matrix rank", "a", a)
+	rows, cols, err := shape("a", a)
+	if err != nil {
+		return 0, err
+	}
+	m := cloneMatrix(a)
+	tolerance := singularTolerance(m)
+	result := 0
+	for col := 0; col < cols && result < rows; col++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		pivot := partialPivot(m, result, col)
+		if math.Abs(m[pivot][col]) <= tolerance {
+			continue
+		}
+		m[pivot], m[result] = m[result], m[pivot]
+		for row := result + 1; row < rows; row++ {
+			factor := m[row][col] / m[result][col]
+			for k := col; k < cols; k++ {
+				m[row][k] -= factor * m[result][k]
+			}
+		}
+		result++
+	}
+	log.Debug("Matrix rank result", "result", result)
+	return result, nil
+}
+
+// Solve solves the linear system a*x = b for x
+func Solve(ctx context.Context, a [][]float64, b []float64) ([]float64, error) {
+	log := logger(ctx)
+	log.Debug("Solving linear system", "a", a, "b", b)
+	n, err := squareSize("a", a)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != n {
+		log.Error("Linear system dimension mismatch", "a", dims(n, n), "b", len(b))
+		return nil, NewError(CodeDimensionMismatch, "length of 'b' must equal number of rows of 'a'").
+			WithDetail("a", dims(n, n)).
+			WithDetail("b", len(b))
+	}
+	m := cloneMatrix(a)
+	x := append([]float64(nil), b...)
+	tolerance := singularTolerance(m)
+	for col := 0; col < n; col++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pivot := partialPivot(m, col, col)
+		if math.Abs(m[pivot][col]) <= tolerance {
+			log.Error("Linear system with singular matrix attempted", "a", a)
+			return nil, NewError(CodeSingularMatrix, "cannot solve system with singular matrix")
+		}
+		m[pivot], m[col] = m[col], m[pivot]
+		x[pivot], x[col] = x[col], x[pivot]
+		for row := col + 1; row < n; row++ {
+			factor := m[row][col] / m[col][col]
+			for k := col; k < n; k++ {
+				m[row][k] -= factor * m[col][k]
+			}
+			x[row] -= factor * x[col]
+		}
+	}
+	// Back substitution
+	for row := n - 1; row >= 0; row-- {
+		for k := row + 1; k < n; k++ {
+			x[row] -= m[row][k] * x[k]
+		}
+		x[row] /= m[row][row]
+	}
+	log.Debug("Linear system result", "result", x)
+	return x, nil
+}
+
+// partialPivot returns the row index at or below start with the largest magnitude in column col
+func partialPivot(m [][]float64, start, col int) int {
+	pivot := start
+	for row := start + 1; row < len(m); row++ {
+		if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+			pivot = row
+		}
+	}
+	return pivot
+}
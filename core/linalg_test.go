@@ -0,0 +1,139 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVectors tests the vector operations
+func TestVectors(t *testing.T) {
+	ctx := context.Background()
+
+	dot, err := Dot(ctx, []float64{1, 2, 3}, []float64{4, 5, 6})
+	require.NoError(t, err)
+	assert.Equal(t, 32.0, dot)
+
+	cross, err := Cross(ctx, []float64{1, 0, 0}, []float64{0, 1, 0})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0, 0, 1}, cross)
+
+	norm, err := Norm(ctx, []float64{3, 4})
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, norm)
+
+	_, err = Dot(ctx, []float64{1}, []float64{1, 2})
+	assert.Equal(t, CodeDimensionMismatch, AsError(err).Code)
+	_, err = Cross(ctx, []float64{1, 2}, []float64{1, 2})
+	assert.Equal(t, CodeDimensionMismatch, AsError(err).Code)
+	_, err = Norm(ctx, nil)
+	assert.Equal(t, CodeDimensionMismatch, AsError(err).Code)
+}
+
+// TestMatrices tests the matrix operations
+func TestMatrices(t *testing.T) {
+	ctx := context.Background()
+	a := [][]float64{{4, 7}, {2, 6}}
+
+	sum, err := MatrixAdd(ctx, a, [][]float64{{1, 1}, {1, 1}})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{5, 8}, {3, 7}}, sum)
+
+	product, err := MatrixMultiply(ctx, a, [][]float64{{1}, {0}})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{4}, {2}}, product)
+
+	transposed, err := Transpose(ctx, a)
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{4, 2}, {7, 6}}, transposed)
+
+	det, err := Determinant(ctx, a)
+	require.NoError(t, err)
+	assert.InDelta(t, 10, det, 1e-9)
+
+	inverse, err := MatrixInverse(ctx, a)
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0.6, -0.7}, inverse[0], 1e-9)
+	assert.InDeltaSlice(t, []float64{-0.2, 0.4}, inverse[1], 1e-9)
+	assert.Equal(t, [][]float64{{4, 7}, {2, 6}}, a, "input is not modified")
+
+	rank, err := Rank(ctx, [][]float64{{1, 2}, {2, 4}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, rank)
+
+	x, err := Solve(ctx, a, []float64{1, 2})
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{-0.8, 0.6}, x, 1e-9)
+}
+
+// TestSmallMatrices tests that well-conditioned matrices of small magnitude are not treated as singular
+func TestSmallMatrices(t *testing.T) {
+	ctx := context.Background()
+
+	det, err := Determinant(ctx, [][]float64{{1e-13}})
+	require.NoError(t, err)
+	assert.Equal(t, 1e-13, det)
+
+	a := [][]float64{{4e-14, 7e-14}, {2e-14, 6e-14}}
+	det, err = Determinant(ctx, a)
+	require.NoError(t, err)
+	assert.InDelta(t, 10e-28, det, 1e-40)
+
+	inverse, err := MatrixInverse(ctx, a)
+	require.NoError(t, err)
+	assert.InEpsilonSlice(t, []float64{0.6e14, -0.7e14}, inverse[0], 1e-9)
+	assert.InEpsilonSlice(t, []float64{-0.2e14, 0.4e14}, inverse[1], 1e-9)
+
+	rank, err := Rank(ctx, a)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rank)
+
+	x, err := Solve(ctx, a, []float64{1e-14, 2e-14})
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{-0.8, 0.6}, x, 1e-9)
+
+	// Rounding residue stays singular whatever the scale
+	rank, err = Rank(ctx, [][]float64{{1e-20, 2e-20, 3e-20}, {4e-20, 5e-20, 6e-20}, {7e-20, 8e-20, 9e-20}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, rank)
+	_, err = MatrixInverse(ctx, [][]float64{{1e20, 2e20}, {2e20, 4e20}})
+	assert.Equal(t, CodeSingularMatrix, AsError(err).Code)
+}
+
+// TestMatrixErrors tests the error codes of the matrix operations
+func TestMatrixErrors(t *testing.T) {
+	ctx := context.Background()
+	singular := [][]float64{{1, 2}, {2, 4}}
+
+	tests := []struct {
+		name string
+		err  error
+		code string
+	}{
+		{"ragged", second(MatrixAdd(ctx, [][]float64{{1, 2}, {3}}, singular)), CodeDimensionMismatch},
+		{"empty", second(Transpose(ctx, nil)), CodeDimensionMismatch},
+		{"incompatible product", second(MatrixMultiply(ctx, singular, [][]float64{{1, 2, 3}})), CodeDimensionMismatch},
+		{"non-square determinant", second(Determinant(ctx, [][]float64{{1, 2, 3}})), CodeDimensionMismatch},
+		{"singular inverse", second(MatrixInverse(ctx, singular)), CodeSingularMatrix},
+		{"singular system", second(Solve(ctx, singular, []float64{1, 2})), CodeSingularMatrix},
+		{"system length", second(Solve(ctx, singular, []float64{1})), CodeDimensionMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.err)
+			assert.Equal(t, tt.code, AsError(tt.err).Code)
+		})
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := Solve(canceled, [][]float64{{1}}, []float64{1})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// second returns the error of a two-value call
+func second[T any](_ T, err error) error {
+	return err
+}
@@ -0,0 +1,547 @@
+package core
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+)
+
+// Integration methods accepted by Integrate
+const (
+	IntegrationGaussKronrod = "gauss-kronrod"
+	IntegrationSimpson      = "simpson"
+)
+
+// RealFunc is a real function of one variable whose evaluation may fail
+type RealFunc func(x float64) (float64, error)
+
+// Quadrature is the result of a numerical integration: the integral, its estimated absolute error and
+// the number of integrand evaluations
+type Quadrature struct {
+	Value         float64
+	ErrorEstimate float64
+	Evaluations   int
+}
+
+// Extremum is the location and value of a local minimum or maximum and the iterations used to find it
+type Extremum struct {
+	X          float64
+	Value      float64
+	Iterations int
+}
+
+// noConvergence returns the error reported when an iterative method exhausts its limit
+func noConvergence(method string, limit int) *Error {
+	return NewError(CodeNoConvergence, fmt.Sprintf("%s did not converge within %d iterations", method, limit)).
+		WithDetail("method", method).
+		WithDetail("max_iterations", limit)
+}
+
+// CheckFinite returns the DOMAIN_ERROR reported when the named output of a numerical method overflows
+// or is undefined, or nil when value is finite
+func CheckFinite(method, name string, value float64) error {
+	if !math.IsInf(value, 0) && !math.IsNaN(value) {
+		return nil
+	}
+	return NewError(CodeDomainError, fmt.Sprintf("%s %s is not a finite number", method, name)).
+		WithDetail("method", method).
+		WithDetail(name, fmt.Sprint(value))
+}
+
+// simpson integrates f over [a, b] by adaptive Simpson's rule. maxSplits bounds the number of
+// interval subdivisions; the error estimate is the sum of the Richardson corrections.
+func simpson(ctx context.Context, f RealFunc, a, b, tolerance float64, maxSplits int) (Quadrature, error) {
+	q := Quadrature{}
+	eval := func(x float64) (float64, error) {
+		q.Evaluations++
+		y, err := f(x)
+		if err != nil {
+			return 0, err
+		}
+		return y, CheckFinite("simpson", "integrand", y)
+	}
+	fa, err := eval(a)
+	if err != nil {
+		return q, err
+	}
+	fb, err := eval(b)
+	if err != nil {
+		return q, err
+	}
+	m := (a + b) / 2
+	fm, err := eval(m)
+	if err != nil {
+		return q, err
+	}
+
+	splits := 0
+	var step func(a, b, fa, fm, fb, whole, tolerance float64) (float64, error)
+	step = func(a, b, fa, fm, fb, whole, tolerance float64) (float64, error) {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		m := (a + b) / 2
+		lm, rm := (a+m)/2, (m+b)/2
+		flm, err := eval(lm)
+		if err != nil {
+			return 0, err
+		}
+		frm, err := eval(rm)
+		if err != nil {
+			return 0, err
+		}
+		left := (m - a) / 6 * (fa + 4*flm + fm)
+		right := (b - m) / 6 * (fm + 4*frm + fb)
+		delta := left + right - whole
+		if math.Abs(delta) <= 15*tolerance || m == a || m == b {
+			q.ErrorEstimate += math.Abs(delta) / 15
+			return left + right + delta/15, nil
+		}
+		if splits++; splits > maxSplits {
+			return 0, noConvergence("simpson", maxSplits)
+		}
+		l, err := step(a, m, fa, flm, fm, left, tolerance/2)
+		if err != nil {
+			return 0, err
+		}
+		r, err := step(m, b, fm, frm, fb, right, tolerance/2)
+		if err != nil {
+			return 0, err
+		}
+		return l + r, nil
+	}
+	whole := (b - a) / 6 * (fa + 4*fm + fb)
+	if q.Value, err = step(a, b, fa, fm, fb, whole, tolerance); err != nil {
+		return q, err
+	}
+	if err := CheckFinite("simpson", "result", q.Value); err != nil {
+		return q, err
+	}
+	return q, CheckFinite("simpson", "error_estimate", q.ErrorEstimate)
+}
+
+// Gauss-Kronrod 7-15 nodes and weights on [-1, 1]; odd-indexed Kronrod nodes are the Gauss nodes
+var (
+	kronrodNodes = [8]float64{
+		0.991455371120812639206854697526329,
+		0.949107912342758524526189684047851,
+		0.864864423359769072789712788640926,
+		0.741531185599394439863864773280788,
+		0.586087235467691130294144845693013,
+		0.405845151377397166906606412076961,
+		0.207784955007898467600689403773245,
+		0.000000000000000000000000000000000,
+	}
+	kronrodWeights = [8]float64{
+		0.022935322010529224963732008058970,
+		0.063092092629978553290700663189204,
+		0.104790010322250183839876322541518,
+		0.140653259715525918745189590510238,
+		0.169004726639267902826583426598550,
+		0.190350578064785409913256402421014,
+		0.204432940075298892414161999234649,
+		0.209482141084727828012999174891714,
+	}
+	gaussWeights = [4]float64{
+		0.129484966168869693270611432679082,
+		0.279705391489276667901467771423780,
+		0.381830050505118944950369775488975,
+		0.417959183673469387755102040816327,
+	}
+)
+
+// kronrodInterval is a subinterval with its Kronrod estimate and error
+type kronrodInterval struct {
+	a, b, value, err float64
+}
+
+// intervalHeap orders subintervals by decreasing error
+type intervalHeap []kronrodInterval
+
+func (h intervalHeap) Len() int           { return len(h) }
+func (h intervalHeap) Less(i, j int) bool { return h[i].err > h[j].err }
+func (h intervalHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *intervalHeap) Push(x any)        { *h = append(*h, x.(kronrodInterval)) }
+func (h *intervalHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// kronrod applies the 15-point Kronrod rule to [a, b], estimating the error against the 7-point Gauss rule
+func kronrod(f RealFunc, a, b float64) (kronrodInterval, error) {
+	center, half := (a+b)/2, (b-a)/2
+	fc, err := f(center)
+	if err != nil {
+		return kronrodInterval{}, err
+	}
+	k := fc * kronrodWeights[7]
+	g := fc * gaussWeights[3]
+	for i := 0; i < 7; i++ {
+		dx := half * kronrodNodes[i]
+		f1, err := f(center - dx)
+		if err != nil {
+			return kronrodInterval{}, err
+		}
+		f2, err := f(center + dx)
+		if err != nil {
+			return kronrodInterval{}, err
+		}
+		k += kronrodWeights[i] * (f1 + f2)
+		if i%2 == 1 {
+			g += gaussWeights[i/2] * (f1 + f2)
+		}
+	}
+	return kronrodInterval{a: a, b: b, value: k * half, err: math.Abs((k - g) * half)}, nil
+}
+
+// gaussKronrod integrates f over [a, b] by globally adaptive Gauss-Kronrod 7-15 quadrature,
+// bisecting the subinterval with the largest error until the total error is within tolerance
+func gaussKronrod(ctx context.Context, f RealFunc, a, b, tolerance float64, maxSplits int) (Quadrature, error) {
+	q := Quadrature{}
+	eval := func(x float64) (float64, error) {
+		q.Evaluations++
+		return f(x)
+	}
+	first, err := kronrod(eval, a, b)
+	if err != nil {
+		return q, err
+	}
+	intervals := &intervalHeap{first}
+	q.Value, q.ErrorEstimate = first.value, first.err
+	for splits := 0; q.ErrorEstimate > tolerance; splits++ {
+		if err := ctx.Err(); err != nil {
+			return q, err
+		}
+		if splits >= maxSplits {
+			return q, noConvergence("gauss-kronrod", maxSplits)
+		}
+		worst := heap.Pop(intervals).(kronrodInterval)
+		m := (worst.a + worst.b) / 2
+		if m == worst.a || m == worst.b {
+			// The interval cannot be split further at float64 precision
+			heap.Push(intervals, worst)
+			break
+		}
+		left, err := kronrod(eval, worst.a, m)
+		if err != nil {
+			return q, err
+		}
+		right, err := kronrod(eval, m, worst.b)
+		if err != nil {
+			return q, err
+		}
+		heap.Push(intervals, left)
+		heap.Push(intervals, right)
+		q.Value += left.value + right.value - worst.value
+		q.ErrorEstimate += left.err + right.err - worst.err
+	}
+	// Re-sum to avoid drift from the incremental updates
+	q.Value, q.ErrorEstimate = 0, 0
+	for _, interval := range *intervals {
+		q.Value += interval.value
+		q.ErrorEstimate += interval.err
+	}
+	if err := CheckFinite("gauss-kronrod", "result", q.Value); err != nil {
+		return q, err
+	}
+	return q, CheckFinite("gauss-kronrod", "error_estimate", q.ErrorEstimate)
+}
+
+// Integrate returns the definite integral of f from a to b by method, IntegrationGaussKronrod (the
+// default when empty) or IntegrationSimpson. maxSplits bounds the interval subdivisions.
+func Integrate(ctx context.Context, method string, f RealFunc, a, b, tolerance float64, maxSplits int) (Quadrature, error) {
+	log := logger(ctx)
+	log.Debug("Performing integration", "a", a, "b", b, "method", method)
+	if method == "" {
+		method = IntegrationGaussKronrod
+	}
+	sign := 1.0
+	if a > b {
+		a, b, sign = b, a, -1
+	}
+	var q Quadrature
+	var err error
+	switch {
+	case method != IntegrationGaussKronrod && method != IntegrationSimpson:
+		err = NewError(CodeInvalidInput, fmt.Sprintf("unknown integration method '%s'", method)).
+			WithDetail("method", method).
+			WithDetail("allowed", []string{IntegrationGaussKronrod, IntegrationSimpson})
+	case a == b:
+	case method == IntegrationSimpson:
+		q, err = simpson(ctx, f, a, b, tolerance, maxSplits)
+	default:
+		q, err = gaussKronrod(ctx, f, a, b, tolerance, maxSplits)
+	}
+	if err != nil {
+		log.Error("Integration failed", "method", method, "error", err)
+		return q, err
+	}
+	q.Value *= sign
+	log.Debug("Integration result", "result", q.Value, "error_estimate", q.ErrorEstimate, "evaluations", q.Evaluations)
+	return q, nil
+}
+
+// bracket evaluates f at both ends of [a, b] and checks that they differ in sign
+func bracket(f RealFunc, a, b float64) (float64, float64, error) {
+	fa, err := f(a)
+	if err != nil {
+		return 0, 0, err
+	}
+	fb, err := f(b)
+	if err != nil {
+		return 0, 0, err
+	}
+	if fa*fb > 0 {
+		return 0, 0, NewError(CodeDomainError, fmt.Sprintf("f(a) and f(b) must differ in sign to bracket a root, got f(%g) = %g and f(%g) = %g", a, fa, b, fb)).
+			WithDetail("fa", fa).
+			WithDetail("fb", fb)
+	}
+	return fa, fb, nil
+}
+
+// Bisection finds a root of f in [a, b] by repeated halving. It returns the root and the iterations used.
+func Bisection(ctx context.Context, f RealFunc, a, b, tolerance float64, maxIterations int) (float64, int, error) {
+	logger(ctx).Debug("Performing bisection", "a", a, "b", b)
+	fa, fb, err := bracket(f, a, b)
+	if err != nil {
+		return 0, 0, err
+	}
+	if fa == 0 {
+		return a, 0, nil
+	}
+	if fb == 0 {
+		return b, 0, nil
+	}
+	for i := 1; i <= maxIterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return 0, i, err
+		}
+		m := a + (b-a)/2
+		fm, err := f(m)
+		if err != nil {
+			return 0, i, err
+		}
+		if fm == 0 || (b-a)/2 < tolerance {
+			return m, i, nil
+		}
+		if math.Signbit(fm) == math.Signbit(fa) {
+			a, fa = m, fm
+		} else {
+			b = m
+		}
+	}
+	return 0, maxIterations, noConvergence("bisection", maxIterations)
+}
+
+// Brent finds a root of f in [a, b] by Brent's method, combining bisection, secant and inverse
+// quadratic interpolation steps. It returns the root and the iterations used.
+func Brent(ctx context.Context, f RealFunc, a, b, tolerance float64, maxIterations int) (float64, int, error) {
+	logger(ctx).Debug("Performing brent", "a", a, "b", b)
+	fa, fb, err := bracket(f, a, b)
+	if err != nil {
+		return 0, 0, err
+	}
+	c, fc := a, fa
+	d := b - a
+	e := d
+	for i := 1; i <= maxIterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return 0, i, err
+		}
+		if math.Signbit(fb) == math.Signbit(fc) {
+			c, fc = a, fa
+			d = b - a
+			e = d
+		}
+		if math.Abs(fc) < math.Abs(fb) {
+			a, b, c = b, c, b
+			fa, fb, fc = fb, fc, fb
+		}
+		tol := 2*math.SmallestNonzeroFloat64 + tolerance/2
+		m := (c - b) / 2
+		if math.Abs(m) <= tol || fb == 0 {
+			return b, i, nil
+		}
+		if math.Abs(e) >= tol && math.Abs(fa) > math.Abs(fb) {
+			var p, q float64
+			s := fb / fa
+			if a == c {
+				// Secant step
+				p = 2 * m * s
+				q = 1 - s
+			} else {
+				// Inverse quadratic interpolation
+				q = fa / fc
+				r := fb / fc
+				p = s * (2*m*q*(q-r) - (b-a)*(r-1))
+				q = (q - 1) * (r - 1) * (s - 1)
+			}
+			if p > 0 {
+				q = -q
+			} else {
+				p = -p
+			}
+			if 2*p < math.Min(3*m*q-math.Abs(tol*q), math.Abs(e*q)) {
+				e = d
+				d = p / q
+			} else {
+				d, e = m, m
+			}
+		} else {
+			d, e = m, m
+		}
+		a, fa = b, fb
+		if math.Abs(d) > tol {
+			b += d
+		} else {
+			b += math.Copysign(tol, m)
+		}
+		if fb, err = f(b); err != nil {
+			return 0, i, err
+		}
+	}
+	return 0, maxIterations, noConvergence("brent", maxIterations)
+}
+
+// Newton finds a root of f from x0 using its derivative df. It returns the root and the iterations used.
+func Newton(ctx context.Context, f, df RealFunc, x0, tolerance float64, maxIterations int) (float64, int, error) {
+	logger(ctx).Debug("Performing newton", "x0", x0)
+	x := x0
+	for i := 1; i <= maxIterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return 0, i, err
+		}
+		fx, err := f(x)
+		if err != nil {
+			return 0, i, err
+		}
+		if fx == 0 {
+			return x, i, nil
+		}
+		dfx, err := df(x)
+		if err != nil {
+			return 0, i, err
+		}
+		if dfx == 0 {
+			return 0, i, NewError(CodeNoConvergence, fmt.Sprintf("newton reached a zero derivative at x = %g", x)).
+				WithDetail("method", "newton").
+				WithDetail("x", x).
+				WithDetail("iterations", i)
+		}
+		step := fx / dfx
+		x -= step
+		if err := CheckFinite("newton", "x", x); err != nil {
+			return 0, i, err
+		}
+		if math.Abs(step) <= tolerance*(1+math.Abs(x)) {
+			return x, i, nil
+		}
+	}
+	return 0, maxIterations, noConvergence("newton", maxIterations)
+}
+
+// minimize finds a local minimum of f in [a, b] by Brent's method, combining golden-section
+// search with parabolic interpolation
+func minimize(ctx context.Context, f RealFunc, a, b, tolerance float64, maxIterations int) (Extremum, error) {
+	const golden = 0.3819660112501051 // (3 - sqrt(5)) / 2
+	x := a + golden*(b-a)
+	w, v := x, x
+	fx, err := f(x)
+	if err != nil {
+		return Extremum{}, err
+	}
+	fw, fv := fx, fx
+	var d, e float64
+	for i := 1; i <= maxIterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return Extremum{}, err
+		}
+		m := (a + b) / 2
+		tol1 := tolerance*math.Abs(x) + 1e-12
+		tol2 := 2 * tol1
+		if math.Abs(x-m) <= tol2-(b-a)/2 {
+			return Extremum{X: x, Value: fx, Iterations: i}, CheckFinite("brent", "value", fx)
+		}
+		parabolic := false
+		if math.Abs(e) > tol1 {
+			r := (x - w) * (fx - fv)
+			q := (x - v) * (fx - fw)
+			p := (x-v)*q - (x-w)*r
+			q = 2 * (q - r)
+			if q > 0 {
+				p = -p
+			}
+			q = math.Abs(q)
+			if math.Abs(p) < math.Abs(q*e/2) && p > q*(a-x) && p < q*(b-x) {
+				e, d = d, p/q
+				parabolic = true
+				if u := x + d; u-a < tol2 || b-u < tol2 {
+					d = math.Copysign(tol1, m-x)
+				}
+			}
+		}
+		if !parabolic {
+			if x < m {
+				e = b - x
+			} else {
+				e = a - x
+			}
+			d = golden * e
+		}
+		u := x + d
+		if math.Abs(d) < tol1 {
+			u = x + math.Copysign(tol1, d)
+		}
+		fu, err := f(u)
+		if err != nil {
+			return Extremum{}, err
+		}
+		if fu <= fx {
+			if u < x {
+				b = x
+			} else {
+				a = x
+			}
+			v, fv, w, fw, x, fx = w, fw, x, fx, u, fu
+		} else {
+			if u < x {
+				a = u
+			} else {
+				b = u
+			}
+			if fu <= fw || w == x {
+				v, fv, w, fw = w, fw, u, fu
+			} else if fu <= fv || v == x || v == w {
+				v, fv = u, fu
+			}
+		}
+	}
+	return Extremum{}, noConvergence("brent", maxIterations)
+}
+
+// Minimize finds a local minimum of f in [a, b] by Brent's method
+func Minimize(ctx context.Context, f RealFunc, a, b, tolerance float64, maxIterations int) (Extremum, error) {
+	logger(ctx).Debug("Performing minimisation", "a", a, "b", b)
+	if a > b {
+		a, b = b, a
+	}
+	return minimize(ctx, f, a, b, tolerance, maxIterations)
+}
+
+// Maximize finds a local maximum of f in [a, b] by minimising -f
+func Maximize(ctx context.Context, f RealFunc, a, b, tolerance float64, maxIterations int) (Extremum, error) {
+	logger(ctx).Debug("Performing maximisation", "a", a, "b", b)
+	if a > b {
+		a, b = b, a
+	}
+	negated := func(x float64) (float64, error) {
+		y, err := f(x)
+		return -y, err
+	}
+	result, err := minimize(ctx, negated, a, b, tolerance, maxIterations)
+	result.Value = -result.Value
+	return result, err
+}
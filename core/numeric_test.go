@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIntegrationMethods tests the quadrature rules against known integrals
+func TestIntegrationMethods(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		f        RealFunc
+		a, b     float64
+		expected float64
+	}{
+		{"polynomial", func(x float64) (float64, error) { return x * x, nil }, 0, 3, 9},
+		{"sine", func(x float64) (float64, error) { return math.Sin(x), nil }, 0, math.Pi, 2},
+		{"gaussian", func(x float64) (float64, error) { return math.Exp(-x * x), nil }, -5, 5, math.Sqrt(math.Pi)},
+		{"sqrt", func(x float64) (float64, error) { return math.Sqrt(x), nil }, 0, 1, 2.0 / 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, method := range []string{IntegrationSimpson, IntegrationGaussKronrod} {
+				q, err := Integrate(ctx, method, tt.f, tt.a, tt.b, 1e-10, 1000)
+				assert.NoError(t, err)
+				assert.InDelta(t, tt.expected, q.Value, 1e-8)
+				assert.LessOrEqual(t, q.ErrorEstimate, 1e-8)
+				assert.Positive(t, q.Evaluations)
+
+				q, err = Integrate(ctx, method, tt.f, tt.b, tt.a, 1e-10, 1000)
+				assert.NoError(t, err)
+				assert.InDelta(t, -tt.expected, q.Value, 1e-8)
+			}
+		})
+	}
+
+	oscillating := func(x float64) (float64, error) { return math.Sin(1 / x), nil }
+	_, err := Integrate(ctx, IntegrationGaussKronrod, oscillating, 1e-6, 1, 1e-12, 5)
+	assert.Equal(t, CodeNoConvergence, AsError(err).Code)
+	_, err = Integrate(ctx, IntegrationSimpson, oscillating, 1e-6, 1, 1e-12, 5)
+	assert.Equal(t, CodeNoConvergence, AsError(err).Code)
+
+	overflowing := func(x float64) (float64, error) { return math.Exp(x), nil }
+	_, err = Integrate(ctx, IntegrationGaussKronrod, overflowing, 0, 1000, 1e-10, 1000)
+	assert.Equal(t, CodeDomainError, AsError(err).Code)
+	_, err = Integrate(ctx, IntegrationSimpson, overflowing, 0, 1000, 1e-10, 1000)
+	assert.Equal(t, CodeDomainError, AsError(err).Code)
+}
+
+// TestRootFindingMethods tests bisection, Brent and Newton on x^2 - 2
+func TestRootFindingMethods(t *testing.T) {
+	ctx := context.Background()
+	f := func(x float64) (float64, error) { return x*x - 2, nil }
+	df := func(x float64) (float64, error) { return 2 * x, nil }
+
+	root, _, err := Bisection(ctx, f, 0, 2, 1e-12, 100)
+	assert.NoError(t, err)
+	assert.InDelta(t, math.Sqrt2, root, 1e-10)
+
+	root, brentIterations, err := Brent(ctx, f, 0, 2, 1e-12, 100)
+	assert.NoError(t, err)
+	assert.InDelta(t, math.Sqrt2, root, 1e-10)
+	assert.Less(t, brentIterations, 20)
+
+	root, _, err = Newton(ctx, f, df, 1, 1e-12, 100)
+	assert.NoError(t, err)
+	assert.InDelta(t, math.Sqrt2, root, 1e-10)
+
+	_, _, err = Brent(ctx, f, 2, 3, 1e-12, 100)
+	assert.Equal(t, CodeDomainError, AsError(err).Code)
+	_, _, err = Bisection(ctx, f, 0, 2, 1e-12, 5)
+	assert.Equal(t, CodeNoConvergence, AsError(err).Code)
+	_, _, err = Newton(ctx, f, df, 0, 1e-12, 100)
+	assert.Equal(t, CodeNoConvergence, AsError(err).Code)
+}
+
+// TestMinimize tests Brent's minimisation and maximisation
+func TestMinimize(t *testing.T) {
+	ctx := context.Background()
+	f := func(x float64) (float64, error) { return (x-1)*(x-1) + 3, nil }
+	result, err := Minimize(ctx, f, -4, 5, 1e-10, 100)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, result.X, 1e-6)
+	assert.InDelta(t, 3, result.Value, 1e-10)
+
+	cosine := func(x float64) (float64, error) { return math.Cos(x), nil }
+	result, err = Minimize(ctx, cosine, 0, 2*math.Pi, 1e-10, 100)
+	assert.NoError(t, err)
+	assert.InDelta(t, math.Pi, result.X, 1e-6)
+
+	_, err = Minimize(ctx, cosine, 0, 2*math.Pi, 1e-10, 2)
+	assert.Equal(t, CodeNoConvergence, AsError(err).Code)
+
+	_, err = Minimize(ctx, func(x float64) (float64, error) { return -math.Exp(x), nil }, 0, 1000, 1e-10, 100)
+	assert.Equal(t, CodeDomainError, AsError(err).Code)
+
+	result, err = Maximize(ctx, cosine, 2*math.Pi, math.Pi/2, 1e-10, 100)
+	assert.NoError(t, err)
+	assert.InDelta(t, 2*math.Pi, result.X, 1e-4)
+	assert.InDelta(t, 1, result.Value, 1e-8)
+}
+
+// TestNumericCanceled tests that the numerical methods stop once the context is canceled
+func TestNumericCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	f := func(x float64) (float64, error) { return x*x - 2, nil }
+
+	_, err := Integrate(ctx, IntegrationSimpson, f, 0, 100, 1e-12, 1000)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = Integrate(ctx, IntegrationGaussKronrod, func(x float64) (float64, error) { return math.Sin(1 / x), nil }, 1e-6, 1, 1e-12, 1000)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, _, err = Brent(ctx, f, 0, 2, 1e-12, 100)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = Minimize(ctx, f, -1, 1, 1e-10, 100)
+	assert.ErrorIs(t, err, context.Canceled)
+}
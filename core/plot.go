@@ -0,0 +1,241 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// plotRefineDepth bounds how often an initial interval is bisected
+	plotRefineDepth = 10
+	// plotFlatness is the midpoint deviation, relative to the y range, below which an interval is straight enough
+	plotFlatness = 0.002
+	// plotJump is the change in y, relative to the y range, across a fully refined interval that is drawn as a gap
+	plotJump = 0.05
+)
+
+// Point is a sampled point of a plot
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Gap is an x range where the expression is undefined or discontinuous
+type Gap struct {
+	From float64 `json:"from"`
+	To   float64 `json:"to"`
+}
+
+// PlotResult is a sampled curve as continuous segments separated by gaps, with the range of the
+// sampled values. SVG holds the rendering when one was asked for.
+type PlotResult struct {
+	Segments [][]Point `json:"segments"`
+	Gaps     []Gap     `json:"gaps"`
+	Points   int       `json:"points"`
+	YMin     float64   `json:"y_min"`
+	YMax     float64   `json:"y_max"`
+	SVG      string    `json:"svg,omitempty"`
+}
+
+// plotSample is an evaluated x; ok is false where evaluation failed.
+// split marks a discontinuity between the previous sample and this one.
+type plotSample struct {
+	x, y      float64
+	ok, split bool
+}
+
+// plotter samples a function, bisecting intervals that are curved, cross a domain boundary or jump
+type plotter struct {
+	f           RealFunc
+	maxPoints   int
+	evaluations int
+	yRange      float64
+	samples     []plotSample
+}
+
+// eval samples f at x
+func (p *plotter) eval(x float64) plotSample {
+	p.evaluations++
+	y, err := p.f(x)
+	if err != nil || math.IsNaN(y) || math.IsInf(y, 0) {
+		return plotSample{x: x}
+	}
+	return plotSample{x: x, y: y, ok: true}
+}
+
+// discontinuous reports whether the fully refined interval [l, r] jumps rather than climbs steeply:
+// the change in y is large and halving the interval leaves almost all of it in one half
+func (p *plotter) discontinuous(l, r plotSample) bool {
+	jump := math.Abs(r.y - l.y)
+	if jump <= plotJump*p.yRange {
+		return false
+	}
+	if p.evaluations >= p.maxPoints {
+		return true
+	}
+	m := p.eval((l.x + r.x) / 2)
+	if !m.ok {
+		return true
+	}
+	return math.Max(math.Abs(m.y-l.y), math.Abs(r.y-m.y)) > 0.9*jump
+}
+
+// refine appends the samples after l up to and including r
+func (p *plotter) refine(ctx context.Context, l, r plotSample, depth int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if depth >= plotRefineDepth || p.evaluations >= p.maxPoints {
+		r.split = l.ok && r.ok && p.discontinuous(l, r)
+		p.samples = append(p.samples, r)
+		return nil
+	}
+	m := p.eval((l.x + r.x) / 2)
+	if !l.ok && !r.ok && !m.ok {
+		p.samples = append(p.samples, r)
+		return nil
+	}
+	if l.ok && r.ok && m.ok &&
+		math.Abs(m.y-(l.y+r.y)/2) <= plotFlatness*p.yRange &&
+		math.Abs(r.y-l.y) <= plotJump*p.yRange {
+		p.samples = append(p.samples, r)
+		return nil
+	}
+	if err := p.refine(ctx, l, m, depth+1); err != nil {
+		return err
+	}
+	return p.refine(ctx, m, r, depth+1)
+}
+
+// Plot samples f over [a, b] and splits the samples into continuous segments separated by the gaps
+// where f is undefined or jumps. The intervals uniform intervals are bisected adaptively where the
+// curve bends; maxPoints bounds the evaluations of f.
+func Plot(ctx context.Context, f RealFunc, a, b float64, intervals, maxPoints int) (PlotResult, error) {
+	log := logger(ctx)
+	log.Debug("Performing plot", "a", a, "b", b, "samples", intervals, "max_points", maxPoints)
+	if intervals < 1 || intervals >= maxPoints {
+		return PlotResult{}, NewError(CodeInvalidInput, "samples must be positive and less than max_points").
+			WithDetail("samples", intervals).
+			WithDetail("max_points", maxPoints)
+	}
+	if a == b || math.IsNaN(a) || math.IsNaN(b) || math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return PlotResult{}, NewError(CodeInvalidInput, "a and b must be finite and differ").
+			WithDetail("a", fmt.Sprint(a)).
+			WithDetail("b", fmt.Sprint(b))
+	}
+	if a > b {
+		a, b = b, a
+	}
+	p := &plotter{f: f, maxPoints: maxPoints}
+	initial := make([]plotSample, intervals+1)
+	yMin, yMax := math.Inf(1), math.Inf(-1)
+	for i := range initial {
+		initial[i] = p.eval(a + (b-a)*float64(i)/float64(intervals))
+		if initial[i].ok {
+			yMin, yMax = math.Min(yMin, initial[i].y), math.Max(yMax, initial[i].y)
+		}
+	}
+	p.yRange = yMax - yMin
+	if p.yRange == 0 || math.IsInf(p.yRange, 0) {
+		p.yRange = 1
+	}
+
+	p.samples = append(p.samples, initial[0])
+	for i := 1; i < len(initial); i++ {
+		if err := p.refine(ctx, initial[i-1], initial[i], 0); err != nil {
+			return PlotResult{}, err
+		}
+	}
+
+	result := PlotResult{Segments: [][]Point{}, Gaps: []Gap{}}
+	var segment []Point
+	gapStart := math.NaN()
+	if !p.samples[0].ok {
+		gapStart = a
+	}
+	yMin, yMax = math.Inf(1), math.Inf(-1)
+	for _, sample := range p.samples {
+		if !sample.ok || sample.split {
+			if len(segment) > 0 {
+				result.Segments = append(result.Segments, segment)
+				gapStart = segment[len(segment)-1].X
+				segment = nil
+			}
+		}
+		if !sample.ok {
+			continue
+		}
+		if len(segment) == 0 && !math.IsNaN(gapStart) {
+			result.Gaps = append(result.Gaps, Gap{From: gapStart, To: sample.x})
+			gapStart = math.NaN()
+		}
+		segment = append(segment, Point{X: sample.x, Y: sample.y})
+		yMin, yMax = math.Min(yMin, sample.y), math.Max(yMax, sample.y)
+		result.Points++
+	}
+	if len(segment) > 0 {
+		result.Segments = append(result.Segments, segment)
+	} else if !math.IsNaN(gapStart) {
+		result.Gaps = append(result.Gaps, Gap{From: gapStart, To: b})
+	}
+	if result.Points > 0 {
+		result.YMin, result.YMax = yMin, yMax
+	}
+	log.Debug("Plot result", "points", result.Points, "segments", len(result.Segments), "evaluations", p.evaluations)
+	return result, nil
+}
+
+// viewRange returns the y range drawn by RenderSVG: the 2nd to 98th percentile of the sampled values
+// with a margin, so that poles do not flatten the rest of the curve
+func viewRange(plot PlotResult) (float64, float64) {
+	var ys []float64
+	for _, segment := range plot.Segments {
+		for _, p := range segment {
+			ys = append(ys, p.Y)
+		}
+	}
+	if len(ys) == 0 {
+		return 0, 0
+	}
+	sort.Float64s(ys)
+	lo, hi := ys[len(ys)*2/100], ys[(len(ys)-1)*98/100]
+	margin := (hi - lo) / 10
+	return lo - margin, hi + margin
+}
+
+// RenderSVG draws the plot of [a, b] as a width by height SVG image titled title: the segments as
+// polylines with axes where they fall inside the plotted area. Points outside the view range are
+// clipped by the SVG viewport.
+func RenderSVG(title string, plot PlotResult, a, b float64, width, height int) string {
+	yMin, yMax := viewRange(plot)
+	if yMax == yMin {
+		yMin, yMax = yMin-1, yMax+1
+	}
+	w, h := float64(width), float64(height)
+	px := func(x float64) float64 { return (x - a) / (b - a) * w }
+	py := func(y float64) float64 { return h - (y-yMin)/(yMax-yMin)*h }
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	fmt.Fprintf(&sb, `<title>%s</title>`, html.EscapeString(title))
+	fmt.Fprintf(&sb, `<rect width="%d" height="%d" fill="white"/>`, width, height)
+	if a <= 0 && b >= 0 {
+		fmt.Fprintf(&sb, `<line x1="%.2f" y1="0" x2="%.2f" y2="%d" stroke="#999" stroke-width="1"/>`, px(0), px(0), height)
+	}
+	if yMin <= 0 && yMax >= 0 {
+		fmt.Fprintf(&sb, `<line x1="0" y1="%.2f" x2="%d" y2="%.2f" stroke="#999" stroke-width="1"/>`, py(0), width, py(0))
+	}
+	for _, segment := range plot.Segments {
+		points := make([]string, len(segment))
+		for i, p := range segment {
+			points[i] = fmt.Sprintf("%.2f,%.2f", px(p.X), py(p.Y))
+		}
+		fmt.Fprintf(&sb, `<polyline points="%s" fill="none" stroke="#1f77b4" stroke-width="1.5"/>`, strings.Join(points, " "))
+	}
+	sb.WriteString(`</svg>`)
+	return sb.String()
+}
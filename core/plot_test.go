@@ -0,0 +1,46 @@
+package core
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPlot tests sampling continuous and discontinuous functions and rendering them
+func TestPlot(t *testing.T) {
+	ctx := context.Background()
+	sine := func(x float64) (float64, error) { return math.Sin(x), nil }
+
+	result, err := Plot(ctx, sine, 2*math.Pi, 0, 100, 2000)
+	require.NoError(t, err)
+	require.Len(t, result.Segments, 1)
+	assert.Empty(t, result.Gaps)
+	assert.Equal(t, 0.0, result.Segments[0][0].X)
+	assert.InDelta(t, 1, result.YMax, 1e-3)
+
+	reciprocal := func(x float64) (float64, error) { return 1 / x, nil }
+	result, err = Plot(ctx, reciprocal, -1, 1, 100, 2000)
+	require.NoError(t, err)
+	assert.Len(t, result.Segments, 2)
+	require.Len(t, result.Gaps, 1)
+	assert.Less(t, result.Gaps[0].From, 0.0)
+	assert.GreaterOrEqual(t, result.Gaps[0].To, 0.0)
+
+	svg := RenderSVG("1/x", result, -1, 1, 320, 200)
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="320" height="200"`))
+	assert.Equal(t, 2, strings.Count(svg, "<polyline"))
+
+	_, err = Plot(ctx, sine, 1, 1, 10, 100)
+	assert.Equal(t, CodeInvalidInput, AsError(err).Code)
+	_, err = Plot(ctx, sine, 0, 1, 100, 100)
+	assert.Equal(t, CodeInvalidInput, AsError(err).Code)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = Plot(canceled, sine, 0, 1, 10, 100)
+	assert.ErrorIs(t, err, context.Canceled)
+}
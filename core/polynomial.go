@@ -0,0 +1,296 @@
+package core
+
+import (
+	"context"
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+const (
+	// durandKernerIterations bounds the Durand-Kerner iterations for polynomials of degree 5 and above
+	durandKernerIterations = 2000
+	// durandKernerTolerance is the relative root change below which Durand-Kerner has converged
+	durandKernerTolerance = 1e-14
+	// multiplicityTolerance is the relative distance below which roots are merged as one repeated root
+	multiplicityTolerance = 1e-5
+	// realTolerance is the relative imaginary part below which a root is reported as real
+	realTolerance = 1e-10
+)
+
+// Solution counts reported by the solvers
+const (
+	SolutionsUnique   = "unique"
+	SolutionsFinite   = "finite"
+	SolutionsNone     = "none"
+	SolutionsInfinite = "infinite"
+)
+
+// PolynomialRoot is a root of a polynomial with its multiplicity
+type PolynomialRoot struct {
+	Real         float64 `json:"real"`
+	Imag         float64 `json:"imag"`
+	Multiplicity int     `json:"multiplicity"`
+}
+
+// PolynomialSolution holds the distinct roots of a polynomial with their multiplicities.
+// Solutions is "finite", or "none"/"infinite" for nonzero/zero constant polynomials.
+type PolynomialSolution struct {
+	Degree    int              `json:"degree"`
+	Solutions string           `json:"solutions"`
+	Roots     []PolynomialRoot `json:"roots"`
+	Method    string           `json:"method,omitempty"`
+}
+
+// SolvePolynomial returns the roots of the polynomial with coefficients from the highest degree down,
+// e.g. [1, -3, 2] for x^2 - 3x + 2. Leading zeros do not contribute to the degree. Polynomials up to
+// degree 4 are solved in closed form, higher degrees by Durand-Kerner iteration.
+func SolvePolynomial(ctx context.Context, coefficients []float64) (PolynomialSolution, error) {
+	log := logger(ctx)
+	log.Debug("Performing polynomial roots", "coefficients", coefficients)
+	var trimmed []float64
+	for i, c := range coefficients {
+		if math.IsNaN(c) || math.IsInf(c, 0) {
+			return PolynomialSolution{}, NewError(CodeInvalidInput, "coefficients must be finite").WithDetail("index", i)
+		}
+		if len(trimmed) > 0 || c != 0 {
+			trimmed = append(trimmed, c)
+		}
+	}
+
+	solution := PolynomialSolution{Roots: []PolynomialRoot{}}
+	switch len(trimmed) {
+	case 0:
+		solution.Solutions = SolutionsInfinite
+	case 1:
+		solution.Solutions = SolutionsNone
+	default:
+		roots, method, err := polynomialRoots(ctx, trimmed)
+		if err != nil {
+			log.Error("Polynomial roots failed", "coefficients", trimmed, "error", err)
+			return PolynomialSolution{}, err
+		}
+		solution.Degree = len(trimmed) - 1
+		solution.Solutions = SolutionsFinite
+		solution.Roots = roots
+		solution.Method = method
+	}
+	log.Debug("Polynomial roots result", "solutions", solution.Solutions, "roots", solution.Roots)
+	return solution, nil
+}
+
+// horner evaluates the polynomial with coefficients in descending order at z, returning p(z) and p'(z)
+func horner(coefficients []complex128, z complex128) (complex128, complex128) {
+	var p, dp complex128
+	for _, c := range coefficients {
+		dp = dp*z + p
+		p = p*z + c
+	}
+	return p, dp
+}
+
+// polish refines a root of the polynomial by a few Newton steps, keeping the original if they do not help
+func polish(coefficients []complex128, z complex128) complex128 {
+	best, _ := horner(coefficients, z)
+	for range 3 {
+		p, dp := horner(coefficients, z)
+		if dp == 0 {
+			break
+		}
+		next := z - p/dp
+		value, _ := horner(coefficients, next)
+		if cmplx.Abs(value) >= cmplx.Abs(best) {
+			break
+		}
+		z, best = next, value
+	}
+	return z
+}
+
+// quadraticRoots returns the roots of z^2 + b z + c, avoiding cancellation
+func quadraticRoots(b, c complex128) []complex128 {
+	d := cmplx.Sqrt(b*b - 4*c)
+	// Choose the sign that adds magnitudes
+	if real(cmplx.Conj(b)*d) < 0 {
+		d = -d
+	}
+	q := -(b + d) / 2
+	if q == 0 {
+		return []complex128{0, 0}
+	}
+	return []complex128{q, c / q}
+}
+
+// cubicRoots returns the roots of z^3 + a z^2 + b z + c by Cardano's method
+func cubicRoots(a, b, c complex128) []complex128 {
+	// Depressed cubic t^3 + p t + q with z = t - a/3
+	p := b - a*a/3
+	q := 2*a*a*a/27 - a*b/3 + c
+	shift := -a / 3
+	if p == 0 && q == 0 {
+		return []complex128{shift, shift, shift}
+	}
+	d := cmplx.Sqrt(q*q/4 + p*p*p/27)
+	u3 := -q/2 + d
+	if cmplx.Abs(-q/2-d) > cmplx.Abs(u3) {
+		u3 = -q/2 - d
+	}
+	u := cmplx.Pow(u3, 1.0/3)
+	omega := complex(-0.5, math.Sqrt(3)/2)
+	roots := make([]complex128, 3)
+	for k := range roots {
+		uk := u
+		for range k {
+			uk *= omega
+		}
+		roots[k] = uk - p/(3*uk) + shift
+	}
+	return roots
+}
+
+// quarticRoots returns the roots of z^4 + a z^3 + b z^2 + c z + d by Ferrari's method
+func quarticRoots(a, b, c, d complex128) []complex128 {
+	// Depressed quartic y^4 + p y^2 + q y + r with z = y - a/4
+	p := b - 3*a*a/8
+	q := c - a*b/2 + a*a*a/8
+	r := d - a*c/4 + a*a*b/16 - 3*a*a*a*a/256
+	shift := -a / 4
+
+	var ys []complex128
+	if cmplx.Abs(q) < 1e-14*(1+cmplx.Abs(p)+cmplx.Abs(r)) {
+		// Biquadratic: y^2 solves w^2 + p w + r
+		for _, w := range quadraticRoots(p, r) {
+			s := cmplx.Sqrt(w)
+			ys = append(ys, s, -s)
+		}
+	} else {
+		// Resolvent cubic 8m^3 + 8p m^2 + (2p^2 - 8r) m - q^2 = 0; any nonzero root completes the square
+		var m complex128
+		for _, root := range cubicRoots(p, p*p/4-r, -q*q/8) {
+			if cmplx.Abs(root) > cmplx.Abs(m) {
+				m = root
+			}
+		}
+		s := cmplx.Sqrt(2 * m)
+		ys = append(ys, quadraticRoots(-s, p/2+m+q/(2*s))...)
+		ys = append(ys, quadraticRoots(s, p/2+m-q/(2*s))...)
+	}
+	roots := make([]complex128, len(ys))
+	for i, y := range ys {
+		roots[i] = y + shift
+	}
+	return roots
+}
+
+// durandKerner finds all roots of a monic polynomial simultaneously
+func durandKerner(ctx context.Context, monic []complex128) ([]complex128, error) {
+	n := len(monic) - 1
+	// Start spread over a circle enclosing all roots (Cauchy bound), rotated off the real axis
+	radius := 0.0
+	for _, c := range monic[1:] {
+		radius = math.Max(radius, cmplx.Abs(c))
+	}
+	radius++
+	roots := make([]complex128, n)
+	for i := range roots {
+		roots[i] = cmplx.Rect(radius, 2*math.Pi*float64(i)/float64(n)+0.4)
+	}
+	for range durandKernerIterations {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		change := 0.0
+		for i := range roots {
+			p, _ := horner(monic, roots[i])
+			denominator := complex(1, 0)
+			for j := range roots {
+				if i != j {
+					denominator *= roots[i] - roots[j]
+				}
+			}
+			if denominator == 0 {
+				denominator = complex(durandKernerTolerance, 0)
+			}
+			delta := p / denominator
+			roots[i] -= delta
+			change = math.Max(change, cmplx.Abs(delta)/(1+cmplx.Abs(roots[i])))
+		}
+		if change < durandKernerTolerance {
+			return roots, nil
+		}
+	}
+	return nil, noConvergence("durand-kerner", durandKernerIterations)
+}
+
+// polynomialRoots returns the roots of the polynomial with real coefficients in descending order
+// of degree, grouped by multiplicity. The leading coefficient must be nonzero.
+func polynomialRoots(ctx context.Context, coefficients []float64) ([]PolynomialRoot, string, error) {
+	n := len(coefficients) - 1
+	monic := make([]complex128, n+1)
+	for i, c := range coefficients {
+		monic[i] = complex(c/coefficients[0], 0)
+	}
+
+	var roots []complex128
+	method := "closed-form"
+	switch n {
+	case 1:
+		roots = []complex128{-monic[1]}
+	case 2:
+		roots = quadraticRoots(monic[1], monic[2])
+	case 3:
+		roots = cubicRoots(monic[1], monic[2], monic[3])
+	case 4:
+		roots = quarticRoots(monic[1], monic[2], monic[3], monic[4])
+	default:
+		method = "durand-kerner"
+		var err error
+		if roots, err = durandKerner(ctx, monic); err != nil {
+			return nil, method, err
+		}
+	}
+	for i, z := range roots {
+		roots[i] = polish(monic, z)
+	}
+	return groupRoots(roots), method, nil
+}
+
+// groupRoots merges roots closer than multiplicityTolerance, snaps negligible imaginary parts to zero
+// and orders the result by real then imaginary part
+func groupRoots(roots []complex128) []PolynomialRoot {
+	var groups [][]complex128
+	for _, z := range roots {
+		merged := false
+		for i, group := range groups {
+			if cmplx.Abs(z-group[0]) <= multiplicityTolerance*(1+cmplx.Abs(z)) {
+				groups[i] = append(group, z)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			groups = append(groups, []complex128{z})
+		}
+	}
+
+	result := make([]PolynomialRoot, len(groups))
+	for i, group := range groups {
+		var sum complex128
+		for _, z := range group {
+			sum += z
+		}
+		mean := sum / complex(float64(len(group)), 0)
+		re, im := real(mean), imag(mean)
+		if math.Abs(im) <= realTolerance*(1+math.Abs(re)) {
+			im = 0
+		}
+		result[i] = PolynomialRoot{Real: re, Imag: im, Multiplicity: len(group)}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Real != result[j].Real {
+			return result[i].Real < result[j].Real
+		}
+		return result[i].Imag < result[j].Imag
+	})
+	return result
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPolynomialRoots tests closed-form and Durand-Kerner roots with multiplicities
+func TestPolynomialRoots(t *testing.T) {
+	tests := []struct {
+		name         string
+		coefficients []float64
+		method       string
+		expected     []PolynomialRoot
+	}{
+		{"linear", []float64{2, -3}, "closed-form", []PolynomialRoot{{1.5, 0, 1}}},
+		{"quadratic", []float64{1, -3, 2}, "closed-form", []PolynomialRoot{{1, 0, 1}, {2, 0, 1}}},
+		{"complex pair", []float64{1, 2, 5}, "closed-form", []PolynomialRoot{{-1, -2, 1}, {-1, 2, 1}}},
+		{"double root", []float64{1, -2, 1}, "closed-form", []PolynomialRoot{{1, 0, 2}}},
+		{"cubic", []float64{1, -6, 11, -6}, "closed-form", []PolynomialRoot{{1, 0, 1}, {2, 0, 1}, {3, 0, 1}}},
+		{"cubic double root", []float64{1, -4, 5, -2}, "closed-form", []PolynomialRoot{{1, 0, 2}, {2, 0, 1}}},
+		{"triple root", []float64{1, -3, 3, -1}, "closed-form", []PolynomialRoot{{1, 0, 3}}},
+		{"quartic", []float64{1, 0, -5, 0, 4}, "closed-form", []PolynomialRoot{{-2, 0, 1}, {-1, 0, 1}, {1, 0, 1}, {2, 0, 1}}},
+		{"quartic complex", []float64{1, -2, 2, -2, 1}, "closed-form", []PolynomialRoot{{0, -1, 1}, {0, 1, 1}, {1, 0, 2}}},
+		// (x-1)^2 (x+2) (x^2+1)
+		{"quintic", []float64{1, 0, -2, 2, -3, 2}, "durand-kerner", []PolynomialRoot{{-2, 0, 1}, {0, -1, 1}, {0, 1, 1}, {1, 0, 2}}},
+		{"sextic", []float64{1, 0, 0, 0, 0, 0, -1}, "durand-kerner", []PolynomialRoot{
+			{-1, 0, 1}, {-0.5, -0.8660254037844386, 1}, {-0.5, 0.8660254037844386, 1},
+			{0.5, -0.8660254037844386, 1}, {0.5, 0.8660254037844386, 1}, {1, 0, 1},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roots, method, err := polynomialRoots(context.Background(), tt.coefficients)
+			require.NoError(t, err)
+			assert.Equal(t, tt.method, method)
+			require.Len(t, roots, len(tt.expected))
+			for i, root := range roots {
+				assert.InDelta(t, tt.expected[i].Real, root.Real, 1e-5)
+				assert.InDelta(t, tt.expected[i].Imag, root.Imag, 1e-5)
+				assert.Equal(t, tt.expected[i].Multiplicity, root.Multiplicity)
+			}
+		})
+	}
+}
+
+// TestSolvePolynomial tests leading zeros, constant polynomials and invalid coefficients
+func TestSolvePolynomial(t *testing.T) {
+	ctx := context.Background()
+
+	solution, err := SolvePolynomial(ctx, []float64{0, 0, 1, -1})
+	require.NoError(t, err)
+	assert.Equal(t, 1, solution.Degree)
+	assert.Equal(t, SolutionsFinite, solution.Solutions)
+	assert.Equal(t, []PolynomialRoot{{1, 0, 1}}, solution.Roots)
+
+	solution, err = SolvePolynomial(ctx, []float64{0, 5})
+	require.NoError(t, err)
+	assert.Equal(t, SolutionsNone, solution.Solutions)
+	assert.Empty(t, solution.Roots)
+
+	solution, err = SolvePolynomial(ctx, []float64{0, 0})
+	require.NoError(t, err)
+	assert.Equal(t, SolutionsInfinite, solution.Solutions)
+
+	_, err = SolvePolynomial(ctx, []float64{1, math.Inf(1)})
+	assert.Equal(t, CodeInvalidInput, AsError(err).Code)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = SolvePolynomial(canceled, []float64{1, 0, -2, 2, -3, 2})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,353 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxRationalBits bounds the size of numerators and denominators in rational mode
+	maxRationalBits = 4096
+	// rationalDecimalDigits is the number of fractional digits in the decimal form of a rational result
+	rationalDecimalDigits = 20
+)
+
+// Rational is a value in rational mode; Approximate marks values rounded from an irrational or
+// measured value
+type Rational struct {
+	Value       *big.Rat
+	Approximate bool
+}
+
+// RationalResult holds the forms of an exact result. Numerator and Denominator are decimal strings
+// since they may exceed float64 precision; Approximate is set when an operand or the operation was
+// not exact.
+type RationalResult struct {
+	Result      float64 `json:"result"`
+	Numerator   string  `json:"numerator"`
+	Denominator string  `json:"denominator"`
+	Fraction    string  `json:"fraction"`
+	Mixed       string  `json:"mixed"`
+	Decimal     string  `json:"decimal"`
+	Approximate bool    `json:"approximate"`
+}
+
+// RationalFunc is a rational mode operation; unary operations ignore b
+type RationalFunc func(ctx context.Context, a, b Rational) (Rational, error)
+
+// rationalOperation is a rational mode operation and whether it is unary
+type rationalOperation struct {
+	op    RationalFunc
+	unary bool
+}
+
+// rationalOperations are the rational mode operations keyed by name
+var rationalOperations = map[string]rationalOperation{
+	"add":        {addRational, false},
+	"subtract":   {subtractRational, false},
+	"multiply":   {multiplyRational, false},
+	"divide":     {divideRational, false},
+	"percentage": {percentageRational, false},
+	"power":      {powerRational, false},
+	"root":       {rootRational, false},
+	"sqrt":       {sqrtRational, true},
+	"inverse":    {inverseRational, true},
+	"negative":   {negativeRational, true},
+}
+
+// RationalOperation returns the named rational mode operation and whether it is unary. The
+// operation rejects results whose numerator or denominator exceed 4096 bits.
+func RationalOperation(name string) (op RationalFunc, unary bool, ok bool) {
+	operation, ok := rationalOperations[name]
+	if !ok {
+		return nil, false, false
+	}
+	op = func(ctx context.Context, a, b Rational) (Rational, error) {
+		result, err := operation.op(ctx, a, b)
+		if err != nil {
+			return Rational{}, err
+		}
+		return checkRationalSize(result)
+	}
+	return op, operation.unary, true
+}
+
+// RationalOperationNames returns the names of the rational mode operations in alphabetical order
+func RationalOperationNames() []string {
+	return slices.Sorted(maps.Keys(rationalOperations))
+}
+
+// UnknownRationalOperationError returns the error reported for names that are not rational operations
+func UnknownRationalOperationError(name string) *Error {
+	return NewError(CodeUnknownOperation, fmt.Sprintf("unknown rational operation '%s'", name)).
+		WithDetail("name", name).
+		WithDetail("available", RationalOperationNames())
+}
+
+// RationalFromFloat converts a float64 to a rational through its shortest decimal representation,
+// so that 0.1 becomes 1/10 rather than the nearest binary fraction
+func RationalFromFloat(f float64, approximate bool) (Rational, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Rational{}, NewError(CodeDomainError, "result is not a finite number")
+	}
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return Rational{Value: r, Approximate: approximate}, nil
+}
+
+// checkRationalSize rejects values whose numerator or denominator exceed maxRationalBits
+func checkRationalSize(r Rational) (Rational, error) {
+	if r.Value.Num().BitLen() > maxRationalBits || r.Value.Denom().BitLen() > maxRationalBits {
+		return Rational{}, NewError(CodeInvalidInput, "rational result is too large").WithDetail("max_bits", maxRationalBits)
+	}
+	return r, nil
+}
+
+// ParseRational parses a fraction ("1/3"), mixed number ("-2 1/3") or decimal ("0.1") literal exactly.
+// It reports false for other text and fails when the value is too large for rational mode.
+func ParseRational(text string) (Rational, bool, error) {
+	r, ok := parseRational(text)
+	if !ok {
+		return Rational{}, false, nil
+	}
+	result, err := checkRationalSize(Rational{Value: r})
+	return result, true, err
+}
+
+// parseRational parses a fraction, mixed number or decimal literal, reporting false for other text
+func parseRational(text string) (*big.Rat, bool) {
+	text = strings.TrimSpace(text)
+	whole, fraction, mixed := strings.Cut(text, " ")
+	if !mixed {
+		r, ok := new(big.Rat).SetString(text)
+		return r, ok && !strings.ContainsAny(text, "xXpP")
+	}
+	// Mixed number: a whole part and a proper fraction sharing the whole part's sign
+	w, ok := new(big.Int).SetString(whole, 10)
+	if !ok || !strings.Contains(fraction, "/") {
+		return nil, false
+	}
+	f, ok := new(big.Rat).SetString(strings.TrimSpace(fraction))
+	if !ok || f.Sign() < 0 || f.Cmp(big.NewRat(1, 1)) >= 0 {
+		return nil, false
+	}
+	if strings.HasPrefix(whole, "-") {
+		f.Neg(f)
+	}
+	return f.Add(f, new(big.Rat).SetInt(w)), true
+}
+
+// formatMixed formats r as a mixed number such as "-2 1/3"
+func formatMixed(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	num := new(big.Int).Abs(r.Num())
+	whole, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if whole.Sign() == 0 {
+		return r.RatString()
+	}
+	sign := ""
+	if r.Sign() < 0 {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s%s %s/%s", sign, whole, rem, r.Denom())
+}
+
+// formatDecimal formats r with up to rationalDecimalDigits fractional digits, trimming trailing zeros
+func formatDecimal(r *big.Rat) string {
+	str := r.FloatString(rationalDecimalDigits)
+	if strings.Contains(str, ".") {
+		str = strings.TrimRight(strings.TrimRight(str, "0"), ".")
+	}
+	if str == "-0" {
+		str = "0"
+	}
+	return str
+}
+
+// Result returns the forms of r: its nearest float64, fraction, mixed number and decimal
+func (r Rational) Result() RationalResult {
+	f, _ := r.Value.Float64()
+	return RationalResult{
+		Result:      f,
+		Numerator:   r.Value.Num().String(),
+		Denominator: r.Value.Denom().String(),
+		Fraction:    r.Value.RatString(),
+		Mixed:       formatMixed(r.Value),
+		Decimal:     formatDecimal(r.Value),
+		Approximate: r.Approximate,
+	}
+}
+
+// Rational operation implementations
+
+// addRational returns a + b
+func addRational(ctx context.Context, a, b Rational) (Rational, error) {
+	logger(ctx).Debug("Performing rational addition", "a", a.Value.RatString(), "b", b.Value.RatString())
+	result := Rational{new(big.Rat).Add(a.Value, b.Value), a.Approximate || b.Approximate}
+	logger(ctx).Debug("Rational addition result", "result", result.Value.RatString())
+	return result, nil
+}
+
+// subtractRational returns a - b
+func subtractRational(ctx context.Context, a, b Rational) (Rational, error) {
+	logger(ctx).Debug("Performing rational subtraction", "a", a.Value.RatString(), "b", b.Value.RatString())
+	result := Rational{new(big.Rat).Sub(a.Value, b.Value), a.Approximate || b.Approximate}
+	logger(ctx).Debug("Rational subtraction result", "result", result.Value.RatString())
+	return result, nil
+}
+
+// multiplyRational returns a * b
+func multiplyRational(ctx context.Context, a, b Rational) (Rational, error) {
+	logger(ctx).Debug("Performing rational multiplication", "a", a.Value.RatString(), "b", b.Value.RatString())
+	result := Rational{new(big.Rat).Mul(a.Value, b.Value), a.Approximate || b.Approximate}
+	logger(ctx).Debug("Rational multiplication result", "result", result.Value.RatString())
+	return result, nil
+}
+
+// divideRational returns a / b
+func divideRational(ctx context.Context, a, b Rational) (Rational, error) {
+	logger(ctx).Debug("Performing rational division", "a", a.Value.RatString(), "b", b.Value.RatString())
+	if b.Value.Sign() == 0 {
+		logger(ctx).Error("Division by zero attempted", "a", a.Value.RatString(), "b", b.Value.RatString())
+		return Rational{}, NewError(CodeDivisionByZero, "cannot divide by zero")
+	}
+	result := Rational{new(big.Rat).Quo(a.Value, b.Value), a.Approximate || b.Approximate}
+	logger(ctx).Debug("Rational division result", "result", result.Value.RatString())
+	return result, nil
+}
+
+// percentageRational returns b percent of a
+func percentageRational(ctx context.Context, a, b Rational) (Rational, error) {
+	logger(ctx).Debug("Performing rational percentage", "a", a.Value.RatString(), "b", b.Value.RatString())
+	value := new(big.Rat).Mul(a.Value, b.Value)
+	result := Rational{value.Quo(value, big.NewRat(100, 1)), a.Approximate || b.Approximate}
+	logger(ctx).Debug("Rational percentage result", "result", result.Value.RatString())
+	return result, nil
+}
+
+// powerRational is exact for integer exponents and approximates otherwise
+func powerRational(ctx context.Context, a, b Rational) (Rational, error) {
+	logger(ctx).Debug("Performing rational power", "a", a.Value.RatString(), "b", b.Value.RatString())
+	if !b.Value.IsInt() {
+		af, _ := a.Value.Float64()
+		bf, _ := b.Value.Float64()
+		result, err := RationalFromFloat(math.Pow(af, bf), true)
+		if err != nil {
+			logger(ctx).Error("Rational power failed", "a", a.Value.RatString(), "b", b.Value.RatString(), "error", err)
+			return Rational{}, err
+		}
+		logger(ctx).Debug("Rational power result", "result", result.Value.RatString(), "approximate", true)
+		return result, nil
+	}
+	n := b.Value.Num()
+	if a.Value.Sign() == 0 {
+		if n.Sign() < 0 {
+			logger(ctx).Error("Negative power of zero attempted", "b", b.Value.RatString())
+			return Rational{}, NewError(CodeDivisionByZero, "cannot raise zero to a negative power")
+		}
+		if n.Sign() == 0 {
+			return Rational{big.NewRat(1, 1), a.Approximate || b.Approximate}, nil
+		}
+		return Rational{new(big.Rat), a.Approximate || b.Approximate}, nil
+	}
+	bits := max(a.Value.Num().BitLen(), a.Value.Denom().BitLen())
+	if !n.IsInt64() || (bits > 1 && new(big.Int).Abs(n).Int64() > int64(maxRationalBits/(bits-1))) {
+		logger(ctx).Error("Rational power too large", "a", a.Value.RatString(), "b", b.Value.RatString())
+		return Rational{}, NewError(CodeInvalidInput, "rational result is too large").WithDetail("max_bits", maxRationalBits)
+	}
+	exponent := new(big.Int).Abs(n)
+	num := new(big.Int).Exp(a.Value.Num(), exponent, nil)
+	den := new(big.Int).Exp(a.Value.Denom(), exponent, nil)
+	if n.Sign() < 0 {
+		num, den = den, num
+	}
+	result := Rational{new(big.Rat).SetFrac(num, den), a.Approximate || b.Approximate}
+	logger(ctx).Debug("Rational power result", "result", result.Value.RatString())
+	return result, nil
+}
+
+// exactRoot returns the exact nth root of x >= 0 when x is a perfect nth power
+func exactRoot(x *big.Int, n int) (*big.Int, bool) {
+	if n == 2 {
+		r := new(big.Int).Sqrt(x)
+		return r, new(big.Int).Mul(r, r).Cmp(x) == 0
+	}
+	f, _ := new(big.Float).SetInt(x).Float64()
+	estimate := math.Round(math.Pow(f, 1/float64(n)))
+	if math.IsInf(estimate, 0) || estimate > 1<<53 {
+		return nil, false
+	}
+	r := big.NewInt(int64(estimate))
+	return r, new(big.Int).Exp(r, big.NewInt(int64(n)), nil).Cmp(x) == 0
+}
+
+// rootRational is exact for perfect powers under integer degrees and approximates otherwise
+func rootRational(ctx context.Context, a, b Rational) (Rational, error) {
+	logger(ctx).Debug("Performing rational root", "a", a.Value.RatString(), "b", b.Value.RatString())
+	if b.Value.Sign() == 0 {
+		logger(ctx).Error("Zeroth root attempted", "a", a.Value.RatString())
+		return Rational{}, NewError(CodeDomainError, "cannot calculate 0th root")
+	}
+	if b.Value.IsInt() && b.Value.Num().IsInt64() && b.Value.Num().Int64() > 0 && b.Value.Num().Int64() <= 64 {
+		n := int(b.Value.Num().Int64())
+		if a.Value.Sign() < 0 && n%2 == 0 {
+			logger(ctx).Error("Even root of negative number attempted", "a", a.Value.RatString(), "b", n)
+			return Rational{}, NewError(CodeDomainError, "cannot calculate even root of negative number")
+		}
+		num, numOK := exactRoot(new(big.Int).Abs(a.Value.Num()), n)
+		den, denOK := exactRoot(a.Value.Denom(), n)
+		if numOK && denOK {
+			result := Rational{new(big.Rat).SetFrac(num, den), a.Approximate || b.Approximate}
+			if a.Value.Sign() < 0 {
+				result.Value.Neg(result.Value)
+			}
+			logger(ctx).Debug("Rational root result", "result", result.Value.RatString())
+			return result, nil
+		}
+	}
+	af, _ := a.Value.Float64()
+	bf, _ := b.Value.Float64()
+	f, err := Root(ctx, af, bf)
+	if err != nil {
+		return Rational{}, err
+	}
+	result, err := RationalFromFloat(f, true)
+	if err != nil {
+		logger(ctx).Error("Rational root failed", "a", a.Value.RatString(), "b", b.Value.RatString(), "error", err)
+		return Rational{}, err
+	}
+	logger(ctx).Debug("Rational root result", "result", result.Value.RatString(), "approximate", true)
+	return result, nil
+}
+
+// sqrtRational returns the square root of a
+func sqrtRational(ctx context.Context, a, _ Rational) (Rational, error) {
+	return rootRational(ctx, a, Rational{Value: big.NewRat(2, 1)})
+}
+
+// inverseRational returns 1 / a
+func inverseRational(ctx context.Context, a, _ Rational) (Rational, error) {
+	logger(ctx).Debug("Performing rational inverse", "a", a.Value.RatString())
+	if a.Value.Sign() == 0 {
+		logger(ctx).Error("Inverse of zero attempted", "a", a.Value.RatString())
+		return Rational{}, NewError(CodeDivisionByZero, "cannot calculate inverse of zero")
+	}
+	result := Rational{new(big.Rat).Inv(a.Value), a.Approximate}
+	logger(ctx).Debug("Rational inverse result", "result", result.Value.RatString())
+	return result, nil
+}
+
+// negativeRational returns -a
+func negativeRational(ctx context.Context, a, _ Rational) (Rational, error) {
+	logger(ctx).Debug("Performing rational negation", "a", a.Value.RatString())
+	result := Rational{new(big.Rat).Neg(a.Value), a.Approximate}
+	logger(ctx).Debug("Rational negation result", "result", result.Value.RatString())
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseRational tests that fractions, mixed numbers and decimals parse exactly
+func TestParseRational(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"1/3", "1/3"},
+		{"-2 1/3", "-7/3"},
+		{"0.1", "1/10"},
+		{"4/2", "2"},
+	}
+	for _, tt := range tests {
+		r, ok, err := ParseRational(tt.text)
+		require.NoError(t, err)
+		require.True(t, ok, tt.text)
+		assert.Equal(t, tt.expected, r.Value.RatString())
+		assert.False(t, r.Approximate)
+	}
+
+	_, ok, err := ParseRational("pi")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+// TestRationalOperation tests exact results, unary operations and unknown names
+func TestRationalOperation(t *testing.T) {
+	ctx := context.Background()
+	third := Rational{Value: big.NewRat(1, 3)}
+	sixth := Rational{Value: big.NewRat(1, 6)}
+
+	add, unary, ok := RationalOperation("add")
+	require.True(t, ok)
+	assert.False(t, unary)
+	result, err := add(ctx, third, sixth)
+	require.NoError(t, err)
+	assert.Equal(t, "1/2", result.Value.RatString())
+	assert.Equal(t, "0.5", result.Result().Decimal)
+
+	root, _, _ := RationalOperation("root")
+	result, err = root(ctx, Rational{Value: big.NewRat(8, 27)}, Rational{Value: big.NewRat(3, 1)})
+	require.NoError(t, err)
+	assert.Equal(t, "2/3", result.Value.RatString())
+	assert.False(t, result.Approximate)
+
+	sqrt, unary, ok := RationalOperation("sqrt")
+	require.True(t, ok)
+	assert.True(t, unary)
+	result, err = sqrt(ctx, Rational{Value: big.NewRat(2, 1)}, Rational{})
+	require.NoError(t, err)
+	assert.True(t, result.Approximate)
+
+	divide, _, _ := RationalOperation("divide")
+	_, err = divide(ctx, third, Rational{Value: new(big.Rat)})
+	assert.Equal(t, CodeDivisionByZero, AsError(err).Code)
+
+	_, _, ok = RationalOperation("modulo")
+	assert.False(t, ok)
+	assert.Equal(t, CodeUnknownOperation, UnknownRationalOperationError("modulo").Code)
+	assert.Contains(t, RationalOperationNames(), "inverse")
+}
+
+// TestRationalResult tests the fraction, mixed number and decimal forms of a result
+func TestRationalResult(t *testing.T) {
+	result := Rational{Value: big.NewRat(-7, 3)}.Result()
+	assert.Equal(t, "-7", result.Numerator)
+	assert.Equal(t, "3", result.Denominator)
+	assert.Equal(t, "-7/3", result.Fraction)
+	assert.Equal(t, "-2 1/3", result.Mixed)
+	assert.InDelta(t, -7.0/3, result.Result, 1e-15)
+
+	_, err := RationalFromFloat(0, false)
+	assert.NoError(t, err)
+	r, err := RationalFromFloat(0.1, true)
+	require.NoError(t, err)
+	assert.Equal(t, "1/10", r.Value.RatString())
+	assert.True(t, r.Approximate)
+}
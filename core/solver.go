@@ -0,0 +1,283 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"calculator/internal/expr"
+)
+
+// LinearSystemSolution is the solution of a system of linear equations.
+// Solutions is "unique", "none" or "infinite"; for infinitely many solutions Solution holds the
+// solution with every free variable set to zero and General expresses the other variables in terms of them.
+type LinearSystemSolution struct {
+	Solutions     string             `json:"solutions"`
+	Variables     []string           `json:"variables"`
+	Rank          int                `json:"rank"`
+	Solution      map[string]float64 `json:"solution,omitempty"`
+	FreeVariables []string           `json:"free_variables,omitempty"`
+	General       map[string]string  `json:"general,omitempty"`
+}
+
+// linearForm is an expression of the form sum(coefficients[v] * v) + constant
+type linearForm struct {
+	coefficients map[string]float64
+	constant     float64
+}
+
+// constantForm returns a linear form without variables
+func constantForm(value float64) linearForm {
+	return linearForm{coefficients: map[string]float64{}, constant: value}
+}
+
+// scale multiplies every term of f by k
+func (f linearForm) scale(k float64) linearForm {
+	result := constantForm(f.constant * k)
+	for v, c := range f.coefficients {
+		result.coefficients[v] = c * k
+	}
+	return result
+}
+
+// plus adds k times g to f
+func (f linearForm) plus(g linearForm, k float64) linearForm {
+	result := f.scale(1)
+	result.constant += k * g.constant
+	for v, c := range g.coefficients {
+		result.coefficients[v] += k * c
+	}
+	return result
+}
+
+// isConstant reports whether f has no variable terms
+func (f linearForm) isConstant() bool {
+	for _, c := range f.coefficients {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// errNonlinear is returned by linearize for terms that are not linear in the unknowns
+var errNonlinear = NewError(CodeInvalidInput, "equation is not linear")
+
+// linearize converts n into a linear form. Identifiers naming constants are constants; every other
+// identifier is an unknown.
+func linearize(n expr.Node) (linearForm, error) {
+	switch n := n.(type) {
+	case *expr.Num:
+		return constantForm(n.Value), nil
+	case *expr.Var:
+		if constant, ok := LookupConstant(n.Name); ok {
+			return constantForm(constant.Value), nil
+		}
+		return linearForm{coefficients: map[string]float64{n.Name: 1}}, nil
+	case *expr.Unary:
+		x, err := linearize(n.X)
+		return x.scale(-1), err
+	case *expr.Binary:
+		l, err := linearize(n.L)
+		if err != nil {
+			return linearForm{}, err
+		}
+		r, err := linearize(n.R)
+		if err != nil {
+			return linearForm{}, err
+		}
+		switch n.Op {
+		case '+':
+			return l.plus(r, 1), nil
+		case '-':
+			return l.plus(r, -1), nil
+		case '*':
+			if l.isConstant() {
+				return r.scale(l.constant), nil
+			}
+			if r.isConstant() {
+				return l.scale(r.constant), nil
+			}
+		case '/':
+			if r.isConstant() {
+				if r.constant == 0 {
+					return linearForm{}, NewError(CodeDivisionByZero, "division by zero")
+				}
+				return l.scale(1 / r.constant), nil
+			}
+		case '^':
+			if l.isConstant() && r.isConstant() {
+				value, err := expr.Eval(&expr.Binary{Op: '^', L: &expr.Num{Value: l.constant}, R: &expr.Num{Value: r.constant}}, expr.Vars{})
+				return constantForm(value), ExpressionError(err)
+			}
+		}
+	case *expr.Call:
+		args := make([]expr.Node, len(n.Args))
+		for i, arg := range n.Args {
+			a, err := linearize(arg)
+			if err != nil {
+				return linearForm{}, err
+			}
+			if !a.isConstant() {
+				return linearForm{}, errNonlinear
+			}
+			args[i] = &expr.Num{Value: a.constant}
+		}
+		value, err := expr.Eval(&expr.Call{Name: n.Name, Args: args}, expr.Vars{})
+		if err != nil {
+			return linearForm{}, ExpressionError(err)
+		}
+		return constantForm(value), nil
+	}
+	return linearForm{}, errNonlinear
+}
+
+// formatTerm formats the term c*v of a general solution, where first reports whether it leads
+func formatTerm(c float64, v string, first bool) string {
+	sign := " + "
+	if c < 0 {
+		sign, c = " - ", -c
+		if first {
+			sign = "-"
+		}
+	} else if first {
+		sign = ""
+	}
+	coefficient := strconv.FormatFloat(c, 'g', -1, 64) + "*"
+	if c == 1 {
+		coefficient = ""
+	}
+	return sign + coefficient + v
+}
+
+// solveLinearForms reduces the augmented system to reduced row echelon form and classifies its solutions
+func solveLinearForms(ctx context.Context, forms []linearForm) (LinearSystemSolution, error) {
+	log := logger(ctx)
+	seen := map[string]bool{}
+	variables := []string{}
+	for _, f := range forms {
+		for v, c := range f.coefficients {
+			if c != 0 && !seen[v] {
+				seen[v] = true
+				variables = append(variables, v)
+			}
+		}
+	}
+	sort.Strings(variables)
+	log.Debug("Solving linear equations", "equations", len(forms), "variables", variables)
+
+	// Augmented matrix [A | b] for A x = b, where each equation is sum(c v) + constant = 0
+	cols := len(variables)
+	m := make([][]float64, len(forms))
+	for i, f := range forms {
+		m[i] = make([]float64, cols+1)
+		for j, v := range variables {
+			m[i][j] = f.coefficients[v]
+		}
+		m[i][cols] = -f.constant
+	}
+
+	tolerance := singularTolerance(m)
+	var pivots []int
+	row := 0
+	for col := 0; col < cols && row < len(m); col++ {
+		if err := ctx.Err(); err != nil {
+			return LinearSystemSolution{}, err
+		}
+		pivot := partialPivot(m, row, col)
+		if math.Abs(m[pivot][col]) <= tolerance {
+			continue
+		}
+		m[pivot], m[row] = m[row], m[pivot]
+		scale := m[row][col]
+		for k := col; k <= cols; k++ {
+			m[row][k] /= scale
+		}
+		for other := range m {
+			if other != row && m[other][col] != 0 {
+				factor := m[other][col]
+				for k := col; k <= cols; k++ {
+					m[other][k] -= factor * m[row][k]
+				}
+			}
+		}
+		pivots = append(pivots, col)
+		row++
+	}
+
+	solution := LinearSystemSolution{Variables: variables, Rank: len(pivots)}
+	for r := len(pivots); r < len(m); r++ {
+		if math.Abs(m[r][cols]) > tolerance {
+			solution.Solutions = SolutionsNone
+			log.Debug("Linear equations result", "solutions", solution.Solutions)
+			return solution, nil
+		}
+	}
+
+	isPivot := make(map[int]bool, len(pivots))
+	for _, col := range pivots {
+		isPivot[col] = true
+	}
+	solution.Solution = make(map[string]float64, cols)
+	for j, v := range variables {
+		if !isPivot[j] {
+			solution.FreeVariables = append(solution.FreeVariables, v)
+			solution.Solution[v] = 0
+		}
+	}
+	for r, col := range pivots {
+		solution.Solution[variables[col]] = m[r][cols] + 0
+	}
+	if len(solution.FreeVariables) == 0 {
+		solution.Solutions = SolutionsUnique
+		log.Debug("Linear equations result", "solutions", solution.Solutions, "solution", solution.Solution)
+		return solution, nil
+	}
+
+	solution.Solutions = SolutionsInfinite
+	solution.General = make(map[string]string, len(pivots))
+	for r, col := range pivots {
+		var sb strings.Builder
+		first := true
+		if m[r][cols] != 0 {
+			sb.WriteString(strconv.FormatFloat(m[r][cols], 'g', -1, 64))
+			first = false
+		}
+		for j := col + 1; j < cols; j++ {
+			if !isPivot[j] && math.Abs(m[r][j]) > tolerance {
+				sb.WriteString(formatTerm(-m[r][j], variables[j], first))
+				first = false
+			}
+		}
+		if first {
+			sb.WriteString("0")
+		}
+		solution.General[variables[col]] = sb.String()
+	}
+	log.Debug("Linear equations result", "solutions", solution.Solutions, "free_variables", solution.FreeVariables)
+	return solution, nil
+}
+
+// SolveLinearSystem solves a system of linear equations in named variables such as
+// ["2x + y = 3", "x - y = 0"]. Identifiers naming built-in constants are constants; every other
+// identifier is an unknown.
+func SolveLinearSystem(ctx context.Context, equations []string) (LinearSystemSolution, error) {
+	forms := make([]linearForm, len(equations))
+	for i, equation := range equations {
+		lhs, rhs, err := expr.ParseEquation(equation)
+		if err == nil {
+			forms[i], err = linearize(&expr.Binary{Op: '-', L: lhs, R: rhs})
+		}
+		if err != nil {
+			logger(ctx).Error("Invalid equation", "equation", equation, "error", err)
+			calcErr := AsError(ExpressionError(err))
+			return LinearSystemSolution{}, NewError(calcErr.Code, fmt.Sprintf("equation %d: %s", i+1, calcErr.Message)).
+				WithDetail("index", i).
+				WithDetail("equation", equation)
+		}
+	}
+	return solveLinearForms(ctx, forms)
+}
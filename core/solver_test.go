@@ -0,0 +1,36 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSolveLinearSystem tests unique, dependent and invalid systems
+func TestSolveLinearSystem(t *testing.T) {
+	ctx := context.Background()
+
+	solution, err := SolveLinearSystem(ctx, []string{"2x + y = 3", "x - y = 0"})
+	require.NoError(t, err)
+	assert.Equal(t, SolutionsUnique, solution.Solutions)
+	assert.InDelta(t, 1, solution.Solution["x"], 1e-12)
+	assert.InDelta(t, 1, solution.Solution["y"], 1e-12)
+
+	solution, err = SolveLinearSystem(ctx, []string{"0.1a + 0.2b = 0.3", "0.3a + 0.6b = 0.9"})
+	require.NoError(t, err)
+	assert.Equal(t, SolutionsInfinite, solution.Solutions)
+	assert.Equal(t, 1, solution.Rank)
+	assert.Equal(t, []string{"b"}, solution.FreeVariables)
+
+	_, err = SolveLinearSystem(ctx, []string{"x = 1", "x*y = 2"})
+	calcErr := AsError(err)
+	assert.Equal(t, CodeInvalidInput, calcErr.Code)
+	assert.Equal(t, 1, calcErr.Details["index"])
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = SolveLinearSystem(canceled, []string{"x = 1"})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,392 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Base dimensions, indexes into Dimension
+const (
+	dimLength = iota
+	dimMass
+	dimTime
+	dimTemperature
+	dimData
+	dimCount
+)
+
+// baseUnitSymbols are the coherent units used to express dimensioned results
+var baseUnitSymbols = [dimCount]string{"m", "kg", "s", "K", "bit"}
+
+// Dimension holds the exponent of each base dimension
+type Dimension [dimCount]int
+
+// Unit describes a unit of measure relative to the coherent base units: si = (value-Offset)*Factor + Origin.
+// Offset is the unit's reading at Origin, so units sharing an Origin (C and F read 0 and 32 at 273.15 K)
+// convert between each other without rounding through the base unit.
+type Unit struct {
+	Symbol   string    `json:"symbol"`
+	Name     string    `json:"name"`
+	Category string    `json:"category"`
+	Factor   float64   `json:"-"`
+	Offset   float64   `json:"-"`
+	Origin   float64   `json:"-"`
+	Dim      Dimension `json:"-"`
+}
+
+// Quantity is a value with a unit
+type Quantity struct {
+	Value float64
+	Unit  Unit
+}
+
+// QuantityFunc is an operation on dimensioned values
+type QuantityFunc func(ctx context.Context, a, b Quantity) (Quantity, error)
+
+var (
+	dimensionless = Dimension{}
+	length        = Dimension{dimLength: 1}
+	mass          = Dimension{dimMass: 1}
+	duration      = Dimension{dimTime: 1}
+	temperature   = Dimension{dimTemperature: 1}
+	dataSize      = Dimension{dimData: 1}
+	area          = Dimension{dimLength: 2}
+	volume        = Dimension{dimLength: 3}
+	speed         = Dimension{dimLength: 1, dimTime: -1}
+	energy        = Dimension{dimMass: 1, dimLength: 2, dimTime: -2}
+)
+
+// unitCatalogue lists every supported unit
+var unitCatalogue = []Unit{
+	// Length
+	{"m", "metre", "length", 1, 0, 0, length},
+	{"km", "kilometre", "length", 1e3, 0, 0, length},
+	{"cm", "centimetre", "length", 1e-2, 0, 0, length},
+	{"mm", "millimetre", "length", 1e-3, 0, 0, length},
+	{"um", "micrometre", "length", 1e-6, 0, 0, length},
+	{"nm", "nanometre", "length", 1e-9, 0, 0, length},
+	{"in", "inch", "length", 0.0254, 0, 0, length},
+	{"ft", "foot", "length", 0.3048, 0, 0, length},
+	{"yd", "yard", "length", 0.9144, 0, 0, length},
+	{"mi", "mile", "length", 1609.344, 0, 0, length},
+	{"nmi", "nautical mile", "length", 1852, 0, 0, length},
+
+	// Mass
+	{"kg", "kilogram", "mass", 1, 0, 0, mass},
+	{"g", "gram", "mass", 1e-3, 0, 0, mass},
+	{"mg", "milligram", "mass", 1e-6, 0, 0, mass},
+	{"ug", "microgram", "mass", 1e-9, 0, 0, mass},
+	{"t", "tonne", "mass", 1e3, 0, 0, mass},
+	{"lb", "pound", "mass", 0.45359237, 0, 0, mass},
+	{"oz", "ounce", "mass", 0.028349523125, 0, 0, mass},
+	{"st", "stone", "mass", 6.35029318, 0, 0, mass},
+
+	// Temperature
+	{"K", "kelvin", "temperature", 1, 0, 0, temperature},
+	{"C", "celsius", "temperature", 1, 0, 273.15, temperature},
+	{"F", "fahrenheit", "temperature", 5.0 / 9.0, 32, 273.15, temperature},
+	{"R", "rankine", "temperature", 5.0 / 9.0, 0, 0, temperature},
+
+	// Time
+	{"s", "second", "time", 1, 0, 0, duration},
+	{"ms", "millisecond", "time", 1e-3, 0, 0, duration},
+	{"us", "microsecond", "time", 1e-6, 0, 0, duration},
+	{"ns", "nanosecond", "time", 1e-9, 0, 0, duration},
+	{"min", "minute", "time", 60, 0, 0, duration},
+	{"h", "hour", "time", 3600, 0, 0, duration},
+	{"d", "day", "time", 86400, 0, 0, duration},
+	{"wk", "week", "time", 604800, 0, 0, duration},
+	{"yr", "year", "time", 31557600, 0, 0, duration},
+
+	// Area
+	{"m2", "square metre", "area", 1, 0, 0, area},
+	{"km2", "square kilometre", "area", 1e6, 0, 0, area},
+	{"cm2", "square centimetre", "area", 1e-4, 0, 0, area},
+	{"mm2", "square millimetre", "area", 1e-6, 0, 0, area},
+	{"ha", "hectare", "area", 1e4, 0, 0, area},
+	{"acre", "acre", "area", 4046.8564224, 0, 0, area},
+	{"in2", "square inch", "area", 0.00064516, 0, 0, area},
+	{"ft2", "square foot", "area", 0.09290304, 0, 0, area},
+	{"mi2", "square mile", "area", 2589988.110336, 0, 0, area},
+
+	// Volume
+	{"m3", "cubic metre", "volume", 1, 0, 0, volume},
+	{"L", "litre", "volume", 1e-3, 0, 0, volume},
+	{"mL", "millilitre", "volume", 1e-6, 0, 0, volume},
+	{"cm3", "cubic centimetre", "volume", 1e-6, 0, 0, volume},
+	{"in3", "cubic inch", "volume", 1.6387064e-5, 0, 0, volume},
+	{"ft3", "cubic foot", "volume", 0.028316846592, 0, 0, volume},
+	{"gal", "US gallon", "volume", 3.785411784e-3, 0, 0, volume},
+	{"qt", "US quart", "volume", 9.46352946e-4, 0, 0, volume},
+	{"pt", "US pint", "volume", 4.73176473e-4, 0, 0, volume},
+	{"floz", "US fluid ounce", "volume", 2.95735295625e-5, 0, 0, volume},
+
+	// Speed
+	{"m/s", "metre per second", "speed", 1, 0, 0, speed},
+	{"km/h", "kilometre per hour", "speed", 1e3 / 3600, 0, 0, speed},
+	{"mph", "mile per hour", "speed", 1609.344 / 3600, 0, 0, speed},
+	{"kn", "knot", "speed", 1852.0 / 3600, 0, 0, speed},
+	{"ft/s", "foot per second", "speed", 0.3048, 0, 0, speed},
+
+	// Data size
+	{"bit", "bit", "data", 1, 0, 0, dataSize},
+	{"B", "byte", "data", 8, 0, 0, dataSize},
+	{"kbit", "kilobit", "data", 1e3, 0, 0, dataSize},
+	{"Mbit", "megabit", "data", 1e6, 0, 0, dataSize},
+	{"Gbit", "gigabit", "data", 1e9, 0, 0, dataSize},
+	{"kB", "kilobyte", "data", 8e3, 0, 0, dataSize},
+	{"MB", "megabyte", "data", 8e6, 0, 0, dataSize},
+	{"GB", "gigabyte", "data", 8e9, 0, 0, dataSize},
+	{"TB", "terabyte", "data", 8e12, 0, 0, dataSize},
+	{"PB", "petabyte", "data", 8e15, 0, 0, dataSize},
+	{"KiB", "kibibyte", "data", 8 * (1 << 10), 0, 0, dataSize},
+	{"MiB", "mebibyte", "data", 8 * (1 << 20), 0, 0, dataSize},
+	{"GiB", "gibibyte", "data", 8 * (1 << 30), 0, 0, dataSize},
+	{"TiB", "tebibyte", "data", 8 * (1 << 40), 0, 0, dataSize},
+	{"PiB", "pebibyte", "data", 8 * (1 << 50), 0, 0, dataSize},
+
+	// Energy
+	{"J", "joule", "energy", 1, 0, 0, energy},
+	{"kJ", "kilojoule", "energy", 1e3, 0, 0, energy},
+	{"MJ", "megajoule", "energy", 1e6, 0, 0, energy},
+	{"cal", "calorie", "energy", 4.184, 0, 0, energy},
+	{"kcal", "kilocalorie", "energy", 4184, 0, 0, energy},
+	{"Wh", "watt hour", "energy", 3600, 0, 0, energy},
+	{"kWh", "kilowatt hour", "energy", 3.6e6, 0, 0, energy},
+	{"eV", "electronvolt", "energy", 1.602176634e-19, 0, 0, energy},
+	{"BTU", "British thermal unit", "energy", 1055.05585262, 0, 0, energy},
+}
+
+// unitsBySymbol and unitsByName index unitCatalogue; symbols are case-sensitive, names are not
+var unitsBySymbol, unitsByName = indexUnits(unitCatalogue)
+
+// indexUnits indexes units by symbol, including common aliases, and by singular and plural name
+func indexUnits(units []Unit) (map[string]Unit, map[string]Unit) {
+	bySymbol := make(map[string]Unit, len(units))
+	byName := make(map[string]Unit, len(units))
+	for _, u := range units {
+		bySymbol[u.Symbol] = u
+		byName[strings.ToLower(u.Name)] = u
+		byName[strings.ToLower(u.Name)+"s"] = u
+	}
+	// Common aliases
+	for alias, symbol := range map[string]string{
+		"°C": "C", "degC": "C", "°F": "F", "degF": "F", "l": "L", "ml": "mL", "µm": "um", "µg": "ug",
+		"µs": "us", "sec": "s", "hr": "h", "day": "d", "kph": "km/h", "b": "bit", "meter": "m", "meters": "m",
+	} {
+		bySymbol[alias] = bySymbol[symbol]
+	}
+	return bySymbol, byName
+}
+
+// LookupUnit finds a unit by case-sensitive symbol or alias, such as "km/h" or "°C", or by name, such
+// as "kilometres"
+func LookupUnit(name string) (Unit, error) {
+	if u, ok := unitsBySymbol[name]; ok {
+		return u, nil
+	}
+	if u, ok := unitsByName[strings.ToLower(name)]; ok {
+		return u, nil
+	}
+	return Unit{}, NewError(CodeUnknownUnit, fmt.Sprintf("unknown unit '%s'", name)).WithDetail("unit", name)
+}
+
+// toBase converts value in u to the coherent base unit
+func (u Unit) toBase(value float64) float64 {
+	return (value-u.Offset)*u.Factor + u.Origin
+}
+
+// fromBase converts a value in the coherent base unit to u
+func (u Unit) fromBase(value float64) float64 {
+	return (value-u.Origin)/u.Factor + u.Offset
+}
+
+// affine reports whether u does not share its zero with the base unit, like the offset temperatures
+func (u Unit) affine() bool {
+	return u.Offset != 0 || u.Origin != 0
+}
+
+// convertTo converts value in u to the unit to of the same dimension
+func (u Unit) convertTo(to Unit, value float64) float64 {
+	if u.Origin == to.Origin {
+		return (value-u.Offset)*u.Factor/to.Factor + to.Offset
+	}
+	return to.fromBase(u.toBase(value))
+}
+
+// String formats a dimension as a product of base units, e.g. "kg*m^2/s^2"
+func (d Dimension) String() string {
+	var num, den []string
+	for i, exp := range d {
+		switch {
+		case exp == 1:
+			num = append(num, baseUnitSymbols[i])
+		case exp > 1:
+			num = append(num, fmt.Sprintf("%s^%d", baseUnitSymbols[i], exp))
+		case exp == -1:
+			den = append(den, baseUnitSymbols[i])
+		case exp < -1:
+			den = append(den, fmt.Sprintf("%s^%d", baseUnitSymbols[i], -exp))
+		}
+	}
+	if len(num) == 0 && len(den) == 0 {
+		return ""
+	}
+	out := strings.Join(num, "*")
+	if out == "" {
+		out = "1"
+	}
+	if len(den) > 0 {
+		out += "/" + strings.Join(den, "/")
+	}
+	return out
+}
+
+// coherentUnit returns the catalogue unit with factor 1 for d, or a synthesized base-unit expression
+func coherentUnit(d Dimension) Unit {
+	for _, u := range unitCatalogue {
+		if u.Dim == d && u.Factor == 1 && !u.affine() {
+			return u
+		}
+	}
+	return Unit{Symbol: d.String(), Name: d.String(), Category: "derived", Factor: 1, Dim: d}
+}
+
+// incompatibleUnitsError reports that two units have different dimensions
+func incompatibleUnitsError(message string, a, b Unit) *Error {
+	return NewError(CodeIncompatibleUnits, message).
+		WithDetail("a", a.Symbol).
+		WithDetail("b", b.Symbol).
+		WithDetail("a_category", a.Category).
+		WithDetail("b_category", b.Category)
+}
+
+// Units returns the unit catalogue ordered by category, or only the units of category when it is not
+// empty
+func Units(category string) []Unit {
+	units := make([]Unit, 0, len(unitCatalogue))
+	for _, u := range unitCatalogue {
+		if category == "" || u.Category == category {
+			units = append(units, u)
+		}
+	}
+	sort.SliceStable(units, func(i, j int) bool { return units[i].Category < units[j].Category })
+	return units
+}
+
+// Convert converts value from one unit to another of the same dimension, such as "km/h" to "m/s"
+func Convert(ctx context.Context, value float64, from, to string) (Quantity, error) {
+	log := logger(ctx)
+	log.Debug("Performing unit conversion", "value", value, "from", from, "to", to)
+	fromUnit, err := LookupUnit(from)
+	if err != nil {
+		return Quantity{}, err
+	}
+	toUnit, err := LookupUnit(to)
+	if err != nil {
+		return Quantity{}, err
+	}
+	if fromUnit.Dim != toUnit.Dim {
+		log.Error("Conversion between incompatible units attempted", "from", from, "to", to)
+		return Quantity{}, incompatibleUnitsError(
+			fmt.Sprintf("cannot convert %s (%s) to %s (%s)", fromUnit.Symbol, fromUnit.Category, toUnit.Symbol, toUnit.Category),
+			fromUnit, toUnit)
+	}
+	result := fromUnit.convertTo(toUnit, value)
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		log.Error("Unit conversion out of range", "value", value, "from", from, "to", to)
+		return Quantity{}, NewError(CodeDomainError, "conversion result is not a finite number").
+			WithDetail("result", fmt.Sprint(result))
+	}
+	log.Debug("Unit conversion result", "result", result)
+	return Quantity{Value: result, Unit: toUnit}, nil
+}
+
+// NewQuantity returns value in the named unit; an empty unit is dimensionless
+func NewQuantity(value float64, unit string) (Quantity, error) {
+	if unit == "" {
+		return Quantity{Value: value, Unit: coherentUnit(dimensionless)}, nil
+	}
+	u, err := LookupUnit(unit)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{Value: value, Unit: u}, nil
+}
+
+// AddQuantities adds b to a, expressing the result in a's unit
+func AddQuantities(ctx context.Context, a, b Quantity) (Quantity, error) {
+	return sumQuantities(ctx, a, b, Add)
+}
+
+// SubtractQuantities subtracts b from a, expressing the result in a's unit
+func SubtractQuantities(ctx context.Context, a, b Quantity) (Quantity, error) {
+	return sumQuantities(ctx, a, b, Subtract)
+}
+
+// sumQuantities applies the sum or difference op to a and b in a's unit
+func sumQuantities(ctx context.Context, a, b Quantity, op BinaryFunc) (Quantity, error) {
+	log := logger(ctx)
+	log.Debug("Performing dimensioned sum", "a", a.Value, "a_unit", a.Unit.Symbol, "b", b.Value, "b_unit", b.Unit.Symbol)
+	if a.Unit.Dim != b.Unit.Dim {
+		log.Error("Sum of incompatible units attempted", "a_unit", a.Unit.Symbol, "b_unit", b.Unit.Symbol)
+		return Quantity{}, incompatibleUnitsError("cannot add or subtract quantities of different dimensions", a.Unit, b.Unit)
+	}
+	bValue := b.Value
+	if a.Unit.Symbol != b.Unit.Symbol {
+		if a.Unit.affine() || b.Unit.affine() {
+			return Quantity{}, incompatibleUnitsError("cannot add or subtract offset temperatures in different units", a.Unit, b.Unit)
+		}
+		bValue = b.Value * b.Unit.Factor / a.Unit.Factor
+	}
+	result, err := op(ctx, a.Value, bValue)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{Value: result, Unit: a.Unit}, nil
+}
+
+// MultiplyQuantities multiplies a by b, expressing the result in coherent base units
+func MultiplyQuantities(ctx context.Context, a, b Quantity) (Quantity, error) {
+	return productQuantities(ctx, a, b, Multiply, 1)
+}
+
+// DivideQuantities divides a by b, expressing the result in coherent base units
+func DivideQuantities(ctx context.Context, a, b Quantity) (Quantity, error) {
+	return productQuantities(ctx, a, b, Divide, -1)
+}
+
+// productQuantities applies the product or quotient op to a and b; sign is the exponent b's dimension
+// contributes to the result
+func productQuantities(ctx context.Context, a, b Quantity, op BinaryFunc, sign int) (Quantity, error) {
+	log := logger(ctx)
+	log.Debug("Performing dimensioned product", "a", a.Value, "a_unit", a.Unit.Symbol, "b", b.Value, "b_unit", b.Unit.Symbol)
+	if a.Unit.affine() || b.Unit.affine() {
+		log.Error("Product of offset unit attempted", "a_unit", a.Unit.Symbol, "b_unit", b.Unit.Symbol)
+		return Quantity{}, incompatibleUnitsError("cannot multiply or divide offset temperatures, convert to K first", a.Unit, b.Unit)
+	}
+	// A dimensionless operand scales the other without changing its unit
+	if b.Unit.Dim == dimensionless {
+		result, err := op(ctx, a.Value, b.Value)
+		return Quantity{Value: result, Unit: a.Unit}, err
+	}
+	if a.Unit.Dim == dimensionless && sign > 0 {
+		result, err := op(ctx, a.Value, b.Value)
+		return Quantity{Value: result, Unit: b.Unit}, err
+	}
+	var dim Dimension
+	for i := range dim {
+		dim[i] = a.Unit.Dim[i] + sign*b.Unit.Dim[i]
+	}
+	result, err := op(ctx, a.Unit.toBase(a.Value), b.Unit.toBase(b.Value))
+	if err != nil {
+		return Quantity{}, err
+	}
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		return Quantity{}, NewError(CodeDomainError, "result is not a finite number")
+	}
+	return Quantity{Value: result, Unit: coherentUnit(dim)}, nil
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTemperatureConversions tests that exact temperatures convert exactly in both directions
+func TestTemperatureConversions(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		celsius    float64
+		fahrenheit float64
+	}{
+		{0, 32},
+		{100, 212},
+		{-40, -40},
+	}
+	for _, tt := range tests {
+		result, err := Convert(ctx, tt.celsius, "C", "F")
+		assert.NoError(t, err)
+		assert.Equal(t, tt.fahrenheit, result.Value)
+
+		result, err = Convert(ctx, tt.fahrenheit, "F", "C")
+		assert.NoError(t, err)
+		assert.Equal(t, tt.celsius, result.Value)
+	}
+
+	result, err := Convert(ctx, 100, "C", "K")
+	assert.NoError(t, err)
+	assert.Equal(t, 373.15, result.Value)
+	result, err = Convert(ctx, 491.67, "R", "F")
+	assert.NoError(t, err)
+	assert.InDelta(t, 32, result.Value, 1e-9)
+}
+
+// TestConvertOverflow tests that a conversion beyond the range of float64 is a DOMAIN_ERROR
+func TestConvertOverflow(t *testing.T) {
+	_, err := Convert(context.Background(), 1e308, "km", "mm")
+	assert.Equal(t, CodeDomainError, AsError(err).Code)
+}
+
+// TestQuantities tests sums, products and quotients of dimensioned values
+func TestQuantities(t *testing.T) {
+	ctx := context.Background()
+	quantity := func(value float64, unit string) Quantity {
+		q, err := NewQuantity(value, unit)
+		require.NoError(t, err)
+		return q
+	}
+
+	sum, err := AddQuantities(ctx, quantity(1, "km"), quantity(500, "m"))
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, sum.Value)
+	assert.Equal(t, "km", sum.Unit.Symbol)
+
+	speed, err := DivideQuantities(ctx, quantity(100, "m"), quantity(20, "s"))
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, speed.Value)
+	assert.Equal(t, "m/s", speed.Unit.Symbol)
+
+	scaled, err := MultiplyQuantities(ctx, quantity(3, ""), quantity(2, "kg"))
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, scaled.Value)
+	assert.Equal(t, "kg", scaled.Unit.Symbol)
+
+	_, err = SubtractQuantities(ctx, quantity(1, "m"), quantity(1, "s"))
+	assert.Equal(t, CodeIncompatibleUnits, AsError(err).Code)
+	_, err = MultiplyQuantities(ctx, quantity(20, "C"), quantity(2, "m"))
+	assert.Equal(t, CodeIncompatibleUnits, AsError(err).Code)
+	_, err = NewQuantity(1, "furlong")
+	assert.Equal(t, CodeUnknownUnit, AsError(err).Code)
+	_, err = Convert(ctx, 1, "m", "kg")
+	assert.Equal(t, CodeIncompatibleUnits, AsError(err).Code)
+
+	units := Units("speed")
+	require.NotEmpty(t, units)
+	for _, u := range units {
+		assert.Equal(t, "speed", u.Category)
+	}
+}
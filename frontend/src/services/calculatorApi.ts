@@ -4,7 +4,23 @@ import {
   BinaryRequest, 
   UnaryRequest,
   BinaryOperation,
-  UnaryOperation
+  UnaryOperation,
+  Operation,
+  CalculatorSession,
+  SessionOperationResponse,
+  MemoryAction,
+  PlotRequest,
+  PlotResponse,
+  RationalRequest,
+  RationalResponse,
+  PolynomialRequest,
+  PolynomialResponse,
+  LinearSystemRequest,
+  LinearSystemResponse,
+  HistoryResponse,
+  GraphQLResponse,
+  WSResponse,
+  CalculationEvent
 } from '../types/calculator';
 
 const API_BASE_URL = 'http://localhost:8080/api/v1';
@@ -52,6 +68,157 @@ class CalculatorApiService {
     return this.handleResponse<CalculatorResponse>(response);
   }
 
+  // Sessions
+  async createSession(): Promise<CalculatorSession> {
+    const response = await fetch(`${API_BASE_URL}/sessions`, { method: 'POST' });
+    return this.handleResponse<CalculatorSession>(response);
+  }
+
+  async getSession(id: string): Promise<CalculatorSession> {
+    const response = await fetch(`${API_BASE_URL}/sessions/${id}`);
+    return this.handleResponse<CalculatorSession>(response);
+  }
+
+  async setAccumulator(id: string, value: number): Promise<CalculatorSession> {
+    const response = await fetch(`${API_BASE_URL}/sessions/${id}/accumulator`, {
+      method: 'PUT',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ value }),
+    });
+    return this.handleResponse<CalculatorSession>(response);
+  }
+
+  async sessionOperation(id: string, operation: Operation, b?: number): Promise<SessionOperationResponse> {
+    const response = await fetch(`${API_BASE_URL}/sessions/${id}/operations/${operation}`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify(b === undefined ? {} : { b }),
+    });
+    return this.handleResponse<SessionOperationResponse>(response);
+  }
+
+  async sessionMemory(id: string, action: MemoryAction, value?: number, register?: string): Promise<CalculatorSession> {
+    const response = await fetch(`${API_BASE_URL}/sessions/${id}/memory`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ action, value, register }),
+    });
+    return this.handleResponse<CalculatorSession>(response);
+  }
+
+  // Plotting
+  async plot(request: PlotRequest): Promise<PlotResponse> {
+    const response = await fetch(`${API_BASE_URL}/plot`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify(request),
+    });
+    return this.handleResponse<PlotResponse>(response);
+  }
+
+  // Rational mode
+  async rational(operation: string, request: RationalRequest): Promise<RationalResponse> {
+    const response = await fetch(`${API_BASE_URL}/rational/${operation}`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify(request),
+    });
+    return this.handleResponse<RationalResponse>(response);
+  }
+
+  // Equation solvers
+  async solvePolynomial(request: PolynomialRequest): Promise<PolynomialResponse> {
+    const response = await fetch(`${API_BASE_URL}/solve/polynomial`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify(request),
+    });
+    return this.handleResponse<PolynomialResponse>(response);
+  }
+
+  async solveLinearSystem(request: LinearSystemRequest): Promise<LinearSystemResponse> {
+    const response = await fetch(`${API_BASE_URL}/solve/linear`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify(request),
+    });
+    return this.handleResponse<LinearSystemResponse>(response);
+  }
+
+  // History of the caller identified by apiKey
+  async history(apiKey: string, limit?: number): Promise<HistoryResponse> {
+    const query = limit === undefined ? '' : `?limit=${limit}`;
+    const response = await fetch(`${API_BASE_URL}/history${query}`, {
+      headers: {
+        'X-API-Key': apiKey,
+      },
+    });
+    return this.handleResponse<HistoryResponse>(response);
+  }
+
+  // GraphQL: several results, history and sessions in one request. Failed fields are null
+  // and listed in errors with their error codes.
+  async graphql<T>(query: string, variables?: Record<string, unknown>, apiKey?: string): Promise<GraphQLResponse<T>> {
+    const headers: Record<string, string> = {
+      'Content-Type': 'application/json',
+    };
+    if (apiKey) {
+      headers['X-API-Key'] = apiKey;
+    }
+    const response = await fetch('http://localhost:8080/graphql', {
+      method: 'POST',
+      headers,
+      body: JSON.stringify({ query, variables }),
+    });
+    return this.handleResponse<GraphQLResponse<T>>(response);
+  }
+
+  // WebSocket channel: send WSRequest messages with socket.send(JSON.stringify(request)); every
+  // reply carries the request's id
+  openSocket(onMessage: (message: WSResponse) => void, apiKey?: string, sessionId?: string): WebSocket {
+    const params = new URLSearchParams();
+    if (apiKey) {
+      params.set('api_key', apiKey);
+    }
+    if (sessionId) {
+      params.set('session_id', sessionId);
+    }
+    const query = params.toString();
+    const socket = new WebSocket(`ws://localhost:8080/ws${query ? `?${query}` : ''}`);
+    socket.onmessage = (event) => onMessage(JSON.parse(event.data) as WSResponse);
+    return socket;
+  }
+
+  // Events feed: EventSource reconnects with Last-Event-ID on its own, so no calculation is missed
+  // while the buffer holds it
+  openEvents(onEvent: (event: CalculationEvent) => void, operations: string[] = [], clients: string[] = []): EventSource {
+    const params = new URLSearchParams();
+    if (operations.length > 0) {
+      params.set('operation', operations.join(','));
+    }
+    if (clients.length > 0) {
+      params.set('client', clients.join(','));
+    }
+    const query = params.toString();
+    const source = new EventSource(`${API_BASE_URL}/events${query ? `?${query}` : ''}`);
+    source.addEventListener('calculation', (event) => onEvent(JSON.parse((event as MessageEvent).data) as CalculationEvent));
+    return source;
+  }
+
   // Health check
   async healthCheck(): Promise<{ status: string }> {
     const response = await fetch('http://localhost:8080/health');
@@ -1,10 +1,23 @@
 // API Response types
 export interface CalculatorResponse {
   result: number;
+  unit?: string;
+  formatted?: string;
+}
+
+// Rounding and formatting options, sent as query parameters
+export interface FormatOptions {
+  scale?: number;
+  significant?: number;
+  rounding?: 'half-up' | 'half-even' | 'down' | 'ceiling' | 'floor';
+  notation?: 'plain' | 'scientific' | 'engineering';
+  locale?: string;
 }
 
 export interface ErrorResponse {
   error: string;
+  code?: string;
+  details?: Record<string, unknown>;
 }
 
 // Binary operation request types
@@ -24,6 +37,164 @@ export type UnaryOperation = 'inverse' | 'negative' | 'sqrt';
 
 export type Operation = BinaryOperation | UnaryOperation;
 
+// Server-side session types
+export interface CalculatorSession {
+  id: string;
+  accumulator: number;
+  ans: number;
+  memory: Record<string, number>;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface SessionOperationResponse {
+  result: number;
+  session: CalculatorSession;
+}
+
+export type MemoryAction = 'MC' | 'MR' | 'M+' | 'M-' | 'MS';
+
+// Plot types
+export interface PlotRequest {
+  expression: string;
+  variable?: string;
+  a: number | string;
+  b: number | string;
+  samples?: number;
+  max_points?: number;
+  svg?: boolean;
+  width?: number;
+  height?: number;
+}
+
+export interface PlotPoint {
+  x: number;
+  y: number;
+}
+
+export interface PlotGap {
+  from: number;
+  to: number;
+}
+
+export interface PlotResponse {
+  segments: PlotPoint[][];
+  gaps: PlotGap[];
+  points: number;
+  y_min: number;
+  y_max: number;
+  svg?: string;
+}
+
+// Rational mode types
+export interface RationalRequest {
+  a: number | string;
+  b?: number | string;
+}
+
+export interface RationalResponse {
+  result: number;
+  numerator: string;
+  denominator: string;
+  fraction: string;
+  mixed: string;
+  decimal: string;
+  approximate: boolean;
+}
+
+// Solver types
+export interface PolynomialRequest {
+  coefficients: (number | string)[];
+}
+
+export interface PolynomialRoot {
+  real: number;
+  imag: number;
+  multiplicity: number;
+}
+
+export interface PolynomialResponse {
+  degree: number;
+  solutions: 'finite' | 'none' | 'infinite';
+  roots: PolynomialRoot[];
+  method?: string;
+}
+
+export interface LinearSystemRequest {
+  equations: string[];
+}
+
+export interface LinearSystemResponse {
+  solutions: 'unique' | 'none' | 'infinite';
+  variables: string[];
+  rank: number;
+  solution?: Record<string, number>;
+  free_variables?: string[];
+  general?: Record<string, string>;
+}
+
+// Server-side history and GraphQL types
+export interface ServerHistoryEntry {
+  operation: string;
+  operands: number[];
+  result: number;
+  unit?: string;
+  created_at: string;
+}
+
+export interface HistoryResponse {
+  history: ServerHistoryEntry[];
+}
+
+export interface GraphQLError {
+  message: string;
+  path?: (string | number)[];
+  extensions?: {
+    code: string;
+    details?: Record<string, unknown>;
+  };
+}
+
+export interface GraphQLResponse<T> {
+  data?: T;
+  errors?: GraphQLError[];
+}
+
+// WebSocket message types
+export type WSRequestType = 'calculate' | 'session_operation' | 'session' | 'ping';
+
+export interface WSRequest {
+  id: string;
+  type?: WSRequestType;
+  operation?: string;
+  a?: number | string;
+  b?: number | string;
+  session?: string;
+}
+
+export interface WSResponse {
+  id?: string;
+  type: 'result' | 'error' | 'session' | 'pong';
+  result?: number;
+  error?: string;
+  code?: string;
+  details?: Record<string, unknown>;
+  session?: CalculatorSession;
+}
+
+// Events feed types
+export interface CalculationEvent {
+  id: number;
+  time: string;
+  operation: string;
+  transport: 'http' | 'grpc' | 'graphql' | 'websocket';
+  client: string;
+  inputs?: unknown;
+  result?: unknown;
+  code?: string;
+  latency_ms: number;
+}
+
 // Validation error types
 export interface ValidationError {
   field: string;
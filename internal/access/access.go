@@ -0,0 +1,149 @@
+// Package access decides which callers may use which permissions.
+//
+// A Policy grants permissions to roles and roles to principals: the API keys callers authenticate with
+// and the subjects of their bearer tokens. Callers whose API key and token subject the policy does not
+// list are unauthenticated and hold the default roles.
+package access
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Permissions guarding expensive or administrative endpoints
+const (
+	// PermissionCompute allows the expensive calculations: expression evaluation, user-defined function
+	// calls, compound interest, future value and amortization schedules, calculus, plotting, the solvers
+	// and HTTP and gRPC batches
+	PermissionCompute = "compute"
+	// PermissionManageFunctions allows creating, replacing and deleting user-defined functions
+	PermissionManageFunctions = "functions.manage"
+	// PermissionClearHistory allows clearing calculation history
+	PermissionClearHistory = "history.clear"
+	// PermissionReadAudit allows querying the audit trail
+	PermissionReadAudit = "audit.read"
+	// PermissionManageTenants allows creating, listing and disabling tenants and reading their metrics
+	PermissionManageTenants = "tenants.manage"
+	// PermissionAll grants every permission
+	PermissionAll = "*"
+)
+
+// Permissions lists the permissions a role may be granted
+var Permissions = []string{PermissionCompute, PermissionManageFunctions, PermissionClearHistory, PermissionReadAudit, PermissionManageTenants, PermissionAll}
+
+// Config defines roles and the principals holding them, as read from a JSON configuration file
+type Config struct {
+	// Roles maps each role name to the permissions it grants
+	Roles map[string][]string `json:"roles"`
+	// Principals maps each API key to its roles
+	Principals map[string][]string `json:"principals"`
+	// Subjects maps each bearer token subject, written "tenant/subject", to its roles
+	Subjects map[string][]string `json:"subjects"`
+	// DefaultRoles are held by unauthenticated callers
+	DefaultRoles []string `json:"default_roles"`
+}
+
+// Policy answers whether a principal holds a permission. It is immutable and safe for concurrent use.
+type Policy struct {
+	principals map[string]map[string]bool
+	subjects   map[string]map[string]bool
+	defaults   map[string]bool
+}
+
+// Principal identifies a caller by the API key it sent and the tenant and subject of its bearer token,
+// any of which may be empty
+type Principal struct {
+	APIKey  string
+	Tenant  string
+	Subject string
+}
+
+// New returns the policy defined by cfg, rejecting unknown permissions and undefined roles
+func New(cfg Config) (*Policy, error) {
+	for role, permissions := range cfg.Roles {
+		for _, permission := range permissions {
+			if !slices.Contains(Permissions, permission) {
+				return nil, fmt.Errorf("role '%s' grants unknown permission '%s'", role, permission)
+			}
+		}
+	}
+	grants := func(roles []string) (map[string]bool, error) {
+		permissions := map[string]bool{}
+		for _, role := range roles {
+			granted, ok := cfg.Roles[role]
+			if !ok {
+				return nil, fmt.Errorf("role '%s' is not defined", role)
+			}
+			for _, permission := range granted {
+				permissions[permission] = true
+			}
+		}
+		return permissions, nil
+	}
+
+	p := &Policy{
+		principals: make(map[string]map[string]bool, len(cfg.Principals)),
+		subjects:   make(map[string]map[string]bool, len(cfg.Subjects)),
+	}
+	var err error
+	if p.defaults, err = grants(cfg.DefaultRoles); err != nil {
+		return nil, err
+	}
+	for key, roles := range cfg.Principals {
+		if key == "" {
+			return nil, fmt.Errorf("principal API keys cannot be empty")
+		}
+		if p.principals[key], err = grants(roles); err != nil {
+			return nil, err
+		}
+	}
+	for subject, roles := range cfg.Subjects {
+		if tenant, name, ok := strings.Cut(subject, "/"); !ok || tenant == "" || name == "" {
+			return nil, fmt.Errorf("subject '%s' must be written tenant/subject", subject)
+		}
+		if p.subjects[subject], err = grants(roles); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Load reads a JSON Config from r and returns its policy
+func Load(r io.Reader) (*Policy, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	var cfg Config
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid access configuration: %w", err)
+	}
+	return New(cfg)
+}
+
+// grants returns the permissions of principal: those of its API key when listed, otherwise those of
+// its token subject when listed, otherwise the defaults. It reports whether either was listed.
+func (p *Policy) grants(principal Principal) (map[string]bool, bool) {
+	if permissions, ok := p.principals[principal.APIKey]; ok {
+		return permissions, true
+	}
+	if principal.Subject != "" {
+		if permissions, ok := p.subjects[principal.Tenant+"/"+principal.Subject]; ok {
+			return permissions, true
+		}
+	}
+	return p.defaults, false
+}
+
+// Authenticated reports whether principal's API key or token subject is listed by the policy
+func (p *Policy) Authenticated(principal Principal) bool {
+	_, ok := p.grants(principal)
+	return ok
+}
+
+// Allowed reports whether principal holds permission
+func (p *Policy) Allowed(principal Principal, permission string) bool {
+	permissions, _ := p.grants(principal)
+	return permissions[permission] || permissions[PermissionAll]
+}
@@ -0,0 +1,86 @@
+package access
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPolicy tests granting permissions through roles
+func TestPolicy(t *testing.T) {
+	p, err := Load(strings.NewReader(`{
+		"roles": {
+			"user": ["functions.manage"],
+			"analyst": ["compute", "history.clear"],
+			"admin": ["*"]
+		},
+		"principals": {"alice": ["user", "analyst"], "bob": ["user"], "root": ["admin"]},
+		"subjects": {"acme/carol": ["analyst"], "default/dave": ["admin"]},
+		"default_roles": []
+	}`))
+	require.NoError(t, err)
+
+	carol := Principal{Tenant: "acme", Subject: "carol"}
+	tests := []struct {
+		principal  Principal
+		permission string
+		allowed    bool
+	}{
+		{Principal{APIKey: "alice"}, PermissionCompute, true},
+		{Principal{APIKey: "alice"}, PermissionManageFunctions, true},
+		{Principal{APIKey: "alice"}, PermissionReadAudit, false},
+		{Principal{APIKey: "bob"}, PermissionCompute, false},
+		{Principal{APIKey: "bob"}, PermissionManageFunctions, true},
+		{Principal{APIKey: "root"}, PermissionReadAudit, true},
+		{Principal{APIKey: "root"}, PermissionCompute, true},
+		{Principal{}, PermissionManageFunctions, false},
+		{Principal{APIKey: "mallory"}, PermissionCompute, false},
+		{carol, PermissionCompute, true},
+		{carol, PermissionManageFunctions, false},
+		{Principal{Tenant: "default", Subject: "dave"}, PermissionReadAudit, true},
+		// Subjects are listed per tenant
+		{Principal{Tenant: "other", Subject: "carol"}, PermissionCompute, false},
+		// A listed API key takes precedence over the token subject
+		{Principal{APIKey: "bob", Tenant: "acme", Subject: "carol"}, PermissionCompute, false},
+		{Principal{APIKey: "mallory", Tenant: "acme", Subject: "carol"}, PermissionCompute, true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, p.Allowed(tt.principal, tt.permission), "%+v %s", tt.principal, tt.permission)
+	}
+	assert.True(t, p.Authenticated(Principal{APIKey: "bob"}))
+	assert.True(t, p.Authenticated(carol))
+	assert.False(t, p.Authenticated(Principal{APIKey: "mallory"}))
+	assert.False(t, p.Authenticated(Principal{Tenant: "acme", Subject: "mallory"}))
+	assert.False(t, p.Authenticated(Principal{}))
+
+	p, err = New(Config{Roles: map[string][]string{"guest": {PermissionCompute}}, DefaultRoles: []string{"guest"}})
+	require.NoError(t, err)
+	assert.True(t, p.Allowed(Principal{}, PermissionCompute))
+	assert.True(t, p.Allowed(Principal{APIKey: "mallory"}, PermissionCompute))
+	assert.False(t, p.Allowed(Principal{}, PermissionClearHistory))
+}
+
+// TestInvalidConfig tests rejecting configurations that do not define a policy
+func TestInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{"unknown permission", `{"roles": {"user": ["launch"]}}`, "role 'user' grants unknown permission 'launch'"},
+		{"undefined role", `{"principals": {"alice": ["user"]}}`, "role 'user' is not defined"},
+		{"undefined default role", `{"default_roles": ["guest"]}`, "role 'guest' is not defined"},
+		{"empty key", `{"roles": {"user": []}, "principals": {"": ["user"]}}`, "principal API keys cannot be empty"},
+		{"subject without tenant", `{"roles": {"user": []}, "subjects": {"carol": ["user"]}}`, "subject 'carol' must be written tenant/subject"},
+		{"undefined subject role", `{"subjects": {"acme/carol": ["user"]}}`, "role 'user' is not defined"},
+		{"unknown field", `{"users": {}}`, `invalid access configuration: json: unknown field "users"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(strings.NewReader(tt.config))
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
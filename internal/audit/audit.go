@@ -0,0 +1,249 @@
+// Package audit keeps a tamper-evident trail of calculations and administrative actions.
+//
+// Records are append-only and hash-chained: each record's hash covers its contents and the hash of the
+// record before it, so editing, reordering or deleting a record breaks the chain and is detected by Verify.
+// Removing records from the end leaves a valid but shorter chain; compare the head hash with one noted
+// earlier to detect it.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Actions recorded in the audit trail
+const (
+	ActionCalculation        = "calculation"
+	ActionSessionCreate      = "session.create"
+	ActionSessionDelete      = "session.delete"
+	ActionSessionClear       = "session.clear"
+	ActionSessionAccumulator = "session.accumulator"
+	ActionSessionMemory      = "session.memory"
+	ActionVariableCreate     = "variable.create"
+	ActionVariableUpdate     = "variable.update"
+	ActionVariableDelete     = "variable.delete"
+	ActionFunctionCreate     = "function.create"
+	ActionFunctionUpdate     = "function.update"
+	ActionFunctionDelete     = "function.delete"
+	ActionHistoryClear       = "history.clear"
+	ActionAuthFailure        = "auth.failure"
+	ActionAccessDenied       = "access.denied"
+	ActionTenantCreate       = "tenant.create"
+	ActionTenantDisable      = "tenant.disable"
+	ActionTenantEnable       = "tenant.enable"
+)
+
+// maxLineBytes bounds a record read by Read
+const maxLineBytes = 1 << 20
+
+// Record is one entry of the audit trail. Seq, Time, PrevHash and Hash are set by Log.Append.
+type Record struct {
+	Seq  uint64    `json:"seq"`
+	Time time.Time `json:"time"`
+	// Actor is who acted: an owner key such as "key:alice" or "session:<id>", or "client:<id or address>"
+	Actor    string `json:"actor"`
+	Action   string `json:"action"`
+	Resource string `json:"resource,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Code     string `json:"code,omitempty"`
+	// Details holds the action's inputs and result as a JSON object
+	Details  json.RawMessage `json:"details,omitempty"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash"`
+}
+
+// ChainError reports where the hash chain of a trail is broken
+type ChainError struct {
+	Seq    uint64
+	Reason string
+}
+
+// Error implements the error interface
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit record %d: %s", e.Seq, e.Reason)
+}
+
+// computeHash returns the hash of r, covering every field except Hash itself
+func computeHash(r Record) (string, error) {
+	r.Hash = ""
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Verify checks that records form one unbroken chain starting at the first record of a trail
+func Verify(records []Record) error {
+	prev := ""
+	for i, r := range records {
+		if r.Seq != uint64(i)+1 {
+			return &ChainError{Seq: r.Seq, Reason: fmt.Sprintf("expected sequence number %d", i+1)}
+		}
+		if r.PrevHash != prev {
+			return &ChainError{Seq: r.Seq, Reason: "previous hash does not match the preceding record"}
+		}
+		hash, err := computeHash(r)
+		if err != nil {
+			return &ChainError{Seq: r.Seq, Reason: err.Error()}
+		}
+		if r.Hash != hash {
+			return &ChainError{Seq: r.Seq, Reason: "hash does not match the record's contents"}
+		}
+		prev = r.Hash
+	}
+	return nil
+}
+
+// Read parses a trail written by a Log, one JSON record per line
+func Read(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineBytes)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// Query selects records of a Log. Zero fields match every record.
+type Query struct {
+	Actor  string
+	Action string
+	Since  time.Time
+	Until  time.Time
+	// Limit is the maximum number of records returned; zero or less returns all
+	Limit int
+}
+
+// matches reports whether r is selected by q
+func (q Query) matches(r Record) bool {
+	return (q.Actor == "" || r.Actor == q.Actor) &&
+		(q.Action == "" || r.Action == q.Action) &&
+		(q.Since.IsZero() || !r.Time.Before(q.Since)) &&
+		(q.Until.IsZero() || r.Time.Before(q.Until))
+}
+
+// Log appends records to a trail, writing each as a JSON line to its writer and keeping the most recent
+// in memory for queries. It is safe for concurrent use.
+type Log struct {
+	mu         sync.Mutex
+	w          io.Writer
+	maxRecords int
+	records    []Record // oldest first
+	seq        uint64
+	head       string
+	now        func() time.Time
+}
+
+// New returns an empty trail writing records to w, which may be nil to keep them in memory only, and
+// keeping up to maxRecords of them for queries; a non-positive maxRecords keeps all
+func New(w io.Writer, maxRecords int) *Log {
+	return &Log{w: w, maxRecords: maxRecords, now: time.Now}
+}
+
+// Resume verifies records, the trail written so far, and continues the chain after them
+func (l *Log) Resume(records []Record) error {
+	if err := Verify(records); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.seq != 0 {
+		return errors.New("audit log already has records")
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	last := records[len(records)-1]
+	l.seq, l.head = last.Seq, last.Hash
+	if l.maxRecords > 0 && len(records) > l.maxRecords {
+		records = records[len(records)-l.maxRecords:]
+	}
+	l.records = append([]Record(nil), records...)
+	return nil
+}
+
+// Append chains r after the last record and writes it, returning the record as stored. If writing fails
+// the record is not added to the trail.
+func (l *Log) Append(r Record) (Record, error) {
+	if len(r.Details) > 0 {
+		// Store details in the compact form they are written in, so the hash survives a round trip
+		details, err := json.Marshal(r.Details)
+		if err != nil {
+			return Record{}, err
+		}
+		r.Details = details
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r.Seq = l.seq + 1
+	r.Time = l.now().UTC()
+	r.PrevHash = l.head
+	hash, err := computeHash(r)
+	if err != nil {
+		return Record{}, err
+	}
+	r.Hash = hash
+	if l.w != nil {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return Record{}, err
+		}
+		if _, err := l.w.Write(append(line, '\n')); err != nil {
+			return Record{}, err
+		}
+	}
+	l.seq, l.head = r.Seq, r.Hash
+	l.records = append(l.records, r)
+	if l.maxRecords > 0 && len(l.records) >= 2*l.maxRecords {
+		// Trim only once the slice has doubled, so appends stay amortized O(1)
+		l.records = append(l.records[:0:0], l.window()...)
+	}
+	return r, nil
+}
+
+// window returns the most recent records kept for queries, oldest first
+func (l *Log) window() []Record {
+	if l.maxRecords > 0 && len(l.records) > l.maxRecords {
+		return l.records[len(l.records)-l.maxRecords:]
+	}
+	return l.records
+}
+
+// Query returns the records in memory selected by q, newest first
+func (l *Log) Query(q Query) []Record {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	window := l.window()
+	records := []Record{}
+	for i := len(window) - 1; i >= 0 && (q.Limit <= 0 || len(records) < q.Limit); i-- {
+		if q.matches(window[i]) {
+			records = append(records, window[i])
+		}
+	}
+	return records
+}
+
+// Head returns the sequence number and hash of the last record; both are zero for an empty trail
+func (l *Log) Head() (uint64, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.seq, l.head
+}
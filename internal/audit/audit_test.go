@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// appendRecords appends n calculations by alice and a variable change by bob to l
+func appendRecords(t *testing.T, l *Log, n int) {
+	t.Helper()
+	for i := range n {
+		_, err := l.Append(Record{Actor: "key:alice", Action: ActionCalculation, Resource: "add", Status: 200,
+			Details: json.RawMessage(`{"inputs": {"a": 1, "b": ` + string(rune('0'+i)) + `}, "result": 3}`)})
+		require.NoError(t, err)
+	}
+	_, err := l.Append(Record{Actor: "key:bob", Action: ActionVariableCreate, Resource: "variables", Status: 201})
+	require.NoError(t, err)
+}
+
+// TestChain tests that records written by a Log read back as a valid chain
+func TestChain(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, 0)
+	appendRecords(t, l, 3)
+
+	records, err := Read(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	require.NoError(t, Verify(records))
+	assert.Equal(t, uint64(1), records[0].Seq)
+	assert.Empty(t, records[0].PrevHash)
+	assert.Equal(t, records[0].Hash, records[1].PrevHash)
+	assert.JSONEq(t, `{"inputs": {"a": 1, "b": 0}, "result": 3}`, string(records[0].Details))
+	seq, head := l.Head()
+	assert.Equal(t, uint64(4), seq)
+	assert.Equal(t, records[3].Hash, head)
+
+	// A resumed log continues the chain
+	resumed := New(&buf, 0)
+	require.NoError(t, resumed.Resume(records))
+	appendRecords(t, resumed, 1)
+	records, err = Read(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	assert.NoError(t, Verify(records))
+}
+
+// TestTampering tests that edited, deleted and reordered records are detected
+func TestTampering(t *testing.T) {
+	var buf bytes.Buffer
+	appendRecords(t, New(&buf, 0), 3)
+	original, err := Read(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		tamper func([]Record) []Record
+		seq    uint64
+	}{
+		{"edited result", func(r []Record) []Record {
+			r[1].Details = json.RawMessage(`{"inputs":{"a":1,"b":1},"result":4}`)
+			return r
+		}, 2},
+		{"edited actor", func(r []Record) []Record { r[3].Actor = "key:mallory"; return r }, 4},
+		{"deleted record", func(r []Record) []Record { return append(r[:1], r[2:]...) }, 3},
+		{"deleted first record", func(r []Record) []Record { return r[1:] }, 2},
+		{"reordered records", func(r []Record) []Record { r[1], r[2] = r[2], r[1]; return r }, 3},
+		{"rehashed edit", func(r []Record) []Record {
+			r[1].Actor = "key:mallory"
+			r[1].Hash, _ = computeHash(r[1])
+			return r
+		}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := tt.tamper(append([]Record(nil), original...))
+			err := Verify(records)
+			var chainErr *ChainError
+			require.True(t, errors.As(err, &chainErr), "expected a chain error, got %v", err)
+			assert.Equal(t, tt.seq, chainErr.Seq)
+		})
+	}
+
+	assert.Error(t, New(nil, 0).Resume(original[1:]))
+	_, err = Read(strings.NewReader("{\"seq\": 1}\nnot json\n"))
+	assert.EqualError(t, err, "line 2: invalid character 'o' in literal null (expecting 'u')")
+}
+
+// TestQuery tests selecting records newest first
+func TestQuery(t *testing.T) {
+	l := New(nil, 3)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	appendRecords(t, l, 3)
+
+	records := l.Query(Query{})
+	require.Len(t, records, 3, "only the most recent records are kept in memory")
+	assert.Equal(t, uint64(4), records[0].Seq)
+
+	records = l.Query(Query{Actor: "key:alice"})
+	require.Len(t, records, 2)
+	assert.Equal(t, uint64(3), records[0].Seq)
+
+	assert.Len(t, l.Query(Query{Action: ActionVariableCreate}), 1)
+	assert.Len(t, l.Query(Query{Limit: 1}), 1)
+	records = l.Query(Query{Since: time.Date(2026, 1, 1, 0, 3, 0, 0, time.UTC), Until: time.Date(2026, 1, 1, 0, 4, 0, 0, time.UTC)})
+	require.Len(t, records, 1)
+	assert.Equal(t, uint64(3), records[0].Seq)
+
+	// The window keeps sliding once older records are trimmed
+	for range 5 {
+		_, err := l.Append(Record{Actor: "key:carol", Action: ActionCalculation})
+		require.NoError(t, err)
+	}
+	records = l.Query(Query{})
+	require.Len(t, records, 3)
+	assert.Equal(t, uint64(9), records[0].Seq)
+	assert.Equal(t, uint64(7), records[2].Seq)
+}
@@ -0,0 +1,134 @@
+// Package cache provides a concurrency-safe LRU cache whose entries expire after a TTL and whose total
+// size is bounded in bytes as well as in entries.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Stats reports the state and counters of a cache
+type Stats struct {
+	Entries     int     `json:"entries"`
+	Bytes       int64   `json:"bytes"`
+	MaxEntries  int     `json:"max_entries"`
+	MaxBytes    int64   `json:"max_bytes"`
+	Hits        uint64  `json:"hits"`
+	Misses      uint64  `json:"misses"`
+	Evictions   uint64  `json:"evictions"`
+	Expirations uint64  `json:"expirations"`
+	HitRatio    float64 `json:"hit_ratio"`
+}
+
+// entry is a cached value with its accounted size and expiry
+type entry[V any] struct {
+	key     string
+	value   V
+	size    int64
+	expires time.Time
+}
+
+// LRU is a least-recently-used cache. The zero value is not usable; create one with New.
+type LRU[V any] struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	ttl        time.Duration
+	now        func() time.Time
+
+	order *list.List // front is most recently used
+	items map[string]*list.Element
+	bytes int64
+
+	hits, misses, evictions, expirations uint64
+}
+
+// New returns a cache holding at most maxEntries entries and maxBytes bytes, each for at most ttl.
+// A non-positive bound or ttl disables that limit.
+func New[V any](maxEntries int, maxBytes int64, ttl time.Duration) *LRU[V] {
+	return &LRU[V]{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		ttl:        ttl,
+		now:        time.Now,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value cached for key and marks it as recently used
+func (c *LRU[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var zero V
+	el, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return zero, false
+	}
+	e := el.Value.(*entry[V])
+	if c.ttl > 0 && !c.now().Before(e.expires) {
+		c.remove(el)
+		c.expirations++
+		c.misses++
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	c.hits++
+	return e.value, true
+}
+
+// Add caches value under key, accounting size bytes for it, and evicts least recently used entries
+// until the cache is within its bounds. Values larger than the byte bound are not cached.
+func (c *LRU[V]) Add(key string, value V, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return
+	}
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	e := &entry[V]{key: key, value: value, size: size, expires: c.now().Add(c.ttl)}
+	c.items[key] = c.order.PushFront(e)
+	c.bytes += size
+	for (c.maxEntries > 0 && c.order.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.remove(c.order.Back())
+		c.evictions++
+	}
+}
+
+// Len returns the number of cached entries, including expired entries not yet removed
+func (c *LRU[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Stats returns the current size and counters of the cache
+func (c *LRU[V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := Stats{
+		Entries:     c.order.Len(),
+		Bytes:       c.bytes,
+		MaxEntries:  c.maxEntries,
+		MaxBytes:    c.maxBytes,
+		Hits:        c.hits,
+		Misses:      c.misses,
+		Evictions:   c.evictions,
+		Expirations: c.expirations,
+	}
+	if lookups := c.hits + c.misses; lookups > 0 {
+		stats.HitRatio = float64(c.hits) / float64(lookups)
+	}
+	return stats
+}
+
+// remove deletes el from the cache
+func (c *LRU[V]) remove(el *list.Element) {
+	e := c.order.Remove(el).(*entry[V])
+	delete(c.items, e.key)
+	c.bytes -= e.size
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLRUEviction tests that the least recently used entries are evicted first
+func TestLRUEviction(t *testing.T) {
+	c := New[int](2, 0, 0)
+	c.Add("a", 1, 1)
+	c.Add("b", 2, 1)
+
+	// Using a makes b the least recently used
+	value, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	c.Add("c", 3, 1)
+
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+
+	stats := c.Stats()
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, uint64(3), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Evictions)
+	assert.InDelta(t, 0.75, stats.HitRatio, 1e-12)
+}
+
+// TestLRUMemoryBound tests that the byte bound evicts entries and rejects oversized values
+func TestLRUMemoryBound(t *testing.T) {
+	c := New[string](0, 100, 0)
+	c.Add("a", "x", 40)
+	c.Add("b", "y", 40)
+	c.Add("c", "z", 40)
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, int64(80), c.Stats().Bytes)
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+
+	c.Add("huge", "w", 101)
+	_, ok = c.Get("huge")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	// Replacing an entry accounts only its new size
+	c.Add("b", "y2", 10)
+	assert.Equal(t, int64(50), c.Stats().Bytes)
+}
+
+// TestLRUExpiry tests that entries expire after the TTL
+func TestLRUExpiry(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := New[int](0, 0, time.Minute)
+	c.now = func() time.Time { return now }
+	c.Add("a", 1, 1)
+
+	now = now.Add(59 * time.Second)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, uint64(1), c.Stats().Expirations)
+}
@@ -0,0 +1,118 @@
+package calculator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"calculator/internal/access"
+	"calculator/internal/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Roles of the policy used when Service.Access is nil
+const (
+	defaultUserRole  = "user"
+	defaultAdminRole = "admin"
+)
+
+// accessPolicy returns a safe access policy (never nil). If Access is nil, a policy granting every
+// caller the permissions of the non-admin endpoints, and every permission to AdminKeys, is created on
+// first use.
+func (s *Service) accessPolicy() *access.Policy {
+	s.accessOnce.Do(func() {
+		if s.Access != nil {
+			return
+		}
+		cfg := access.Config{
+			Roles: map[string][]string{
+				defaultUserRole:  {access.PermissionCompute, access.PermissionManageFunctions, access.PermissionClearHistory},
+				defaultAdminRole: {access.PermissionAll},
+			},
+			Principals:   make(map[string][]string, len(s.AdminKeys)),
+			DefaultRoles: []string{defaultUserRole},
+		}
+		for _, key := range s.AdminKeys {
+			if key != "" {
+				cfg.Principals[key] = []string{defaultAdminRole}
+			}
+		}
+		policy, err := access.New(cfg)
+		if err != nil {
+			// The configuration above is always valid
+			panic(err)
+		}
+		s.Access = policy
+	})
+	return s.Access
+}
+
+// principalFromContext returns the caller's API key and the tenant and subject of its bearer token,
+// as resolved by ScopeTenant
+func principalFromContext(c *gin.Context) access.Principal {
+	return access.Principal{
+		APIKey:  c.GetHeader(APIKeyHeader),
+		Tenant:  tenantFromContext(c),
+		Subject: c.GetString(subjectContextKey),
+	}
+}
+
+// authorize returns nil when principal holds permission. Otherwise it returns an UNAUTHORIZED error for
+// callers the policy does not know, and a FORBIDDEN error for authenticated callers lacking the permission.
+func (s *Service) authorize(principal access.Principal, permission string) *Error {
+	policy := s.accessPolicy()
+	if policy.Allowed(principal, permission) {
+		return nil
+	}
+	if !policy.Authenticated(principal) {
+		return newError(CodeUnauthorized, fmt.Sprintf("an %s or bearer token with the '%s' permission is required", APIKeyHeader, permission)).
+			WithDetail("permission", permission)
+	}
+	return newError(CodeForbidden, fmt.Sprintf("the caller does not have the '%s' permission", permission)).
+		WithDetail("permission", permission)
+}
+
+// deniedStatus returns the HTTP status of an error returned by authorize
+func deniedStatus(err *Error) int {
+	if err.Code == CodeUnauthorized {
+		return http.StatusUnauthorized
+	}
+	return http.StatusForbidden
+}
+
+// auditDenied records a request refused by authorize as an authentication failure or a denied access
+func (s *Service) auditDenied(actor, resource string, err *Error) {
+	action := audit.ActionAccessDenied
+	if err.Code == CodeUnauthorized {
+		action = audit.ActionAuthFailure
+	}
+	details, _ := json.Marshal(err.Details)
+	s.appendAudit(audit.Record{
+		Actor:    actor,
+		Action:   action,
+		Resource: resource,
+		Status:   deniedStatus(err),
+		Code:     err.Code,
+		Details:  details,
+	})
+}
+
+// RequirePermission returns middleware admitting only callers whose X-API-Key or bearer token subject
+// holds permission; it runs after ScopeTenant, which verifies the token. Unknown
+// callers are answered with 401 Unauthorized and authenticated callers lacking the permission with
+// 403 Forbidden; both are recorded in the audit trail.
+func (s *Service) RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := s.authorize(principalFromContext(c), permission)
+		if err == nil {
+			c.Next()
+			return
+		}
+		s.logger().Error("Permission denied", "operation", c.Request.URL.Path, "method", c.Request.Method, "permission", permission, "code", err.Code)
+		s.auditDenied(actorFromContext(c), strings.TrimPrefix(c.Request.URL.Path, "/api/v1/"), err)
+		s.respondError(c, deniedStatus(err), err)
+		c.Abort()
+	}
+}
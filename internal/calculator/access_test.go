@@ -0,0 +1,204 @@
+package calculator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"calculator/internal/access"
+	"calculator/internal/audit"
+	"calculator/internal/calculator/calculatorpb"
+	"calculator/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// testPolicy grants compute to analysts, function management to developers and everything to admins.
+// The token subject carol of the default tenant is an analyst.
+func testPolicy(t *testing.T) *access.Policy {
+	t.Helper()
+	policy, err := access.Load(strings.NewReader(`{
+		"roles": {
+			"analyst": ["compute"],
+			"developer": ["functions.manage"],
+			"admin": ["*"]
+		},
+		"principals": {"alice": ["analyst"], "bob": ["developer"], "root": ["admin"]},
+		"subjects": {"default/carol": ["analyst"]}
+	}`))
+	require.NoError(t, err)
+	return policy
+}
+
+// TestRequirePermission tests that each route group admits only callers holding its permission
+func TestRequirePermission(t *testing.T) {
+	s := &Service{Access: testPolicy(t)}
+	r := gin.New()
+	SetupRoutes(r, s)
+
+	derivative := map[string]any{"expression": "x^2"}
+	function := map[string]any{"definition": "sq(x) = x^2"}
+	batch := map[string]any{"calculations": []map[string]any{{"operation": "add", "a": 1, "b": 2}}}
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		apiKey string
+		body   any
+		status int
+		code   string
+	}{
+		{"compute allowed", "POST", "/api/v1/derivative", "alice", derivative, http.StatusOK, ""},
+		{"compute forbidden", "POST", "/api/v1/derivative", "bob", derivative, http.StatusForbidden, CodeForbidden},
+		{"compute unauthenticated", "POST", "/api/v1/solve/polynomial", "", map[string]any{"coefficients": []float64{1, -1}}, http.StatusUnauthorized, CodeUnauthorized},
+		{"unknown key", "POST", "/api/v1/plot", "mallory", map[string]any{"expression": "x"}, http.StatusUnauthorized, CodeUnauthorized},
+		{"cheap operations are open", "POST", "/api/v1/add", "", map[string]any{"a": 1, "b": 2}, http.StatusOK, ""},
+		{"eval forbidden", "POST", "/api/v1/eval", "bob", map[string]any{"expression": "1+1"}, http.StatusForbidden, CodeForbidden},
+		{"eval allowed", "POST", "/api/v1/eval", "alice", map[string]any{"expression": "1+1"}, http.StatusOK, ""},
+		{"batch forbidden", "POST", "/api/v1/batch", "bob", batch, http.StatusForbidden, CodeForbidden},
+		{"batch allowed", "POST", "/api/v1/batch", "alice", batch, http.StatusOK, ""},
+		{"function call unauthenticated", "POST", "/api/v1/functions/sq/call", "", map[string]any{"args": []float64{2}}, http.StatusUnauthorized, CodeUnauthorized},
+		{"amortization unauthenticated", "POST", "/api/v1/finance/amortization", "", map[string]any{"principal": 1000, "rate": 0.01, "periods": 12}, http.StatusUnauthorized, CodeUnauthorized},
+		{"future value unauthenticated", "POST", "/api/v1/finance/future-value", "", map[string]any{"rate": 0.01, "periods": 12, "present_value": 100}, http.StatusUnauthorized, CodeUnauthorized},
+		{"compound interest unauthenticated", "POST", "/api/v1/finance/compound-interest", "", map[string]any{"principal": 100, "rate": 0.05, "years": 1}, http.StatusUnauthorized, CodeUnauthorized},
+		{"functions forbidden", "POST", "/api/v1/functions", "alice", function, http.StatusForbidden, CodeForbidden},
+		{"functions allowed", "POST", "/api/v1/functions", "bob", function, http.StatusCreated, ""},
+		{"functions readable", "GET", "/api/v1/functions", "alice", nil, http.StatusOK, ""},
+		{"history forbidden", "DELETE", "/api/v1/history", "bob", nil, http.StatusForbidden, CodeForbidden},
+		{"history allowed", "DELETE", "/api/v1/history", "root", nil, http.StatusNoContent, ""},
+		{"audit forbidden", "GET", "/api/v1/admin/audit", "alice", nil, http.StatusForbidden, CodeForbidden},
+		{"audit allowed", "GET", "/api/v1/admin/audit", "root", nil, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := auditRequest(r, tt.method, tt.url, tt.apiKey, tt.body)
+			if tt.code != "" {
+				assertErrorCode(t, w, tt.status, tt.code)
+				var response ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.NotEmpty(t, response.Details["permission"])
+				return
+			}
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+
+	denied := s.auditLog().Query(audit.Query{Action: audit.ActionAccessDenied})
+	require.Len(t, denied, 6)
+	assert.Equal(t, "key:alice", denied[0].Actor)
+	assert.Equal(t, "admin/audit", denied[0].Resource)
+	assert.Equal(t, http.StatusForbidden, denied[0].Status)
+	assert.JSONEq(t, `{"permission": "audit.read"}`, string(denied[0].Details))
+	assert.Len(t, s.auditLog().Query(audit.Query{Action: audit.ActionAuthFailure}), 6)
+}
+
+// TestSessionFunctionPermission tests that calling a user-defined function as a session operation needs
+// the compute permission, over HTTP and WebSocket, while built-in operations stay open
+func TestSessionFunctionPermission(t *testing.T) {
+	s := &Service{Access: testPolicy(t)}
+	r := gin.New()
+	SetupRoutes(r, s)
+
+	w := auditRequest(r, "POST", "/api/v1/functions", "bob", map[string]any{"definition": "sq(x) = x^2"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = auditRequest(r, "POST", "/api/v1/functions", "root", map[string]any{"definition": "sq(x) = x^2"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = auditRequest(r, "POST", "/api/v1/sessions", "", nil)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var session storage.Session
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+	url := "/api/v1/sessions/" + session.ID + "/operations/"
+
+	w = auditRequest(r, "POST", url+"sq", "bob", map[string]any{"a": 3})
+	assertErrorCode(t, w, http.StatusForbidden, CodeForbidden)
+	w = auditRequest(r, "POST", url+"sq", "", map[string]any{"a": 3})
+	assertErrorCode(t, w, http.StatusNotFound, CodeUnknownOperation)
+	w = auditRequest(r, "POST", url+"add", "bob", map[string]any{"a": 3, "b": 4})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = auditRequest(r, "POST", url+"sq", "root", map[string]any{"a": 3})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response SessionOperationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 9.0, response.Result)
+
+	conn := dialWebSocket(t, s, "", http.Header{APIKeyHeader: {"bob"}})
+	replies := exchange(t, conn, WSRequest{ID: "1", Type: WSTypeSessionOperation, Session: session.ID, Operation: "sq", A: &Operand{Value: 3}}, 1)
+	assert.Equal(t, WSTypeError, replies[0].Type)
+	assert.Equal(t, CodeForbidden, replies[0].Code)
+
+	denied := s.auditLog().Query(audit.Query{Action: audit.ActionAccessDenied})
+	require.Len(t, denied, 2)
+	assert.Equal(t, "key:bob", denied[0].Actor)
+	assert.Equal(t, "sessions/"+session.ID+"/operations/sq", denied[0].Resource)
+}
+
+// TestRequirePermissionBearer tests that the subject of a bearer token is granted its permissions
+func TestRequirePermissionBearer(t *testing.T) {
+	s := &Service{Access: testPolicy(t), TokenSecret: testTokenSecret}
+	r := gin.New()
+	SetupRoutes(r, s)
+	eval := map[string]any{"expression": "2*3"}
+
+	w := tenantRequest(r, "POST", "/api/v1/eval", eval, bearer(t, "default", "carol"))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = tenantRequest(r, "POST", "/api/v1/eval", eval, bearer(t, "default", "dave"))
+	assertErrorCode(t, w, http.StatusUnauthorized, CodeUnauthorized)
+	w = tenantRequest(r, "GET", "/api/v1/admin/audit", nil, bearer(t, "default", "carol"))
+	assertErrorCode(t, w, http.StatusForbidden, CodeForbidden)
+
+	denied := s.auditLog().Query(audit.Query{Action: audit.ActionAccessDenied})
+	require.Len(t, denied, 1)
+	assert.Equal(t, "user:carol", denied[0].Actor)
+}
+
+// TestDefaultAccessPolicy tests that without a policy every caller may use the expensive endpoints
+// and only admin keys may read the audit trail
+func TestDefaultAccessPolicy(t *testing.T) {
+	s := &Service{AdminKeys: []string{"root"}}
+	r := gin.New()
+	SetupRoutes(r, s)
+
+	w := auditRequest(r, "POST", "/api/v1/derivative", "", map[string]any{"expression": "x^2"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = auditRequest(r, "DELETE", "/api/v1/history", "alice", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = auditRequest(r, "GET", "/api/v1/admin/audit", "alice", nil)
+	assertErrorCode(t, w, http.StatusUnauthorized, CodeUnauthorized)
+	w = auditRequest(r, "GET", "/api/v1/admin/audit", "root", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestGRPCBatchPermission tests that gRPC batches need the compute permission
+func TestGRPCBatchPermission(t *testing.T) {
+	s := &Service{Access: testPolicy(t)}
+	client := setupGRPC(t, s)
+	req := &calculatorpb.BatchRequest{Requests: []*calculatorpb.CalculateRequest{{Id: "1", Operation: "add", A: value(1), B: value(2)}}}
+
+	_, err := client.Batch(context.Background(), req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "bob")
+	_, err = client.Batch(ctx, req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "alice")
+	resp, err := client.Batch(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, resp.GetResponses()[0].GetResult())
+
+	// Single operations stay open
+	_, err = client.Calculate(context.Background(), req.GetRequests()[0])
+	assert.NoError(t, err)
+
+	denied := s.auditLog().Query(audit.Query{Action: audit.ActionAccessDenied})
+	require.Len(t, denied, 1)
+	assert.Equal(t, "key:bob", denied[0].Actor)
+	assert.Equal(t, "grpc:batch", denied[0].Resource)
+}
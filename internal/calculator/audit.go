@@ -0,0 +1,177 @@
+package calculator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"calculator/internal/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultAuditRecords is how many recent audit records an in-memory trail keeps
+	defaultAuditRecords = 100000
+	// defaultAuditLimit and maxAuditLimit bound the records returned by an audit query
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditResponse represents the audit records selected by a query, newest first, and the head of the trail
+type AuditResponse struct {
+	Records  []audit.Record `json:"records"`
+	HeadSeq  uint64         `json:"head_seq"`
+	HeadHash string         `json:"head_hash"`
+}
+
+// NewAuditLog returns an audit trail for Service.Audit appending records to file, such as a file opened
+// for reading and appending, and keeping the most recent in memory for queries. The records already in
+// file are verified and the chain continued.
+func NewAuditLog(file io.ReadWriter) (*audit.Log, error) {
+	records, err := audit.Read(file)
+	if err != nil {
+		return nil, err
+	}
+	trail := audit.New(file, defaultAuditRecords)
+	if err := trail.Resume(records); err != nil {
+		return nil, err
+	}
+	return trail, nil
+}
+
+// auditLog returns a safe audit trail (never nil). If Audit is nil, an in-memory trail is created on first use.
+func (s *Service) auditLog() *audit.Log {
+	s.auditOnce.Do(func() {
+		if s.Audit == nil {
+			s.Audit = audit.New(nil, defaultAuditRecords)
+		}
+	})
+	return s.Audit
+}
+
+// actorFromContext returns who makes a request: the caller's owner key, or the client named by
+// X-Client-ID or its IP address for anonymous callers
+func actorFromContext(c *gin.Context) string {
+	if owner, ok := ownerFromContext(c); ok {
+		return owner
+	}
+	return qualify(tenantFromContext(c), "client:"+clientFromContext(c))
+}
+
+// appendAudit adds record to the audit trail, logging rather than failing the request when it cannot be written
+func (s *Service) appendAudit(record audit.Record) {
+	if _, err := s.auditLog().Append(record); err != nil {
+		s.logger().Error("Failed to write audit record", "action", record.Action, "actor", record.Actor, "error", err)
+	}
+}
+
+// auditDetails encodes the inputs and result of an audited action, leaving out those that are nil
+func auditDetails(inputs, result any) json.RawMessage {
+	details := map[string]any{}
+	if inputs != nil {
+		details["inputs"] = inputs
+	}
+	if result != nil {
+		details["result"] = result
+	}
+	if len(details) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// AuditAction returns middleware recording each request in the audit trail as action, with the caller,
+// the path below /api/v1, the inputs (the JSON body or query parameters), the status and the result or
+// error code
+func (s *Service) AuditAction(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		inputs := requestInputs(c)
+		writer := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		record := audit.Record{
+			Actor:    actorFromContext(c),
+			Action:   action,
+			Resource: strings.TrimPrefix(c.Request.URL.Path, "/api/v1/"),
+			Status:   writer.Status(),
+		}
+		var result any
+		if raw, code := responseOutcome(writer.Status(), writer.body.Bytes()); code != "" {
+			record.Code = code
+		} else if raw != nil {
+			result = raw
+		}
+		record.Details = auditDetails(inputs, result)
+		s.appendAudit(record)
+	}
+}
+
+// auditEvaluation records a calculation made by actor through evaluate by the gRPC, GraphQL or WebSocket APIs
+func (s *Service) auditEvaluation(transport, actor, operation string, inputs map[string]any, result float64, err error) {
+	record := audit.Record{
+		Actor:    actor,
+		Action:   audit.ActionCalculation,
+		Resource: transport + ":" + operation,
+	}
+	if err != nil {
+		record.Code = toError(err).Code
+		record.Details = auditDetails(inputs, nil)
+	} else {
+		record.Details = auditDetails(inputs, result)
+	}
+	s.appendAudit(record)
+}
+
+// parseAuditTime parses an RFC 3339 time query parameter; an empty value is the zero time
+func parseAuditTime(param, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, newError(CodeInvalidInput, fmt.Sprintf("%s must be an RFC 3339 time, got '%s'", param, value)).
+			WithDetail("param", param)
+	}
+	return t, nil
+}
+
+// AuditRecords handles querying the audit trail, newest first, optionally filtered by the actor, action,
+// since and until (RFC 3339 times) query parameters and bounded by limit
+func (s *Service) AuditRecords(c *gin.Context) {
+	query := audit.Query{Actor: c.Query("actor"), Action: c.Query("action"), Limit: defaultAuditLimit}
+	var err error
+	if query.Since, err = parseAuditTime("since", c.Query("since")); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if query.Until, err = parseAuditTime("until", c.Query("until")); err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxAuditLimit {
+			s.logger().Error("Invalid audit limit", "operation", c.Request.URL.Path, "method", c.Request.Method, "limit", limit)
+			s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, fmt.Sprintf("limit must be an integer from 1 to %d, got '%s'", maxAuditLimit, limit)).
+				WithDetail("param", "limit"))
+			return
+		}
+		query.Limit = n
+	}
+
+	records := s.auditLog().Query(query)
+	seq, hash := s.auditLog().Head()
+	s.logger().Info("Audit records listed", "operation", c.Request.URL.Path, "method", c.Request.Method, "count", len(records))
+	c.JSON(http.StatusOK, AuditResponse{Records: records, HeadSeq: seq, HeadHash: hash})
+}
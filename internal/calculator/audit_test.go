@@ -0,0 +1,145 @@
+package calculator
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"calculator/internal/audit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// auditRequest sends a request through r with an optional JSON body and API key
+func auditRequest(r http.Handler, method, url, apiKey string, body any) *httptest.ResponseRecorder {
+	c, w := setupTestContext(method, url, body)
+	if apiKey != "" {
+		c.Request.Header.Set(APIKeyHeader, apiKey)
+	}
+	r.ServeHTTP(w, c.Request)
+	return w
+}
+
+// TestAuditTrail tests that calculations and administrative actions are recorded and can be queried
+func TestAuditTrail(t *testing.T) {
+	s := &Service{AdminKeys: []string{"root"}}
+	r := gin.New()
+	SetupRoutes(r, s)
+
+	auditRequest(r, "POST", "/api/v1/add", "alice", map[string]any{"a": 2, "b": 3})
+	auditRequest(r, "GET", "/api/v1/divide?a=1&b=0", "alice", nil)
+	auditRequest(r, "POST", "/api/v1/variables", "alice", map[string]any{"name": "x", "value": 4})
+	auditRequest(r, "DELETE", "/api/v1/variables/x", "alice", nil)
+	auditRequest(r, "DELETE", "/api/v1/history", "bob", nil)
+	auditRequest(r, "GET", "/api/v1/variables", "alice", nil)
+	s.publishEvaluation(TransportGRPC, "", "worker-1", "sqrt", &Operand{Value: 9}, nil, time.Now(), 3, nil)
+
+	w := auditRequest(r, "GET", "/api/v1/admin/audit", "root", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response AuditResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Records, 6, "reads are not audited")
+	assert.Equal(t, uint64(6), response.HeadSeq)
+	assert.Equal(t, response.Records[0].Hash, response.HeadHash)
+
+	records := slices.Clone(response.Records)
+	slices.Reverse(records)
+	require.NoError(t, audit.Verify(records))
+
+	add := records[0]
+	assert.Equal(t, "key:alice", add.Actor)
+	assert.Equal(t, audit.ActionCalculation, add.Action)
+	assert.Equal(t, "add", add.Resource)
+	assert.Equal(t, http.StatusOK, add.Status)
+	assert.JSONEq(t, `{"inputs": {"a": 2, "b": 3}, "result": 5}`, string(add.Details))
+
+	divide := records[1]
+	assert.Equal(t, CodeDivisionByZero, divide.Code)
+	assert.JSONEq(t, `{"inputs": {"a": "1", "b": "0"}}`, string(divide.Details))
+
+	assert.Equal(t, audit.ActionVariableCreate, records[2].Action)
+	assert.Equal(t, audit.ActionVariableDelete, records[3].Action)
+	assert.Equal(t, "variables/x", records[3].Resource)
+	assert.Equal(t, "key:bob", records[4].Actor)
+	assert.Equal(t, audit.ActionHistoryClear, records[4].Action)
+	assert.Equal(t, "client:worker-1", records[5].Actor)
+	assert.Equal(t, "grpc:sqrt", records[5].Resource)
+	assert.JSONEq(t, `{"inputs": {"a": 9}, "result": 3}`, string(records[5].Details))
+
+	// Filters
+	w = auditRequest(r, "GET", "/api/v1/admin/audit?actor=key:alice&action=calculation&limit=1", "root", nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Records, 1)
+	assert.Equal(t, "divide", response.Records[0].Resource)
+
+	w = auditRequest(r, "GET", "/api/v1/admin/audit?since="+time.Now().Add(time.Hour).Format(time.RFC3339), "root", nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Empty(t, response.Records)
+
+	w = auditRequest(r, "GET", "/api/v1/admin/audit?limit=0", "root", nil)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+	w = auditRequest(r, "GET", "/api/v1/admin/audit?until=yesterday", "root", nil)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+}
+
+// TestAuditAuthFailures tests that admin endpoints need an admin API key and that failures are recorded
+func TestAuditAuthFailures(t *testing.T) {
+	s := &Service{AdminKeys: []string{"root"}}
+	r := gin.New()
+	SetupRoutes(r, s)
+
+	w := auditRequest(r, "GET", "/api/v1/admin/audit", "", nil)
+	assertErrorCode(t, w, http.StatusUnauthorized, CodeUnauthorized)
+	w = auditRequest(r, "GET", "/api/v1/admin/audit", "alice", nil)
+	assertErrorCode(t, w, http.StatusUnauthorized, CodeUnauthorized)
+
+	records := s.auditLog().Query(audit.Query{Action: audit.ActionAuthFailure})
+	require.Len(t, records, 2)
+	assert.Equal(t, "key:alice", records[0].Actor)
+	assert.Equal(t, "admin/audit", records[0].Resource)
+	assert.Equal(t, CodeUnauthorized, records[0].Code)
+
+	// Without admin keys the admin endpoints are closed
+	s = &Service{}
+	r = gin.New()
+	SetupRoutes(r, s)
+	w = auditRequest(r, "GET", "/api/v1/admin/audit", "", nil)
+	assertErrorCode(t, w, http.StatusUnauthorized, CodeUnauthorized)
+}
+
+// TestNewAuditLog tests continuing the trail of an audit log file and refusing a tampered one
+func TestNewAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	open := func() *os.File {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = file.Close() })
+		return file
+	}
+
+	for range 2 {
+		trail, err := NewAuditLog(open())
+		require.NoError(t, err)
+		_, err = trail.Append(audit.Record{Actor: "key:alice", Action: audit.ActionSessionCreate})
+		require.NoError(t, err)
+	}
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	records, err := audit.Read(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.NoError(t, audit.Verify(records))
+
+	require.NoError(t, os.WriteFile(path, bytes.Replace(data, []byte("key:alice"), []byte("key:eve"), 1), 0600))
+	_, err = NewAuditLog(open())
+	var chainErr *audit.ChainError
+	assert.ErrorAs(t, err, &chainErr)
+}
@@ -0,0 +1,65 @@
+package calculator
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BatchCalculation is one operation of a batch, with operands that are numbers or names of constants,
+// variables or "ans"
+type BatchCalculation struct {
+	Operation string   `json:"operation"`
+	A         *Operand `json:"a"`
+	B         *Operand `json:"b"`
+}
+
+// BatchRequest represents several operations applied in order
+type BatchRequest struct {
+	Calculations []BatchCalculation `json:"calculations" binding:"required"`
+}
+
+// BatchResult is the outcome of one operation of a batch: its result, or the error fields of the
+// error envelope
+type BatchResult struct {
+	Result  *float64       `json:"result,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Code    string         `json:"code,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// BatchResponse represents the outcomes of a batch, in the order of its calculations
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// Batch handles applying several operations in order. A failed operation does not stop the batch;
+// its error is reported in its place.
+func (s *Service) Batch(c *gin.Context) {
+	var req BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind batch JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing batch request", "operation", c.Request.URL.Path, "method", c.Request.Method, "size", len(req.Calculations))
+
+	ctx := c.Request.Context()
+	sc := scopeFromContext(c)
+	response := BatchResponse{Results: make([]BatchResult, len(req.Calculations))}
+	for i, calculation := range req.Calculations {
+		if err := ctx.Err(); err != nil {
+			s.logger().Error("Batch request cancelled", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+			return
+		}
+		result, err := s.evaluate(ctx, sc, calculation.Operation, calculation.A, calculation.B)
+		if err != nil {
+			calcErr := toError(err)
+			response.Results[i] = BatchResult{Error: calcErr.Message, Code: calcErr.Code, Details: calcErr.Details}
+			continue
+		}
+		response.Results[i] = BatchResult{Result: &result}
+	}
+	s.respondJSON(c, response)
+}
@@ -0,0 +1,51 @@
+package calculator
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"calculator/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBatch tests that a batch applies its operations in order, reporting each failure in its place
+func TestBatch(t *testing.T) {
+	s := &Service{Store: storage.NewMemory()}
+	s.store().SetVariable("key:alice", "k", 4)
+
+	c, w := setupTestContext("POST", "/batch", map[string]any{"calculations": []map[string]any{
+		{"operation": "add", "a": 2, "b": "$k"},
+		{"operation": "divide", "a": 1, "b": 0},
+		{"operation": "modulo", "a": 1, "b": 2},
+		{"operation": "subtract", "a": 1},
+		{"operation": "multiply", "a": 1e300, "b": 1e300},
+		{"operation": "sqrt", "a": 9},
+	}})
+	c.Request.Header.Set(APIKeyHeader, "alice")
+	s.Batch(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response BatchResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Results, 6)
+
+	require.NotNil(t, response.Results[0].Result)
+	assert.Equal(t, 6.0, *response.Results[0].Result)
+	for i, code := range []string{CodeDivisionByZero, CodeUnknownOperation, CodeInvalidInput, CodeDomainError} {
+		assert.Nil(t, response.Results[i+1].Result)
+		assert.Equal(t, code, response.Results[i+1].Code)
+		assert.NotEmpty(t, response.Results[i+1].Error)
+	}
+	require.NotNil(t, response.Results[5].Result)
+	assert.Equal(t, 3.0, *response.Results[5].Result)
+
+	history := s.store().History("key:alice", 0)
+	require.Len(t, history, 2)
+	assert.Equal(t, "sqrt", history[0].Operation)
+
+	c, w = setupTestContext("POST", "/batch", map[string]any{"operation": "add"})
+	s.Batch(c)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+}
@@ -0,0 +1,166 @@
+package calculator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"calculator/core"
+	"calculator/internal/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// CacheHeader reports whether a result was served from the result cache (HIT) or computed (MISS)
+	CacheHeader = "X-Cache"
+
+	// Default bounds of the result cache
+	defaultCacheEntries = 10000
+	defaultCacheBytes   = 16 << 20
+	defaultCacheTTL     = 10 * time.Minute
+
+	// cacheEntryOverhead approximates the bytes of bookkeeping per cached result
+	cacheEntryOverhead = 128
+	// httpCacheMaxAge is how long browsers and CDNs may reuse a GET result of literal operands
+	httpCacheMaxAge = 24 * time.Hour
+)
+
+// Cache modes separate results of the same operation and inputs computed differently
+const (
+	cacheModeFloat    = "float"
+	cacheModeRational = "rational"
+)
+
+// NewCache returns a result cache for Service.Cache with the given bounds
+func NewCache(maxEntries int, maxBytes int64, ttl time.Duration) *cache.LRU[any] {
+	return cache.New[any](maxEntries, maxBytes, ttl)
+}
+
+// resultCache returns a safe cache (never nil). If Cache is nil, a default cache is created on first use.
+func (s *Service) resultCache() *cache.LRU[any] {
+	s.cacheOnce.Do(func() {
+		if s.Cache == nil {
+			s.Cache = NewCache(defaultCacheEntries, defaultCacheBytes, defaultCacheTTL)
+		}
+	})
+	return s.Cache
+}
+
+// cacheKey builds the key of a deterministic operation from its mode, operation and resolved inputs.
+// Numbers are written in their shortest form so that 2 and 2.0 share a key.
+func cacheKey(mode, operation string, inputs ...any) string {
+	var sb strings.Builder
+	sb.WriteString(mode)
+	sb.WriteString("|")
+	sb.WriteString(operation)
+	for _, input := range inputs {
+		sb.WriteString("|")
+		switch v := input.(type) {
+		case float64:
+			sb.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+		case string:
+			sb.WriteString(strconv.Quote(v))
+		default:
+			fmt.Fprint(&sb, v)
+		}
+	}
+	return sb.String()
+}
+
+// cached returns the result cached under key, or computes and caches it, setting the X-Cache header.
+// size approximates the bytes held by a result beyond the key. Errors are not cached.
+func cached[V any](s *Service, c *gin.Context, key string, size func(V) int64, compute func() (V, error)) (V, error) {
+	if value, ok := s.resultCache().Get(key); ok {
+		if result, ok := value.(V); ok {
+			s.logger().Debug("Result cache hit", "operation", c.Request.URL.Path, "key", key)
+			c.Header(CacheHeader, "HIT")
+			return result, nil
+		}
+	}
+	c.Header(CacheHeader, "MISS")
+	result, err := compute()
+	if err != nil {
+		return result, err
+	}
+	s.resultCache().Add(key, result, cacheEntryOverhead+int64(len(key))+size(result))
+	return result, nil
+}
+
+// fixedSize is the size function of results holding no variable-length data
+func fixedSize[V any](V) int64 {
+	return 0
+}
+
+// setCacheControl lets browsers and CDNs reuse a GET result that depends only on literal or constant
+// query operands. Results using variables, ANS or a caller's session are private and revalidated.
+func setCacheControl(c *gin.Context, params ...string) {
+	public := c.GetHeader(SessionHeader) == "" && c.GetHeader(APIKeyHeader) == "" && c.GetHeader("Authorization") == ""
+	for _, param := range params {
+		value := c.Query(param)
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			if _, ok := core.LookupConstant(value); !ok {
+				public = false
+			}
+		}
+	}
+	if public {
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(httpCacheMaxAge.Seconds())))
+	} else {
+		c.Header("Cache-Control", "private, no-cache")
+	}
+}
+
+// etagMatches reports whether the If-None-Match header value lists etag or "*"
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// respondJSON writes a successful response. A response holding NaN or an infinity, which JSON cannot
+// represent, is reported as a DOMAIN_ERROR. GET responses carry an ETag of the body and are answered
+// with 304 Not Modified when it matches If-None-Match.
+func (s *Service) respondJSON(c *gin.Context, response any) {
+	body, err := json.Marshal(response)
+	var unsupported *json.UnsupportedValueError
+	if errors.As(err, &unsupported) {
+		s.logger().Error("Result is not finite", "operation", c.Request.URL.Path, "method", c.Request.Method, "value", unsupported.Str)
+		s.respondError(c, http.StatusBadRequest, newError(CodeDomainError, "result is not a finite number").
+			WithDetail("result", unsupported.Str))
+		return
+	}
+	if err != nil {
+		s.respondError(c, http.StatusInternalServerError, newError(CodeInternal, "failed to encode response"))
+		return
+	}
+	if c.Request.Method != http.MethodGet {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	if match := c.GetHeader("If-None-Match"); match != "" && etagMatches(match, etag) {
+		s.logger().Debug("Response not modified", "operation", c.Request.URL.Path, "etag", etag)
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// CacheStats handles reporting result cache size and hit/miss counters
+func (s *Service) CacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, s.resultCache().Stats())
+}
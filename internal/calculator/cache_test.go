@@ -0,0 +1,162 @@
+package calculator
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResultCache tests cache hits and misses across operations, inputs and modes
+func TestResultCache(t *testing.T) {
+	s := &Service{}
+	add := func(body any) (int, string) {
+		c, w := setupTestContext("POST", "/add", body)
+		s.Add(c)
+		return w.Code, w.Header().Get(CacheHeader)
+	}
+
+	status, header := add(map[string]any{"a": 2, "b": 3})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "MISS", header)
+
+	// The same resolved inputs hit, however they are written
+	_, header = add(map[string]any{"a": 2.0, "b": "3"})
+	assert.Equal(t, "HIT", header)
+
+	_, header = add(map[string]any{"a": 3, "b": 2})
+	assert.Equal(t, "MISS", header)
+
+	// A different operation on the same inputs misses
+	c, w := setupTestContext("POST", "/multiply", map[string]any{"a": 2, "b": 3})
+	s.Multiply(c)
+	assert.Equal(t, "MISS", w.Header().Get(CacheHeader))
+
+	// GET shares results with POST
+	c, w = setupTestContext("GET", "/add?a=2&b=3", nil)
+	s.AddGET(c)
+	assertResponse(t, w, http.StatusOK, 5, "")
+	assert.Equal(t, "HIT", w.Header().Get(CacheHeader))
+
+	// Rational mode results are cached separately
+	w = requestRational(s, "add", "", map[string]any{"a": 2, "b": 3})
+	assert.Equal(t, "MISS", w.Header().Get(CacheHeader))
+	w = requestRational(s, "add", "", map[string]any{"a": "4/2", "b": 3})
+	assert.Equal(t, "HIT", w.Header().Get(CacheHeader))
+	assert.Equal(t, "5", decodeRational(t, w).Fraction)
+
+	// Units are part of the key
+	c, w = setupTestContext("POST", "/add", map[string]any{"a": 2, "a_unit": "m", "b": 3, "b_unit": "cm"})
+	s.Add(c)
+	assert.Equal(t, "MISS", w.Header().Get(CacheHeader))
+
+	// Errors are not cached
+	for range 2 {
+		c, w = setupTestContext("POST", "/divide", map[string]any{"a": 1, "b": 0})
+		s.Divide(c)
+		assertErrorCode(t, w, http.StatusBadRequest, CodeDivisionByZero)
+		assert.Equal(t, "MISS", w.Header().Get(CacheHeader))
+	}
+
+	c, w = setupTestContext("GET", "/cache/stats", nil)
+	s.CacheStats(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var stats struct {
+		Entries int    `json:"entries"`
+		Hits    uint64 `json:"hits"`
+		Misses  uint64 `json:"misses"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, 5, stats.Entries)
+	assert.Equal(t, uint64(3), stats.Hits)
+	assert.Equal(t, uint64(7), stats.Misses)
+}
+
+// TestResultCacheVariables tests that results depending on variables follow the variable's value
+func TestResultCacheVariables(t *testing.T) {
+	s := &Service{}
+	s.store().SetVariable("key:alice", "x", 2)
+	power := func() *http.Response {
+		c, w := setupTestContext("GET", "/power?a=$x&b=2", nil)
+		c.Request.Header.Set(APIKeyHeader, "alice")
+		s.PowerGET(c)
+		return w.Result()
+	}
+
+	response := power()
+	assert.Equal(t, "private, no-cache", response.Header.Get("Cache-Control"))
+	s.store().SetVariable("key:alice", "x", 3)
+	response = power()
+	assert.Equal(t, "MISS", response.Header.Get(CacheHeader))
+	var result Response
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&result))
+	assert.Equal(t, 9.0, result.Result)
+}
+
+// TestETag tests conditional GET requests
+func TestETag(t *testing.T) {
+	s := &Service{}
+	get := func(url, ifNoneMatch string) *http.Response {
+		c, w := setupTestContext("GET", url, nil)
+		if ifNoneMatch != "" {
+			c.Request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		s.PowerGET(c)
+		return w.Result()
+	}
+
+	first := get("/power?a=2&b=3", "")
+	require.Equal(t, http.StatusOK, first.StatusCode)
+	etag := first.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "public, max-age=86400", first.Header.Get("Cache-Control"))
+
+	tests := []struct {
+		name        string
+		url         string
+		ifNoneMatch string
+		status      int
+	}{
+		{"matching etag", "/power?a=2&b=3", etag, http.StatusNotModified},
+		{"weak etag in list", "/power?a=2&b=3", `"other", W/` + etag, http.StatusNotModified},
+		{"wildcard", "/power?a=2&b=3", "*", http.StatusNotModified},
+		{"stale etag", "/power?a=2&b=3", `"other"`, http.StatusOK},
+		{"different inputs", "/power?a=2&b=4", etag, http.StatusOK},
+		{"different format", "/power?a=2&b=3&scale=2", etag, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := get(tt.url, tt.ifNoneMatch)
+			assert.Equal(t, tt.status, response.StatusCode)
+			assert.NotEmpty(t, response.Header.Get("ETag"))
+		})
+	}
+
+	// POST responses carry no ETag
+	c, w := setupTestContext("POST", "/power", map[string]any{"a": 2, "b": 3})
+	s.Power(c)
+	assert.Empty(t, w.Header().Get("ETag"))
+}
+
+// TestNonFiniteResponse tests that results JSON cannot represent are reported as DOMAIN_ERROR
+func TestNonFiniteResponse(t *testing.T) {
+	s := &Service{}
+	c, w := setupTestContext("GET", "/power?a=10&b=400", nil)
+	c.Request.Header.Set(APIKeyHeader, "alice")
+	s.PowerGET(c)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeDomainError)
+	assert.Contains(t, w.Body.String(), `"result":"+Inf"`)
+	assert.Empty(t, w.Header().Get("ETag"))
+	assert.Empty(t, s.store().History("key:alice", 0))
+
+	// Nested values are checked for every method
+	for _, method := range []string{"GET", "POST"} {
+		c, w = setupTestContext(method, "/matrix", nil)
+		s.respondJSON(c, map[string]any{"rows": [][]float64{{1, math.NaN()}}})
+		assertErrorCode(t, w, http.StatusBadRequest, CodeDomainError)
+		assert.Contains(t, w.Body.String(), `"result":"NaN"`)
+	}
+}
@@ -0,0 +1,672 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: calculator/v1/calculator.proto
+
+package calculatorpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Operand is a literal number or a reference to a constant ("pi"), a variable ("$x") or "ans"
+type Operand struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*Operand_Value
+	//	*Operand_Ref
+	Kind          isOperand_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Operand) Reset() {
+	*x = Operand{}
+	mi := &file_calculator_v1_calculator_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Operand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Operand) ProtoMessage() {}
+
+func (x *Operand) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_v1_calculator_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Operand.ProtoReflect.Descriptor instead.
+func (*Operand) Descriptor() ([]byte, []int) {
+	return file_calculator_v1_calculator_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Operand) GetKind() isOperand_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *Operand) GetValue() float64 {
+	if x != nil {
+		if x, ok := x.Kind.(*Operand_Value); ok {
+			return x.Value
+		}
+	}
+	return 0
+}
+
+func (x *Operand) GetRef() string {
+	if x != nil {
+		if x, ok := x.Kind.(*Operand_Ref); ok {
+			return x.Ref
+		}
+	}
+	return ""
+}
+
+type isOperand_Kind interface {
+	isOperand_Kind()
+}
+
+type Operand_Value struct {
+	Value float64 `protobuf:"fixed64,1,opt,name=value,proto3,oneof"`
+}
+
+type Operand_Ref struct {
+	Ref string `protobuf:"bytes,2,opt,name=ref,proto3,oneof"`
+}
+
+func (*Operand_Value) isOperand_Kind() {}
+
+func (*Operand_Ref) isOperand_Kind() {}
+
+type CalculateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id is echoed in the response to correlate batch and stream results
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// operation names a built-in operation such as "add" or "inverse"
+	Operation string   `protobuf:"bytes,2,opt,name=operation,proto3" json:"operation,omitempty"`
+	A         *Operand `protobuf:"bytes,3,opt,name=a,proto3" json:"a,omitempty"`
+	// b is required by binary operations other than sqrt
+	B             *Operand `protobuf:"bytes,4,opt,name=b,proto3" json:"b,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalculateRequest) Reset() {
+	*x = CalculateRequest{}
+	mi := &file_calculator_v1_calculator_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalculateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculateRequest) ProtoMessage() {}
+
+func (x *CalculateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_v1_calculator_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculateRequest.ProtoReflect.Descriptor instead.
+func (*CalculateRequest) Descriptor() ([]byte, []int) {
+	return file_calculator_v1_calculator_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CalculateRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CalculateRequest) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *CalculateRequest) GetA() *Operand {
+	if x != nil {
+		return x.A
+	}
+	return nil
+}
+
+func (x *CalculateRequest) GetB() *Operand {
+	if x != nil {
+		return x.B
+	}
+	return nil
+}
+
+type CalculateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Types that are valid to be assigned to Outcome:
+	//
+	//	*CalculateResponse_Result
+	//	*CalculateResponse_Error
+	Outcome       isCalculateResponse_Outcome `protobuf_oneof:"outcome"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalculateResponse) Reset() {
+	*x = CalculateResponse{}
+	mi := &file_calculator_v1_calculator_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalculateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculateResponse) ProtoMessage() {}
+
+func (x *CalculateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_v1_calculator_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculateResponse.ProtoReflect.Descriptor instead.
+func (*CalculateResponse) Descriptor() ([]byte, []int) {
+	return file_calculator_v1_calculator_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CalculateResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CalculateResponse) GetOutcome() isCalculateResponse_Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return nil
+}
+
+func (x *CalculateResponse) GetResult() float64 {
+	if x != nil {
+		if x, ok := x.Outcome.(*CalculateResponse_Result); ok {
+			return x.Result
+		}
+	}
+	return 0
+}
+
+func (x *CalculateResponse) GetError() *Error {
+	if x != nil {
+		if x, ok := x.Outcome.(*CalculateResponse_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isCalculateResponse_Outcome interface {
+	isCalculateResponse_Outcome()
+}
+
+type CalculateResponse_Result struct {
+	Result float64 `protobuf:"fixed64,2,opt,name=result,proto3,oneof"`
+}
+
+type CalculateResponse_Error struct {
+	Error *Error `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*CalculateResponse_Result) isCalculateResponse_Outcome() {}
+
+func (*CalculateResponse_Error) isCalculateResponse_Outcome() {}
+
+// Error is a calculator error with the same code and details as the HTTP API
+type Error struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Details       map[string]string      `protobuf:"bytes,3,rep,name=details,proto3" json:"details,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_calculator_v1_calculator_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_v1_calculator_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_calculator_v1_calculator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Error) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Error) GetDetails() map[string]string {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+type BatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requests      []*CalculateRequest    `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchRequest) Reset() {
+	*x = BatchRequest{}
+	mi := &file_calculator_v1_calculator_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchRequest) ProtoMessage() {}
+
+func (x *BatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_v1_calculator_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchRequest.ProtoReflect.Descriptor instead.
+func (*BatchRequest) Descriptor() ([]byte, []int) {
+	return file_calculator_v1_calculator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchRequest) GetRequests() []*CalculateRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+type BatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Responses     []*CalculateResponse   `protobuf:"bytes,1,rep,name=responses,proto3" json:"responses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchResponse) Reset() {
+	*x = BatchResponse{}
+	mi := &file_calculator_v1_calculator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchResponse) ProtoMessage() {}
+
+func (x *BatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_v1_calculator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchResponse.ProtoReflect.Descriptor instead.
+func (*BatchResponse) Descriptor() ([]byte, []int) {
+	return file_calculator_v1_calculator_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchResponse) GetResponses() []*CalculateResponse {
+	if x != nil {
+		return x.Responses
+	}
+	return nil
+}
+
+type ListOperationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOperationsRequest) Reset() {
+	*x = ListOperationsRequest{}
+	mi := &file_calculator_v1_calculator_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOperationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOperationsRequest) ProtoMessage() {}
+
+func (x *ListOperationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_v1_calculator_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOperationsRequest.ProtoReflect.Descriptor instead.
+func (*ListOperationsRequest) Descriptor() ([]byte, []int) {
+	return file_calculator_v1_calculator_proto_rawDescGZIP(), []int{6}
+}
+
+type Operation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Unary         bool                   `protobuf:"varint,2,opt,name=unary,proto3" json:"unary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Operation) Reset() {
+	*x = Operation{}
+	mi := &file_calculator_v1_calculator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Operation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Operation) ProtoMessage() {}
+
+func (x *Operation) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_v1_calculator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Operation.ProtoReflect.Descriptor instead.
+func (*Operation) Descriptor() ([]byte, []int) {
+	return file_calculator_v1_calculator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Operation) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Operation) GetUnary() bool {
+	if x != nil {
+		return x.Unary
+	}
+	return false
+}
+
+type ListOperationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Operations    []*Operation           `protobuf:"bytes,1,rep,name=operations,proto3" json:"operations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOperationsResponse) Reset() {
+	*x = ListOperationsResponse{}
+	mi := &file_calculator_v1_calculator_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOperationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOperationsResponse) ProtoMessage() {}
+
+func (x *ListOperationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_v1_calculator_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOperationsResponse.ProtoReflect.Descriptor instead.
+func (*ListOperationsResponse) Descriptor() ([]byte, []int) {
+	return file_calculator_v1_calculator_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListOperationsResponse) GetOperations() []*Operation {
+	if x != nil {
+		return x.Operations
+	}
+	return nil
+}
+
+var File_calculator_v1_calculator_proto protoreflect.FileDescriptor
+
+const file_calculator_v1_calculator_proto_rawDesc = "" +
+	"\n" +
+	"\x1ecalculator/v1/calculator.proto\x12\rcalculator.v1\"=\n" +
+	"\aOperand\x12\x16\n" +
+	"\x05value\x18\x01 \x01(\x01H\x00R\x05value\x12\x12\n" +
+	"\x03ref\x18\x02 \x01(\tH\x00R\x03refB\x06\n" +
+	"\x04kind\"\x8c\x01\n" +
+	"\x10CalculateRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1c\n" +
+	"\toperation\x18\x02 \x01(\tR\toperation\x12$\n" +
+	"\x01a\x18\x03 \x01(\v2\x16.calculator.v1.OperandR\x01a\x12$\n" +
+	"\x01b\x18\x04 \x01(\v2\x16.calculator.v1.OperandR\x01b\"v\n" +
+	"\x11CalculateResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\x06result\x18\x02 \x01(\x01H\x00R\x06result\x12,\n" +
+	"\x05error\x18\x03 \x01(\v2\x14.calculator.v1.ErrorH\x00R\x05errorB\t\n" +
+	"\aoutcome\"\xae\x01\n" +
+	"\x05Error\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12;\n" +
+	"\adetails\x18\x03 \x03(\v2!.calculator.v1.Error.DetailsEntryR\adetails\x1a:\n" +
+	"\fDetailsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"K\n" +
+	"\fBatchRequest\x12;\n" +
+	"\brequests\x18\x01 \x03(\v2\x1f.calculator.v1.CalculateRequestR\brequests\"O\n" +
+	"\rBatchResponse\x12>\n" +
+	"\tresponses\x18\x01 \x03(\v2 .calculator.v1.CalculateResponseR\tresponses\"\x17\n" +
+	"\x15ListOperationsRequest\"5\n" +
+	"\tOperation\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05unary\x18\x02 \x01(\bR\x05unary\"R\n" +
+	"\x16ListOperationsResponse\x128\n" +
+	"\n" +
+	"operations\x18\x01 \x03(\v2\x18.calculator.v1.OperationR\n" +
+	"operations2\xd9\x02\n" +
+	"\n" +
+	"Calculator\x12]\n" +
+	"\x0eListOperations\x12$.calculator.v1.ListOperationsRequest\x1a%.calculator.v1.ListOperationsResponse\x12N\n" +
+	"\tCalculate\x12\x1f.calculator.v1.CalculateRequest\x1a .calculator.v1.CalculateResponse\x12B\n" +
+	"\x05Batch\x12\x1b.calculator.v1.BatchRequest\x1a\x1c.calculator.v1.BatchResponse\x12X\n" +
+	"\x0fCalculateStream\x12\x1f.calculator.v1.CalculateRequest\x1a .calculator.v1.CalculateResponse(\x010\x01B-Z+calculator/internal/calculator/calculatorpbb\x06proto3"
+
+var (
+	file_calculator_v1_calculator_proto_rawDescOnce sync.Once
+	file_calculator_v1_calculator_proto_rawDescData []byte
+)
+
+func file_calculator_v1_calculator_proto_rawDescGZIP() []byte {
+	file_calculator_v1_calculator_proto_rawDescOnce.Do(func() {
+		file_calculator_v1_calculator_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_calculator_v1_calculator_proto_rawDesc), len(file_calculator_v1_calculator_proto_rawDesc)))
+	})
+	return file_calculator_v1_calculator_proto_rawDescData
+}
+
+var file_calculator_v1_calculator_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_calculator_v1_calculator_proto_goTypes = []any{
+	(*Operand)(nil),                // 0: calculator.v1.Operand
+	(*CalculateRequest)(nil),       // 1: calculator.v1.CalculateRequest
+	(*CalculateResponse)(nil),      // 2: calculator.v1.CalculateResponse
+	(*Error)(nil),                  // 3: calculator.v1.Error
+	(*BatchRequest)(nil),           // 4: calculator.v1.BatchRequest
+	(*BatchResponse)(nil),          // 5: calculator.v1.BatchResponse
+	(*ListOperationsRequest)(nil),  // 6: calculator.v1.ListOperationsRequest
+	(*Operation)(nil),              // 7: calculator.v1.Operation
+	(*ListOperationsResponse)(nil), // 8: calculator.v1.ListOperationsResponse
+	nil,                            // 9: calculator.v1.Error.DetailsEntry
+}
+var file_calculator_v1_calculator_proto_depIdxs = []int32{
+	0,  // 0: calculator.v1.CalculateRequest.a:type_name -> calculator.v1.Operand
+	0,  // 1: calculator.v1.CalculateRequest.b:type_name -> calculator.v1.Operand
+	3,  // 2: calculator.v1.CalculateResponse.error:type_name -> calculator.v1.Error
+	9,  // 3: calculator.v1.Error.details:type_name -> calculator.v1.Error.DetailsEntry
+	1,  // 4: calculator.v1.BatchRequest.requests:type_name -> calculator.v1.CalculateRequest
+	2,  // 5: calculator.v1.BatchResponse.responses:type_name -> calculator.v1.CalculateResponse
+	7,  // 6: calculator.v1.ListOperationsResponse.operations:type_name -> calculator.v1.Operation
+	6,  // 7: calculator.v1.Calculator.ListOperations:input_type -> calculator.v1.ListOperationsRequest
+	1,  // 8: calculator.v1.Calculator.Calculate:input_type -> calculator.v1.CalculateRequest
+	4,  // 9: calculator.v1.Calculator.Batch:input_type -> calculator.v1.BatchRequest
+	1,  // 10: calculator.v1.Calculator.CalculateStream:input_type -> calculator.v1.CalculateRequest
+	8,  // 11: calculator.v1.Calculator.ListOperations:output_type -> calculator.v1.ListOperationsResponse
+	2,  // 12: calculator.v1.Calculator.Calculate:output_type -> calculator.v1.CalculateResponse
+	5,  // 13: calculator.v1.Calculator.Batch:output_type -> calculator.v1.BatchResponse
+	2,  // 14: calculator.v1.Calculator.CalculateStream:output_type -> calculator.v1.CalculateResponse
+	11, // [11:15] is the sub-list for method output_type
+	7,  // [7:11] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_calculator_v1_calculator_proto_init() }
+func file_calculator_v1_calculator_proto_init() {
+	if File_calculator_v1_calculator_proto != nil {
+		return
+	}
+	file_calculator_v1_calculator_proto_msgTypes[0].OneofWrappers = []any{
+		(*Operand_Value)(nil),
+		(*Operand_Ref)(nil),
+	}
+	file_calculator_v1_calculator_proto_msgTypes[2].OneofWrappers = []any{
+		(*CalculateResponse_Result)(nil),
+		(*CalculateResponse_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_calculator_v1_calculator_proto_rawDesc), len(file_calculator_v1_calculator_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_calculator_v1_calculator_proto_goTypes,
+		DependencyIndexes: file_calculator_v1_calculator_proto_depIdxs,
+		MessageInfos:      file_calculator_v1_calculator_proto_msgTypes,
+	}.Build()
+	File_calculator_v1_calculator_proto = out.File
+	file_calculator_v1_calculator_proto_goTypes = nil
+	file_calculator_v1_calculator_proto_depIdxs = nil
+}
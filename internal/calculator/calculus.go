@@ -0,0 +1,509 @@
+package calculator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+
+	"calculator/core"
+	"calculator/internal/expr"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxDerivativeOrder limits repeated differentiation
+	maxDerivativeOrder = 10
+	// defaultTolerance is the absolute tolerance of the numerical methods
+	defaultTolerance = 1e-10
+	// defaultIntegrationSplits bounds the interval subdivisions of the integration methods
+	defaultIntegrationSplits = 1000
+	// defaultIterations bounds the iterations of the root-finding and optimisation methods
+	defaultIterations = 100
+	// maxIterationLimit caps the max_iterations a request may ask for
+	maxIterationLimit = 100000
+)
+
+// DerivativeRequest represents a request to differentiate an expression.
+// Variable defaults to "x" and Order to 1; when At is set the derivative is also evaluated there.
+type DerivativeRequest struct {
+	Expression string   `json:"expression" binding:"required"`
+	Variable   string   `json:"variable"`
+	Order      int      `json:"order"`
+	At         *Operand `json:"at"`
+}
+
+// DerivativeResponse represents a simplified derivative and, optionally, its value at a point
+type DerivativeResponse struct {
+	Derivative string   `json:"derivative"`
+	Variable   string   `json:"variable"`
+	Order      int      `json:"order"`
+	Result     *float64 `json:"result,omitempty"`
+}
+
+// NumericRequest holds the fields shared by the numerical methods.
+// Tolerance defaults to 1e-10; MaxIterations defaults to 1000 subdivisions for integration and
+// 100 iterations for root finding and optimisation.
+type NumericRequest struct {
+	Expression    string  `json:"expression" binding:"required"`
+	Variable      string  `json:"variable"`
+	Tolerance     float64 `json:"tolerance"`
+	MaxIterations int     `json:"max_iterations"`
+}
+
+// IntegrateRequest represents a definite integral of an expression from A to B.
+// Method is "gauss-kronrod" (default) or "simpson".
+type IntegrateRequest struct {
+	NumericRequest
+	A      *Operand `json:"a"`
+	B      *Operand `json:"b"`
+	Method string   `json:"method"`
+}
+
+// IntegrateResponse represents the value of an integral and its estimated absolute error
+type IntegrateResponse struct {
+	Result        float64 `json:"result"`
+	ErrorEstimate float64 `json:"error_estimate"`
+	Evaluations   int     `json:"evaluations"`
+	Method        string  `json:"method"`
+}
+
+// RootRequest represents a search for a root of an expression.
+// Method is "brent" (default) or "bisection", which need the bracketing interval [A, B],
+// or "newton", which starts from X0 (default: the midpoint of [A, B]).
+type RootRequest struct {
+	NumericRequest
+	A      *Operand `json:"a"`
+	B      *Operand `json:"b"`
+	X0     *Operand `json:"x0"`
+	Method string   `json:"method"`
+}
+
+// RootResponse represents a root, the expression's value there and the iterations used
+type RootResponse struct {
+	Root       float64 `json:"root"`
+	Value      float64 `json:"value"`
+	Iterations int     `json:"iterations"`
+	Method     string  `json:"method"`
+}
+
+// OptimizeRequest represents a search for a local minimum or maximum of an expression in [A, B]
+type OptimizeRequest struct {
+	NumericRequest
+	A *Operand `json:"a"`
+	B *Operand `json:"b"`
+}
+
+// OptimizeResponse represents the location and value of an extremum
+type OptimizeResponse struct {
+	X          float64 `json:"x"`
+	Value      float64 `json:"value"`
+	Iterations int     `json:"iterations"`
+}
+
+// expression is a parsed expression in one variable, bound to the caller's variables and functions
+type expression struct {
+	node      expr.Node
+	variable  string
+	params    map[string]float64
+	functions functionSet
+}
+
+// parseExpression parses src for the caller. Identifiers other than variable resolve to the caller's
+// variables or constants, and calls to the caller's functions are inlined.
+func (s *Service) parseExpression(c *gin.Context, src, variable string) (*expression, error) {
+	if variable == "" {
+		variable = "x"
+	}
+	if err := core.ValidateVariableName(variable); err != nil {
+		return nil, err
+	}
+	return s.compileExpression(c, src, variable)
+}
+
+// compileExpression parses src for the caller like parseExpression; an empty variable leaves every
+// identifier to resolve to a variable or constant
+func (s *Service) compileExpression(c *gin.Context, src, variable string) (*expression, error) {
+	node, err := expr.Parse(src)
+	if err != nil {
+		return nil, core.ExpressionError(err)
+	}
+
+	e := &expression{variable: variable, params: make(map[string]float64), functions: functionSet{}}
+	if owner, ok := ownerFromContext(c); ok {
+		e.functions = s.functions(owner)
+		for _, v := range s.store().Variables(owner) {
+			e.params[v.Name] = v.Value
+		}
+	}
+	if e.node, err = e.functions.inline(node, 1, newCallBudget()); err != nil {
+		return nil, core.ExpressionError(err)
+	}
+	for _, name := range expr.Identifiers(e.node) {
+		_, isVariable := e.params[name]
+		_, isConstant := core.LookupConstant(name)
+		if name != variable && !isVariable && !isConstant {
+			return nil, newError(CodeUndefinedVariable, fmt.Sprintf("unknown identifier '%s'", name)).
+				WithDetail("name", name).
+				WithDetail("variable", variable)
+		}
+	}
+	return e, nil
+}
+
+// inline replaces calls to functions in set by their bodies, spending one call of budget per inlined call
+func (set functionSet) inline(n expr.Node, depth int, budget *callBudget) (expr.Node, error) {
+	if depth > maxCallDepth {
+		return nil, &expr.Error{Kind: expr.ErrRecursion, Pos: -1, Msg: fmt.Sprintf("maximum call depth of %d exceeded", maxCallDepth)}
+	}
+	var err error
+	bindings := make(map[*expr.Call]expr.Node)
+	expr.Walk(n, func(node expr.Node) {
+		call, ok := node.(*expr.Call)
+		if !ok || err != nil {
+			return
+		}
+		fn, ok := set[call.Name]
+		if !ok {
+			return
+		}
+		if _, isBuiltin := expr.LookupBuiltin(call.Name); isBuiltin {
+			return
+		}
+		if len(call.Args) != len(fn.Params) {
+			err = &expr.Error{Kind: expr.ErrArity, Pos: -1, Msg: fmt.Sprintf("%s expects %d argument(s), got %d", fn.Name, len(fn.Params), len(call.Args))}
+			return
+		}
+		if err = budget.spend(); err != nil {
+			return
+		}
+		var body expr.Node
+		if body, err = functionBody(fn); err != nil {
+			return
+		}
+		args := make(map[string]expr.Node, len(fn.Params))
+		for i, param := range fn.Params {
+			if args[param], err = set.inline(call.Args[i], depth, budget); err != nil {
+				return
+			}
+		}
+		inlined := expr.Substitute(body, args)
+		if err = budget.spendNodes(inlined); err != nil {
+			return
+		}
+		bindings[call], err = set.inline(inlined, depth+1, budget)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replaceCalls(n, bindings), nil
+}
+
+// replaceCalls returns a copy of n with the given call nodes replaced
+func replaceCalls(n expr.Node, bindings map[*expr.Call]expr.Node) expr.Node {
+	switch n := n.(type) {
+	case *expr.Unary:
+		return &expr.Unary{Op: n.Op, X: replaceCalls(n.X, bindings)}
+	case *expr.Binary:
+		return &expr.Binary{Op: n.Op, L: replaceCalls(n.L, bindings), R: replaceCalls(n.R, bindings)}
+	case *expr.Call:
+		if replacement, ok := bindings[n]; ok {
+			return replacement
+		}
+		args := make([]expr.Node, len(n.Args))
+		for i, arg := range n.Args {
+			args[i] = replaceCalls(arg, bindings)
+		}
+		return &expr.Call{Name: n.Name, Args: args}
+	default:
+		return n
+	}
+}
+
+// eval evaluates node with the expression variable set to x
+func (e *expression) eval(node expr.Node, x float64) (float64, error) {
+	e.params[e.variable] = x
+	result, err := expr.Eval(node, &functionEnv{functions: e.functions, params: e.params, budget: newCallBudget()})
+	if err != nil {
+		return 0, core.ExpressionError(err)
+	}
+	return result, nil
+}
+
+// derivative differentiates expression order times with respect to variable
+func (s *Service) derivative(e *expression, order int) (expr.Node, error) {
+	s.logger().Debug("Performing derivative", "expression", e.node.String(), "variable", e.variable, "order", order)
+	node := e.node
+	for range order {
+		d, err := expr.Derive(node, e.variable)
+		if err != nil {
+			s.logger().Error("Differentiation failed", "expression", node.String(), "error", err)
+			return nil, core.ExpressionError(err)
+		}
+		node = d
+	}
+	s.logger().Debug("Derivative result", "derivative", node.String())
+	return node, nil
+}
+
+// Derivative handles symbolic differentiation of an expression
+func (s *Service) Derivative(c *gin.Context) {
+	var req DerivativeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind derivative JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if req.Order == 0 {
+		req.Order = 1
+	}
+	if req.Order < 1 || req.Order > maxDerivativeOrder {
+		s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, fmt.Sprintf("order must be between 1 and %d", maxDerivativeOrder)).WithDetail("order", req.Order))
+		return
+	}
+	e, err := s.parseExpression(c, req.Expression, req.Variable)
+	if err != nil {
+		s.logger().Error("Invalid expression", "operation", c.Request.URL.Path, "method", c.Request.Method, "expression", req.Expression, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	at, err := s.resolveOptionalOperand(scopeFromContext(c), "at", req.At)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing derivative request", "operation", c.Request.URL.Path, "method", c.Request.Method, "expression", req.Expression, "variable", e.variable, "order", req.Order)
+
+	node, err := s.derivative(e, req.Order)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	response := DerivativeResponse{Derivative: node.String(), Variable: e.variable, Order: req.Order}
+	if at != nil {
+		result, err := e.eval(node, *at)
+		if err == nil {
+			err = core.CheckFinite("derivative", "result", result)
+		}
+		if err != nil {
+			s.logger().Error("Derivative evaluation failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "at", *at, "error", err)
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		response.Result = &result
+		s.recordAnswer(c, result)
+	}
+
+	s.logger().Info("Derivative successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "derivative", response.Derivative)
+	s.respondJSON(c, response)
+}
+
+// function returns the expression as a real function of its variable
+func (e *expression) function() core.RealFunc {
+	return func(x float64) (float64, error) {
+		return e.eval(e.node, x)
+	}
+}
+
+// bindNumericRequest binds req, applies the defaults of base and parses its expression
+func (s *Service) bindNumericRequest(c *gin.Context, req any, base *NumericRequest, iterations int) (*expression, bool) {
+	if err := c.ShouldBindJSON(req); err != nil {
+		s.logger().Error("Failed to bind numeric JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return nil, false
+	}
+	if base.Tolerance == 0 {
+		base.Tolerance = defaultTolerance
+	}
+	if base.MaxIterations == 0 {
+		base.MaxIterations = iterations
+	}
+	if base.Tolerance < 0 || math.IsNaN(base.Tolerance) {
+		s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, "tolerance must be positive").WithDetail("tolerance", base.Tolerance))
+		return nil, false
+	}
+	if base.MaxIterations < 1 || base.MaxIterations > maxIterationLimit {
+		s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, fmt.Sprintf("max_iterations must be between 1 and %d", maxIterationLimit)).
+			WithDetail("max_iterations", base.MaxIterations))
+		return nil, false
+	}
+	e, err := s.parseExpression(c, base.Expression, base.Variable)
+	if err != nil {
+		s.logger().Error("Invalid expression", "operation", c.Request.URL.Path, "method", c.Request.Method, "expression", base.Expression, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return nil, false
+	}
+	return e, true
+}
+
+// resolveInterval resolves the required interval bounds a and b
+func (s *Service) resolveInterval(c *gin.Context, a, b *Operand) (float64, float64, error) {
+	sc := scopeFromContext(c)
+	bounds := [2]float64{}
+	for i, o := range []*Operand{a, b} {
+		param := [2]string{"a", "b"}[i]
+		if o == nil {
+			return 0, 0, newError(CodeInvalidInput, fmt.Sprintf("invalid value for parameter '%s'", param)).WithDetail("param", param)
+		}
+		value, err := s.resolveOperand(sc, param, *o)
+		if err != nil {
+			return 0, 0, err
+		}
+		if math.IsInf(value, 0) || math.IsNaN(value) {
+			return 0, 0, newError(CodeInvalidInput, fmt.Sprintf("parameter '%s' must be finite", param)).WithDetail("param", param)
+		}
+		bounds[i] = value
+	}
+	return bounds[0], bounds[1], nil
+}
+
+// Integrate handles numerical integration of an expression over [a, b]
+func (s *Service) Integrate(c *gin.Context) {
+	var req IntegrateRequest
+	e, ok := s.bindNumericRequest(c, &req, &req.NumericRequest, defaultIntegrationSplits)
+	if !ok {
+		return
+	}
+	if req.Method == "" {
+		req.Method = core.IntegrationGaussKronrod
+	}
+	a, b, err := s.resolveInterval(c, req.A, req.B)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing integration request", "operation", c.Request.URL.Path, "method", c.Request.Method, "expression", req.Expression, "a", a, "b", b, "integration_method", req.Method)
+
+	q, err := core.Integrate(s.withLogger(c.Request.Context()), req.Method, e.function(), a, b, req.Tolerance, req.MaxIterations)
+	if err != nil {
+		s.logger().Error("Integration failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "expression", req.Expression, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Integration successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "result", q.Value, "error_estimate", q.ErrorEstimate)
+	s.recordAnswer(c, q.Value)
+	s.respondJSON(c, IntegrateResponse{Result: q.Value, ErrorEstimate: q.ErrorEstimate, Evaluations: q.Evaluations, Method: req.Method})
+}
+
+// findRoot finds a root of the expression by the method of req
+func (s *Service) findRoot(ctx context.Context, e *expression, req RootRequest, a, b, x0 float64) (float64, int, error) {
+	s.logger().Debug("Performing root finding", "expression", e.node.String(), "root_method", req.Method)
+	var root float64
+	var iterations int
+	var err error
+	switch req.Method {
+	case "bisection":
+		root, iterations, err = core.Bisection(ctx, e.function(), a, b, req.Tolerance, req.MaxIterations)
+	case "newton":
+		var d expr.Node
+		if d, err = s.derivative(e, 1); err == nil {
+			df := func(x float64) (float64, error) { return e.eval(d, x) }
+			root, iterations, err = core.Newton(ctx, e.function(), df, x0, req.Tolerance, req.MaxIterations)
+		}
+	default:
+		root, iterations, err = core.Brent(ctx, e.function(), a, b, req.Tolerance, req.MaxIterations)
+	}
+	if err != nil {
+		s.logger().Error("Root finding failed", "expression", e.node.String(), "root_method", req.Method, "error", err)
+		return 0, iterations, err
+	}
+	s.logger().Debug("Root finding result", "root", root, "iterations", iterations)
+	return root, iterations, nil
+}
+
+// FindRoot handles finding a root of an expression
+func (s *Service) FindRoot(c *gin.Context) {
+	var req RootRequest
+	e, ok := s.bindNumericRequest(c, &req, &req.NumericRequest, defaultIterations)
+	if !ok {
+		return
+	}
+	if req.Method == "" {
+		req.Method = "brent"
+	}
+	var a, b, x0 float64
+	var err error
+	switch req.Method {
+	case "brent", "bisection":
+		a, b, err = s.resolveInterval(c, req.A, req.B)
+	case "newton":
+		if req.X0 != nil {
+			x0, err = s.resolveOperand(scopeFromContext(c), "x0", *req.X0)
+		} else if a, b, err = s.resolveInterval(c, req.A, req.B); err == nil {
+			x0 = (a + b) / 2
+		}
+	default:
+		err = newError(CodeInvalidInput, fmt.Sprintf("unknown root-finding method '%s'", req.Method)).
+			WithDetail("method", req.Method).
+			WithDetail("allowed", []string{"bisection", "brent", "newton"})
+	}
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing root finding request", "operation", c.Request.URL.Path, "method", c.Request.Method, "expression", req.Expression, "root_method", req.Method)
+
+	root, iterations, err := s.findRoot(s.withLogger(c.Request.Context()), e, req, a, b, x0)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	value, err := e.eval(e.node, root)
+	if err == nil {
+		err = core.CheckFinite(req.Method, "value", value)
+	}
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Root finding successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "root", root, "iterations", iterations)
+	s.recordAnswer(c, root)
+	s.respondJSON(c, RootResponse{Root: root, Value: value, Iterations: iterations, Method: req.Method})
+}
+
+// Minimize handles finding a local minimum of an expression in [a, b]
+func (s *Service) Minimize(c *gin.Context) {
+	s.handleOptimize(c, false)
+}
+
+// Maximize handles finding a local maximum of an expression in [a, b]
+func (s *Service) Maximize(c *gin.Context) {
+	s.handleOptimize(c, true)
+}
+
+// handleOptimize handles the common logic for minimisation and maximisation
+func (s *Service) handleOptimize(c *gin.Context, maximize bool) {
+	var req OptimizeRequest
+	e, ok := s.bindNumericRequest(c, &req, &req.NumericRequest, defaultIterations)
+	if !ok {
+		return
+	}
+	a, b, err := s.resolveInterval(c, req.A, req.B)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing optimisation request", "operation", c.Request.URL.Path, "method", c.Request.Method, "expression", req.Expression, "a", a, "b", b, "maximize", maximize)
+
+	optimize := core.Minimize
+	if maximize {
+		optimize = core.Maximize
+	}
+	result, err := optimize(s.withLogger(c.Request.Context()), e.function(), a, b, req.Tolerance, req.MaxIterations)
+	if err != nil {
+		s.logger().Error("Optimisation failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Optimisation successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "x", result.X, "value", result.Value)
+	s.recordAnswer(c, result.X)
+	s.respondJSON(c, OptimizeResponse{X: result.X, Value: result.Value, Iterations: result.Iterations})
+}
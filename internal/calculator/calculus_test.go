@@ -0,0 +1,110 @@
+package calculator
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"calculator/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDerivative tests the derivative endpoint
+func TestDerivative(t *testing.T) {
+	s := &Service{Store: storage.NewMemory()}
+	defineFunction(s, "alice", "sq(u) = u^2")
+	s.store().SetVariable("key:alice", "k", 3)
+
+	tests := []struct {
+		name       string
+		body       map[string]any
+		derivative string
+		result     *float64
+		code       string
+	}{
+		{name: "polynomial", body: map[string]any{"expression": "x^3 + 2x"}, derivative: "3 * x^2 + 2"},
+		{name: "evaluated", body: map[string]any{"expression": "sin(x)", "at": "pi"}, derivative: "cos(x)", result: ptr(-1.0)},
+		{name: "variable", body: map[string]any{"expression": "t^2 * y", "variable": "t"}, code: CodeUndefinedVariable},
+		{name: "second order", body: map[string]any{"expression": "x^3", "order": 2, "at": 2}, derivative: "6 * x", result: ptr(12.0)},
+		{name: "user function inlined", body: map[string]any{"expression": "k * sq(x)", "at": 1}, derivative: "2 * k * x", result: ptr(6.0)},
+		{name: "syntax error", body: map[string]any{"expression": "x +"}, code: CodeInvalidInput},
+		{name: "unknown function", body: map[string]any{"expression": "f(x)"}, code: CodeUndefinedVariable},
+		{name: "invalid order", body: map[string]any{"expression": "x", "order": 11}, code: CodeInvalidInput},
+		{name: "evaluation error", body: map[string]any{"expression": "ln(x)", "at": 0}, code: CodeDivisionByZero},
+		{name: "overflow", body: map[string]any{"expression": "x^2", "at": 1e308}, code: CodeDomainError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestContext("POST", "/derivative", tt.body)
+			c.Request.Header.Set(APIKeyHeader, "alice")
+			s.Derivative(c)
+			if tt.code != "" {
+				assertErrorCode(t, w, http.StatusBadRequest, tt.code)
+				return
+			}
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response DerivativeResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.derivative, response.Derivative)
+			if tt.result != nil {
+				assert.InDelta(t, *tt.result, *response.Result, 1e-9)
+			} else {
+				assert.Nil(t, response.Result)
+			}
+		})
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+// TestNumericEndpoints tests the integration, root-finding and optimisation endpoints
+func TestNumericEndpoints(t *testing.T) {
+	s := &Service{}
+
+	tests := []struct {
+		name     string
+		handler  func(*gin.Context)
+		body     map[string]any
+		field    string
+		expected float64
+		code     string
+	}{
+		{"integrate", s.Integrate, map[string]any{"expression": "x^2", "a": 0, "b": 3}, "result", 9, ""},
+		{"integrate simpson", s.Integrate, map[string]any{"expression": "sin(t)", "variable": "t", "a": 0, "b": "pi", "method": "simpson"}, "result", 2, ""},
+		{"integrate reversed", s.Integrate, map[string]any{"expression": "x", "a": 2, "b": 0}, "result", -2, ""},
+		{"integrate missing bound", s.Integrate, map[string]any{"expression": "x", "a": 0}, "", 0, CodeInvalidInput},
+		{"integrate unknown method", s.Integrate, map[string]any{"expression": "x", "a": 0, "b": 1, "method": "romberg"}, "", 0, CodeInvalidInput},
+		{"integrate domain error", s.Integrate, map[string]any{"expression": "ln(x)", "a": -1, "b": 1}, "", 0, CodeDomainError},
+		{"integrate overflow", s.Integrate, map[string]any{"expression": "exp(x)", "a": 0, "b": 1000}, "", 0, CodeDomainError},
+		{"integrate overflow simpson", s.Integrate, map[string]any{"expression": "exp(x)", "a": 0, "b": 1000, "method": "simpson"}, "", 0, CodeDomainError},
+		{"integrate iteration limit", s.Integrate, map[string]any{"expression": "sin(1/x)", "a": 0.000001, "b": 1, "max_iterations": 3}, "", 0, CodeNoConvergence},
+		{"root brent", s.FindRoot, map[string]any{"expression": "x^3 - x - 2", "a": 1, "b": 2}, "root", 1.5213797068045676, ""},
+		{"root bisection", s.FindRoot, map[string]any{"expression": "cos(x) - x", "a": 0, "b": 1, "method": "bisection"}, "root", 0.7390851332151607, ""},
+		{"root newton", s.FindRoot, map[string]any{"expression": "x^2 - 2", "x0": 1, "method": "newton"}, "root", 1.4142135623730951, ""},
+		{"root not bracketed", s.FindRoot, map[string]any{"expression": "x^2 + 1", "a": -1, "b": 1}, "", 0, CodeDomainError},
+		{"root newton flat", s.FindRoot, map[string]any{"expression": "x^2 + 1", "x0": 0, "method": "newton"}, "", 0, CodeNoConvergence},
+		{"root iteration limit", s.FindRoot, map[string]any{"expression": "x - 0.3", "a": 0, "b": 1, "method": "bisection", "max_iterations": 2}, "", 0, CodeNoConvergence},
+		{"minimize", s.Minimize, map[string]any{"expression": "(x - 2)^2 + 1", "a": 0, "b": 5}, "x", 2, ""},
+		{"maximize", s.Maximize, map[string]any{"expression": "sin(x)", "a": 0, "b": 3}, "value", 1, ""},
+		{"minimize unbounded", s.Minimize, map[string]any{"expression": "-exp(x)", "a": 0, "b": 1000}, "", 0, CodeDomainError},
+		{"invalid tolerance", s.Minimize, map[string]any{"expression": "x", "a": 0, "b": 1, "tolerance": -1}, "", 0, CodeInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestContext("POST", "/", tt.body)
+			tt.handler(c)
+			if tt.code != "" {
+				assertErrorCode(t, w, http.StatusBadRequest, tt.code)
+				return
+			}
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response map[string]any
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.InDelta(t, tt.expected, response[tt.field], 1e-6)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+
+	"calculator/core"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Stable error codes returned in the "code" field of error responses
+const (
+	CodeInvalidInput      = core.CodeInvalidInput
+	CodeDivisionByZero    = core.CodeDivisionByZero
+	CodeDomainError       = core.CodeDomainError
+	CodeDimensionMismatch = core.CodeDimensionMismatch
+	CodeSingularMatrix    = core.CodeSingularMatrix
+	CodeNoConvergence     = core.CodeNoConvergence
+	CodeUnknownUnit       = core.CodeUnknownUnit
+	CodeIncompatibleUnits = core.CodeIncompatibleUnits
+	CodeUndefinedVariable = core.CodeUndefinedVariable
+	CodeNotFound          = core.CodeNotFound
+	CodeConflict          = core.CodeConflict
+	CodeUnknownOperation  = core.CodeUnknownOperation
+	CodeRecursionLimit    = core.CodeRecursionLimit
+	CodeRateLimited       = core.CodeRateLimited
+	CodeUnauthorized      = core.CodeUnauthorized
+	CodeForbidden         = core.CodeForbidden
+	CodeInternal          = core.CodeInternal
+)
+
+// Error is a calculator error carrying a stable, machine-readable code
+type Error = core.Error
+
+// newError creates a calculator error with the given code and message
+func newError(code, message string) *Error {
+	return core.NewError(code, message)
+}
+
+// ErrorResponse represents the structured error envelope returned by all endpoints
+type ErrorResponse struct {
+	Error   string         `json:"error"`
+	Code    string         `json:"code"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// toError converts any error into a calculator error, defaulting to CodeInvalidInput
+func toError(err error) *Error {
+	return core.AsError(err)
+}
+
+// checkFinite returns a DOMAIN_ERROR when result overflowed or is undefined, since JSON cannot represent it
+func checkFinite(result float64) error {
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return newError(CodeDomainError, "result is not a finite number").WithDetail("result", fmt.Sprint(result))
+	}
+	return nil
+}
+
+// respondError writes err to the client using the structured error envelope
+func (s *Service) respondError(c *gin.Context, status int, err error) {
+	calcErr := toError(err)
+	c.JSON(status, ErrorResponse{
+		Error:   calcErr.Message,
+		Code:    calcErr.Code,
+		Details: calcErr.Details,
+	})
+}
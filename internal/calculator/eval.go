@@ -0,0 +1,43 @@
+package calculator
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EvalRequest represents an arithmetic expression to evaluate, such as "2*(3+4)". The expression may
+// use built-in functions, constants and the caller's variables and functions.
+type EvalRequest struct {
+	Expression string `json:"expression" binding:"required"`
+}
+
+// Eval handles evaluating an arithmetic expression
+func (s *Service) Eval(c *gin.Context) {
+	var req EvalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind eval JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	e, err := s.compileExpression(c, req.Expression, "")
+	if err != nil {
+		s.logger().Error("Invalid expression", "operation", c.Request.URL.Path, "method", c.Request.Method, "expression", req.Expression, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing eval request", "operation", c.Request.URL.Path, "method", c.Request.Method, "expression", req.Expression)
+
+	result, err := e.eval(e.node, 0)
+	if err != nil {
+		s.logger().Error("Expression evaluation failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "expression", req.Expression, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Eval successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "result", result)
+	if s.respondResult(c, Response{Result: result}) {
+		s.recordRequestHistory(c, nil, result, "")
+	}
+}
@@ -0,0 +1,56 @@
+package calculator
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"calculator/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEval tests the expression evaluation endpoint
+func TestEval(t *testing.T) {
+	s := &Service{Store: storage.NewMemory()}
+	defineFunction(s, "alice", "sq(u) = u^2")
+	s.store().SetVariable("key:alice", "k", 3)
+
+	tests := []struct {
+		name       string
+		expression string
+		result     float64
+		code       string
+	}{
+		{name: "arithmetic", expression: "2*(3+4)", result: 14},
+		{name: "precedence", expression: "1 + 2^3 / 4", result: 3},
+		{name: "builtins and constants", expression: "sqrt(16) + cos(pi)", result: 3},
+		{name: "variables and functions", expression: "k * sq(2)", result: 12},
+		{name: "unknown identifier", expression: "x + 1", code: CodeUndefinedVariable},
+		{name: "syntax error", expression: "2 *", code: CodeInvalidInput},
+		{name: "division by zero", expression: "1/0", code: CodeDivisionByZero},
+		{name: "overflow", expression: "10^400", code: CodeDomainError},
+		{name: "missing expression", code: CodeInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestContext("POST", "/eval", map[string]any{"expression": tt.expression})
+			c.Request.Header.Set(APIKeyHeader, "alice")
+			s.Eval(c)
+			if tt.code != "" {
+				assertErrorCode(t, w, http.StatusBadRequest, tt.code)
+				return
+			}
+			require.Equal(t, http.StatusOK, w.Code)
+			var response Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.InDelta(t, tt.result, response.Result, 1e-9)
+		})
+	}
+
+	history := s.store().History("key:alice", 1)
+	require.Len(t, history, 1)
+	assert.Equal(t, "eval", history[0].Operation)
+	assert.Equal(t, 12.0, history[0].Result)
+}
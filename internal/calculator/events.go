@@ -0,0 +1,318 @@
+package calculator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"calculator/internal/events"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ClientHeader names the client in the calculation events feed; the client's IP address is used without it
+	ClientHeader = "X-Client-ID"
+
+	// defaultEventBuffer is how many recent calculations are kept for clients resuming with Last-Event-ID
+	defaultEventBuffer = 1000
+	// eventSubscriberBuffer is how many events a slow feed client may lag behind before it is disconnected
+	eventSubscriberBuffer = 256
+	// eventKeepAlive is how often an idle feed sends a comment to keep proxies from closing it
+	eventKeepAlive = 15 * time.Second
+	// maxEventBodyBytes bounds the request and response bodies captured for an event
+	maxEventBodyBytes = 4096
+)
+
+// Transports over which calculations arrive
+const (
+	TransportHTTP      = "http"
+	TransportGRPC      = "grpc"
+	TransportGraphQL   = "graphql"
+	TransportWebSocket = "websocket"
+)
+
+// CalculationEvent describes a completed calculation in the events feed. Result is set on success and
+// Code on failure.
+type CalculationEvent struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Transport string    `json:"transport"`
+	Tenant    string    `json:"tenant"`
+	Client    string    `json:"client"`
+	Inputs    any       `json:"inputs,omitempty"`
+	Result    any       `json:"result,omitempty"`
+	Code      string    `json:"code,omitempty"`
+	LatencyMS float64   `json:"latency_ms"`
+}
+
+// calculationEventData is the data of a feed event, repeating its ID for clients that log the data alone
+type calculationEventData struct {
+	ID uint64 `json:"id"`
+	CalculationEvent
+}
+
+// NewEventBus returns an events feed for Service.Events remembering the last size calculations
+func NewEventBus(size int) *events.Bus[CalculationEvent] {
+	return events.New[CalculationEvent](size)
+}
+
+// eventBus returns a safe events feed (never nil). If Events is nil, a default feed is created on first use.
+func (s *Service) eventBus() *events.Bus[CalculationEvent] {
+	s.eventsOnce.Do(func() {
+		if s.Events == nil {
+			s.Events = NewEventBus(defaultEventBuffer)
+		}
+	})
+	return s.Events
+}
+
+// clientFromContext returns the client named by the X-Client-ID header, or the client's IP address
+func clientFromContext(c *gin.Context) string {
+	if client := c.GetHeader(ClientHeader); client != "" {
+		return client
+	}
+	return c.ClientIP()
+}
+
+// latencyMS returns the milliseconds elapsed since start
+func latencyMS(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
+
+// publishEvaluation publishes a calculation made through evaluate by the gRPC, GraphQL or WebSocket APIs
+// for a client of tenantID and records it in the audit trail
+func (s *Service) publishEvaluation(transport, tenantID, client, operation string, a, b *Operand, start time.Time, result float64, err error) {
+	inputs := map[string]any{}
+	if a != nil {
+		inputs["a"] = a
+	}
+	if b != nil {
+		inputs["b"] = b
+	}
+	event := CalculationEvent{
+		Time:      start.UTC(),
+		Operation: operation,
+		Transport: transport,
+		Tenant:    tenantID,
+		Client:    client,
+		Inputs:    inputs,
+		LatencyMS: latencyMS(start),
+	}
+	if err != nil {
+		event.Code = toError(err).Code
+	} else {
+		event.Result = result
+	}
+	s.eventBus().Publish(event)
+	s.auditEvaluation(transport, qualify(tenantID, "client:"+client), operation, inputs, result, err)
+}
+
+// capturingWriter keeps the start of the response body for the events feed
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// capture keeps up to maxEventBodyBytes of b; a longer body is not valid JSON and is left out of the event
+func (w *capturingWriter) capture(b []byte) {
+	if remaining := maxEventBodyBytes + 1 - w.body.Len(); remaining > 0 {
+		w.body.Write(b[:min(len(b), remaining)])
+	}
+}
+
+func (w *capturingWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *capturingWriter) WriteString(str string) (int, error) {
+	w.capture([]byte(str))
+	return w.ResponseWriter.WriteString(str)
+}
+
+// eventOperation names the calculation of a route: its path below /api/v1 with the operation and function
+// names filled in, such as "add", "matrix/inverse" or "sessions/:id/operations/add"
+func eventOperation(c *gin.Context) string {
+	operation := strings.TrimPrefix(c.FullPath(), "/api/v1/")
+	if operation == "" {
+		operation = strings.TrimPrefix(c.Request.URL.Path, "/api/v1/")
+	}
+	operation = strings.ReplaceAll(operation, ":operation", c.Param("operation"))
+	return strings.ReplaceAll(operation, ":name", c.Param("name"))
+}
+
+// PublishCalculations is middleware publishing each calculation request to the events feed with its inputs
+// (the JSON body or query parameters), its result or error code and its latency
+func (s *Service) PublishCalculations(c *gin.Context) {
+	start := time.Now()
+	inputs := requestInputs(c)
+	writer := &capturingWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+
+	c.Next()
+
+	event := CalculationEvent{
+		Time:      start.UTC(),
+		Operation: eventOperation(c),
+		Transport: TransportHTTP,
+		Tenant:    tenantFromContext(c),
+		Client:    clientFromContext(c),
+		Inputs:    inputs,
+		LatencyMS: latencyMS(start),
+	}
+	if result, code := responseOutcome(writer.Status(), writer.body.Bytes()); code != "" {
+		event.Code = code
+	} else if result != nil {
+		event.Result = result
+	}
+	s.eventBus().Publish(event)
+}
+
+// requestInputs returns the inputs of a request: its JSON body, or its query parameters for GET requests.
+// The body is left for the handler to read; bodies longer than maxEventBodyBytes or not JSON are left out.
+func requestInputs(c *gin.Context) any {
+	if c.Request.Body != nil && c.Request.Method != http.MethodGet {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return nil
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if len(body) <= maxEventBodyBytes && json.Valid(body) {
+			return json.RawMessage(body)
+		}
+		return nil
+	}
+	query := c.Request.URL.Query()
+	if len(query) == 0 {
+		return nil
+	}
+	params := make(map[string]string, len(query))
+	for key := range query {
+		params[key] = query.Get(key)
+	}
+	return params
+}
+
+// responseOutcome returns the "result" field of a successful response body, or the error code of a failed
+// one; failed responses without a code report their HTTP status
+func responseOutcome(status int, body []byte) (json.RawMessage, string) {
+	var payload struct {
+		Result json.RawMessage `json:"result"`
+		Code   string          `json:"code"`
+	}
+	_ = json.Unmarshal(body, &payload)
+	if status >= http.StatusBadRequest {
+		if payload.Code == "" {
+			return nil, strconv.Itoa(status)
+		}
+		return nil, payload.Code
+	}
+	return payload.Result, ""
+}
+
+// splitFilter returns the comma-separated values of a filter query parameter
+func splitFilter(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// StreamEvents handles streaming the calculations of the caller's tenant as Server-Sent Events, optionally
+// filtered by the comma-separated operation and client query parameters. Clients resuming with Last-Event-ID first receive the buffered
+// calculations they missed; a "missed" event warns when some have already left the buffer.
+func (s *Service) StreamEvents(c *gin.Context) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var after uint64
+	if lastEventID != "" {
+		var err error
+		if after, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			s.logger().Error("Invalid Last-Event-ID", "operation", c.Request.URL.Path, "method", c.Request.Method, "last_event_id", lastEventID)
+			s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, fmt.Sprintf("invalid Last-Event-ID '%s'", lastEventID)).
+				WithDetail("last_event_id", lastEventID))
+			return
+		}
+	} else {
+		// New clients receive only calculations made from now on
+		after = s.eventBus().LastID()
+	}
+	operations := splitFilter(c.Query("operation"))
+	clients := splitFilter(c.Query("client"))
+	tenantID := tenantFromContext(c)
+	matches := func(event CalculationEvent) bool {
+		return sameTenant(event.Tenant, tenantID) &&
+			(len(operations) == 0 || slices.Contains(operations, event.Operation)) &&
+			(len(clients) == 0 || slices.Contains(clients, event.Client))
+	}
+
+	sub, backlog, missed := s.eventBus().Subscribe(after, eventSubscriberBuffer)
+	defer sub.Close()
+
+	s.logger().Info("Events feed opened", "operation", c.Request.URL.Path, "method", c.Request.Method, "after", after, "operations", operations, "clients", clients)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	sent := 0
+	send := func(event events.Event[CalculationEvent]) error {
+		if !matches(event.Data) {
+			return nil
+		}
+		sent++
+		return sse.Encode(c.Writer, sse.Event{
+			Id:    strconv.FormatUint(event.ID, 10),
+			Event: "calculation",
+			Data:  calculationEventData{ID: event.ID, CalculationEvent: event.Data},
+		})
+	}
+	if missed {
+		_ = sse.Encode(c.Writer, sse.Event{Event: "missed", Data: gin.H{"last_event_id": after}})
+	}
+	for _, event := range backlog {
+		if err := send(event); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			s.logger().Info("Events feed closed", "operation", c.Request.URL.Path, "method", c.Request.Method, "sent", sent)
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				// The client fell behind; it reconnects with Last-Event-ID to resume from the buffer
+				s.logger().Error("Events feed client too slow", "operation", c.Request.URL.Path, "method", c.Request.Method, "sent", sent)
+				return
+			}
+			if err := send(event); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := io.WriteString(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,189 @@
+package calculator
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupEventsServer serves a few calculation routes behind PublishCalculations and the events feed
+func setupEventsServer(t *testing.T, s *Service) *httptest.Server {
+	t.Helper()
+	r := gin.New()
+	api := r.Group("/api/v1")
+	calc := api.Group("", s.PublishCalculations)
+	calc.POST("/add", s.Add)
+	calc.GET("/divide", s.DivideGET)
+	calc.POST("/rational/:operation", s.RationalOperation)
+	api.GET("/events", s.StreamEvents)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// sseEvent is a parsed Server-Sent Event
+type sseEvent struct {
+	id, event string
+	data      map[string]any
+}
+
+// readEvents opens the feed at url and reads n events
+func readEvents(t *testing.T, url string, header http.Header, n int, publish func()) []sseEvent {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	require.NoError(t, err)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	if publish != nil {
+		publish()
+	}
+
+	var events []sseEvent
+	var current sseEvent
+	scanner := bufio.NewScanner(resp.Body)
+	for len(events) < n && scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if current.event != "" {
+				events = append(events, current)
+			}
+			current = sseEvent{}
+		case strings.HasPrefix(line, "id:"):
+			current.id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "event:"):
+			current.event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &current.data))
+		}
+	}
+	require.Len(t, events, n)
+	return events
+}
+
+// TestPublishCalculations tests the events published for HTTP calculations
+func TestPublishCalculations(t *testing.T) {
+	s := &Service{}
+	server := setupEventsServer(t, s)
+
+	req, _ := http.NewRequest("POST", server.URL+"/api/v1/add", strings.NewReader(`{"a": 2, "b": 3}`))
+	req.Header.Set(ClientHeader, "dashboard")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	resp, err = http.Get(server.URL + "/api/v1/divide?a=1&b=0")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	resp, err = http.Post(server.URL+"/api/v1/rational/add", "application/json", strings.NewReader(`{"a": "1/3", "b": "1/6"}`))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	sub, backlog, _ := s.eventBus().Subscribe(0, 1)
+	sub.Close()
+	require.Len(t, backlog, 3)
+
+	add := backlog[0].Data
+	assert.Equal(t, "add", add.Operation)
+	assert.Equal(t, TransportHTTP, add.Transport)
+	assert.Equal(t, "dashboard", add.Client)
+	assert.JSONEq(t, `{"a": 2, "b": 3}`, string(add.Inputs.(json.RawMessage)))
+	assert.JSONEq(t, "5", string(add.Result.(json.RawMessage)))
+	assert.Empty(t, add.Code)
+	assert.GreaterOrEqual(t, add.LatencyMS, 0.0)
+
+	divide := backlog[1].Data
+	assert.Equal(t, "divide", divide.Operation)
+	assert.Equal(t, "127.0.0.1", divide.Client)
+	assert.Equal(t, map[string]string{"a": "1", "b": "0"}, divide.Inputs)
+	assert.Equal(t, CodeDivisionByZero, divide.Code)
+	assert.Nil(t, divide.Result)
+
+	rational := backlog[2].Data
+	assert.Equal(t, "rational/add", rational.Operation)
+	assert.JSONEq(t, "0.5", string(rational.Result.(json.RawMessage)))
+}
+
+// TestPublishEvaluations tests that calculations over the other APIs reach the feed
+func TestPublishEvaluations(t *testing.T) {
+	s := &Service{}
+	c, _ := setupTestContext("POST", "/graphql", GraphQLRequest{Query: `mutation { add(a: 1, b: "pi") divide(a: 1, b: 0) }`})
+	c.Request.Header.Set(ClientHeader, "web")
+	s.GraphQL(c)
+
+	sub, backlog, _ := s.eventBus().Subscribe(0, 1)
+	sub.Close()
+	require.Len(t, backlog, 2)
+	assert.Equal(t, TransportGraphQL, backlog[0].Data.Transport)
+	assert.Equal(t, "web", backlog[0].Data.Client)
+	assert.Equal(t, map[string]any{"a": &Operand{Value: 1}, "b": &Operand{Ref: "pi"}}, backlog[0].Data.Inputs)
+	assert.InDelta(t, 4.14159, backlog[0].Data.Result, 1e-5)
+	assert.Equal(t, CodeDivisionByZero, backlog[1].Data.Code)
+}
+
+// TestStreamEvents tests filtering, Last-Event-ID resume and live delivery
+func TestStreamEvents(t *testing.T) {
+	s := &Service{Events: NewEventBus(3)}
+	server := setupEventsServer(t, s)
+	publish := func(operation, client string) {
+		s.eventBus().Publish(CalculationEvent{Operation: operation, Client: client, Transport: TransportHTTP, Result: 1.0})
+	}
+	for _, operation := range []string{"add", "divide", "add", "multiply"} {
+		publish(operation, "alice")
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		events := readEvents(t, server.URL+"/api/v1/events", http.Header{"Last-Event-ID": {"2"}}, 2, nil)
+		assert.Equal(t, "3", events[0].id)
+		assert.Equal(t, "calculation", events[0].event)
+		assert.Equal(t, 3.0, events[0].data["id"])
+		assert.Equal(t, "add", events[0].data["operation"])
+		assert.Equal(t, "4", events[1].id)
+	})
+
+	t.Run("missed", func(t *testing.T) {
+		events := readEvents(t, server.URL+"/api/v1/events?last_event_id=0", nil, 2, nil)
+		assert.Equal(t, "missed", events[0].event)
+		assert.Equal(t, "2", events[1].id)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		events := readEvents(t, server.URL+"/api/v1/events?last_event_id=0&operation=add,multiply&client=alice", nil, 3, nil)
+		assert.Equal(t, "missed", events[0].event)
+		assert.Equal(t, "3", events[1].id)
+		assert.Equal(t, "multiply", events[2].data["operation"])
+	})
+
+	t.Run("live", func(t *testing.T) {
+		events := readEvents(t, server.URL+"/api/v1/events?operation=power", nil, 1, func() {
+			publish("add", "bob")
+			publish("power", "bob")
+		})
+		assert.Equal(t, "6", events[0].id)
+		assert.Equal(t, "bob", events[0].data["client"])
+	})
+
+	t.Run("invalid last event id", func(t *testing.T) {
+		c, w := setupTestContext("GET", "/api/v1/events?last_event_id=abc", nil)
+		s.StreamEvents(c)
+		assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+	})
+}
@@ -0,0 +1,169 @@
+package calculator
+
+import (
+	"net/http"
+
+	"calculator/core"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompoundInterestRequest represents a compound interest request
+type CompoundInterestRequest struct {
+	Principal        float64 `json:"principal"`
+	Rate             float64 `json:"rate"`
+	Years            float64 `json:"years"`
+	CompoundsPerYear int     `json:"compounds_per_year"`
+}
+
+// CompoundInterestResponse represents a compound interest response
+type CompoundInterestResponse = core.CompoundInterestResult
+
+// TimeValueRequest represents a time-value-of-money request (present value, future value, payment).
+// The fields are described on core.TimeValue.
+type TimeValueRequest = core.TimeValue
+
+// CashFlowRequest represents a net present value or internal rate of return request.
+// CashFlows[0] occurs at time zero and is not discounted.
+type CashFlowRequest struct {
+	Rate      float64   `json:"rate"`
+	CashFlows []float64 `json:"cash_flows" binding:"required"`
+	Guess     *float64  `json:"guess"`
+}
+
+// AmortizationRequest represents a loan amortization schedule request
+type AmortizationRequest struct {
+	Principal float64 `json:"principal"`
+	Rate      float64 `json:"rate"`
+	Periods   int     `json:"periods"`
+}
+
+// AmortizationPayment is a single row of an amortization schedule
+type AmortizationPayment = core.AmortizationPayment
+
+// AmortizationResponse represents a loan amortization schedule response
+type AmortizationResponse = core.AmortizationSchedule
+
+// CompoundInterest handles compound interest calculation
+func (s *Service) CompoundInterest(c *gin.Context) {
+	var req CompoundInterestRequest
+	handleFinanceOperation(s, c, &req, func() (any, error) {
+		return core.CompoundInterest(s.withLogger(c.Request.Context()), req.Principal, req.Rate, req.Years, req.CompoundsPerYear)
+	})
+}
+
+// FutureValue handles future value calculation
+func (s *Service) FutureValue(c *gin.Context) {
+	var req TimeValueRequest
+	handleFinanceOperation(s, c, &req, func() (any, error) {
+		result, err := core.FutureValue(s.withLogger(c.Request.Context()), req)
+		return Response{Result: result}, err
+	})
+}
+
+// PresentValue handles present value calculation
+func (s *Service) PresentValue(c *gin.Context) {
+	var req TimeValueRequest
+	handleFinanceOperation(s, c, &req, func() (any, error) {
+		result, err := core.PresentValue(s.withLogger(c.Request.Context()), req)
+		return Response{Result: result}, err
+	})
+}
+
+// Payment handles periodic payment (PMT) calculation
+func (s *Service) Payment(c *gin.Context) {
+	var req TimeValueRequest
+	handleFinanceOperation(s, c, &req, func() (any, error) {
+		result, err := core.Payment(s.withLogger(c.Request.Context()), req)
+		return Response{Result: result}, err
+	})
+}
+
+// NPV handles net present value calculation
+func (s *Service) NPV(c *gin.Context) {
+	var req CashFlowRequest
+	handleFinanceOperation(s, c, &req, func() (any, error) {
+		result, err := core.NPV(s.withLogger(c.Request.Context()), req.Rate, req.CashFlows)
+		return Response{Result: result}, err
+	})
+}
+
+// IRR handles internal rate of return calculation
+func (s *Service) IRR(c *gin.Context) {
+	var req CashFlowRequest
+	handleFinanceOperation(s, c, &req, func() (any, error) {
+		guess := 0.1
+		if req.Guess != nil {
+			guess = *req.Guess
+		}
+		result, err := core.IRR(s.withLogger(c.Request.Context()), req.CashFlows, guess)
+		return Response{Result: result}, err
+	})
+}
+
+// Amortization handles loan amortization schedule calculation
+func (s *Service) Amortization(c *gin.Context) {
+	var req AmortizationRequest
+	handleFinanceOperation(s, c, &req, func() (any, error) {
+		return core.Amortization(s.withLogger(c.Request.Context()), req.Principal, req.Rate, req.Periods)
+	})
+}
+
+// PercentChange handles percentage change from a to b
+func (s *Service) PercentChange(c *gin.Context) {
+	s.handleOperation(c, core.PercentChange)
+}
+
+// PercentChangeGET handles percentage change via GET
+func (s *Service) PercentChangeGET(c *gin.Context) {
+	s.handleGetOperation(c, core.PercentChange)
+}
+
+// Markup handles marking up price a by b percent
+func (s *Service) Markup(c *gin.Context) {
+	s.handleOperation(c, core.Markup)
+}
+
+// MarkupGET handles markup via GET
+func (s *Service) MarkupGET(c *gin.Context) {
+	s.handleGetOperation(c, core.Markup)
+}
+
+// Discount handles discounting price a by b percent
+func (s *Service) Discount(c *gin.Context) {
+	s.handleOperation(c, core.Discount)
+}
+
+// DiscountGET handles discount via GET
+func (s *Service) DiscountGET(c *gin.Context) {
+	s.handleGetOperation(c, core.Discount)
+}
+
+// handleFinanceOperation handles the common logic for financial operations via POST.
+// req is bound from the JSON body before op is called.
+func handleFinanceOperation[T any](s *Service, c *gin.Context, req *T, op func() (any, error)) {
+	if err := c.ShouldBindJSON(req); err != nil {
+		s.logger().Error("Failed to bind finance JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing finance operation request", "operation", c.Request.URL.Path, "method", c.Request.Method, "request", *req)
+
+	response, err := op()
+	if err != nil {
+		s.logger().Error("Finance operation failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "request", *req, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if result, ok := response.(Response); ok {
+		if response, err = s.formatResponse(c, result); err != nil {
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	s.logger().Info("Finance operation successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "result", response)
+	s.respondJSON(c, response)
+}
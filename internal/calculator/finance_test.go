@@ -0,0 +1,136 @@
+package calculator
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFinanceOperations tests the scalar financial handlers
+func TestFinanceOperations(t *testing.T) {
+	s := &Service{}
+
+	tests := []struct {
+		name           string
+		handler        gin.HandlerFunc
+		body           map[string]any
+		expectedStatus int
+		expectedResult float64
+		expectedCode   string
+	}{
+		{"future value of deposit", s.FutureValue, map[string]any{"rate": 0.05, "periods": 10, "present_value": -1000}, http.StatusOK, 1628.894627, ""},
+		{"future value zero rate", s.FutureValue, map[string]any{"rate": 0, "periods": 10, "payment": -100}, http.StatusOK, 1000, ""},
+		{"future value invalid rate", s.FutureValue, map[string]any{"rate": -1, "periods": 10, "present_value": -1000}, http.StatusBadRequest, 0, CodeDomainError},
+		{"present value of annuity", s.PresentValue, map[string]any{"rate": 0.05, "periods": 10, "payment": -100}, http.StatusOK, 772.173493, ""},
+		{"payment for loan", s.Payment, map[string]any{"rate": 0.01, "periods": 12, "present_value": 10000}, http.StatusOK, -888.487887, ""},
+		{"payment annuity due", s.Payment, map[string]any{"rate": 0.01, "periods": 12, "present_value": 10000, "due": true}, http.StatusOK, -879.690977, ""},
+		{"payment zero periods", s.Payment, map[string]any{"rate": 0.01, "periods": 0, "present_value": 10000}, http.StatusBadRequest, 0, CodeInvalidInput},
+		{"npv", s.NPV, map[string]any{"rate": 0.1, "cash_flows": []float64{-1000, 500, 500, 500}}, http.StatusOK, 243.425995, ""},
+		{"npv no cash flows", s.NPV, map[string]any{"rate": 0.1, "cash_flows": []float64{}}, http.StatusBadRequest, 0, CodeInvalidInput},
+		{"irr", s.IRR, map[string]any{"cash_flows": []float64{-1000, 500, 500, 500}}, http.StatusOK, 0.233752, ""},
+		{"irr no sign change", s.IRR, map[string]any{"cash_flows": []float64{100, 200}}, http.StatusBadRequest, 0, CodeDomainError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestContext("POST", "/finance", tt.body)
+			tt.handler(c)
+
+			if tt.expectedStatus != http.StatusOK {
+				assertErrorCode(t, w, tt.expectedStatus, tt.expectedCode)
+				return
+			}
+			assertResponse(t, w, tt.expectedStatus, tt.expectedResult, "")
+		})
+	}
+}
+
+// TestFinanceOverflow tests that a finance response holding a value beyond float64 is a DOMAIN_ERROR
+func TestFinanceOverflow(t *testing.T) {
+	c, w := setupTestContext("POST", "/finance/amortization", map[string]any{"principal": 1, "rate": 0.01, "periods": 1})
+	var req AmortizationRequest
+	handleFinanceOperation(&Service{}, c, &req, func() (any, error) {
+		return AmortizationResponse{Payment: math.Inf(1)}, nil
+	})
+	assertErrorCode(t, w, http.StatusBadRequest, CodeDomainError)
+}
+
+// TestCompoundInterest tests the compound interest handler
+func TestCompoundInterest(t *testing.T) {
+	s := &Service{}
+
+	c, w := setupTestContext("POST", "/finance/compound-interest", map[string]any{
+		"principal": 1000, "rate": 0.05, "years": 10, "compounds_per_year": 12,
+	})
+	s.CompoundInterest(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response CompoundInterestResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.InDelta(t, 1647.009498, response.Amount, 0.0001)
+	assert.InDelta(t, 647.009498, response.Interest, 0.0001)
+
+	c, w = setupTestContext("POST", "/finance/compound-interest", map[string]any{
+		"principal": 1000, "rate": 0.05, "years": 10, "compounds_per_year": 0,
+	})
+	s.CompoundInterest(c)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+
+	c, w = setupTestContext("POST", "/finance/compound-interest", map[string]any{
+		"principal": 1000, "rate": 0.05, "years": 1, "compounds_per_year": 1000000,
+	})
+	s.CompoundInterest(c)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+	assert.Contains(t, w.Body.String(), `"max":8760`)
+
+	c, w = setupTestContext("POST", "/finance/future-value", map[string]any{
+		"rate": 1, "periods": 1030, "present_value": -1,
+	})
+	s.FutureValue(c)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeDomainError)
+}
+
+// TestAmortization tests the amortization schedule handler
+func TestAmortization(t *testing.T) {
+	s := &Service{}
+
+	c, w := setupTestContext("POST", "/finance/amortization", map[string]any{
+		"principal": 10000, "rate": 0.01, "periods": 12,
+	})
+	s.Amortization(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response AmortizationResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.InDelta(t, 888.487887, response.Payment, 0.0001)
+	assert.Len(t, response.Schedule, 12)
+	assert.InDelta(t, 100, response.Schedule[0].Interest, 0.0001)
+	assert.InDelta(t, 788.487887, response.Schedule[0].Principal, 0.0001)
+	assert.Equal(t, 0.0, response.Schedule[11].Balance)
+	assert.InDelta(t, 661.854641, response.TotalInterest, 0.0001)
+}
+
+// TestPercentChange tests both PercentChangeGET and PercentChange handlers
+func TestPercentChange(t *testing.T) {
+	s := &Service{}
+
+	c, w := setupTestContext("GET", "/percent-change?a=80&b=100", nil)
+	s.PercentChangeGET(c)
+	assertResponse(t, w, http.StatusOK, 25, "")
+
+	c, w = setupTestContext("POST", "/percent-change", map[string]float64{"a": 0, "b": 5})
+	s.PercentChange(c)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeDivisionByZero)
+
+	c, w = setupTestContext("GET", "/markup?a=80&b=25", nil)
+	s.MarkupGET(c)
+	assertResponse(t, w, http.StatusOK, 100, "")
+
+	c, w = setupTestContext("POST", "/discount", map[string]float64{"a": 100, "b": 15})
+	s.Discount(c)
+	assertResponse(t, w, http.StatusOK, 85, "")
+}
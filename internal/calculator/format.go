@@ -0,0 +1,325 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// maxFormatDigits bounds the scale and significant digits a request may ask for
+	maxFormatDigits = 30
+	// allDigits is the last digit position of a value that was not rounded, whose trailing zeros are dropped
+	allDigits = math.MaxInt
+)
+
+// Rounding modes
+const (
+	RoundHalfUp   = "half-up"
+	RoundHalfEven = "half-even"
+	RoundDown     = "down"
+	RoundCeiling  = "ceiling"
+	RoundFloor    = "floor"
+)
+
+// Output notations
+const (
+	NotationPlain       = "plain"
+	NotationScientific  = "scientific"
+	NotationEngineering = "engineering"
+)
+
+// numberLocale describes how a locale writes numbers. Grouping lists the group sizes from the decimal
+// separator leftwards; the last size repeats.
+type numberLocale struct {
+	Group    string
+	Decimal  string
+	Grouping []int
+}
+
+// numberLocales lists the supported locales by language tag; a tag not listed falls back to its language
+var numberLocales = map[string]numberLocale{
+	"en":    {",", ".", []int{3}},
+	"en-IN": {",", ".", []int{3, 2}},
+	"hi":    {",", ".", []int{3, 2}},
+	"de":    {".", ",", []int{3}},
+	"de-CH": {"'", ".", []int{3}},
+	"es":    {".", ",", []int{3}},
+	"it":    {".", ",", []int{3}},
+	"nl":    {".", ",", []int{3}},
+	"pt":    {".", ",", []int{3}},
+	"fr":    {"\u202f", ",", []int{3}},
+	"ru":    {"\u00a0", ",", []int{3}},
+	"pl":    {"\u00a0", ",", []int{3}},
+	"sv":    {"\u00a0", ",", []int{3}},
+	"ja":    {",", ".", []int{3}},
+	"zh":    {",", ".", []int{3}},
+}
+
+// FormatOptions controls how a result is rounded and written. At most one of Scale (digits after the
+// decimal point, negative to round to tens, hundreds, ...) and Significant may be set.
+type FormatOptions struct {
+	Scale       *int
+	Significant *int
+	Rounding    string
+	Notation    string
+	Locale      string
+}
+
+// lookupLocale returns the number format of tag, trying the full tag and then its language
+func lookupLocale(tag string) (numberLocale, bool) {
+	tag = strings.ReplaceAll(tag, "_", "-")
+	if locale, ok := numberLocales[tag]; ok {
+		return locale, true
+	}
+	for key, locale := range numberLocales {
+		if strings.EqualFold(key, tag) {
+			return locale, true
+		}
+	}
+	language, _, _ := strings.Cut(tag, "-")
+	locale, ok := numberLocales[strings.ToLower(language)]
+	return locale, ok
+}
+
+// formatOptionsFromQuery reads the scale, significant, rounding, notation and locale query parameters,
+// returning nil when none is present
+func formatOptionsFromQuery(c *gin.Context) (*FormatOptions, error) {
+	query := c.Request.URL.Query()
+	present := false
+	for _, param := range []string{"scale", "significant", "rounding", "notation", "locale"} {
+		present = present || query.Has(param)
+	}
+	if !present {
+		return nil, nil
+	}
+
+	opts := &FormatOptions{
+		Rounding: c.DefaultQuery("rounding", RoundHalfUp),
+		Notation: c.DefaultQuery("notation", NotationPlain),
+		Locale:   c.Query("locale"),
+	}
+	digits := func(param string, min int) (*int, error) {
+		if !query.Has(param) {
+			return nil, nil
+		}
+		n, err := strconv.Atoi(query.Get(param))
+		if err != nil || n < min || n > maxFormatDigits {
+			return nil, newError(CodeInvalidInput, fmt.Sprintf("%s must be an integer between %d and %d", param, min, maxFormatDigits)).
+				WithDetail("param", param).
+				WithDetail("value", query.Get(param))
+		}
+		return &n, nil
+	}
+	var err error
+	if opts.Scale, err = digits("scale", -maxFormatDigits); err != nil {
+		return nil, err
+	}
+	if opts.Significant, err = digits("significant", 1); err != nil {
+		return nil, err
+	}
+	if opts.Scale != nil && opts.Significant != nil {
+		return nil, newError(CodeInvalidInput, "scale and significant cannot be combined")
+	}
+	switch opts.Rounding {
+	case RoundHalfUp, RoundHalfEven, RoundDown, RoundCeiling, RoundFloor:
+	default:
+		return nil, newError(CodeInvalidInput, fmt.Sprintf("unknown rounding mode '%s'", opts.Rounding)).
+			WithDetail("rounding", opts.Rounding).
+			WithDetail("available", []string{RoundHalfUp, RoundHalfEven, RoundDown, RoundCeiling, RoundFloor})
+	}
+	if query.Has("rounding") && opts.Scale == nil && opts.Significant == nil {
+		return nil, newError(CodeInvalidInput, "rounding requires scale or significant")
+	}
+	switch opts.Notation {
+	case NotationPlain, NotationScientific, NotationEngineering:
+	default:
+		return nil, newError(CodeInvalidInput, fmt.Sprintf("unknown notation '%s'", opts.Notation)).
+			WithDetail("notation", opts.Notation).
+			WithDetail("available", []string{NotationPlain, NotationScientific, NotationEngineering})
+	}
+	if opts.Locale != "" {
+		if _, ok := lookupLocale(opts.Locale); !ok {
+			return nil, newError(CodeInvalidInput, fmt.Sprintf("unsupported locale '%s'", opts.Locale)).WithDetail("locale", opts.Locale)
+		}
+	}
+	return opts, nil
+}
+
+// leadingExponent returns the power of ten of the most significant digit of a nonzero d
+func leadingExponent(d decimal.Decimal) int {
+	return int(d.NumDigits()) + int(d.Exponent()) - 1
+}
+
+// round rounds d to the given number of decimal places using the rounding mode
+func round(d decimal.Decimal, places int32, mode string) decimal.Decimal {
+	switch mode {
+	case RoundHalfEven:
+		return d.RoundBank(places)
+	case RoundDown:
+		return d.RoundDown(places)
+	case RoundCeiling:
+		return d.RoundCeil(places)
+	case RoundFloor:
+		return d.RoundFloor(places)
+	default:
+		return d.Round(places)
+	}
+}
+
+// Round rounds value to the requested scale or significant digits. It returns the rounded value and the
+// power of ten of its last kept digit, which is allDigits when no rounding was requested.
+func (o FormatOptions) Round(value float64) (decimal.Decimal, int) {
+	d := decimal.NewFromFloat(value)
+	switch {
+	case o.Scale != nil:
+		return round(d, int32(*o.Scale), o.Rounding), -*o.Scale
+	case o.Significant != nil && !d.IsZero():
+		places := *o.Significant - 1 - leadingExponent(d)
+		d = round(d, int32(places), o.Rounding)
+		// Rounding up may add a digit (9.99 -> 10.0); keep the requested number of digits
+		if !d.IsZero() && leadingExponent(d) > *o.Significant-1-places {
+			places--
+			d = round(d, int32(places), o.Rounding)
+		}
+		return d, -places
+	case o.Significant != nil:
+		return d, 1 - *o.Significant
+	}
+	return d, allDigits
+}
+
+// decimalDigits returns the decimal digits of |d| and the power of ten of the last one, padded with zeros down to
+// last, or trimmed of trailing zeros for allDigits
+func decimalDigits(d decimal.Decimal, last int) (string, int) {
+	coefficient := d.Coefficient()
+	str := strings.TrimPrefix(coefficient.String(), "-")
+	exp := int(d.Exponent())
+	if last == allDigits {
+		if str == "0" {
+			return str, 0
+		}
+		trimmed := strings.TrimRight(str, "0")
+		return trimmed, exp + len(str) - len(trimmed)
+	}
+	if exp > last {
+		str += strings.Repeat("0", exp-last)
+		exp = last
+	}
+	return str, exp
+}
+
+// group inserts separators into the integer digits following the locale's grouping sizes
+func group(integer string, locale numberLocale) string {
+	if locale.Group == "" || len(locale.Grouping) == 0 {
+		return integer
+	}
+	var parts []string
+	for i := 0; len(integer) > 0; i++ {
+		size := locale.Grouping[min(i, len(locale.Grouping)-1)]
+		if len(integer) <= size {
+			parts = append(parts, integer)
+			break
+		}
+		parts = append(parts, integer[len(integer)-size:])
+		integer = integer[:len(integer)-size]
+	}
+	var sb strings.Builder
+	for i := len(parts) - 1; i >= 0; i-- {
+		sb.WriteString(parts[i])
+		if i > 0 {
+			sb.WriteString(locale.Group)
+		}
+	}
+	return sb.String()
+}
+
+// Format writes d, whose last kept digit has the given power of ten (or allDigits), in the
+// requested notation and locale
+func (o FormatOptions) Format(d decimal.Decimal, last int) string {
+	locale := numberLocale{Decimal: "."}
+	if o.Locale != "" {
+		locale, _ = lookupLocale(o.Locale)
+	}
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
+	}
+	str, exp := decimalDigits(d, last)
+
+	// Split the digits into a mantissa and a power of ten
+	power := 0
+	if o.Notation != NotationPlain {
+		leading := 0
+		if strings.TrimLeft(str, "0") != "" {
+			leading = len(str) - 1 + exp
+		}
+		power = leading
+		if o.Notation == NotationEngineering {
+			power = leading - ((leading%3)+3)%3
+		}
+		exp -= power
+	}
+
+	// Place the decimal point: the mantissa is str * 10^exp
+	var integer, fraction string
+	switch {
+	case exp >= 0:
+		integer = str + strings.Repeat("0", exp)
+	case -exp >= len(str):
+		integer, fraction = "0", strings.Repeat("0", -exp-len(str))+str
+	default:
+		integer, fraction = str[:len(str)+exp], str[len(str)+exp:]
+	}
+	integer = strings.TrimLeft(integer, "0")
+	if integer == "" {
+		integer = "0"
+	}
+
+	result := sign + group(integer, locale)
+	if fraction != "" {
+		result += locale.Decimal + fraction
+	}
+	if o.Notation != NotationPlain {
+		result += "e" + strconv.Itoa(power)
+	}
+	return result
+}
+
+// formatResponse rounds and formats response.Result as the request's format query parameters ask
+func (s *Service) formatResponse(c *gin.Context, response Response) (Response, error) {
+	opts, err := formatOptionsFromQuery(c)
+	if err != nil || opts == nil || math.IsNaN(response.Result) || math.IsInf(response.Result, 0) {
+		return response, err
+	}
+	rounded, last := opts.Round(response.Result)
+	response.Result = rounded.InexactFloat64()
+	response.Formatted = opts.Format(rounded, last)
+	s.logger().Debug("Formatted result", "result", response.Result, "formatted", response.Formatted)
+	return response, nil
+}
+
+// respondResult records result as the caller's ANS and writes it, rounded and formatted on request,
+// reporting whether it was written. ANS keeps the unrounded value. A result that overflowed or is
+// undefined is a DOMAIN_ERROR and, like invalid format options, leaves ANS unchanged.
+func (s *Service) respondResult(c *gin.Context, response Response) bool {
+	if err := checkFinite(response.Result); err != nil {
+		s.logger().Error("Result is not finite", "operation", c.Request.URL.Path, "method", c.Request.Method, "result", response.Result)
+		s.respondError(c, http.StatusBadRequest, err)
+		return false
+	}
+	formatted, err := s.formatResponse(c, response)
+	if err != nil {
+		s.logger().Error("Invalid format options", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return false
+	}
+	s.recordAnswer(c, response.Result)
+	s.respondJSON(c, formatted)
+	return true
+}
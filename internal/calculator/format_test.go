@@ -0,0 +1,137 @@
+package calculator
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(n int) *int {
+	return &n
+}
+
+// TestFormatOptions tests rounding modes, notations and locales
+func TestFormatOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     float64
+		opts      FormatOptions
+		rounded   float64
+		formatted string
+	}{
+		{"plain", 1234.5, FormatOptions{Notation: NotationPlain}, 1234.5, "1234.5"},
+		{"scale keeps zeros", 2.5, FormatOptions{Scale: intPtr(2), Rounding: RoundHalfUp, Notation: NotationPlain}, 2.5, "2.50"},
+		{"half-up", 2.345, FormatOptions{Scale: intPtr(2), Rounding: RoundHalfUp, Notation: NotationPlain}, 2.35, "2.35"},
+		{"half-up negative", -2.345, FormatOptions{Scale: intPtr(2), Rounding: RoundHalfUp, Notation: NotationPlain}, -2.35, "-2.35"},
+		{"half-even", 2.345, FormatOptions{Scale: intPtr(2), Rounding: RoundHalfEven, Notation: NotationPlain}, 2.34, "2.34"},
+		{"down", -2.349, FormatOptions{Scale: intPtr(2), Rounding: RoundDown, Notation: NotationPlain}, -2.34, "-2.34"},
+		{"ceiling", -2.349, FormatOptions{Scale: intPtr(2), Rounding: RoundCeiling, Notation: NotationPlain}, -2.34, "-2.34"},
+		{"floor", -2.341, FormatOptions{Scale: intPtr(2), Rounding: RoundFloor, Notation: NotationPlain}, -2.35, "-2.35"},
+		{"negative scale", 1264, FormatOptions{Scale: intPtr(-2), Rounding: RoundHalfUp, Notation: NotationPlain}, 1300, "1300"},
+		{"significant", 0.00123456, FormatOptions{Significant: intPtr(3), Rounding: RoundHalfUp, Notation: NotationPlain}, 0.00123, "0.00123"},
+		{"significant pads", 1.5, FormatOptions{Significant: intPtr(3), Rounding: RoundHalfUp, Notation: NotationPlain}, 1.5, "1.50"},
+		{"significant carry", 9.996, FormatOptions{Significant: intPtr(3), Rounding: RoundHalfUp, Notation: NotationPlain}, 10, "10.0"},
+		{"significant zero", 0, FormatOptions{Significant: intPtr(3), Rounding: RoundHalfUp, Notation: NotationPlain}, 0, "0.00"},
+		{"scientific", 123456, FormatOptions{Notation: NotationScientific}, 123456, "1.23456e5"},
+		{"scientific significant", 123456, FormatOptions{Significant: intPtr(3), Rounding: RoundHalfUp, Notation: NotationScientific}, 123000, "1.23e5"},
+		{"scientific small", -0.000042, FormatOptions{Notation: NotationScientific}, -0.000042, "-4.2e-5"},
+		{"scientific zero", 0, FormatOptions{Notation: NotationScientific}, 0, "0e0"},
+		{"engineering", 123456, FormatOptions{Notation: NotationEngineering}, 123456, "123.456e3"},
+		{"engineering small", 0.000042, FormatOptions{Notation: NotationEngineering}, 0.000042, "42e-6"},
+		{"engineering significant", 1234, FormatOptions{Significant: intPtr(2), Rounding: RoundHalfUp, Notation: NotationEngineering}, 1200, "1.2e3"},
+		{"locale en", 1234567.891, FormatOptions{Scale: intPtr(2), Rounding: RoundHalfUp, Notation: NotationPlain, Locale: "en-US"}, 1234567.89, "1,234,567.89"},
+		{"locale de", -1234567.891, FormatOptions{Scale: intPtr(2), Rounding: RoundHalfUp, Notation: NotationPlain, Locale: "de"}, -1234567.89, "-1.234.567,89"},
+		{"locale fr", 1234.5, FormatOptions{Notation: NotationPlain, Locale: "fr_FR"}, 1234.5, "1\u202f234,5"},
+		{"locale de-CH", 1234.5, FormatOptions{Notation: NotationPlain, Locale: "de-ch"}, 1234.5, "1'234.5"},
+		{"locale en-IN", 123456789, FormatOptions{Notation: NotationPlain, Locale: "en-IN"}, 123456789, "12,34,56,789"},
+		{"locale short", 999, FormatOptions{Notation: NotationPlain, Locale: "en"}, 999, "999"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rounded, last := tt.opts.Round(tt.value)
+			assert.InDelta(t, tt.rounded, rounded.InexactFloat64(), 1e-12)
+			assert.Equal(t, tt.formatted, tt.opts.Format(rounded, last))
+		})
+	}
+}
+
+// TestFormattedResponse tests format query parameters on operation endpoints
+func TestFormattedResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		body      any
+		status    int
+		result    float64
+		formatted string
+		code      string
+	}{
+		{"no options", "/divide", map[string]any{"a": 2, "b": 3}, http.StatusOK, 2.0 / 3, "", ""},
+		{"scale", "/divide?scale=3", map[string]any{"a": 2, "b": 3}, http.StatusOK, 0.667, "0.667", ""},
+		{"rounding mode", "/divide?scale=3&rounding=down", map[string]any{"a": 2, "b": 3}, http.StatusOK, 0.666, "0.666", ""},
+		{"notation only", "/divide?notation=scientific", map[string]any{"a": 1, "b": 8}, http.StatusOK, 0.125, "1.25e-1", ""},
+		{"locale", "/divide?scale=2&locale=de-DE", map[string]any{"a": 10000, "b": 3}, http.StatusOK, 3333.33, "3.333,33", ""},
+		{"invalid scale", "/divide?scale=abc", map[string]any{"a": 1, "b": 3}, http.StatusBadRequest, 0, "", CodeInvalidInput},
+		{"scale out of range", "/divide?scale=31", map[string]any{"a": 1, "b": 3}, http.StatusBadRequest, 0, "", CodeInvalidInput},
+		{"scale and significant", "/divide?scale=2&significant=2", map[string]any{"a": 1, "b": 3}, http.StatusBadRequest, 0, "", CodeInvalidInput},
+		{"unknown rounding", "/divide?scale=2&rounding=up", map[string]any{"a": 1, "b": 3}, http.StatusBadRequest, 0, "", CodeInvalidInput},
+		{"rounding without digits", "/divide?rounding=floor", map[string]any{"a": 1, "b": 3}, http.StatusBadRequest, 0, "", CodeInvalidInput},
+		{"unknown notation", "/divide?notation=roman", map[string]any{"a": 1, "b": 3}, http.StatusBadRequest, 0, "", CodeInvalidInput},
+		{"unknown locale", "/divide?locale=xx", map[string]any{"a": 1, "b": 3}, http.StatusBadRequest, 0, "", CodeInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestContext("POST", tt.url, tt.body)
+			(&Service{}).Divide(c)
+			if tt.code != "" {
+				assertErrorCode(t, w, tt.status, tt.code)
+				return
+			}
+			require.Equal(t, tt.status, w.Code)
+			var response Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.InDelta(t, tt.result, response.Result, 1e-12)
+			assert.Equal(t, tt.formatted, response.Formatted)
+		})
+	}
+
+	t.Run("GET", func(t *testing.T) {
+		c, w := setupTestContext("GET", "/power?a=2&b=0.5&significant=4&notation=engineering", nil)
+		(&Service{}).PowerGET(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		var response Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1.414, response.Result)
+		assert.Equal(t, "1.414e0", response.Formatted)
+	})
+
+	t.Run("scalar linear algebra result", func(t *testing.T) {
+		c, w := setupTestContext("POST", "/vector/dot?scale=1", map[string]any{"a": []float64{0.25, 1}, "b": []float64{1, 0.2}})
+		(&Service{}).VectorDot(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		var response Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "0.5", response.Formatted)
+	})
+
+	t.Run("ANS keeps the unrounded value", func(t *testing.T) {
+		s := &Service{}
+		c, w := setupTestContext("POST", "/sessions", nil)
+		s.CreateSession(c)
+		id := decodeSession(t, w).ID
+
+		c, w = setupTestContext("POST", "/divide?scale=2", map[string]any{"a": 1, "b": 3})
+		c.Request.Header.Set(SessionHeader, id)
+		s.Divide(c)
+		assertResponse(t, w, http.StatusOK, 0.33, "")
+
+		c, w = setupTestContext("GET", "/sessions/"+id, nil)
+		s.GetSession(withParam(c, "id", id))
+		assert.Equal(t, 1.0/3, decodeSession(t, w).Ans)
+	})
+}
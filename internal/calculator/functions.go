@@ -0,0 +1,456 @@
+package calculator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"calculator/core"
+	"calculator/internal/expr"
+	"calculator/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxCallDepth limits how deeply user-defined functions may call one another
+	maxCallDepth = 16
+	// maxFunctionCalls limits the user-defined function calls made, or inlined, by one evaluation, so that
+	// bodies calling a function several times cannot multiply into exponentially many calls
+	maxFunctionCalls = 10000
+	// maxInlinedNodes limits the size of the function bodies inlined into one expression, which grow
+	// exponentially when nested calls repeat their parameters
+	maxInlinedNodes = 100000
+)
+
+// FunctionRequest represents a function definition such as "hyp(a, b) = sqrt(a^2 + b^2)"
+type FunctionRequest struct {
+	Definition string `json:"definition" binding:"required"`
+}
+
+// FunctionsResponse represents the list of functions for the caller
+type FunctionsResponse struct {
+	Functions []storage.Function `json:"functions"`
+}
+
+// FunctionCallRequest represents the arguments of a function call
+type FunctionCallRequest struct {
+	Args []Operand `json:"args"`
+}
+
+// functionNotFound returns the error reported for unknown function names
+func functionNotFound(name string) *Error {
+	return newError(CodeNotFound, fmt.Sprintf("function '%s' not found", name)).WithDetail("name", name)
+}
+
+// functionSet is a snapshot of an owner's functions keyed by name. Calls evaluate against the
+// snapshot so they never touch the store, which allows them inside store update callbacks.
+type functionSet map[string]storage.Function
+
+// functions returns a snapshot of the owner's functions
+func (s *Service) functions(owner string) functionSet {
+	set := make(functionSet)
+	for _, fn := range s.store().Functions(owner) {
+		set[fn.Name] = fn
+	}
+	return set
+}
+
+// functionBody returns the parsed body of fn, parsing Body only for functions stored without their Node
+func functionBody(fn storage.Function) (expr.Node, error) {
+	if fn.Node != nil {
+		return fn.Node, nil
+	}
+	return expr.Parse(fn.Body)
+}
+
+// callBudget counts the user-defined function calls and inlined nodes left to one evaluation
+type callBudget struct {
+	calls int
+	nodes int
+}
+
+// newCallBudget returns the budget of a new evaluation
+func newCallBudget() *callBudget {
+	return &callBudget{calls: maxFunctionCalls, nodes: maxInlinedNodes}
+}
+
+// spend takes one call from the budget, failing once it is exhausted
+func (b *callBudget) spend() error {
+	if b.calls == 0 {
+		return newError(CodeRecursionLimit, fmt.Sprintf("evaluation makes more than %d function calls", maxFunctionCalls)).
+			WithDetail("max_calls", maxFunctionCalls)
+	}
+	b.calls--
+	return nil
+}
+
+// spendNodes takes the nodes of n from the budget, failing once it is exhausted
+func (b *callBudget) spendNodes(n expr.Node) error {
+	if !b.take(n) {
+		return newError(CodeRecursionLimit, fmt.Sprintf("inlined functions expand to more than %d nodes", maxInlinedNodes)).
+			WithDetail("max_nodes", maxInlinedNodes)
+	}
+	return nil
+}
+
+// take counts the nodes of n against the budget, stopping as soon as it is exhausted
+func (b *callBudget) take(n expr.Node) bool {
+	if b.nodes == 0 {
+		return false
+	}
+	b.nodes--
+	switch n := n.(type) {
+	case *expr.Unary:
+		return b.take(n.X)
+	case *expr.Binary:
+		return b.take(n.L) && b.take(n.R)
+	case *expr.Call:
+		for _, arg := range n.Args {
+			if !b.take(arg) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// functionEnv evaluates function bodies: identifiers resolve to parameters or constants
+// and calls resolve to the owner's functions
+type functionEnv struct {
+	functions functionSet
+	params    map[string]float64
+	depth     int
+	budget    *callBudget
+}
+
+// Lookup returns the value of a parameter or constant
+func (env *functionEnv) Lookup(name string) (float64, bool) {
+	if value, ok := env.params[name]; ok {
+		return value, true
+	}
+	if constant, ok := core.LookupConstant(name); ok {
+		return constant.Value, true
+	}
+	return 0, false
+}
+
+// Call invokes one of the owner's functions
+func (env *functionEnv) Call(name string, args []float64) (float64, error) {
+	fn, ok := env.functions[name]
+	if !ok {
+		return 0, &expr.Error{Kind: expr.ErrUndefined, Pos: -1, Msg: fmt.Sprintf("undefined function '%s'", name)}
+	}
+	return env.functions.call(fn, args, env.depth+1, env.budget)
+}
+
+// call evaluates fn with args at the given call depth, spending one call of budget
+func (set functionSet) call(fn storage.Function, args []float64, depth int, budget *callBudget) (float64, error) {
+	if err := budget.spend(); err != nil {
+		return 0, err
+	}
+	if depth > maxCallDepth {
+		return 0, &expr.Error{Kind: expr.ErrRecursion, Pos: -1, Msg: fmt.Sprintf("maximum call depth of %d exceeded", maxCallDepth)}
+	}
+	if len(args) != len(fn.Params) {
+		return 0, &expr.Error{Kind: expr.ErrArity, Pos: -1, Msg: fmt.Sprintf("%s expects %d argument(s), got %d", fn.Name, len(fn.Params), len(args))}
+	}
+	body, err := functionBody(fn)
+	if err != nil {
+		return 0, err
+	}
+	params := make(map[string]float64, len(args))
+	for i, param := range fn.Params {
+		params[param] = args[i]
+	}
+	return expr.Eval(body, &functionEnv{functions: set, params: params, depth: depth, budget: budget})
+}
+
+// applyFunction calls the named function of set, reporting errors with calculator codes
+func (s *Service) applyFunction(set functionSet, name string, args []float64) (float64, error) {
+	s.logger().Debug("Performing function call", "name", name, "args", args)
+	result, err := set.call(set[name], args, 1, newCallBudget())
+	if err != nil {
+		s.logger().Error("Function call failed", "name", name, "error", err)
+		return 0, core.ExpressionError(err)
+	}
+	s.logger().Debug("Function call result", "name", name, "result", result)
+	return result, nil
+}
+
+// functionOperations returns the owner's named function as a binary or unary operation.
+// Both are nil unless the function exists and takes one or two parameters.
+func (s *Service) functionOperations(owner, name string) (core.BinaryFunc, core.UnaryFunc) {
+	if owner == "" {
+		return nil, nil
+	}
+	set := s.functions(owner)
+	fn, ok := set[name]
+	if !ok {
+		return nil, nil
+	}
+	switch len(fn.Params) {
+	case 1:
+		return nil, func(_ context.Context, a float64) (float64, error) { return s.applyFunction(set, name, []float64{a}) }
+	case 2:
+		return func(_ context.Context, a, b float64) (float64, error) {
+			return s.applyFunction(set, name, []float64{a, b})
+		}, nil
+	}
+	return nil, nil
+}
+
+// callDepth returns the depth of the deepest call chain made by body.
+// defined overrides the stored function of the same name, so redefinitions are checked as a whole.
+func (s *Service) callDepth(owner string, defined *expr.Definition, body expr.Node, visiting map[string]bool) (int, error) {
+	depth := 1
+	for _, call := range expr.Calls(body) {
+		if _, ok := expr.LookupBuiltin(call.Name); ok {
+			continue
+		}
+		if visiting[call.Name] {
+			return 0, newError(CodeRecursionLimit, fmt.Sprintf("function '%s' is recursive", defined.Name)).
+				WithDetail("name", defined.Name).
+				WithDetail("via", call.Name)
+		}
+		var callee expr.Node
+		if call.Name == defined.Name {
+			callee = defined.Body
+		} else {
+			fn, ok := s.store().Function(owner, call.Name)
+			if !ok {
+				// Calls to deleted functions fail at call time
+				continue
+			}
+			parsed, err := functionBody(fn)
+			if err != nil {
+				return 0, core.ExpressionError(err)
+			}
+			callee = parsed
+		}
+		visiting[call.Name] = true
+		d, err := s.callDepth(owner, defined, callee, visiting)
+		delete(visiting, call.Name)
+		if err != nil {
+			return 0, err
+		}
+		depth = max(depth, d+1)
+	}
+	return depth, nil
+}
+
+// validateFunction checks a definition: its name and parameters, that every identifier is a parameter
+// or constant, that every call targets a builtin or existing function with matching arity, and that
+// the definition is neither recursive nor nested deeper than maxCallDepth
+func (s *Service) validateFunction(owner string, def *expr.Definition) error {
+	if !identifierPattern.MatchString(def.Name) {
+		return newError(CodeInvalidInput, fmt.Sprintf("invalid function name '%s'", def.Name)).WithDetail("name", def.Name)
+	}
+	if _, ok := expr.LookupBuiltin(def.Name); ok {
+		return newError(CodeInvalidInput, fmt.Sprintf("cannot redefine built-in function '%s'", def.Name)).WithDetail("name", def.Name)
+	}
+	if _, ok := core.LookupConstant(def.Name); ok {
+		return newError(CodeInvalidInput, fmt.Sprintf("cannot redefine constant '%s'", def.Name)).WithDetail("name", def.Name)
+	}
+	if core.IsOperation(def.Name) {
+		return newError(CodeInvalidInput, fmt.Sprintf("cannot redefine operation '%s'", def.Name)).WithDetail("name", def.Name)
+	}
+
+	params := make(map[string]bool, len(def.Params))
+	for _, param := range def.Params {
+		if err := core.ValidateVariableName(param); err != nil {
+			return err
+		}
+		params[param] = true
+	}
+	for _, name := range expr.Identifiers(def.Body) {
+		if _, ok := core.LookupConstant(name); !params[name] && !ok {
+			return newError(CodeUndefinedVariable, fmt.Sprintf("unknown identifier '%s'", name)).
+				WithDetail("name", name).
+				WithDetail("params", def.Params)
+		}
+	}
+	for _, call := range expr.Calls(def.Body) {
+		arity := len(def.Params)
+		if b, ok := expr.LookupBuiltin(call.Name); ok {
+			arity = b.Arity
+		} else if fn, ok := s.store().Function(owner, call.Name); ok {
+			arity = len(fn.Params)
+		} else if call.Name != def.Name {
+			return newError(CodeUndefinedVariable, fmt.Sprintf("unknown function '%s'", call.Name)).WithDetail("name", call.Name)
+		}
+		if len(call.Args) != arity {
+			return newError(CodeInvalidInput, fmt.Sprintf("%s expects %d argument(s), got %d", call.Name, arity, len(call.Args))).
+				WithDetail("name", call.Name).
+				WithDetail("expected", arity).
+				WithDetail("actual", len(call.Args))
+		}
+	}
+
+	depth, err := s.callDepth(owner, def, def.Body, map[string]bool{def.Name: true})
+	if err != nil {
+		return err
+	}
+	if depth > maxCallDepth {
+		return newError(CodeRecursionLimit, fmt.Sprintf("function '%s' nests %d calls, more than the maximum of %d", def.Name, depth, maxCallDepth)).
+			WithDetail("name", def.Name).
+			WithDetail("depth", depth).
+			WithDetail("max_depth", maxCallDepth)
+	}
+	return nil
+}
+
+// ListFunctions handles listing the caller's functions
+func (s *Service) ListFunctions(c *gin.Context) {
+	owner, ok := s.requireOwner(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, FunctionsResponse{Functions: s.store().Functions(owner)})
+}
+
+// GetFunction handles reading one of the caller's functions
+func (s *Service) GetFunction(c *gin.Context) {
+	owner, ok := s.requireOwner(c)
+	if !ok {
+		return
+	}
+	name := c.Param("name")
+	fn, found := s.store().Function(owner, name)
+	if !found {
+		s.logger().Error("Function not found", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", name)
+		s.respondError(c, http.StatusNotFound, functionNotFound(name))
+		return
+	}
+	c.JSON(http.StatusOK, fn)
+}
+
+// CreateFunction handles defining a new function, failing if it already exists
+func (s *Service) CreateFunction(c *gin.Context) {
+	s.handleSetFunction(c, "", false)
+}
+
+// PutFunction handles defining or replacing the function named in the path
+func (s *Service) PutFunction(c *gin.Context) {
+	s.handleSetFunction(c, c.Param("name"), true)
+}
+
+// DeleteFunction handles deleting one of the caller's functions
+func (s *Service) DeleteFunction(c *gin.Context) {
+	owner, ok := s.requireOwner(c)
+	if !ok {
+		return
+	}
+	name := c.Param("name")
+	if !s.store().DeleteFunction(owner, name) {
+		s.logger().Error("Function not found", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", name)
+		s.respondError(c, http.StatusNotFound, functionNotFound(name))
+		return
+	}
+	s.logger().Info("Function deleted", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", name)
+	c.Status(http.StatusNoContent)
+}
+
+// CallFunction handles calling one of the caller's functions
+func (s *Service) CallFunction(c *gin.Context) {
+	owner, ok := s.requireOwner(c)
+	if !ok {
+		return
+	}
+	name := c.Param("name")
+	var req FunctionCallRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind function call JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	set := s.functions(owner)
+	if _, found := set[name]; !found {
+		s.logger().Error("Function not found", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", name)
+		s.respondError(c, http.StatusNotFound, functionNotFound(name))
+		return
+	}
+
+	sc := scopeFromContext(c)
+	args := make([]float64, len(req.Args))
+	for i, arg := range req.Args {
+		value, err := s.resolveOperand(sc, fmt.Sprintf("args[%d]", i), arg)
+		if err != nil {
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		args[i] = value
+	}
+
+	s.logger().Info("Processing function call request", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", name, "args", args)
+
+	result, err := s.applyFunction(set, name, args)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Function call successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", name, "result", result)
+	s.respondResult(c, Response{Result: result})
+}
+
+// handleSetFunction handles the common logic for creating and replacing functions.
+// A non-empty name must match the name in the definition.
+func (s *Service) handleSetFunction(c *gin.Context, name string, replace bool) {
+	owner, ok := s.requireOwner(c)
+	if !ok {
+		return
+	}
+	var req FunctionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind function JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	def, err := expr.ParseDefinition(req.Definition)
+	if err != nil {
+		s.logger().Error("Invalid function definition", "operation", c.Request.URL.Path, "method", c.Request.Method, "definition", req.Definition, "error", err)
+		s.respondError(c, http.StatusBadRequest, core.ExpressionError(err))
+		return
+	}
+	if name != "" && def.Name != name {
+		s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, fmt.Sprintf("definition names '%s' but path names '%s'", def.Name, name)).WithDetail("name", name))
+		return
+	}
+	if err := s.validateFunction(owner, def); err != nil {
+		s.logger().Error("Invalid function definition", "operation", c.Request.URL.Path, "method", c.Request.Method, "definition", req.Definition, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	existing, exists := s.store().Function(owner, def.Name)
+	if exists && !replace {
+		s.logger().Error("Function already exists", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", def.Name)
+		s.respondError(c, http.StatusConflict, newError(CodeConflict, fmt.Sprintf("function '%s' already exists", def.Name)).WithDetail("name", def.Name))
+		return
+	}
+
+	now := time.Now()
+	fn := storage.Function{
+		Name:       def.Name,
+		Params:     def.Params,
+		Body:       def.Body.String(),
+		Definition: fmt.Sprintf("%s(%s) = %s", def.Name, strings.Join(def.Params, ", "), def.Body),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Node:       def.Body,
+	}
+	if exists {
+		fn.CreatedAt = existing.CreatedAt
+	}
+	s.store().SetFunction(owner, fn)
+
+	s.logger().Info("Function stored", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", fn.Name, "definition", fn.Definition)
+	status := http.StatusOK
+	if !exists {
+		status = http.StatusCreated
+	}
+	c.JSON(status, fn)
+}
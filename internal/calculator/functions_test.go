@@ -0,0 +1,190 @@
+package calculator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"calculator/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// defineFunction posts a function definition for the given API key
+func defineFunction(s *Service, key, definition string) *httptest.ResponseRecorder {
+	c, w := setupTestContext("POST", "/functions", map[string]any{"definition": definition})
+	c.Request.Header.Set(APIKeyHeader, key)
+	s.CreateFunction(c)
+	return w
+}
+
+// callFunction calls a function for the given API key
+func callFunction(s *Service, key, name string, args ...any) *httptest.ResponseRecorder {
+	c, w := setupTestContext("POST", "/functions/"+name+"/call", map[string]any{"args": args})
+	c.Request.Header.Set(APIKeyHeader, key)
+	s.CallFunction(withParam(c, "name", name))
+	return w
+}
+
+// TestFunctionDefinitions tests defining, reading and deleting functions
+func TestFunctionDefinitions(t *testing.T) {
+	s := &Service{Store: storage.NewMemory()}
+
+	w := defineFunction(s, "alice", "hyp(a, b) = sqrt(a^2 + b^2)")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var fn storage.Function
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &fn))
+	assert.Equal(t, []string{"a", "b"}, fn.Params)
+	assert.Equal(t, "hyp(a, b) = sqrt(a^2 + b^2)", fn.Definition)
+
+	w = defineFunction(s, "alice", "hyp(x, y) = x + y")
+	assertErrorCode(t, w, http.StatusConflict, CodeConflict)
+
+	c, w := setupTestContext("PUT", "/functions/hyp", map[string]any{"definition": "hyp(x, y) = x + y"})
+	c.Request.Header.Set(APIKeyHeader, "alice")
+	s.PutFunction(withParam(c, "name", "hyp"))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	c, w = setupTestContext("GET", "/functions", nil)
+	c.Request.Header.Set(APIKeyHeader, "alice")
+	s.ListFunctions(c)
+	var list FunctionsResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list.Functions, 1)
+	assert.Equal(t, "x + y", list.Functions[0].Body)
+
+	// Functions are scoped to their owner
+	c, w = setupTestContext("GET", "/functions/hyp", nil)
+	c.Request.Header.Set(APIKeyHeader, "bob")
+	s.GetFunction(withParam(c, "name", "hyp"))
+	assertErrorCode(t, w, http.StatusNotFound, CodeNotFound)
+
+	c, _ = setupTestContext("DELETE", "/functions/hyp", nil)
+	c.Request.Header.Set(APIKeyHeader, "alice")
+	s.DeleteFunction(withParam(c, "name", "hyp"))
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+
+	c, w = setupTestContext("GET", "/functions", nil)
+	s.ListFunctions(c)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+}
+
+// TestFunctionValidation tests that invalid definitions are rejected when defined
+func TestFunctionValidation(t *testing.T) {
+	s := &Service{Store: storage.NewMemory()}
+	assert.Equal(t, http.StatusCreated, defineFunction(s, "alice", "sq(x) = x^2").Code)
+	assert.Equal(t, http.StatusCreated, defineFunction(s, "alice", "inc(x) = sq(x) + 1").Code)
+
+	tests := []struct {
+		name       string
+		definition string
+		code       string
+	}{
+		{"syntax error", "f(x) = x +", CodeInvalidInput},
+		{"unknown identifier", "f(x) = x + y", CodeUndefinedVariable},
+		{"unknown function", "f(x) = h(x)", CodeUndefinedVariable},
+		{"builtin arity", "f(x) = sqrt(x, 2)", CodeInvalidInput},
+		{"user function arity", "f(x) = sq(x, x)", CodeInvalidInput},
+		{"redefine builtin", "sin(x) = x", CodeInvalidInput},
+		{"redefine constant", "pi(x) = x", CodeInvalidInput},
+		{"redefine operation", "add(a, b) = a - b", CodeInvalidInput},
+		{"constant parameter", "f(e) = e", CodeInvalidInput},
+		{"direct recursion", "f(x) = f(x - 1)", CodeRecursionLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertErrorCode(t, defineFunction(s, "alice", tt.definition), http.StatusBadRequest, tt.code)
+		})
+	}
+
+	// Redefining sq to call inc would make sq and inc mutually recursive
+	c, w := setupTestContext("PUT", "/functions/sq", map[string]any{"definition": "sq(x) = inc(x)"})
+	c.Request.Header.Set(APIKeyHeader, "alice")
+	s.PutFunction(withParam(c, "name", "sq"))
+	assertErrorCode(t, w, http.StatusBadRequest, CodeRecursionLimit)
+
+	// Call chains deeper than maxCallDepth are rejected
+	assert.Equal(t, http.StatusCreated, defineFunction(s, "bob", "f0(x) = x + 1").Code)
+	for i := 1; i < maxCallDepth; i++ {
+		w := defineFunction(s, "bob", fmt.Sprintf("f%d(x) = f%d(x) + 1", i, i-1))
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+	w = defineFunction(s, "bob", fmt.Sprintf("f%d(x) = f%d(x) + 1", maxCallDepth, maxCallDepth-1))
+	assertErrorCode(t, w, http.StatusBadRequest, CodeRecursionLimit)
+	assertResponse(t, callFunction(s, "bob", fmt.Sprintf("f%d", maxCallDepth-1), 0), http.StatusOK, maxCallDepth, "")
+}
+
+// TestFunctionCalls tests calling functions directly and as session operations
+func TestFunctionCalls(t *testing.T) {
+	s := &Service{Store: storage.NewMemory()}
+	defineFunction(s, "alice", "hyp(a, b) = sqrt(a^2 + b^2)")
+	defineFunction(s, "alice", "recip(x) = 1 / x")
+	defineFunction(s, "alice", "area(r) = pi r^2")
+
+	assertResponse(t, callFunction(s, "alice", "hyp", 3, 4), http.StatusOK, 5, "")
+	assertResponse(t, callFunction(s, "alice", "area", "2"), http.StatusOK, 4*3.141592653589793, "")
+	assertErrorCode(t, callFunction(s, "alice", "recip", 0), http.StatusBadRequest, CodeDivisionByZero)
+	assertErrorCode(t, callFunction(s, "alice", "hyp", 3), http.StatusBadRequest, CodeInvalidInput)
+	assertErrorCode(t, callFunction(s, "alice", "missing", 1), http.StatusNotFound, CodeNotFound)
+	assertErrorCode(t, callFunction(s, "bob", "hyp", 3, 4), http.StatusNotFound, CodeNotFound)
+
+	// Functions of one or two parameters act as session operations for their owner
+	c, w := setupTestContext("POST", "/sessions", nil)
+	s.CreateSession(c)
+	var session storage.Session
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+
+	operate := func(operation string, body map[string]any) *httptest.ResponseRecorder {
+		c, w := setupTestContext("POST", "/sessions/"+session.ID+"/operations/"+operation, body)
+		c.Request.Header.Set(APIKeyHeader, "alice")
+		s.SessionOperation(withParam(withParam(c, "id", session.ID), "operation", operation))
+		return w
+	}
+	assertResponse(t, operate("hyp", map[string]any{"a": 6, "b": 8}), http.StatusOK, 10, "")
+	assertResponse(t, operate("recip", nil), http.StatusOK, 0.1, "")
+	assertErrorCode(t, operate("hyp", nil), http.StatusBadRequest, CodeInvalidInput)
+	assertErrorCode(t, operate("recip", map[string]any{"a": 0}), http.StatusBadRequest, CodeDivisionByZero)
+}
+
+// TestFunctionCallBudget tests that functions calling others several times cannot multiply into
+// exponentially many calls or inlined nodes
+func TestFunctionCallBudget(t *testing.T) {
+	s := &Service{Store: storage.NewMemory()}
+	assert.Equal(t, http.StatusCreated, defineFunction(s, "alice", "f0(x) = x + 1").Code)
+	for i := 1; i < maxCallDepth; i++ {
+		w := defineFunction(s, "alice", fmt.Sprintf("f%d(x) = f%d(x - 1) + f%d(x - 1)", i, i-1, i-1))
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	// f8 makes 2^9-1 calls, f15 makes 2^16-1
+	assertResponse(t, callFunction(s, "alice", "f8", 8), http.StatusOK, 256, "")
+	w := callFunction(s, "alice", fmt.Sprintf("f%d", maxCallDepth-1), 0)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeRecursionLimit)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`"max_calls":%d`, maxFunctionCalls))
+
+	eval := func(expression string) *httptest.ResponseRecorder {
+		c, w := setupTestContext("POST", "/eval", map[string]any{"expression": expression})
+		c.Request.Header.Set(APIKeyHeader, "alice")
+		s.Eval(c)
+		return w
+	}
+	assertResponse(t, eval("f8(8)"), http.StatusOK, 256, "")
+	assertErrorCode(t, eval(fmt.Sprintf("f%d(0)", maxCallDepth-1)), http.StatusBadRequest, CodeRecursionLimit)
+
+	// Repeating a parameter grows inlined bodies exponentially with few calls
+	assert.Equal(t, http.StatusCreated, defineFunction(s, "alice", "g0(x) = x").Code)
+	for i := 1; i < maxCallDepth; i++ {
+		w := defineFunction(s, "alice", fmt.Sprintf("g%d(x) = g%d(x + x + x + x)", i, i-1))
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+	w = eval(fmt.Sprintf("g%d(1)", maxCallDepth-1))
+	assertErrorCode(t, w, http.StatusBadRequest, CodeRecursionLimit)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`"max_nodes":%d`, maxInlinedNodes))
+
+	// Bodies are parsed when functions are defined
+	fn, ok := s.store().Function("key:alice", "f1")
+	assert.True(t, ok)
+	assert.Equal(t, "f0(x - 1) + f0(x - 1)", fn.Node.String())
+}
@@ -0,0 +1,376 @@
+package calculator
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"calculator/core"
+	"calculator/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// graphQLMaxDepth bounds the nesting of GraphQL queries
+const graphQLMaxDepth = 10
+
+// graphQLSchema exposes a mutation per built-in operation plus queries of the caller's history and sessions.
+// Operations record history and update the session's ans, so they are mutations: GraphQL runs the fields
+// of a mutation in order, where query fields resolve concurrently. Mutation also exposes history and
+// session, so a document can read back the results of the operations before them.
+// Operands are numbers or strings naming constants, $variables or ans, as in the JSON API.
+const graphQLSchema = `
+	schema {
+		query: Query
+		mutation: Mutation
+	}
+
+	scalar Operand
+	scalar Time
+
+	type Query {
+		operations: [Operation!]!
+		history(limit: Int): [HistoryEntry!]!
+		session(id: ID!): Session
+	}
+
+	type Mutation {
+		add(a: Operand!, b: Operand!): Float
+		subtract(a: Operand!, b: Operand!): Float
+		multiply(a: Operand!, b: Operand!): Float
+		divide(a: Operand!, b: Operand!): Float
+		percentage(a: Operand!, b: Operand!): Float
+		power(a: Operand!, b: Operand!): Float
+		sqrt(a: Operand!, b: Operand): Float
+		root(a: Operand!, b: Operand!): Float
+		percentChange(a: Operand!, b: Operand!): Float
+		markup(a: Operand!, b: Operand!): Float
+		discount(a: Operand!, b: Operand!): Float
+		inverse(a: Operand!): Float
+		negative(a: Operand!): Float
+		history(limit: Int): [HistoryEntry!]!
+		session(id: ID!): Session
+	}
+
+	type Operation {
+		name: String!
+		unary: Boolean!
+	}
+
+	type HistoryEntry {
+		operation: String!
+		operands: [Float!]!
+		result: Float!
+		unit: String
+		createdAt: Time!
+	}
+
+	type Register {
+		name: String!
+		value: Float!
+	}
+
+	type Session {
+		id: ID!
+		accumulator: Float!
+		ans: Float!
+		ansExact: String
+		memory: [Register!]!
+		createdAt: Time!
+		updatedAt: Time!
+	}
+`
+
+// GraphQLRequest represents a GraphQL query posted to /graphql
+type GraphQLRequest struct {
+	Query         string         `json:"query" binding:"required"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// graphQLScopeKey is the context key of the caller's scope during a GraphQL query
+type graphQLScopeKey struct{}
+
+// graphQLClientKey is the context key of the client named in the events feed during a GraphQL query
+type graphQLClientKey struct{}
+
+// graphQLOperand is an operand argument: a number or a string naming a constant, variable or ans
+type graphQLOperand struct {
+	Operand
+}
+
+// ImplementsGraphQLType maps graphQLOperand to the Operand scalar
+func (graphQLOperand) ImplementsGraphQLType(name string) bool {
+	return name == "Operand"
+}
+
+// UnmarshalGraphQL reads an operand from a literal or variable
+func (o *graphQLOperand) UnmarshalGraphQL(input any) error {
+	switch v := input.(type) {
+	case float64:
+		o.Value = v
+	case int32:
+		o.Value = float64(v)
+	case int:
+		o.Value = float64(v)
+	case string:
+		o.Ref = v
+	default:
+		return fmt.Errorf("operand must be a number or string, got %T", input)
+	}
+	return nil
+}
+
+// graphQLResolver resolves the root Query and Mutation types
+type graphQLResolver struct {
+	s *Service
+}
+
+// binaryArgs are the arguments of binary operation mutations
+type binaryArgs struct {
+	A graphQLOperand
+	B graphQLOperand
+}
+
+// unaryArgs are the arguments of unary operation mutations
+type unaryArgs struct {
+	A graphQLOperand
+}
+
+// graphQLSchemaFor returns the parsed GraphQL schema, built on first use
+func (s *Service) graphQLSchemaFor() *graphql.Schema {
+	s.schemaOnce.Do(func() {
+		s.schema = graphql.MustParseSchema(graphQLSchema, &graphQLResolver{s: s},
+			graphql.MaxDepth(graphQLMaxDepth))
+	})
+	return s.schema
+}
+
+// scope returns the caller's scope stored in ctx
+func (r *graphQLResolver) scope(ctx context.Context) scope {
+	sc, _ := ctx.Value(graphQLScopeKey{}).(scope)
+	return sc
+}
+
+// operation applies the named operation, returning a null result and a coded error on failure. Each
+// operation is admitted for the caller's tenant besides the request carrying it.
+func (r *graphQLResolver) operation(ctx context.Context, name string, a, b *graphQLOperand) (*float64, error) {
+	if err := r.s.admitMessage(r.scope(ctx).tenant); err != nil {
+		return nil, err
+	}
+	var aOperand, bOperand *Operand
+	if a != nil {
+		aOperand = &a.Operand
+	}
+	if b != nil {
+		bOperand = &b.Operand
+	}
+	start := time.Now()
+	result, err := r.s.evaluate(ctx, r.scope(ctx), name, aOperand, bOperand)
+	client, _ := ctx.Value(graphQLClientKey{}).(string)
+	r.s.publishEvaluation(TransportGraphQL, r.scope(ctx).tenant, client, name, aOperand, bOperand, start, result, err)
+	if err != nil {
+		return nil, toError(err)
+	}
+	return &result, nil
+}
+
+// Operations lists the built-in operations
+func (r *graphQLResolver) Operations() []operationResolver {
+	names := core.OperationNames()
+	operations := make([]operationResolver, 0, len(names))
+	for _, name := range names {
+		_, isUnary := core.UnaryOperation(name)
+		operations = append(operations, operationResolver{name: name, unary: isUnary})
+	}
+	return operations
+}
+
+// Add resolves the add mutation
+func (r *graphQLResolver) Add(ctx context.Context, args binaryArgs) (*float64, error) {
+	return r.operation(ctx, "add", &args.A, &args.B)
+}
+
+// Subtract resolves the subtract mutation
+func (r *graphQLResolver) Subtract(ctx context.Context, args binaryArgs) (*float64, error) {
+	return r.operation(ctx, "subtract", &args.A, &args.B)
+}
+
+// Multiply resolves the multiply mutation
+func (r *graphQLResolver) Multiply(ctx context.Context, args binaryArgs) (*float64, error) {
+	return r.operation(ctx, "multiply", &args.A, &args.B)
+}
+
+// Divide resolves the divide mutation
+func (r *graphQLResolver) Divide(ctx context.Context, args binaryArgs) (*float64, error) {
+	return r.operation(ctx, "divide", &args.A, &args.B)
+}
+
+// Percentage resolves the percentage mutation
+func (r *graphQLResolver) Percentage(ctx context.Context, args binaryArgs) (*float64, error) {
+	return r.operation(ctx, "percentage", &args.A, &args.B)
+}
+
+// Power resolves the power mutation
+func (r *graphQLResolver) Power(ctx context.Context, args binaryArgs) (*float64, error) {
+	return r.operation(ctx, "power", &args.A, &args.B)
+}
+
+// Sqrt resolves the sqrt mutation, whose b is ignored
+func (r *graphQLResolver) Sqrt(ctx context.Context, args struct {
+	A graphQLOperand
+	B *graphQLOperand
+}) (*float64, error) {
+	return r.operation(ctx, "sqrt", &args.A, args.B)
+}
+
+// Root resolves the root mutation
+func (r *graphQLResolver) Root(ctx context.Context, args binaryArgs) (*float64, error) {
+	return r.operation(ctx, "root", &args.A, &args.B)
+}
+
+// PercentChange resolves the percentChange mutation
+func (r *graphQLResolver) PercentChange(ctx context.Context, args binaryArgs) (*float64, error) {
+	return r.operation(ctx, "percent-change", &args.A, &args.B)
+}
+
+// Markup resolves the markup mutation
+func (r *graphQLResolver) Markup(ctx context.Context, args binaryArgs) (*float64, error) {
+	return r.operation(ctx, "markup", &args.A, &args.B)
+}
+
+// Discount resolves the discount mutation
+func (r *graphQLResolver) Discount(ctx context.Context, args binaryArgs) (*float64, error) {
+	return r.operation(ctx, "discount", &args.A, &args.B)
+}
+
+// Inverse resolves the inverse mutation
+func (r *graphQLResolver) Inverse(ctx context.Context, args unaryArgs) (*float64, error) {
+	return r.operation(ctx, "inverse", &args.A, nil)
+}
+
+// Negative resolves the negative mutation
+func (r *graphQLResolver) Negative(ctx context.Context, args unaryArgs) (*float64, error) {
+	return r.operation(ctx, "negative", &args.A, nil)
+}
+
+// History resolves the caller's recent calculations, newest first
+func (r *graphQLResolver) History(ctx context.Context, args struct{ Limit *int32 }) ([]historyResolver, error) {
+	owner := r.scope(ctx).owner
+	if owner == "" {
+		return nil, newError(CodeInvalidInput, fmt.Sprintf("%s or %s header is required", APIKeyHeader, SessionHeader))
+	}
+	limit := 0
+	if args.Limit != nil {
+		if *args.Limit < 0 {
+			return nil, newError(CodeInvalidInput, "limit must be a non-negative integer").WithDetail("param", "limit").WithDetail("value", *args.Limit)
+		}
+		limit = int(*args.Limit)
+	}
+	entries := r.s.store().History(owner, limit)
+	history := make([]historyResolver, len(entries))
+	for i, entry := range entries {
+		history[i] = historyResolver{entry}
+	}
+	return history, nil
+}
+
+// Session resolves a session by id
+func (r *graphQLResolver) Session(ctx context.Context, args struct{ ID graphql.ID }) (*sessionResolver, error) {
+	id := string(args.ID)
+	session, ok := r.s.tenantSession(r.scope(ctx).tenant, id)
+	if !ok {
+		return nil, sessionNotFound(id)
+	}
+	return &sessionResolver{session}, nil
+}
+
+// operationResolver resolves the Operation type
+type operationResolver struct {
+	name  string
+	unary bool
+}
+
+func (o operationResolver) Name() string { return o.name }
+func (o operationResolver) Unary() bool  { return o.unary }
+
+// historyResolver resolves the HistoryEntry type
+type historyResolver struct {
+	entry storage.HistoryEntry
+}
+
+func (h historyResolver) Operation() string       { return h.entry.Operation }
+func (h historyResolver) Operands() []float64     { return h.entry.Operands }
+func (h historyResolver) Result() float64         { return h.entry.Result }
+func (h historyResolver) CreatedAt() graphql.Time { return graphql.Time{Time: h.entry.CreatedAt} }
+
+func (h historyResolver) Unit() *string {
+	if h.entry.Unit == "" {
+		return nil
+	}
+	return &h.entry.Unit
+}
+
+// registerResolver resolves the Register type
+type registerResolver struct {
+	name  string
+	value float64
+}
+
+func (m registerResolver) Name() string   { return m.name }
+func (m registerResolver) Value() float64 { return m.value }
+
+// sessionResolver resolves the Session type
+type sessionResolver struct {
+	session storage.Session
+}
+
+func (r *sessionResolver) ID() graphql.ID          { return graphql.ID(r.session.ID) }
+func (r *sessionResolver) Accumulator() float64    { return r.session.Accumulator }
+func (r *sessionResolver) Ans() float64            { return r.session.Ans }
+func (r *sessionResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.session.CreatedAt} }
+func (r *sessionResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: r.session.UpdatedAt} }
+
+func (r *sessionResolver) AnsExact() *string {
+	if r.session.AnsExact == "" {
+		return nil
+	}
+	return &r.session.AnsExact
+}
+
+// Memory lists the session's memory registers sorted by name
+func (r *sessionResolver) Memory() []registerResolver {
+	registers := make([]registerResolver, 0, len(r.session.Memory))
+	for _, name := range slices.Sorted(maps.Keys(r.session.Memory)) {
+		registers = append(registers, registerResolver{name: name, value: r.session.Memory[name]})
+	}
+	return registers
+}
+
+// GraphQL handles GraphQL queries. Operation failures leave their field null and are reported in the
+// errors array with the same code and details as the JSON API.
+func (s *Service) GraphQL(c *gin.Context) {
+	var req GraphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind GraphQL request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing GraphQL request", "operation", c.Request.URL.Path, "method", c.Request.Method, "operation_name", req.OperationName)
+
+	start := time.Now()
+	ctx := context.WithValue(c.Request.Context(), graphQLScopeKey{}, scopeFromContext(c))
+	ctx = context.WithValue(ctx, graphQLClientKey{}, clientFromContext(c))
+	response := s.graphQLSchemaFor().Exec(ctx, req.Query, req.OperationName, req.Variables)
+	if len(response.Errors) > 0 {
+		s.logger().Error("GraphQL request completed with errors", "operation", c.Request.URL.Path, "method", c.Request.Method, "errors", len(response.Errors), "duration", time.Since(start))
+	} else {
+		s.logger().Info("GraphQL request successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "duration", time.Since(start))
+	}
+	c.JSON(http.StatusOK, response)
+}
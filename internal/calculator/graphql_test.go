@@ -0,0 +1,155 @@
+package calculator
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"calculator/core"
+	"calculator/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// graphQLResult is a decoded GraphQL response
+type graphQLResult struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Path       []any          `json:"path"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+// queryGraphQL posts query with the given API key and decodes the response
+func queryGraphQL(t *testing.T, s *Service, apiKey, query string, variables map[string]any) graphQLResult {
+	t.Helper()
+	c, w := setupTestContext("POST", "/graphql", GraphQLRequest{Query: query, Variables: variables})
+	if apiKey != "" {
+		c.Request.Header.Set(APIKeyHeader, apiKey)
+	}
+	s.GraphQL(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var result graphQLResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	return result
+}
+
+// TestGraphQLOperations tests several operations in one mutation, with failures reported per field
+func TestGraphQLOperations(t *testing.T) {
+	s := &Service{}
+	result := queryGraphQL(t, s, "", `mutation {
+		sum: add(a: 2, b: 3)
+		product: multiply(a: "pi", b: 2)
+		change: percentChange(a: 50, b: 75)
+		root: sqrt(a: 16)
+		neg: negative(a: 4.5)
+		bad: divide(a: 1, b: 0)
+	}`, nil)
+
+	assert.JSONEq(t, "5", string(result.Data["sum"]))
+	assert.JSONEq(t, "50", string(result.Data["change"]))
+	assert.JSONEq(t, "4", string(result.Data["root"]))
+	assert.JSONEq(t, "-4.5", string(result.Data["neg"]))
+	assert.JSONEq(t, "null", string(result.Data["bad"]))
+	var product float64
+	require.NoError(t, json.Unmarshal(result.Data["product"], &product))
+	assert.InDelta(t, 6.283185, product, 1e-6)
+
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, []any{"bad"}, result.Errors[0].Path)
+	assert.Equal(t, CodeDivisionByZero, result.Errors[0].Extensions["code"])
+}
+
+// TestGraphQLErrors tests that errors carry the same codes and details as the JSON API
+func TestGraphQLErrors(t *testing.T) {
+	s := &Service{}
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		code      string
+		details   map[string]any
+	}{
+		{"undefined variable", `mutation { add(a: "$nope", b: 1) }`, nil, CodeUndefinedVariable, map[string]any{"param": "a", "name": "nope"}},
+		{"invalid operand", `mutation { add(a: "abc", b: 1) }`, nil, CodeInvalidInput, map[string]any{"param": "a", "value": "abc"}},
+		{"domain error", `mutation { sqrt(a: -1) }`, nil, CodeDomainError, nil},
+		{"overflow", `mutation { multiply(a: 1e308, b: 10) }`, nil, CodeDomainError, map[string]any{"result": "+Inf"}},
+		{"unknown session", `{ session(id: "missing") { id } }`, nil, CodeNotFound, map[string]any{"session": "missing"}},
+		{"history without owner", `{ history { result } }`, nil, CodeInvalidInput, nil},
+		{"variables", `mutation($x: Operand!) { inverse(a: $x) }`, map[string]any{"x": 0}, CodeDivisionByZero, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := queryGraphQL(t, s, "", tt.query, tt.variables)
+			require.Len(t, result.Errors, 1)
+			assert.Equal(t, tt.code, result.Errors[0].Extensions["code"])
+			for key, value := range tt.details {
+				assert.Equal(t, value, result.Errors[0].Extensions["details"].(map[string]any)[key])
+			}
+		})
+	}
+
+	// Malformed queries are reported without codes, as are operations outside a mutation
+	for _, query := range []string{`mutation { modulo(a: 1, b: 2) }`, `{ add(a: 1, b: 2) }`} {
+		result := queryGraphQL(t, s, "", query, nil)
+		require.NotEmpty(t, result.Errors)
+		assert.Nil(t, result.Errors[0].Extensions["code"])
+	}
+	result := queryGraphQL(t, s, "", `mutation { modulo(a: 1, b: 2) }`, nil)
+	assert.Contains(t, result.Errors[0].Message, "modulo")
+}
+
+// TestGraphQLHistoryAndSession tests fetching results, history and session state in one request.
+// Mutation fields run in order, so history sees the operations before it.
+func TestGraphQLHistoryAndSession(t *testing.T) {
+	s := &Service{}
+	s.store().SetVariable("key:alice", "x", 4)
+	s.store().CreateSession(storage.Session{ID: "sess", Memory: map[string]float64{"M": 2}})
+
+	queryGraphQL(t, s, "alice", `mutation { power(a: "$x", b: 2) }`, nil)
+	result := queryGraphQL(t, s, "alice", `mutation {
+		add(a: "$x", b: 1)
+		history(limit: 5) { operation operands result }
+		session(id: "sess") { id accumulator memory { name value } }
+	}`, nil)
+	require.Empty(t, result.Errors)
+	assert.JSONEq(t, "5", string(result.Data["add"]))
+	assert.JSONEq(t, `[
+		{"operation": "add", "operands": [4, 1], "result": 5},
+		{"operation": "power", "operands": [4, 2], "result": 16}
+	]`, string(result.Data["history"]))
+	assert.JSONEq(t, `{"id": "sess", "accumulator": 0, "memory": [{"name": "M", "value": 2}]}`, string(result.Data["session"]))
+
+	// History is kept per caller and can be queried on its own
+	result = queryGraphQL(t, s, "alice", `{ history(limit: 1) { operation } }`, nil)
+	assert.JSONEq(t, `[{"operation": "add"}]`, string(result.Data["history"]))
+	result = queryGraphQL(t, s, "bob", `{ history { operation } }`, nil)
+	assert.JSONEq(t, "[]", string(result.Data["history"]))
+}
+
+// TestGraphQLOperationCoverage tests that every built-in operation has a mutation
+func TestGraphQLOperationCoverage(t *testing.T) {
+	s := &Service{}
+	result := queryGraphQL(t, s, "", `{ __schema { mutationType { fields { name } } } }`, nil)
+	var schema struct {
+		MutationType struct {
+			Fields []struct{ Name string } `json:"fields"`
+		} `json:"mutationType"`
+	}
+	require.NoError(t, json.Unmarshal(result.Data["__schema"], &schema))
+	fields := map[string]bool{}
+	for _, field := range schema.MutationType.Fields {
+		fields[field.Name] = true
+	}
+	for _, name := range core.OperationNames() {
+		// Operation names are kebab-case; GraphQL fields are camelCase
+		parts := strings.Split(name, "-")
+		for i := 1; i < len(parts); i++ {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+		assert.True(t, fields[strings.Join(parts, "")], "missing mutation for %s", name)
+	}
+}
@@ -0,0 +1,257 @@
+package calculator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"calculator/core"
+	"calculator/internal/access"
+	"calculator/internal/calculator/calculatorpb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcErrorDomain is the ErrorInfo domain of calculator errors returned over gRPC
+const grpcErrorDomain = "calculator"
+
+// grpcCodes maps calculator error codes to gRPC status codes, mirroring the HTTP statuses;
+// codes not listed map to InvalidArgument
+var grpcCodes = map[string]codes.Code{
+	CodeNotFound:         codes.NotFound,
+	CodeUnknownOperation: codes.NotFound,
+	CodeConflict:         codes.AlreadyExists,
+	CodeRateLimited:      codes.ResourceExhausted,
+	CodeUnauthorized:     codes.Unauthenticated,
+	CodeForbidden:        codes.PermissionDenied,
+	CodeInternal:         codes.Internal,
+}
+
+// grpcServer implements the gRPC Calculator service on the operation registry shared with the HTTP API
+type grpcServer struct {
+	calculatorpb.UnimplementedCalculatorServer
+	s *Service
+}
+
+// NewGRPCServer returns a gRPC server with the Calculator service registered, its requests logged and
+// admitted for the caller's tenant
+func (s *Service) NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(s.logUnaryRPC, s.scopeTenantUnary),
+		grpc.ChainStreamInterceptor(s.logStreamRPC, s.scopeTenantStream))
+	server := grpc.NewServer(opts...)
+	calculatorpb.RegisterCalculatorServer(server, &grpcServer{s: s})
+	return server
+}
+
+// logUnaryRPC logs each unary RPC with its outcome and duration
+func (s *Service) logUnaryRPC(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	s.logRPC(info.FullMethod, start, err)
+	return resp, err
+}
+
+// logStreamRPC logs each streaming RPC with its outcome and duration
+func (s *Service) logStreamRPC(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	s.logRPC(info.FullMethod, start, err)
+	return err
+}
+
+// logRPC logs a completed RPC at Info, or at Error when it failed
+func (s *Service) logRPC(method string, start time.Time, err error) {
+	code := status.Code(err)
+	if err != nil {
+		s.logger().Error("gRPC request failed", "rpc", method, "code", code.String(), "duration", time.Since(start), "error", err)
+		return
+	}
+	s.logger().Info("gRPC request completed", "rpc", method, "code", code.String(), "duration", time.Since(start))
+}
+
+// scopeFromMetadata returns the caller's scope from its tenant and the x-api-key and x-session-id metadata
+func scopeFromMetadata(ctx context.Context) scope {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	call := tenantFromCall(ctx)
+	sc := scope{session: first(strings.ToLower(SessionHeader)), tenant: call.tenant}
+	sc.principal = access.Principal{APIKey: first(strings.ToLower(APIKeyHeader)), Tenant: call.tenant, Subject: call.subject}
+	if key := sc.principal.APIKey; key != "" {
+		sc.owner = qualify(sc.tenant, "key:"+key)
+	} else if call.subject != "" {
+		sc.owner = qualify(sc.tenant, "user:"+call.subject)
+	} else if sc.session != "" {
+		sc.owner = qualify(sc.tenant, "session:"+sc.session)
+	}
+	return sc
+}
+
+// apiKeyFromMetadata returns the caller's x-api-key metadata, or "" when there is none
+func apiKeyFromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(strings.ToLower(APIKeyHeader)); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// detailStrings converts error details into ErrorInfo metadata, encoding non-string values as JSON
+func detailStrings(details map[string]any) map[string]string {
+	if len(details) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(details))
+	for key, value := range details {
+		if str, ok := value.(string); ok {
+			result[key] = str
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			encoded = []byte(fmt.Sprint(value))
+		}
+		result[key] = string(encoded)
+	}
+	return result
+}
+
+// grpcStatus converts err into a gRPC status carrying an ErrorInfo detail with the calculator code and details
+func grpcStatus(err error) error {
+	calcErr := toError(err)
+	code, ok := grpcCodes[calcErr.Code]
+	if !ok {
+		code = codes.InvalidArgument
+	}
+	st := status.New(code, calcErr.Message)
+	info := &errdetails.ErrorInfo{Reason: calcErr.Code, Domain: grpcErrorDomain, Metadata: detailStrings(calcErr.Details)}
+	if withDetails, err := st.WithDetails(info); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// pbError converts err into the error message of a batch or stream response
+func pbError(err error) *calculatorpb.Error {
+	calcErr := toError(err)
+	return &calculatorpb.Error{Code: calcErr.Code, Message: calcErr.Message, Details: detailStrings(calcErr.Details)}
+}
+
+// pbOperand converts a protobuf operand, returning nil when none was supplied
+func pbOperand(o *calculatorpb.Operand) *Operand {
+	switch kind := o.GetKind().(type) {
+	case *calculatorpb.Operand_Value:
+		return &Operand{Value: kind.Value}
+	case *calculatorpb.Operand_Ref:
+		return &Operand{Ref: kind.Ref}
+	}
+	return nil
+}
+
+// clientFromMetadata returns the client named by the x-client-id metadata, or the peer's address
+func clientFromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(strings.ToLower(ClientHeader)); len(values) > 0 && values[0] != "" {
+		return values[0]
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return host
+		}
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// calculate applies the requested operation within sc and publishes it to the events feed
+func (s *Service) calculate(ctx context.Context, sc scope, req *calculatorpb.CalculateRequest) (float64, error) {
+	s.logger().Debug("Received gRPC operation", "id", req.GetId(), "name", req.GetOperation())
+	start := time.Now()
+	a, b := pbOperand(req.GetA()), pbOperand(req.GetB())
+	result, err := s.evaluate(ctx, sc, req.GetOperation(), a, b)
+	s.publishEvaluation(TransportGRPC, sc.tenant, clientFromMetadata(ctx), req.GetOperation(), a, b, start, result, err)
+	return result, err
+}
+
+// calculateResponse applies req and wraps its result or error in a response
+func (s *Service) calculateResponse(ctx context.Context, sc scope, req *calculatorpb.CalculateRequest) *calculatorpb.CalculateResponse {
+	result, err := s.calculate(ctx, sc, req)
+	if err != nil {
+		return &calculatorpb.CalculateResponse{Id: req.GetId(), Outcome: &calculatorpb.CalculateResponse_Error{Error: pbError(err)}}
+	}
+	return &calculatorpb.CalculateResponse{Id: req.GetId(), Outcome: &calculatorpb.CalculateResponse_Result{Result: result}}
+}
+
+// ListOperations returns the available operations
+func (g *grpcServer) ListOperations(ctx context.Context, _ *calculatorpb.ListOperationsRequest) (*calculatorpb.ListOperationsResponse, error) {
+	response := &calculatorpb.ListOperationsResponse{}
+	for _, name := range core.OperationNames() {
+		_, isUnary := core.UnaryOperation(name)
+		response.Operations = append(response.Operations, &calculatorpb.Operation{Name: name, Unary: isUnary})
+	}
+	return response, nil
+}
+
+// Calculate applies one operation, returning failures as a gRPC status
+func (g *grpcServer) Calculate(ctx context.Context, req *calculatorpb.CalculateRequest) (*calculatorpb.CalculateResponse, error) {
+	result, err := g.s.calculate(ctx, scopeFromMetadata(ctx), req)
+	if err != nil {
+		return nil, grpcStatus(err)
+	}
+	return &calculatorpb.CalculateResponse{Id: req.GetId(), Outcome: &calculatorpb.CalculateResponse_Result{Result: result}}, nil
+}
+
+// Batch applies the operations in order, reporting each failure in its response. Batches need the
+// compute permission.
+func (g *grpcServer) Batch(ctx context.Context, req *calculatorpb.BatchRequest) (*calculatorpb.BatchResponse, error) {
+	sc := scopeFromMetadata(ctx)
+	if err := g.s.authorize(sc.principal, access.PermissionCompute); err != nil {
+		actor := sc.owner
+		if actor == "" {
+			actor = qualify(sc.tenant, "client:"+clientFromMetadata(ctx))
+		}
+		g.s.auditDenied(actor, "grpc:batch", err)
+		return nil, grpcStatus(err)
+	}
+	g.s.logger().Info("Processing gRPC batch request", "size", len(req.GetRequests()))
+	response := &calculatorpb.BatchResponse{Responses: make([]*calculatorpb.CalculateResponse, len(req.GetRequests()))}
+	for i, r := range req.GetRequests() {
+		if err := ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+		response.Responses[i] = g.s.calculateResponse(ctx, sc, r)
+	}
+	return response, nil
+}
+
+// CalculateStream applies each received operation and sends back its response until the client closes the stream
+func (g *grpcServer) CalculateStream(stream grpc.BidiStreamingServer[calculatorpb.CalculateRequest, calculatorpb.CalculateResponse]) error {
+	sc := scopeFromMetadata(stream.Context())
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(g.s.calculateResponse(stream.Context(), sc, req)); err != nil {
+			return err
+		}
+	}
+}
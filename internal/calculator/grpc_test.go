@@ -0,0 +1,187 @@
+package calculator
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"calculator/core"
+	"calculator/internal/calculator/calculatorpb"
+	"calculator/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// setupGRPC serves s over an in-memory connection and returns a client
+func setupGRPC(t *testing.T, s *Service) calculatorpb.CalculatorClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := s.NewGRPCServer()
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return calculatorpb.NewCalculatorClient(conn)
+}
+
+// value returns a literal operand
+func value(v float64) *calculatorpb.Operand {
+	return &calculatorpb.Operand{Kind: &calculatorpb.Operand_Value{Value: v}}
+}
+
+// ref returns a constant, variable or ans operand
+func ref(r string) *calculatorpb.Operand {
+	return &calculatorpb.Operand{Kind: &calculatorpb.Operand_Ref{Ref: r}}
+}
+
+// TestGRPCCalculate tests single operations and their error statuses
+func TestGRPCCalculate(t *testing.T) {
+	client := setupGRPC(t, &Service{})
+
+	tests := []struct {
+		name     string
+		req      *calculatorpb.CalculateRequest
+		expected float64
+		code     codes.Code
+		reason   string
+	}{
+		{"add", &calculatorpb.CalculateRequest{Operation: "add", A: value(2), B: value(3)}, 5, codes.OK, ""},
+		{"unary", &calculatorpb.CalculateRequest{Operation: "negative", A: value(4)}, -4, codes.OK, ""},
+		{"sqrt without b", &calculatorpb.CalculateRequest{Operation: "sqrt", A: value(16)}, 4, codes.OK, ""},
+		{"constant", &calculatorpb.CalculateRequest{Operation: "multiply", A: ref("pi"), B: value(2)}, 2 * 3.141592653589793, codes.OK, ""},
+		{"division by zero", &calculatorpb.CalculateRequest{Operation: "divide", A: value(1), B: value(0)}, 0, codes.InvalidArgument, CodeDivisionByZero},
+		{"missing operand", &calculatorpb.CalculateRequest{Operation: "add", A: value(1)}, 0, codes.InvalidArgument, CodeInvalidInput},
+		{"undefined variable", &calculatorpb.CalculateRequest{Operation: "add", A: ref("$missing"), B: value(1)}, 0, codes.InvalidArgument, CodeUndefinedVariable},
+		{"unknown operation", &calculatorpb.CalculateRequest{Operation: "modulo", A: value(1), B: value(2)}, 0, codes.NotFound, CodeUnknownOperation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Calculate(context.Background(), tt.req)
+			if tt.code == codes.OK {
+				require.NoError(t, err)
+				assert.InDelta(t, tt.expected, resp.GetResult(), 1e-9)
+				return
+			}
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.code, st.Code())
+			require.Len(t, st.Details(), 1)
+			info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+			require.True(t, ok)
+			assert.Equal(t, tt.reason, info.GetReason())
+			assert.Equal(t, grpcErrorDomain, info.GetDomain())
+		})
+	}
+}
+
+// TestGRPCScope tests that metadata selects the caller's variables and session
+func TestGRPCScope(t *testing.T) {
+	s := &Service{}
+	s.store().SetVariable("key:alice", "x", 7)
+	s.store().CreateSession(storage.Session{ID: "sess"})
+	client := setupGRPC(t, s)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "alice", "x-session-id", "sess")
+	resp, err := client.Calculate(ctx, &calculatorpb.CalculateRequest{Operation: "multiply", A: ref("$x"), B: value(2)})
+	require.NoError(t, err)
+	assert.Equal(t, 14.0, resp.GetResult())
+
+	session, ok := s.store().Session("sess")
+	require.True(t, ok)
+	assert.Equal(t, 14.0, session.Ans)
+
+	resp, err = client.Calculate(ctx, &calculatorpb.CalculateRequest{Operation: "add", A: ref("ans"), B: value(1)})
+	require.NoError(t, err)
+	assert.Equal(t, 15.0, resp.GetResult())
+
+	// Another caller's variables are not visible
+	_, err = client.Calculate(context.Background(), &calculatorpb.CalculateRequest{Operation: "add", A: ref("$x"), B: value(1)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// TestGRPCBatch tests that batch items succeed or fail independently
+func TestGRPCBatch(t *testing.T) {
+	client := setupGRPC(t, &Service{})
+
+	resp, err := client.Batch(context.Background(), &calculatorpb.BatchRequest{Requests: []*calculatorpb.CalculateRequest{
+		{Id: "1", Operation: "add", A: value(1), B: value(2)},
+		{Id: "2", Operation: "divide", A: value(1), B: value(0)},
+		{Id: "3", Operation: "power", A: value(2), B: value(10)},
+	}})
+	require.NoError(t, err)
+	require.Len(t, resp.GetResponses(), 3)
+
+	assert.Equal(t, "1", resp.GetResponses()[0].GetId())
+	assert.Equal(t, 3.0, resp.GetResponses()[0].GetResult())
+	assert.Equal(t, "2", resp.GetResponses()[1].GetId())
+	assert.Equal(t, CodeDivisionByZero, resp.GetResponses()[1].GetError().GetCode())
+	assert.Equal(t, 1024.0, resp.GetResponses()[2].GetResult())
+}
+
+// TestGRPCStream tests that streamed operations are answered in order with their ids
+func TestGRPCStream(t *testing.T) {
+	client := setupGRPC(t, &Service{})
+
+	stream, err := client.CalculateStream(context.Background())
+	require.NoError(t, err)
+	requests := []*calculatorpb.CalculateRequest{
+		{Id: "a", Operation: "subtract", A: value(10), B: value(4)},
+		{Id: "b", Operation: "unknown", A: value(1)},
+		{Id: "c", Operation: "inverse", A: value(4)},
+	}
+	for _, req := range requests {
+		require.NoError(t, stream.Send(req))
+	}
+	require.NoError(t, stream.CloseSend())
+
+	var responses []*calculatorpb.CalculateResponse
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		responses = append(responses, resp)
+	}
+	require.Len(t, responses, 3)
+	assert.Equal(t, "a", responses[0].GetId())
+	assert.Equal(t, 6.0, responses[0].GetResult())
+	assert.Equal(t, CodeUnknownOperation, responses[1].GetError().GetCode())
+	assert.Equal(t, "c", responses[2].GetId())
+	assert.Equal(t, 0.25, responses[2].GetResult())
+}
+
+// TestGRPCListOperations tests that the operation list matches the shared registry
+func TestGRPCListOperations(t *testing.T) {
+	s := &Service{}
+	client := setupGRPC(t, s)
+
+	resp, err := client.ListOperations(context.Background(), &calculatorpb.ListOperationsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.GetOperations(), len(core.OperationNames()))
+	unary := map[string]bool{}
+	for _, op := range resp.GetOperations() {
+		unary[op.GetName()] = op.GetUnary()
+	}
+	assert.True(t, unary["negative"])
+	assert.False(t, unary["add"])
+}
@@ -0,0 +1,77 @@
+package calculator
+
+import (
+	"math"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"calculator/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HistoryResponse represents the caller's recent calculations, newest first
+type HistoryResponse struct {
+	History []storage.HistoryEntry `json:"history"`
+}
+
+// recordHistory stores a completed operation in owner's history; anonymous callers keep none, and
+// results that are not finite are reported as errors rather than recorded
+func (s *Service) recordHistory(owner, operation string, operands []float64, result float64, unit string) {
+	if owner == "" || math.IsNaN(result) || math.IsInf(result, 0) {
+		return
+	}
+	s.store().AppendHistory(owner, storage.HistoryEntry{
+		Operation: operation,
+		Operands:  operands,
+		Result:    result,
+		Unit:      unit,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+// recordRequestHistory stores the operation named by the request path in the caller's history
+func (s *Service) recordRequestHistory(c *gin.Context, operands []float64, result float64, unit string) {
+	owner, _ := ownerFromContext(c)
+	s.recordHistory(owner, path.Base(c.Request.URL.Path), operands, result, unit)
+}
+
+// parseHistoryLimit validates a history limit; zero means all recorded entries
+func parseHistoryLimit(str string) (int, error) {
+	if str == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(str)
+	if err != nil || limit < 0 {
+		return 0, newError(CodeInvalidInput, "limit must be a non-negative integer").WithDetail("param", "limit").WithDetail("value", str)
+	}
+	return limit, nil
+}
+
+// History handles listing the caller's recent calculations, optionally limited by the limit query parameter
+func (s *Service) History(c *gin.Context) {
+	owner, ok := s.requireOwner(c)
+	if !ok {
+		return
+	}
+	limit, err := parseHistoryLimit(c.Query("limit"))
+	if err != nil {
+		s.logger().Error("Invalid history limit", "operation", c.Request.URL.Path, "method", c.Request.Method, "limit", c.Query("limit"))
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, HistoryResponse{History: s.store().History(owner, limit)})
+}
+
+// ClearHistory handles deleting the caller's calculation history
+func (s *Service) ClearHistory(c *gin.Context) {
+	owner, ok := s.requireOwner(c)
+	if !ok {
+		return
+	}
+	s.store().ClearHistory(owner)
+	s.logger().Info("History cleared", "operation", c.Request.URL.Path, "method", c.Request.Method)
+	c.Status(http.StatusNoContent)
+}
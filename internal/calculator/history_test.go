@@ -0,0 +1,67 @@
+package calculator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHistory tests that operations are recorded per caller and listed newest first
+func TestHistory(t *testing.T) {
+	s := &Service{}
+	request := func(method, url, apiKey string, body any, handler func(*Service, *gin.Context)) *httptest.ResponseRecorder {
+		c, w := setupTestContext(method, url, body)
+		if apiKey != "" {
+			c.Request.Header.Set(APIKeyHeader, apiKey)
+		}
+		handler(s, c)
+		return w
+	}
+
+	request("POST", "/api/v1/add", "alice", map[string]any{"a": 1, "b": 2}, (*Service).Add)
+	request("GET", "/api/v1/sqrt?a=9&b=2", "alice", nil, (*Service).SqrtGET)
+	request("POST", "/api/v1/negative", "alice", map[string]any{"a": 5}, (*Service).Negative)
+	request("POST", "/api/v1/add", "alice", map[string]any{"a": 1, "a_unit": "m", "b": 50, "b_unit": "cm"}, (*Service).Add)
+	request("POST", "/api/v1/divide", "alice", map[string]any{"a": 1, "b": 0}, (*Service).Divide)
+	// Results rejected for their format options or for overflowing are not recorded either
+	request("POST", "/api/v1/divide?scale=abc", "alice", map[string]any{"a": 1, "b": 3}, (*Service).Divide)
+	request("GET", "/api/v1/multiply?a=1e308&b=10", "alice", nil, (*Service).MultiplyGET)
+	request("POST", "/api/v1/inverse?rounding=sideways", "alice", map[string]any{"a": 4}, (*Service).Inverse)
+	request("POST", "/api/v1/add", "", map[string]any{"a": 1, "b": 1}, (*Service).Add)
+
+	w := request("GET", "/api/v1/history", "alice", nil, (*Service).History)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response HistoryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.History, 4)
+	assert.Equal(t, "add", response.History[0].Operation)
+	assert.Equal(t, "m", response.History[0].Unit)
+	assert.Equal(t, 1.5, response.History[0].Result)
+	assert.Equal(t, "negative", response.History[1].Operation)
+	assert.Equal(t, []float64{5}, response.History[1].Operands)
+	assert.Equal(t, "sqrt", response.History[2].Operation)
+	assert.Equal(t, []float64{1, 2}, response.History[3].Operands)
+
+	w = request("GET", "/api/v1/history?limit=1", "alice", nil, (*Service).History)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.History, 1)
+
+	w = request("GET", "/api/v1/history?limit=-1", "alice", nil, (*Service).History)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+
+	w = request("GET", "/api/v1/history", "", nil, (*Service).History)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+
+	c, _ := setupTestContext("DELETE", "/api/v1/history", nil)
+	c.Request.Header.Set(APIKeyHeader, "alice")
+	s.ClearHistory(c)
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	w = request("GET", "/api/v1/history", "alice", nil, (*Service).History)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Empty(t, response.History)
+}
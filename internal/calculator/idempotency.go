@@ -0,0 +1,230 @@
+package calculator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"calculator/internal/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader names a POST request so that retries of it are answered with the first response
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayHeader marks a response replayed for a retried Idempotency-Key
+	IdempotentReplayHeader = "Idempotent-Replayed"
+
+	// Default bounds of the idempotency store
+	defaultIdempotencyEntries = 10000
+	defaultIdempotencyBytes   = 16 << 20
+	defaultIdempotencyTTL     = 24 * time.Hour
+
+	// defaultMaxBodyBytes bounds the request bodies buffered for idempotent replay
+	defaultMaxBodyBytes = 1 << 20
+	// maxIdempotencyKeyLength bounds the Idempotency-Key header
+	maxIdempotencyKeyLength = 255
+	// idempotencyEntryOverhead approximates the bytes of bookkeeping per stored response
+	idempotencyEntryOverhead = 256
+)
+
+// replayedHeaders are the response headers stored with an idempotent response and replayed with it
+var replayedHeaders = []string{"Content-Type", "Cache-Control", "ETag", CacheHeader}
+
+// IdempotentResponse is the first response to a POST request carrying an Idempotency-Key
+type IdempotentResponse struct {
+	// Fingerprint identifies the request method, URL and body the response answered
+	Fingerprint [sha256.Size]byte
+	Status      int
+	Header      http.Header
+	Body        []byte
+}
+
+// NewIdempotencyStore returns a store for Service.Idempotency keeping up to maxEntries responses and
+// maxBytes bytes, each replayed for ttl after the first request
+func NewIdempotencyStore(maxEntries int, maxBytes int64, ttl time.Duration) *cache.LRU[IdempotentResponse] {
+	return cache.New[IdempotentResponse](maxEntries, maxBytes, ttl)
+}
+
+// idempotencyStore returns a safe idempotency store (never nil). If Idempotency is nil, a default store
+// is created on first use.
+func (s *Service) idempotencyStore() *cache.LRU[IdempotentResponse] {
+	s.idempotencyOnce.Do(func() {
+		if s.Idempotency == nil {
+			s.Idempotency = NewIdempotencyStore(defaultIdempotencyEntries, defaultIdempotencyBytes, defaultIdempotencyTTL)
+		}
+	})
+	return s.Idempotency
+}
+
+// idempotencyScope returns the client an Idempotency-Key belongs to: the caller's owner key, or the
+// client named by X-Client-ID or its IP address for anonymous callers
+func idempotencyScope(c *gin.Context) string {
+	if owner, ok := ownerFromContext(c); ok {
+		return owner
+	}
+	return qualify(tenantFromContext(c), "client:"+clientFromContext(c))
+}
+
+// requestFingerprint hashes the method, URL and body of a request
+func requestFingerprint(c *gin.Context, body []byte) [sha256.Size]byte {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", c.Request.Method, c.Request.URL.RequestURI())
+	h.Write(body)
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// maxBodyBytes returns the largest request body buffered for idempotent replay
+func (s *Service) maxBodyBytes() int64 {
+	if s.MaxBodyBytes > 0 {
+		return s.MaxBodyBytes
+	}
+	return defaultMaxBodyBytes
+}
+
+// bufferingWriter keeps the whole response body for the idempotency store
+type bufferingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bufferingWriter) WriteString(str string) (int, error) {
+	w.body.WriteString(str)
+	return w.ResponseWriter.WriteString(str)
+}
+
+// claimIdempotencyKey marks key as being processed, reporting false when another request holds it
+func (s *Service) claimIdempotencyKey(key string) bool {
+	s.idempotencyMu.Lock()
+	defer s.idempotencyMu.Unlock()
+	if s.idempotencyInFlight == nil {
+		s.idempotencyInFlight = make(map[string]struct{})
+	}
+	if _, ok := s.idempotencyInFlight[key]; ok {
+		return false
+	}
+	s.idempotencyInFlight[key] = struct{}{}
+	return true
+}
+
+// releaseIdempotencyKey ends the processing of key
+func (s *Service) releaseIdempotencyKey(key string) {
+	s.idempotencyMu.Lock()
+	defer s.idempotencyMu.Unlock()
+	delete(s.idempotencyInFlight, key)
+}
+
+// ReplayIdempotentRequests is middleware storing the first response to each POST request carrying an Idempotency-Key
+// and replaying it to retries with the same key from the same client, so that a retried M+ or calculation
+// is applied and recorded once. A retry with a different body, or one arriving while the first request is
+// still processed, is a conflict. Server errors are not stored, so such requests can be retried.
+func (s *Service) ReplayIdempotentRequests(c *gin.Context) {
+	key := c.GetHeader(IdempotencyKeyHeader)
+	if c.Request.Method != http.MethodPost || key == "" {
+		c.Next()
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		s.logger().Error("Invalid idempotency key", "operation", c.Request.URL.Path, "method", c.Request.Method, "length", len(key))
+		s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength)))
+		c.Abort()
+		return
+	}
+
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		limit := s.maxBodyBytes()
+		body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, limit))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			s.logger().Error("Request body too large", "operation", c.Request.URL.Path, "method", c.Request.Method, "max_bytes", limit)
+			s.respondError(c, http.StatusRequestEntityTooLarge, newError(CodeInvalidInput, fmt.Sprintf("request body must be at most %d bytes", limit)).
+				WithDetail("max_bytes", limit))
+			c.Abort()
+			return
+		}
+		if err != nil {
+			s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, "failed to read request body"))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	fingerprint := requestFingerprint(c, body)
+	storeKey := idempotencyScope(c) + "|" + key
+
+	if s.replayIdempotent(c, storeKey, key, fingerprint) {
+		return
+	}
+	if !s.claimIdempotencyKey(storeKey) {
+		s.logger().Error("Idempotent request in progress", "operation", c.Request.URL.Path, "method", c.Request.Method, "idempotency_key", key)
+		s.respondError(c, http.StatusConflict, newError(CodeConflict, fmt.Sprintf("a request with %s '%s' is still being processed", IdempotencyKeyHeader, key)).
+			WithDetail("idempotency_key", key))
+		c.Abort()
+		return
+	}
+	defer s.releaseIdempotencyKey(storeKey)
+	// The first request may have completed between the lookup and the claim
+	if s.replayIdempotent(c, storeKey, key, fingerprint) {
+		return
+	}
+
+	writer := &bufferingWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+
+	c.Next()
+
+	c.Writer = writer.ResponseWriter
+	if status := writer.Status(); status < http.StatusInternalServerError {
+		response := IdempotentResponse{Fingerprint: fingerprint, Status: status, Header: http.Header{}, Body: writer.body.Bytes()}
+		for _, name := range replayedHeaders {
+			if value := writer.Header().Get(name); value != "" {
+				response.Header.Set(name, value)
+			}
+		}
+		size := idempotencyEntryOverhead + int64(len(storeKey)+len(response.Body))
+		s.idempotencyStore().Add(storeKey, response, size)
+	}
+}
+
+// replayIdempotent answers the request with the response stored under storeKey, or with a conflict when
+// that response answered a different request. It reports whether the request was answered.
+func (s *Service) replayIdempotent(c *gin.Context, storeKey, key string, fingerprint [sha256.Size]byte) bool {
+	response, ok := s.idempotencyStore().Get(storeKey)
+	if !ok {
+		return false
+	}
+	if response.Fingerprint != fingerprint {
+		s.logger().Error("Idempotency key reused", "operation", c.Request.URL.Path, "method", c.Request.Method, "idempotency_key", key)
+		s.respondError(c, http.StatusConflict, newError(CodeConflict, fmt.Sprintf("%s '%s' was used for a different request", IdempotencyKeyHeader, key)).
+			WithDetail("idempotency_key", key))
+		c.Abort()
+		return true
+	}
+	s.logger().Info("Idempotent response replayed", "operation", c.Request.URL.Path, "method", c.Request.Method, "idempotency_key", key)
+	for name, values := range response.Header {
+		c.Writer.Header()[name] = values
+	}
+	c.Header(IdempotentReplayHeader, "true")
+	if len(response.Body) == 0 {
+		c.Status(response.Status)
+		c.Writer.WriteHeaderNow()
+	} else {
+		c.Data(response.Status, response.Header.Get("Content-Type"), response.Body)
+	}
+	c.Abort()
+	return true
+}
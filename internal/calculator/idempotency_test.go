@@ -0,0 +1,164 @@
+package calculator
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"calculator/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// idempotentRequest sends a JSON POST request through r with the given Idempotency-Key and API key
+func idempotentRequest(r http.Handler, url, key, apiKey string, body any) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	if apiKey != "" {
+		req.Header.Set(APIKeyHeader, apiKey)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// TestIdempotencyReplay tests that retried requests are applied once and answered with the first response
+func TestIdempotencyReplay(t *testing.T) {
+	store := storage.NewMemory()
+	s := &Service{Store: store}
+	r := gin.New()
+	SetupRoutes(r, s)
+
+	w := idempotentRequest(r, "/api/v1/sessions", "", "", nil)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var session storage.Session
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+	memoryURL := "/api/v1/sessions/" + session.ID + "/memory"
+	mPlus := map[string]any{"action": "M+", "value": 5}
+
+	first := idempotentRequest(r, memoryURL, "retry-1", "", mPlus)
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.Empty(t, first.Header().Get(IdempotentReplayHeader))
+	retry := idempotentRequest(r, memoryURL, "retry-1", "", mPlus)
+	require.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(IdempotentReplayHeader))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+	stored, _ := store.Session(session.ID)
+	assert.Equal(t, 5.0, stored.Memory["M"])
+
+	// Without a key, or with a new one, the request is applied again
+	idempotentRequest(r, memoryURL, "", "", mPlus)
+	idempotentRequest(r, memoryURL, "retry-2", "", mPlus)
+	stored, _ = store.Session(session.ID)
+	assert.Equal(t, 15.0, stored.Memory["M"])
+
+	// Calculations are recorded in history once
+	add := map[string]any{"a": 2, "b": 3}
+	for range 3 {
+		w = idempotentRequest(r, "/api/v1/add", "add-1", "alice", add)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	assert.Len(t, store.History("key:alice", 0), 1)
+
+	// Keys are scoped to the client
+	w = idempotentRequest(r, "/api/v1/add", "add-1", "bob", add)
+	assert.Empty(t, w.Header().Get(IdempotentReplayHeader))
+	assert.Len(t, store.History("key:bob", 0), 1)
+
+	// Error responses are replayed too
+	divide := map[string]any{"a": 1, "b": 0}
+	first = idempotentRequest(r, "/api/v1/divide", "divide-1", "alice", divide)
+	retry = idempotentRequest(r, "/api/v1/divide", "divide-1", "alice", divide)
+	assertErrorCode(t, retry, http.StatusBadRequest, CodeDivisionByZero)
+	assert.Equal(t, "true", retry.Header().Get(IdempotentReplayHeader))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+}
+
+// TestIdempotencyConflicts tests reusing a key for a different request and invalid keys
+func TestIdempotencyConflicts(t *testing.T) {
+	s := &Service{}
+	r := gin.New()
+	SetupRoutes(r, s)
+
+	w := idempotentRequest(r, "/api/v1/add", "key-1", "alice", map[string]any{"a": 2, "b": 3})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = idempotentRequest(r, "/api/v1/add", "key-1", "alice", map[string]any{"a": 2, "b": 4})
+	assertErrorCode(t, w, http.StatusConflict, CodeConflict)
+	w = idempotentRequest(r, "/api/v1/subtract", "key-1", "alice", map[string]any{"a": 2, "b": 3})
+	assertErrorCode(t, w, http.StatusConflict, CodeConflict)
+
+	w = idempotentRequest(r, "/api/v1/add", strings.Repeat("k", maxIdempotencyKeyLength+1), "alice", map[string]any{"a": 2, "b": 3})
+	assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+}
+
+// TestIdempotencyBodyLimit tests that the bodies buffered for replay are bounded
+func TestIdempotencyBodyLimit(t *testing.T) {
+	s := &Service{MaxBodyBytes: 16}
+	r := gin.New()
+	SetupRoutes(r, s)
+
+	w := idempotentRequest(r, "/api/v1/add", "small", "alice", map[string]any{"a": 2, "b": 3})
+	require.Equal(t, http.StatusOK, w.Code)
+	w = idempotentRequest(r, "/api/v1/add", "large", "alice", map[string]any{"a": 2, "b": 3, "a_unit": "m"})
+	assertErrorCode(t, w, http.StatusRequestEntityTooLarge, CodeInvalidInput)
+	assert.Contains(t, w.Body.String(), `"max_bytes":16`)
+	assert.Len(t, s.store().History("key:alice", 0), 1)
+
+	// Requests without a key are not buffered
+	w = idempotentRequest(r, "/api/v1/add", "", "alice", map[string]any{"a": 2, "b": 3, "a_unit": "m"})
+	assert.NotEqual(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, int64(defaultMaxBodyBytes), (&Service{}).maxBodyBytes())
+}
+
+// TestIdempotencyInFlight tests that a retry arriving while the first request is processed is a conflict,
+// and that server errors are not stored
+func TestIdempotencyInFlight(t *testing.T) {
+	s := &Service{}
+	r := gin.New()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	fail := true
+	r.POST("/slow", s.ReplayIdempotentRequests, func(c *gin.Context) {
+		close(started)
+		<-release
+		c.JSON(http.StatusOK, gin.H{"result": 1})
+	})
+	r.POST("/flaky", s.ReplayIdempotentRequests, func(c *gin.Context) {
+		if fail {
+			fail = false
+			s.respondError(c, http.StatusInternalServerError, newError(CodeInternal, "unavailable"))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"result": 2})
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- idempotentRequest(r, "/slow", "slow-1", "", nil)
+	}()
+	<-started
+	w := idempotentRequest(r, "/slow", "slow-1", "", nil)
+	assertErrorCode(t, w, http.StatusConflict, CodeConflict)
+	assert.Contains(t, w.Body.String(), `"idempotency_key":"slow-1"`)
+	close(release)
+	assert.Equal(t, http.StatusOK, (<-done).Code)
+	w = idempotentRequest(r, "/slow", "slow-1", "", nil)
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayHeader))
+
+	w = idempotentRequest(r, "/flaky", "flaky-1", "", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	w = idempotentRequest(r, "/flaky", "flaky-1", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(IdempotentReplayHeader))
+}
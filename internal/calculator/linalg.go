@@ -0,0 +1,195 @@
+package calculator
+
+import (
+	"net/http"
+
+	"calculator/core"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VectorRequest represents a vector operation request
+type VectorRequest struct {
+	A []float64 `json:"a" binding:"required"`
+	B []float64 `json:"b"`
+}
+
+// MatrixRequest represents a matrix operation request, matrices are JSON 2D arrays (row-major)
+type MatrixRequest struct {
+	A [][]float64 `json:"a" binding:"required"`
+	B [][]float64 `json:"b"`
+}
+
+// SolveRequest represents a request to solve the linear system Ax=b
+type SolveRequest struct {
+	A [][]float64 `json:"a" binding:"required"`
+	B []float64   `json:"b" binding:"required"`
+}
+
+// VectorResponse represents a vector operation response
+type VectorResponse struct {
+	Result []float64 `json:"result"`
+}
+
+// MatrixResponse represents a matrix operation response
+type MatrixResponse struct {
+	Result [][]float64 `json:"result"`
+}
+
+// VectorDot handles the vector dot product
+func (s *Service) VectorDot(c *gin.Context) {
+	s.handleVectorOperation(c, func(a, b []float64) (any, error) {
+		result, err := core.Dot(s.withLogger(c.Request.Context()), a, b)
+		return Response{Result: result}, err
+	})
+}
+
+// VectorCross handles the 3-dimensional vector cross product
+func (s *Service) VectorCross(c *gin.Context) {
+	s.handleVectorOperation(c, func(a, b []float64) (any, error) {
+		result, err := core.Cross(s.withLogger(c.Request.Context()), a, b)
+		return VectorResponse{Result: result}, err
+	})
+}
+
+// VectorNorm handles the Euclidean norm of a vector
+func (s *Service) VectorNorm(c *gin.Context) {
+	s.handleVectorOperation(c, func(a, _ []float64) (any, error) {
+		result, err := core.Norm(s.withLogger(c.Request.Context()), a)
+		return Response{Result: result}, err
+	})
+}
+
+// MatrixAdd handles matrix addition
+func (s *Service) MatrixAdd(c *gin.Context) {
+	s.handleMatrixOperation(c, func(a, b [][]float64) (any, error) {
+		result, err := core.MatrixAdd(s.withLogger(c.Request.Context()), a, b)
+		return MatrixResponse{Result: result}, err
+	})
+}
+
+// MatrixMultiply handles matrix multiplication
+func (s *Service) MatrixMultiply(c *gin.Context) {
+	s.handleMatrixOperation(c, func(a, b [][]float64) (any, error) {
+		result, err := core.MatrixMultiply(s.withLogger(c.Request.Context()), a, b)
+		return MatrixResponse{Result: result}, err
+	})
+}
+
+// MatrixTranspose handles matrix transposition
+func (s *Service) MatrixTranspose(c *gin.Context) {
+	s.handleMatrixOperation(c, func(a, _ [][]float64) (any, error) {
+		result, err := core.Transpose(s.withLogger(c.Request.Context()), a)
+		return MatrixResponse{Result: result}, err
+	})
+}
+
+// MatrixDeterminant handles the determinant of a square matrix
+func (s *Service) MatrixDeterminant(c *gin.Context) {
+	s.handleMatrixOperation(c, func(a, _ [][]float64) (any, error) {
+		result, err := core.Determinant(s.withLogger(c.Request.Context()), a)
+		return Response{Result: result}, err
+	})
+}
+
+// MatrixInverse handles the inverse of a square matrix
+func (s *Service) MatrixInverse(c *gin.Context) {
+	s.handleMatrixOperation(c, func(a, _ [][]float64) (any, error) {
+		result, err := core.MatrixInverse(s.withLogger(c.Request.Context()), a)
+		return MatrixResponse{Result: result}, err
+	})
+}
+
+// MatrixRank handles the rank of a matrix
+func (s *Service) MatrixRank(c *gin.Context) {
+	s.handleMatrixOperation(c, func(a, _ [][]float64) (any, error) {
+		result, err := core.Rank(s.withLogger(c.Request.Context()), a)
+		return Response{Result: float64(result)}, err
+	})
+}
+
+// MatrixSolve handles solving the linear system Ax=b
+func (s *Service) MatrixSolve(c *gin.Context) {
+	var req SolveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind solve JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing linear system request", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", req.A, "b", req.B)
+
+	result, err := core.Solve(s.withLogger(c.Request.Context()), req.A, req.B)
+	if err != nil {
+		s.logger().Error("Linear system solve failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Linear system solve successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "result", result)
+	s.respondJSON(c, VectorResponse{Result: result})
+}
+
+// handleVectorOperation handles the common logic for vector operations via POST
+func (s *Service) handleVectorOperation(c *gin.Context, op func(a, b []float64) (any, error)) {
+	var req VectorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind vector JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing vector operation request", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", req.A, "b", req.B)
+
+	response, err := op(req.A, req.B)
+	if err != nil {
+		s.logger().Error("Vector operation failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", req.A, "b", req.B, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if result, ok := response.(Response); ok {
+		if err = checkFinite(result.Result); err == nil {
+			response, err = s.formatResponse(c, result)
+		}
+		if err != nil {
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	s.logger().Info("Vector operation successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "result", response)
+	s.respondJSON(c, response)
+}
+
+// handleMatrixOperation handles the common logic for matrix operations via POST
+func (s *Service) handleMatrixOperation(c *gin.Context, op func(a, b [][]float64) (any, error)) {
+	var req MatrixRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind matrix JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing matrix operation request", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", req.A, "b", req.B)
+
+	response, err := op(req.A, req.B)
+	if err != nil {
+		s.logger().Error("Matrix operation failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", req.A, "b", req.B, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if result, ok := response.(Response); ok {
+		if err = checkFinite(result.Result); err == nil {
+			response, err = s.formatResponse(c, result)
+		}
+		if err != nil {
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	s.logger().Info("Matrix operation successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "result", response)
+	s.respondJSON(c, response)
+}
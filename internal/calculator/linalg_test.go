@@ -0,0 +1,150 @@
+package calculator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func assertErrorCode(t *testing.T, w *httptest.ResponseRecorder, expectedStatus int, expectedCode string) {
+	assert.Equal(t, expectedStatus, w.Code)
+
+	var errorResponse ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &errorResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedCode, errorResponse.Code)
+	assert.NotEmpty(t, errorResponse.Error)
+}
+
+// TestVectorOperations tests the vector handlers
+func TestVectorOperations(t *testing.T) {
+	s := &Service{}
+
+	tests := []struct {
+		name           string
+		handler        gin.HandlerFunc
+		body           map[string]any
+		expectedStatus int
+		expected       any
+		expectedCode   string
+	}{
+		{"dot product", s.VectorDot, map[string]any{"a": []float64{1, 2, 3}, "b": []float64{4, 5, 6}}, http.StatusOK, 32.0, ""},
+		{"dot length mismatch", s.VectorDot, map[string]any{"a": []float64{1, 2}, "b": []float64{1}}, http.StatusBadRequest, nil, CodeDimensionMismatch},
+		{"cross product", s.VectorCross, map[string]any{"a": []float64{1, 0, 0}, "b": []float64{0, 1, 0}}, http.StatusOK, []float64{0, 0, 1}, ""},
+		{"cross not 3d", s.VectorCross, map[string]any{"a": []float64{1, 0}, "b": []float64{0, 1}}, http.StatusBadRequest, nil, CodeDimensionMismatch},
+		{"norm", s.VectorNorm, map[string]any{"a": []float64{3, 4}}, http.StatusOK, 5.0, ""},
+		{"norm empty", s.VectorNorm, map[string]any{"a": []float64{}}, http.StatusBadRequest, nil, CodeDimensionMismatch},
+		{"missing a", s.VectorNorm, map[string]any{}, http.StatusBadRequest, nil, CodeInvalidInput},
+		{"dot overflow", s.VectorDot, map[string]any{"a": []float64{1e308, 1e308}, "b": []float64{10, 10}}, http.StatusBadRequest, nil, CodeDomainError},
+		{"cross overflow", s.VectorCross, map[string]any{"a": []float64{1e308, 0, 0}, "b": []float64{0, 1e308, 0}}, http.StatusBadRequest, nil, CodeDomainError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestContext("POST", "/vector", tt.body)
+			tt.handler(c)
+
+			if tt.expectedStatus != http.StatusOK {
+				assertErrorCode(t, w, tt.expectedStatus, tt.expectedCode)
+				return
+			}
+			assert.Equal(t, http.StatusOK, w.Code)
+			switch expected := tt.expected.(type) {
+			case float64:
+				var response Response
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.InDelta(t, expected, response.Result, 0.0001)
+			case []float64:
+				var response VectorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.InDeltaSlice(t, expected, response.Result, 0.0001)
+			}
+		})
+	}
+}
+
+// TestMatrixOperations tests the matrix handlers
+func TestMatrixOperations(t *testing.T) {
+	s := &Service{}
+
+	tests := []struct {
+		name           string
+		handler        gin.HandlerFunc
+		body           map[string]any
+		expectedStatus int
+		expected       any
+		expectedCode   string
+	}{
+		{"add", s.MatrixAdd, map[string]any{"a": [][]float64{{1, 2}, {3, 4}}, "b": [][]float64{{5, 6}, {7, 8}}}, http.StatusOK, [][]float64{{6, 8}, {10, 12}}, ""},
+		{"add dimension mismatch", s.MatrixAdd, map[string]any{"a": [][]float64{{1, 2}}, "b": [][]float64{{1}, {2}}}, http.StatusBadRequest, nil, CodeDimensionMismatch},
+		{"add ragged", s.MatrixAdd, map[string]any{"a": [][]float64{{1, 2}, {3}}, "b": [][]float64{{1, 2}, {3, 4}}}, http.StatusBadRequest, nil, CodeDimensionMismatch},
+		{"multiply", s.MatrixMultiply, map[string]any{"a": [][]float64{{1, 2, 3}}, "b": [][]float64{{1}, {2}, {3}}}, http.StatusOK, [][]float64{{14}}, ""},
+		{"multiply overflow", s.MatrixMultiply, map[string]any{"a": [][]float64{{1e308}}, "b": [][]float64{{10}}}, http.StatusBadRequest, nil, CodeDomainError},
+		{"multiply dimension mismatch", s.MatrixMultiply, map[string]any{"a": [][]float64{{1, 2}}, "b": [][]float64{{1, 2}}}, http.StatusBadRequest, nil, CodeDimensionMismatch},
+		{"transpose", s.MatrixTranspose, map[string]any{"a": [][]float64{{1, 2, 3}, {4, 5, 6}}}, http.StatusOK, [][]float64{{1, 4}, {2, 5}, {3, 6}}, ""},
+		{"determinant", s.MatrixDeterminant, map[string]any{"a": [][]float64{{2, 0, 1}, {1, 3, 2}, {1, 1, 2}}}, http.StatusOK, 6.0, ""},
+		{"determinant singular", s.MatrixDeterminant, map[string]any{"a": [][]float64{{1, 2}, {2, 4}}}, http.StatusOK, 0.0, ""},
+		{"determinant overflow", s.MatrixDeterminant, map[string]any{"a": [][]float64{{1e308, 0}, {0, 1e308}}}, http.StatusBadRequest, nil, CodeDomainError},
+		{"determinant not square", s.MatrixDeterminant, map[string]any{"a": [][]float64{{1, 2}}}, http.StatusBadRequest, nil, CodeDimensionMismatch},
+		{"inverse", s.MatrixInverse, map[string]any{"a": [][]float64{{4, 7}, {2, 6}}}, http.StatusOK, [][]float64{{0.6, -0.7}, {-0.2, 0.4}}, ""},
+		{"inverse singular", s.MatrixInverse, map[string]any{"a": [][]float64{{1, 2}, {2, 4}}}, http.StatusBadRequest, nil, CodeSingularMatrix},
+		{"rank full", s.MatrixRank, map[string]any{"a": [][]float64{{1, 0}, {0, 1}}}, http.StatusOK, 2.0, ""},
+		{"rank deficient", s.MatrixRank, map[string]any{"a": [][]float64{{1, 2, 3}, {2, 4, 6}}}, http.StatusOK, 1.0, ""},
+		{"empty matrix", s.MatrixRank, map[string]any{"a": [][]float64{}}, http.StatusBadRequest, nil, CodeDimensionMismatch},
+		{"solve", s.MatrixSolve, map[string]any{"a": [][]float64{{2, 1}, {1, 3}}, "b": []float64{3, 5}}, http.StatusOK, []float64{0.8, 1.4}, ""},
+		{"solve singular", s.MatrixSolve, map[string]any{"a": [][]float64{{1, 1}, {1, 1}}, "b": []float64{1, 2}}, http.StatusBadRequest, nil, CodeSingularMatrix},
+		{"solve overflow", s.MatrixSolve, map[string]any{"a": [][]float64{{1e-10, 0}, {0, 1}}, "b": []float64{1e308, 1}}, http.StatusBadRequest, nil, CodeDomainError},
+		{"solve dimension mismatch", s.MatrixSolve, map[string]any{"a": [][]float64{{1, 0}, {0, 1}}, "b": []float64{1}}, http.StatusBadRequest, nil, CodeDimensionMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestContext("POST", "/matrix", tt.body)
+			tt.handler(c)
+
+			if tt.expectedStatus != http.StatusOK {
+				assertErrorCode(t, w, tt.expectedStatus, tt.expectedCode)
+				return
+			}
+			assert.Equal(t, http.StatusOK, w.Code)
+			switch expected := tt.expected.(type) {
+			case float64:
+				var response Response
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.InDelta(t, expected, response.Result, 0.0001)
+			case []float64:
+				var response VectorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.InDeltaSlice(t, expected, response.Result, 0.0001)
+			case [][]float64:
+				var response MatrixResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Len(t, response.Result, len(expected))
+				for i := range expected {
+					assert.InDeltaSlice(t, expected[i], response.Result[i], 0.0001)
+				}
+			}
+		})
+	}
+}
+
+// TestErrorCodes tests that existing operation errors carry stable codes
+func TestErrorCodes(t *testing.T) {
+	s := &Service{}
+
+	c, w := setupTestContext("GET", "/divide?a=1&b=0", nil)
+	s.DivideGET(c)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeDivisionByZero)
+
+	c, w = setupTestContext("GET", "/sqrt?a=-1&b=0", nil)
+	s.SqrtGET(c)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeDomainError)
+
+	c, w = setupTestContext("GET", "/add?a=x&b=1", nil)
+	s.AddGET(c)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+}
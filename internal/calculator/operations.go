@@ -0,0 +1,64 @@
+package calculator
+
+import (
+	"context"
+
+	"calculator/core"
+)
+
+// withLogger returns ctx carrying the service logger for the core calculations
+func (s *Service) withLogger(ctx context.Context) context.Context {
+	return core.WithLogger(ctx, s.logger())
+}
+
+// evaluate applies the named built-in operation to operands resolved within sc for the gRPC, GraphQL
+// and WebSocket APIs, recording the result as the session's ANS and in the caller's history. a is
+// required, as is b for binary operations other than sqrt.
+func (s *Service) evaluate(ctx context.Context, sc scope, name string, a, b *Operand) (float64, error) {
+	binary, isBinary := core.BinaryOperation(name)
+	unary, isUnary := core.UnaryOperation(name)
+	if !isBinary && !isUnary {
+		s.logger().Error("Unknown operation", "name", name)
+		return 0, core.UnknownOperationError(name)
+	}
+
+	s.logger().Info("Processing operation request", "name", name)
+
+	if a == nil {
+		return 0, newError(CodeInvalidInput, "invalid value for parameter 'a'").WithDetail("param", "a")
+	}
+	left, err := s.resolveOperand(sc, "a", *a)
+	if err != nil {
+		return 0, err
+	}
+	ctx = s.withLogger(ctx)
+	operands := []float64{left}
+	var result float64
+	if isUnary {
+		result, err = unary(ctx, left)
+	} else {
+		right := 0.0
+		if b != nil {
+			if right, err = s.resolveOperand(sc, "b", *b); err != nil {
+				return 0, err
+			}
+		} else if name != "sqrt" {
+			return 0, newError(CodeInvalidInput, "invalid value for parameter 'b'").WithDetail("param", "b")
+		}
+		operands = append(operands, right)
+		result, err = binary(ctx, left, right)
+	}
+	if err == nil {
+		// A result beyond float64 fails like the JSON API's, before it becomes ANS or a history entry
+		err = checkFinite(result)
+	}
+	if err != nil {
+		s.logger().Error("Operation failed", "name", name, "operands", operands, "error", err)
+		return 0, err
+	}
+
+	s.logger().Info("Operation successful", "name", name, "operands", operands, "result", result)
+	s.recordSessionAnswer(sc.tenant, sc.session, result)
+	s.recordHistory(sc.owner, name, operands, result, "")
+	return result, nil
+}
@@ -0,0 +1,104 @@
+package calculator
+
+import (
+	"fmt"
+	"net/http"
+
+	"calculator/core"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultPlotSamples is the number of uniform intervals sampled before refinement
+	defaultPlotSamples = 100
+	// defaultPlotPoints bounds the evaluations of a plot
+	defaultPlotPoints = 2000
+	// maxPlotPoints caps the max_points a request may ask for
+	maxPlotPoints = 20000
+	// defaultPlotWidth and defaultPlotHeight are the SVG dimensions in pixels
+	defaultPlotWidth  = 640
+	defaultPlotHeight = 400
+)
+
+// PlotRequest represents a request to sample an expression over [A, B].
+// Samples is the number of uniform intervals refined adaptively, MaxPoints bounds the evaluations,
+// and SVG also renders the plot, Width by Height pixels.
+type PlotRequest struct {
+	Expression string   `json:"expression" binding:"required"`
+	Variable   string   `json:"variable"`
+	A          *Operand `json:"a"`
+	B          *Operand `json:"b"`
+	Samples    int      `json:"samples"`
+	MaxPoints  int      `json:"max_points"`
+	SVG        bool     `json:"svg"`
+	Width      int      `json:"width"`
+	Height     int      `json:"height"`
+}
+
+// Point is a sampled point of a plot
+type Point = core.Point
+
+// Gap is an x range where the expression is undefined or discontinuous
+type Gap = core.Gap
+
+// PlotResponse represents the sampled curve as continuous segments separated by gaps
+type PlotResponse = core.PlotResult
+
+// Plot handles sampling an expression over a range for graphing
+func (s *Service) Plot(c *gin.Context) {
+	var req PlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind plot JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if req.Samples == 0 {
+		req.Samples = defaultPlotSamples
+	}
+	if req.MaxPoints == 0 {
+		req.MaxPoints = defaultPlotPoints
+	}
+	if req.Width == 0 {
+		req.Width = defaultPlotWidth
+	}
+	if req.Height == 0 {
+		req.Height = defaultPlotHeight
+	}
+	if req.MaxPoints < 2 || req.MaxPoints > maxPlotPoints {
+		s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, fmt.Sprintf("max_points must be between 2 and %d", maxPlotPoints)).WithDetail("max_points", req.MaxPoints))
+		return
+	}
+	if req.Width < 1 || req.Height < 1 || req.Width > 4096 || req.Height > 4096 {
+		s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, "width and height must be between 1 and 4096").
+			WithDetail("width", req.Width).
+			WithDetail("height", req.Height))
+		return
+	}
+	e, err := s.parseExpression(c, req.Expression, req.Variable)
+	if err != nil {
+		s.logger().Error("Invalid expression", "operation", c.Request.URL.Path, "method", c.Request.Method, "expression", req.Expression, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	a, b, err := s.resolveInterval(c, req.A, req.B)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing plot request", "operation", c.Request.URL.Path, "method", c.Request.Method, "expression", req.Expression, "a", a, "b", b)
+
+	response, err := core.Plot(s.withLogger(c.Request.Context()), e.function(), a, b, req.Samples, req.MaxPoints)
+	if err != nil {
+		s.logger().Error("Plot failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "expression", req.Expression, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if req.SVG {
+		response.SVG = core.RenderSVG(req.Expression, response, min(a, b), max(a, b), req.Width, req.Height)
+	}
+
+	s.logger().Info("Plot successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "points", response.Points, "segments", len(response.Segments))
+	s.respondJSON(c, response)
+}
@@ -0,0 +1,104 @@
+package calculator
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requestPlot(t *testing.T, body map[string]any) PlotResponse {
+	c, w := setupTestContext("POST", "/plot", body)
+	(&Service{}).Plot(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response PlotResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+
+// TestPlot tests adaptive sampling, gaps and SVG rendering
+func TestPlot(t *testing.T) {
+	t.Run("continuous", func(t *testing.T) {
+		response := requestPlot(t, map[string]any{"expression": "sin(x)", "a": 0, "b": "tau"})
+		assert.Len(t, response.Segments, 1)
+		assert.Empty(t, response.Gaps)
+		assert.InDelta(t, 1, response.YMax, 1e-3)
+		assert.InDelta(t, -1, response.YMin, 1e-3)
+		assert.Empty(t, response.SVG)
+	})
+
+	t.Run("pole", func(t *testing.T) {
+		response := requestPlot(t, map[string]any{"expression": "1/x", "a": -1, "b": 1})
+		assert.Len(t, response.Segments, 2)
+		assert.Len(t, response.Gaps, 1)
+		assert.Less(t, response.Gaps[0].From, 0.0)
+		assert.Greater(t, response.Gaps[0].To, 0.0)
+	})
+
+	t.Run("domain boundary", func(t *testing.T) {
+		response := requestPlot(t, map[string]any{"expression": "sqrt(x)", "a": -1, "b": 1, "samples": 10})
+		assert.Len(t, response.Segments, 1)
+		assert.Equal(t, []Gap{{From: -1, To: response.Segments[0][0].X}}, response.Gaps)
+		// Refinement locates the boundary well below the initial sample spacing
+		assert.Less(t, response.Segments[0][0].X, 0.01)
+	})
+
+	t.Run("jumps", func(t *testing.T) {
+		response := requestPlot(t, map[string]any{"expression": "floor(x)", "a": 0.5, "b": 3.5})
+		assert.Len(t, response.Segments, 4)
+		assert.Len(t, response.Gaps, 3)
+	})
+
+	t.Run("undefined everywhere", func(t *testing.T) {
+		response := requestPlot(t, map[string]any{"expression": "ln(x)", "a": -2, "b": -1})
+		assert.Empty(t, response.Segments)
+		assert.Equal(t, []Gap{{From: -2, To: -1}}, response.Gaps)
+	})
+
+	t.Run("point budget", func(t *testing.T) {
+		response := requestPlot(t, map[string]any{"expression": "sin(1/x)", "a": 0.001, "b": 1, "max_points": 300})
+		assert.LessOrEqual(t, response.Points, 300)
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		// Values beyond float64 are left out as a gap rather than breaking the response
+		response := requestPlot(t, map[string]any{"expression": "1e308 * x", "a": 0, "b": 10})
+		require.NotEmpty(t, response.Segments)
+		require.NotEmpty(t, response.Gaps)
+		assert.Equal(t, 10.0, response.Gaps[len(response.Gaps)-1].To)
+	})
+
+}
+
+// TestPlotSVG tests server-side SVG rendering
+func TestPlotSVG(t *testing.T) {
+	response := requestPlot(t, map[string]any{"expression": "tan(x)", "a": -3, "b": 3, "svg": true, "width": 320, "height": 200})
+	assert.True(t, strings.HasPrefix(response.SVG, `<svg xmlns="http://www.w3.org/2000/svg" width="320" height="200"`))
+	assert.Equal(t, len(response.Segments), strings.Count(response.SVG, "<polyline"))
+	assert.Contains(t, response.SVG, "<title>tan(x)</title>")
+}
+
+// TestPlotErrors tests invalid plot requests
+func TestPlotErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body map[string]any
+		code string
+	}{
+		{"missing expression", map[string]any{"a": 0, "b": 1}, CodeInvalidInput},
+		{"missing bound", map[string]any{"expression": "x", "a": 0}, CodeInvalidInput},
+		{"empty range", map[string]any{"expression": "x", "a": 1, "b": 1}, CodeInvalidInput},
+		{"unknown identifier", map[string]any{"expression": "x + y", "a": 0, "b": 1}, CodeUndefinedVariable},
+		{"too many points", map[string]any{"expression": "x", "a": 0, "b": 1, "max_points": 1000000}, CodeInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestContext("POST", "/plot", tt.body)
+			(&Service{}).Plot(c)
+			assertErrorCode(t, w, http.StatusBadRequest, tt.code)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package calculator
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"calculator/core"
+	"calculator/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RationalOperand is an exact request input. Besides a JSON number, whose decimal text is kept exactly,
+// it accepts a string holding a fraction ("1/3"), a mixed number ("-2 1/3"), a decimal ("0.1"),
+// a built-in constant, a variable ("$x") or "ans".
+type RationalOperand struct {
+	Text string
+}
+
+// UnmarshalJSON accepts either a JSON number or a JSON string
+func (o *RationalOperand) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &o.Text)
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	o.Text = number.String()
+	return nil
+}
+
+// RationalRequest represents a rational mode operation request; B is not used by unary operations
+type RationalRequest struct {
+	A *RationalOperand `json:"a"`
+	B *RationalOperand `json:"b"`
+}
+
+// RationalResponse represents an exact result in all its forms
+type RationalResponse = core.RationalResult
+
+// resolveRational resolves o exactly within sc. Constants are irrational or measured, so they are approximate;
+// variables and a float ANS are taken at their shortest decimal representation.
+func (s *Service) resolveRational(sc scope, param string, o RationalOperand) (core.Rational, error) {
+	if r, ok, err := core.ParseRational(o.Text); ok {
+		return r, err
+	}
+	ref := strings.TrimSpace(o.Text)
+	if (ref == "ans" || ref == "$ans") && sc.session != "" {
+		if session, ok := s.tenantSession(sc.tenant, sc.session); ok && session.AnsExact != "" {
+			if r, ok := new(big.Rat).SetString(session.AnsExact); ok {
+				s.logger().Debug("Resolved exact answer", "param", param, "value", session.AnsExact)
+				return core.Rational{Value: r}, nil
+			}
+		}
+	}
+	value, err := s.resolveOperand(sc, param, Operand{Ref: ref})
+	if err != nil {
+		return core.Rational{}, err
+	}
+	_, isConstant := core.LookupConstant(strings.TrimPrefix(ref, "$"))
+	return core.RationalFromFloat(value, isConstant)
+}
+
+// recordRationalAnswer stores r as the exact last answer of the caller's session, if any
+func (s *Service) recordRationalAnswer(c *gin.Context, r core.Rational) {
+	id := c.GetHeader(SessionHeader)
+	if id == "" {
+		return
+	}
+	_, err := s.updateTenantSession(tenantFromContext(c), id, func(session *storage.Session) error {
+		session.Ans, _ = r.Value.Float64()
+		session.AnsExact = r.Value.RatString()
+		return nil
+	})
+	if err != nil {
+		s.logger().Debug("Answer not recorded", "session", id, "error", err)
+	}
+}
+
+// RationalOperation handles an operation in exact rational mode
+func (s *Service) RationalOperation(c *gin.Context) {
+	name := c.Param("operation")
+	operation, unary, ok := core.RationalOperation(name)
+	if !ok {
+		s.logger().Error("Unknown rational operation", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", name)
+		s.respondError(c, http.StatusNotFound, core.UnknownRationalOperationError(name))
+		return
+	}
+
+	var req RationalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind rational JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if req.A == nil {
+		s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, "invalid value for parameter 'a'").WithDetail("param", "a"))
+		return
+	}
+	if req.B == nil && !unary {
+		s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, "invalid value for parameter 'b'").WithDetail("param", "b"))
+		return
+	}
+
+	s.logger().Info("Processing rational operation request", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", name)
+
+	sc := scopeFromContext(c)
+	a, err := s.resolveRational(sc, "a", *req.A)
+	if err != nil {
+		s.logger().Error("Failed to resolve parameter 'a'", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", req.A.Text, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	b := core.Rational{Value: new(big.Rat)}
+	if req.B != nil && !unary {
+		b, err = s.resolveRational(sc, "b", *req.B)
+		if err != nil {
+			s.logger().Error("Failed to resolve parameter 'b'", "operation", c.Request.URL.Path, "method", c.Request.Method, "b", req.B.Text, "error", err)
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	key := cacheKey(cacheModeRational, c.Request.URL.Path, a.Value.RatString(), a.Approximate, b.Value.RatString(), b.Approximate)
+	result, err := cached(s, c, key, func(r core.Rational) int64 { return int64(r.Value.Num().BitLen()+r.Value.Denom().BitLen()) / 8 }, func() (core.Rational, error) {
+		return operation(s.withLogger(c.Request.Context()), a, b)
+	})
+	if err != nil {
+		s.logger().Error("Rational operation failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", name, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Rational operation successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", name, "result", result.Value.RatString(), "approximate", result.Approximate)
+	s.recordRationalAnswer(c, result)
+	s.respondJSON(c, result.Result())
+}
@@ -0,0 +1,137 @@
+package calculator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requestRational runs the named rational operation, sending the session header when id is set
+func requestRational(s *Service, name, id string, body map[string]any) *httptest.ResponseRecorder {
+	c, w := setupTestContext("POST", "/rational/"+name, body)
+	if id != "" {
+		c.Request.Header.Set(SessionHeader, id)
+	}
+	s.RationalOperation(withParam(c, "operation", name))
+	return w
+}
+
+func decodeRational(t *testing.T, w *httptest.ResponseRecorder) RationalResponse {
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response RationalResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+
+// TestRationalOperation tests exact results and their forms
+func TestRationalOperation(t *testing.T) {
+	tests := []struct {
+		name        string
+		operation   string
+		body        map[string]any
+		fraction    string
+		mixed       string
+		decimal     string
+		approximate bool
+	}{
+		{"divide", "divide", map[string]any{"a": 1, "b": 3}, "1/3", "1/3", "0.33333333333333333333", false},
+		{"add fractions", "add", map[string]any{"a": "1/3", "b": "1/6"}, "1/2", "1/2", "0.5", false},
+		{"decimal literals", "add", map[string]any{"a": 0.1, "b": 0.2}, "3/10", "3/10", "0.3", false},
+		{"subtract to mixed", "subtract", map[string]any{"a": "-2", "b": "1/3"}, "-7/3", "-2 1/3", "-2.33333333333333333333", false},
+		{"mixed operand", "multiply", map[string]any{"a": "-2 1/3", "b": 3}, "-7", "-7", "-7", false},
+		{"percentage", "percentage", map[string]any{"a": "1/3", "b": 50}, "1/6", "1/6", "0.16666666666666666667", false},
+		{"integer power", "power", map[string]any{"a": "2/3", "b": 3}, "8/27", "8/27", "0.2962962962962962963", false},
+		{"negative power", "power", map[string]any{"a": "2/3", "b": -2}, "9/4", "2 1/4", "2.25", false},
+		{"fractional power", "power", map[string]any{"a": 2, "b": "1/2"}, "14142135623730951/10000000000000000", "1 4142135623730951/10000000000000000", "1.4142135623730951", true},
+		{"inverse", "inverse", map[string]any{"a": "-3/4"}, "-4/3", "-1 1/3", "-1.33333333333333333333", false},
+		{"negative", "negative", map[string]any{"a": "5/2"}, "-5/2", "-2 1/2", "-2.5", false},
+		{"perfect sqrt", "sqrt", map[string]any{"a": "9/4"}, "3/2", "1 1/2", "1.5", false},
+		{"perfect cube root", "root", map[string]any{"a": "-8/27", "b": 3}, "-2/3", "-2/3", "-0.66666666666666666667", false},
+		{"irrational sqrt", "sqrt", map[string]any{"a": 2}, "14142135623730951/10000000000000000", "1 4142135623730951/10000000000000000", "1.4142135623730951", true},
+		{"constant", "multiply", map[string]any{"a": "pi", "b": 1}, "3141592653589793/1000000000000000", "3 141592653589793/1000000000000000", "3.141592653589793", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := decodeRational(t, requestRational(&Service{}, tt.operation, "", tt.body))
+			assert.Equal(t, tt.fraction, response.Fraction)
+			assert.Equal(t, tt.mixed, response.Mixed)
+			assert.Equal(t, tt.decimal, response.Decimal)
+			assert.Equal(t, tt.approximate, response.Approximate)
+			num, den, _ := strings.Cut(tt.fraction, "/")
+			if den == "" {
+				den = "1"
+			}
+			assert.Equal(t, num, response.Numerator)
+			assert.Equal(t, den, response.Denominator)
+		})
+	}
+}
+
+// TestRationalOperationErrors tests rejected operands and operations
+func TestRationalOperationErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation string
+		body      map[string]any
+		status    int
+		code      string
+	}{
+		{"divide by zero", "divide", map[string]any{"a": 1, "b": "0/5"}, http.StatusBadRequest, CodeDivisionByZero},
+		{"inverse of zero", "inverse", map[string]any{"a": 0}, http.StatusBadRequest, CodeDivisionByZero},
+		{"zero to negative power", "power", map[string]any{"a": 0, "b": -1}, http.StatusBadRequest, CodeDivisionByZero},
+		{"even root of negative", "sqrt", map[string]any{"a": "-1/4"}, http.StatusBadRequest, CodeDomainError},
+		{"zeroth root", "root", map[string]any{"a": 4, "b": 0}, http.StatusBadRequest, CodeDomainError},
+		{"missing b", "add", map[string]any{"a": 1}, http.StatusBadRequest, CodeInvalidInput},
+		{"missing a", "negative", map[string]any{}, http.StatusBadRequest, CodeInvalidInput},
+		{"invalid operand", "add", map[string]any{"a": "1/x", "b": 1}, http.StatusBadRequest, CodeInvalidInput},
+		{"improper mixed fraction", "add", map[string]any{"a": "1 4/3", "b": 1}, http.StatusBadRequest, CodeInvalidInput},
+		{"result too large", "power", map[string]any{"a": 3, "b": 100000}, http.StatusBadRequest, CodeInvalidInput},
+		{"result beyond float64", "multiply", map[string]any{"a": "1e200", "b": "1e200"}, http.StatusBadRequest, CodeDomainError},
+		{"undefined variable", "add", map[string]any{"a": "$missing", "b": 1}, http.StatusBadRequest, CodeUndefinedVariable},
+		{"unknown operation", "modulo", map[string]any{"a": 1, "b": 2}, http.StatusNotFound, CodeUnknownOperation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertErrorCode(t, requestRational(&Service{}, tt.operation, "", tt.body), tt.status, tt.code)
+		})
+	}
+}
+
+// TestRationalAnswer tests that chained operations through ANS stay exact
+func TestRationalAnswer(t *testing.T) {
+	s := &Service{}
+	c, w := setupTestContext("POST", "/sessions", nil)
+	s.CreateSession(c)
+	id := decodeSession(t, w).ID
+
+	response := decodeRational(t, requestRational(s, "divide", id, map[string]any{"a": 1, "b": 3}))
+	assert.Equal(t, "1/3", response.Fraction)
+
+	response = decodeRational(t, requestRational(s, "multiply", id, map[string]any{"a": "ans", "b": 3}))
+	assert.Equal(t, "1", response.Fraction)
+	assert.Equal(t, 1.0, response.Result)
+	assert.False(t, response.Approximate)
+
+	c, w = setupTestContext("GET", "/sessions/"+id, nil)
+	s.GetSession(withParam(c, "id", id))
+	session := decodeSession(t, w)
+	assert.Equal(t, "1", session.AnsExact)
+	assert.Equal(t, 1.0, session.Ans)
+
+	// A floating-point operation replaces the exact answer
+	c, w = setupTestContext("POST", "/add", map[string]any{"a": 1, "b": 2})
+	c.Request.Header.Set(SessionHeader, id)
+	s.Add(c)
+	assertResponse(t, w, http.StatusOK, 3, "")
+
+	c, w = setupTestContext("GET", "/sessions/"+id, nil)
+	s.GetSession(withParam(c, "id", id))
+	assert.Empty(t, decodeSession(t, w).AnsExact)
+}
@@ -0,0 +1,159 @@
+package calculator
+
+import (
+	"net/http"
+
+	"calculator/internal/access"
+	"calculator/internal/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRoutes registers the health check, GraphQL, WebSocket and /api/v1 endpoints of s on r
+func SetupRoutes(r *gin.Engine, s *Service) {
+	// Health check endpoint
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	// GraphQL endpoint
+	r.POST("/graphql", s.ScopeTenant, s.GraphQL)
+
+	// WebSocket endpoint
+	r.GET("/ws", s.ScopeTenant, s.WebSocket)
+
+	// Calculator endpoints, scoped to the caller's tenant; POST requests carrying an Idempotency-Key are
+	// answered once
+	api := r.Group("/api/v1", s.ScopeTenant, s.ReplayIdempotentRequests)
+	{
+		// Calculation endpoints are published to the events feed and recorded in the audit trail
+		calc := api.Group("", s.PublishCalculations, s.AuditAction(audit.ActionCalculation))
+		// Expensive calculations additionally need the compute permission
+		compute := api.Group("", s.RequirePermission(access.PermissionCompute), s.PublishCalculations, s.AuditAction(audit.ActionCalculation))
+
+		// POST endpoints
+		calc.POST("/add", s.Add)
+		calc.POST("/subtract", s.Subtract)
+		calc.POST("/multiply", s.Multiply)
+		calc.POST("/divide", s.Divide)
+		calc.POST("/percentage", s.Percentage)
+		calc.POST("/power", s.Power)
+		calc.POST("/sqrt", s.Sqrt)
+		calc.POST("/root", s.Root)
+		calc.POST("/inverse", s.Inverse)
+		calc.POST("/negative", s.Negative)
+
+		// GET endpoints
+		calc.GET("/add", s.AddGET)
+		calc.GET("/subtract", s.SubtractGET)
+		calc.GET("/multiply", s.MultiplyGET)
+		calc.GET("/divide", s.DivideGET)
+		calc.GET("/percentage", s.PercentageGET)
+		calc.GET("/power", s.PowerGET)
+		calc.GET("/sqrt", s.SqrtGET)
+		calc.GET("/root", s.RootGET)
+		calc.GET("/inverse", s.InverseGET)
+		calc.GET("/negative", s.NegativeGET)
+
+		// Linear algebra endpoints
+		calc.POST("/vector/dot", s.VectorDot)
+		calc.POST("/vector/cross", s.VectorCross)
+		calc.POST("/vector/norm", s.VectorNorm)
+		calc.POST("/matrix/add", s.MatrixAdd)
+		calc.POST("/matrix/multiply", s.MatrixMultiply)
+		calc.POST("/matrix/transpose", s.MatrixTranspose)
+		calc.POST("/matrix/determinant", s.MatrixDeterminant)
+		calc.POST("/matrix/inverse", s.MatrixInverse)
+		calc.POST("/matrix/rank", s.MatrixRank)
+		calc.POST("/matrix/solve", s.MatrixSolve)
+
+		// Financial endpoints
+		compute.POST("/finance/compound-interest", s.CompoundInterest)
+		compute.POST("/finance/future-value", s.FutureValue)
+		calc.POST("/finance/present-value", s.PresentValue)
+		calc.POST("/finance/payment", s.Payment)
+		calc.POST("/finance/npv", s.NPV)
+		calc.POST("/finance/irr", s.IRR)
+		compute.POST("/finance/amortization", s.Amortization)
+		calc.POST("/percent-change", s.PercentChange)
+		calc.POST("/markup", s.Markup)
+		calc.POST("/discount", s.Discount)
+		calc.GET("/percent-change", s.PercentChangeGET)
+		calc.GET("/markup", s.MarkupGET)
+		calc.GET("/discount", s.DiscountGET)
+
+		// Unit conversion endpoints
+		calc.POST("/convert", s.Convert)
+		api.GET("/units", s.Units)
+
+		// Constants and variables endpoints
+		api.GET("/constants", s.Constants)
+		api.GET("/variables", s.ListVariables)
+		api.POST("/variables", s.AuditAction(audit.ActionVariableCreate), s.CreateVariable)
+		api.GET("/variables/:name", s.GetVariable)
+		api.PUT("/variables/:name", s.AuditAction(audit.ActionVariableUpdate), s.PutVariable)
+		api.DELETE("/variables/:name", s.AuditAction(audit.ActionVariableDelete), s.DeleteVariable)
+
+		// Session endpoints
+		api.POST("/sessions", s.AuditAction(audit.ActionSessionCreate), s.CreateSession)
+		api.GET("/sessions/:id", s.GetSession)
+		api.DELETE("/sessions/:id", s.AuditAction(audit.ActionSessionDelete), s.DeleteSession)
+		api.POST("/sessions/:id/clear", s.AuditAction(audit.ActionSessionClear), s.ClearSession)
+		api.PUT("/sessions/:id/accumulator", s.AuditAction(audit.ActionSessionAccumulator), s.SetAccumulator)
+		api.POST("/sessions/:id/memory", s.AuditAction(audit.ActionSessionMemory), s.SessionMemory)
+		calc.POST("/sessions/:id/operations/:operation", s.SessionOperation)
+
+		// History endpoints
+		api.GET("/history", s.History)
+		api.DELETE("/history", s.RequirePermission(access.PermissionClearHistory), s.AuditAction(audit.ActionHistoryClear), s.ClearHistory)
+
+		// Calculation events feed
+		api.GET("/events", s.StreamEvents)
+
+		// Result cache endpoints
+		api.GET("/cache/stats", s.CacheStats)
+
+		// Rational (exact fraction) mode endpoints
+		calc.POST("/rational/:operation", s.RationalOperation)
+
+		// User-defined function endpoints
+		api.GET("/functions", s.ListFunctions)
+		api.POST("/functions", s.RequirePermission(access.PermissionManageFunctions), s.AuditAction(audit.ActionFunctionCreate), s.CreateFunction)
+		api.GET("/functions/:name", s.GetFunction)
+		api.PUT("/functions/:name", s.RequirePermission(access.PermissionManageFunctions), s.AuditAction(audit.ActionFunctionUpdate), s.PutFunction)
+		api.DELETE("/functions/:name", s.RequirePermission(access.PermissionManageFunctions), s.AuditAction(audit.ActionFunctionDelete), s.DeleteFunction)
+		compute.POST("/functions/:name/call", s.CallFunction)
+
+		// Expression evaluation endpoint
+		compute.POST("/eval", s.Eval)
+
+		// Batch endpoint
+		compute.POST("/batch", s.Batch)
+
+		// Calculus endpoints
+		compute.POST("/derivative", s.Derivative)
+		compute.POST("/integrate", s.Integrate)
+		compute.POST("/roots", s.FindRoot)
+		compute.POST("/minimize", s.Minimize)
+		compute.POST("/maximize", s.Maximize)
+
+		// Plotting endpoints
+		compute.POST("/plot", s.Plot)
+
+		// Solver endpoints
+		compute.POST("/solve/polynomial", s.SolvePolynomial)
+		compute.POST("/solve/linear", s.SolveLinearSystem)
+
+		// Admin endpoints
+		admin := api.Group("/admin")
+		admin.GET("/audit", s.RequirePermission(access.PermissionReadAudit), s.AuditRecords)
+
+		tenants := admin.Group("", s.RequirePermission(access.PermissionManageTenants))
+		tenants.GET("/tenants", s.ListTenants)
+		tenants.POST("/tenants", s.AuditAction(audit.ActionTenantCreate), s.CreateTenant)
+		tenants.GET("/tenants/:id", s.GetTenant)
+		tenants.POST("/tenants/:id/disable", s.AuditAction(audit.ActionTenantDisable), s.DisableTenant)
+		tenants.POST("/tenants/:id/enable", s.AuditAction(audit.ActionTenantEnable), s.EnableTenant)
+		tenants.GET("/metrics", s.TenantMetrics)
+	}
+}
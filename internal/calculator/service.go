@@ -1,35 +1,82 @@
 package calculator
 
 import (
-	"errors"
 	"io"
 	"log/slog"
-	"math"
 	"net/http"
-	"strconv"
+	"sync"
+
+	"calculator/core"
+	"calculator/internal/access"
+	"calculator/internal/audit"
+	"calculator/internal/cache"
+	"calculator/internal/events"
+	"calculator/internal/storage"
+	"calculator/internal/tenant"
 
 	"github.com/gin-gonic/gin"
+	graphql "github.com/graph-gophers/graphql-go"
 )
 
 // Request represents the calculator operation request
 type Request struct {
-	A float64 `json:"a" form:"a"`
-	B float64 `json:"b" form:"b"`
+	A     Operand `json:"a" form:"a"`
+	B     Operand `json:"b" form:"b"`
+	AUnit string  `json:"a_unit,omitempty" form:"a_unit"`
+	BUnit string  `json:"b_unit,omitempty" form:"b_unit"`
 }
 
 // UnaryRequest represents the unary operation request
 type UnaryRequest struct {
-	A float64 `json:"a" form:"a"`
+	A Operand `json:"a" form:"a"`
 }
 
-// Response represents the calculator operation response
+// Response represents the calculator operation response.
+// Formatted is set when the request asks for rounding or formatting.
 type Response struct {
-	Result float64 `json:"result"`
+	Result    float64 `json:"result"`
+	Unit      string  `json:"unit,omitempty"`
+	Formatted string  `json:"formatted,omitempty"`
 }
 
 // Service handles calculator operations
 type Service struct {
 	Logger *slog.Logger
+	Store  storage.Store
+	Cache  *cache.LRU[any]
+	Events *events.Bus[CalculationEvent]
+	// Origins lists the browser origins besides the server's own allowed to open WebSocket connections
+	Origins []string
+	// WSLimits bounds each WebSocket connection; nil uses the defaults
+	WSLimits *WebSocketLimits
+	// Idempotency holds the responses replayed to retried POST requests carrying an Idempotency-Key
+	Idempotency *cache.LRU[IdempotentResponse]
+	// MaxBodyBytes bounds the body of a POST request buffered to match its Idempotency-Key; zero uses 1 MiB
+	MaxBodyBytes int64
+	// Audit records calculations and administrative actions; nil keeps an in-memory trail
+	Audit *audit.Log
+	// Access decides which callers may use the expensive and administrative endpoints. Nil grants every
+	// permission to every caller, except those of the admin endpoints, which only AdminKeys hold.
+	Access *access.Policy
+	// AdminKeys lists the API keys allowed to use the /api/v1/admin endpoints when Access is nil
+	AdminKeys []string
+	// Tenants holds the teams sharing the deployment; nil starts with only the default tenant
+	Tenants *tenant.Registry
+	// TokenSecret verifies the HS256 bearer tokens naming a caller's tenant; empty refuses bearer tokens
+	TokenSecret []byte
+
+	storeOnce  sync.Once
+	cacheOnce  sync.Once
+	eventsOnce sync.Once
+	schemaOnce sync.Once
+	schema     *graphql.Schema
+
+	auditOnce           sync.Once
+	accessOnce          sync.Once
+	tenantsOnce         sync.Once
+	idempotencyOnce     sync.Once
+	idempotencyMu       sync.Mutex
+	idempotencyInFlight map[string]struct{}
 }
 
 // logger returns a safe logger (never nil). If Logger is nil, returns a no-op logger.
@@ -40,311 +87,310 @@ func (s *Service) logger() *slog.Logger {
 	return slog.New(slog.NewTextHandler(io.Discard, nil))
 }
 
-// OperationFunc defines the signature for calculator operations
-type OperationFunc func(a, b float64) (float64, error)
-
-// UnaryOperationFunc defines the signature for unary operations
-type UnaryOperationFunc func(a float64) (float64, error)
+// store returns a safe store (never nil). If Store is nil, an in-memory store is created on first use.
+func (s *Service) store() storage.Store {
+	s.storeOnce.Do(func() {
+		if s.Store == nil {
+			s.Store = storage.NewMemory()
+		}
+	})
+	return s.Store
+}
 
 // Add handles addition operation
 func (s *Service) Add(c *gin.Context) {
-	s.handleOperation(c, s.add)
+	s.handleQuantityOperation(c, core.Add, core.AddQuantities)
 }
 
 // AddGET handles addition operation via GET
 func (s *Service) AddGET(c *gin.Context) {
-	s.handleGetOperation(c, s.add)
+	s.handleGetQuantityOperation(c, core.Add, core.AddQuantities)
 }
 
 // Subtract handles subtraction operation
 func (s *Service) Subtract(c *gin.Context) {
-	s.handleOperation(c, s.subtract)
+	s.handleQuantityOperation(c, core.Subtract, core.SubtractQuantities)
 }
 
 // SubtractGET handles subtraction operation via GET
 func (s *Service) SubtractGET(c *gin.Context) {
-	s.handleGetOperation(c, s.subtract)
+	s.handleGetQuantityOperation(c, core.Subtract, core.SubtractQuantities)
 }
 
 // Multiply handles multiplication operation
 func (s *Service) Multiply(c *gin.Context) {
-	s.handleOperation(c, s.multiply)
+	s.handleQuantityOperation(c, core.Multiply, core.MultiplyQuantities)
 }
 
 // MultiplyGET handles multiplication operation via GET
 func (s *Service) MultiplyGET(c *gin.Context) {
-	s.handleGetOperation(c, s.multiply)
+	s.handleGetQuantityOperation(c, core.Multiply, core.MultiplyQuantities)
 }
 
 // Divide handles division operation
 func (s *Service) Divide(c *gin.Context) {
-	s.handleOperation(c, s.divide)
+	s.handleQuantityOperation(c, core.Divide, core.DivideQuantities)
 }
 
 // DivideGET handles division operation via GET
 func (s *Service) DivideGET(c *gin.Context) {
-	s.handleGetOperation(c, s.divide)
+	s.handleGetQuantityOperation(c, core.Divide, core.DivideQuantities)
 }
 
 // Percentage handles percentage operation
 func (s *Service) Percentage(c *gin.Context) {
-	s.handleOperation(c, s.percentage)
+	s.handleOperation(c, core.Percentage)
 }
 
 // PercentageGET handles percentage operation via GET
 func (s *Service) PercentageGET(c *gin.Context) {
-	s.handleGetOperation(c, s.percentage)
+	s.handleGetOperation(c, core.Percentage)
 }
 
 // Power handles power operation
 func (s *Service) Power(c *gin.Context) {
-	s.handleOperation(c, s.power)
+	s.handleOperation(c, core.Power)
 }
 
 // PowerGET handles power operation via GET
 func (s *Service) PowerGET(c *gin.Context) {
-	s.handleGetOperation(c, s.power)
+	s.handleGetOperation(c, core.Power)
 }
 
 // Sqrt handles square root operation
 func (s *Service) Sqrt(c *gin.Context) {
-	s.handleOperation(c, s.sqrt)
+	s.handleOperation(c, core.Sqrt)
 }
 
 // SqrtGET handles square root operation via GET
 func (s *Service) SqrtGET(c *gin.Context) {
-	s.handleGetOperation(c, s.sqrt)
+	s.handleGetOperation(c, core.Sqrt)
 }
 
 // Root handles nth root operation
 func (s *Service) Root(c *gin.Context) {
-	s.handleOperation(c, s.root)
+	s.handleOperation(c, core.Root)
 }
 
 // RootGET handles nth root operation via GET
 func (s *Service) RootGET(c *gin.Context) {
-	s.handleGetOperation(c, s.root)
+	s.handleGetOperation(c, core.Root)
 }
 
 // Inverse handles inverse operation
 func (s *Service) Inverse(c *gin.Context) {
-	s.handleUnaryOperation(c, s.inverse)
+	s.handleUnaryOperation(c, core.Inverse)
 }
 
 // InverseGET handles inverse operation via GET
 func (s *Service) InverseGET(c *gin.Context) {
-	s.handleGetUnaryOperation(c, s.inverse)
+	s.handleGetUnaryOperation(c, core.Inverse)
 }
 
 // Negative handles negative operation
 func (s *Service) Negative(c *gin.Context) {
-	s.handleUnaryOperation(c, s.negative)
+	s.handleUnaryOperation(c, core.Negative)
 }
 
 // NegativeGET handles negative operation via GET
 func (s *Service) NegativeGET(c *gin.Context) {
-	s.handleGetUnaryOperation(c, s.negative)
+	s.handleGetUnaryOperation(c, core.Negative)
 }
 
 // handleOperation handles the common logic for all operations via POST
-func (s *Service) handleOperation(c *gin.Context, op OperationFunc) {
+func (s *Service) handleOperation(c *gin.Context, op core.BinaryFunc) {
+	s.handleQuantityOperation(c, op, nil)
+}
+
+// handleQuantityOperation handles the common logic for operations via POST that may take units.
+// qop is used when either operand has a unit; a nil qop rejects units.
+func (s *Service) handleQuantityOperation(c *gin.Context, op core.BinaryFunc, qop QuantityOperationFunc) {
 	var req Request
 	if err := c.ShouldBindJSON(&req); err != nil {
 		s.logger().Error("Failed to bind JSON request", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		s.respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	s.logger().Info("Processing binary operation request", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", req.A, "b", req.B)
 
-	result, err := op(req.A, req.B)
+	sc := scopeFromContext(c)
+	a, err := s.resolveOperand(sc, "a", req.A)
+	if err != nil {
+		s.logger().Error("Failed to resolve parameter 'a'", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", req.A, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	b, err := s.resolveOperand(sc, "b", req.B)
 	if err != nil {
-		s.logger().Error("Binary operation failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", req.A, "b", req.B, "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		s.logger().Error("Failed to resolve parameter 'b'", "operation", c.Request.URL.Path, "method", c.Request.Method, "b", req.B, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	s.logger().Info("Binary operation successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", req.A, "b", req.B, "result", result)
-	c.JSON(http.StatusOK, Response{Result: result})
+	if req.AUnit != "" || req.BUnit != "" {
+		s.applyQuantityOperation(c, a, req.AUnit, b, req.BUnit, qop)
+		return
+	}
+
+	result, err := cached(s, c, cacheKey(cacheModeFloat, c.Request.URL.Path, a, b), fixedSize, func() (float64, error) {
+		return op(s.withLogger(c.Request.Context()), a, b)
+	})
+	if err != nil {
+		s.logger().Error("Binary operation failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", a, "b", b, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Binary operation successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", a, "b", b, "result", result)
+	if s.respondResult(c, Response{Result: result}) {
+		s.recordRequestHistory(c, []float64{a, b}, result, "")
+	}
 }
 
 // handleGetOperation handles the common logic for all operations via GET
-func (s *Service) handleGetOperation(c *gin.Context, op OperationFunc) {
+func (s *Service) handleGetOperation(c *gin.Context, op core.BinaryFunc) {
+	s.handleGetQuantityOperation(c, op, nil)
+}
+
+// handleGetQuantityOperation handles the common logic for operations via GET that may take units
+func (s *Service) handleGetQuantityOperation(c *gin.Context, op core.BinaryFunc, qop QuantityOperationFunc) {
 	aStr := c.Query("a")
 	bStr := c.Query("b")
 
 	s.logger().Info("Processing binary operation GET request", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", aStr, "b", bStr)
 
-	a, err := strconv.ParseFloat(aStr, 64)
+	a, err := s.parseQueryOperand(c, "a")
 	if err != nil {
 		s.logger().Error("Failed to parse parameter 'a'", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", aStr, "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid value for parameter 'a'"})
+		s.respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	b, err := strconv.ParseFloat(bStr, 64)
+	b, err := s.parseQueryOperand(c, "b")
 	if err != nil {
 		s.logger().Error("Failed to parse parameter 'b'", "operation", c.Request.URL.Path, "method", c.Request.Method, "b", bStr, "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid value for parameter 'b'"})
+		s.respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	s.logger().Debug("Parsed binary operation GET parameters", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", a, "b", b)
 
-	result, err := op(a, b)
+	setCacheControl(c, "a", "b")
+	if aUnit, bUnit := c.Query("a_unit"), c.Query("b_unit"); aUnit != "" || bUnit != "" {
+		s.applyQuantityOperation(c, a, aUnit, b, bUnit, qop)
+		return
+	}
+
+	result, err := cached(s, c, cacheKey(cacheModeFloat, c.Request.URL.Path, a, b), fixedSize, func() (float64, error) {
+		return op(s.withLogger(c.Request.Context()), a, b)
+	})
 	if err != nil {
 		s.logger().Error("Binary operation GET failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", a, "b", b, "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		s.respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	s.logger().Info("Binary operation GET successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", a, "b", b, "result", result)
-	c.JSON(http.StatusOK, Response{Result: result})
+	if s.respondResult(c, Response{Result: result}) {
+		s.recordRequestHistory(c, []float64{a, b}, result, "")
+	}
+}
+
+// applyQuantityOperation runs qop on the dimensioned operands and writes the response
+func (s *Service) applyQuantityOperation(c *gin.Context, aValue float64, aUnit string, bValue float64, bUnit string, qop QuantityOperationFunc) {
+	s.logger().Info("Processing dimensioned operation request", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", aValue, "a_unit", aUnit, "b", bValue, "b_unit", bUnit)
+
+	if qop == nil {
+		s.logger().Error("Units not supported by operation", "operation", c.Request.URL.Path, "method", c.Request.Method)
+		s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, "operation does not support units"))
+		return
+	}
+
+	a, b, err := quantityOperands(aValue, aUnit, bValue, bUnit)
+	if err != nil {
+		s.logger().Error("Failed to resolve operand units", "operation", c.Request.URL.Path, "method", c.Request.Method, "a_unit", aUnit, "b_unit", bUnit, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	key := cacheKey(cacheModeFloat, c.Request.URL.Path, aValue, aUnit, bValue, bUnit)
+	result, err := cached(s, c, key, func(q Quantity) int64 { return int64(len(q.Unit.Symbol) + len(q.Unit.Name)) }, func() (Quantity, error) {
+		return qop(s.withLogger(c.Request.Context()), a, b)
+	})
+	if err != nil {
+		s.logger().Error("Dimensioned operation failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", aValue, "a_unit", aUnit, "b", bValue, "b_unit", bUnit, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Dimensioned operation successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "result", result.Value, "unit", result.Unit.Symbol)
+	if s.respondResult(c, Response{Result: result.Value, Unit: result.Unit.Symbol}) {
+		s.recordRequestHistory(c, []float64{aValue, bValue}, result.Value, result.Unit.Symbol)
+	}
 }
 
 // handleUnaryOperation handles the common logic for unary operations via POST
-func (s *Service) handleUnaryOperation(c *gin.Context, op UnaryOperationFunc) {
+func (s *Service) handleUnaryOperation(c *gin.Context, op core.UnaryFunc) {
 	var req UnaryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		s.logger().Error("Failed to bind unary JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		s.respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	s.logger().Info("Processing unary operation request", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", req.A)
 
-	result, err := op(req.A)
+	sc := scopeFromContext(c)
+	a, err := s.resolveOperand(sc, "a", req.A)
 	if err != nil {
-		s.logger().Error("Unary operation failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", req.A, "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		s.logger().Error("Failed to resolve unary parameter 'a'", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", req.A, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	s.logger().Info("Unary operation successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", req.A, "result", result)
-	c.JSON(http.StatusOK, Response{Result: result})
+	result, err := cached(s, c, cacheKey(cacheModeFloat, c.Request.URL.Path, a), fixedSize, func() (float64, error) {
+		return op(s.withLogger(c.Request.Context()), a)
+	})
+	if err != nil {
+		s.logger().Error("Unary operation failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", a, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Unary operation successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", a, "result", result)
+	if s.respondResult(c, Response{Result: result}) {
+		s.recordRequestHistory(c, []float64{a}, result, "")
+	}
 }
 
 // handleGetUnaryOperation handles the common logic for unary operations via GET
-func (s *Service) handleGetUnaryOperation(c *gin.Context, op UnaryOperationFunc) {
+func (s *Service) handleGetUnaryOperation(c *gin.Context, op core.UnaryFunc) {
 	aStr := c.Query("a")
 
 	s.logger().Info("Processing unary operation GET request", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", aStr)
 
-	a, err := strconv.ParseFloat(aStr, 64)
+	a, err := s.parseQueryOperand(c, "a")
 	if err != nil {
 		s.logger().Error("Failed to parse unary parameter 'a'", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", aStr, "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid value for parameter 'a'"})
+		s.respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	s.logger().Debug("Parsed unary operation GET parameter", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", a)
 
-	result, err := op(a)
+	setCacheControl(c, "a")
+	result, err := cached(s, c, cacheKey(cacheModeFloat, c.Request.URL.Path, a), fixedSize, func() (float64, error) {
+		return op(s.withLogger(c.Request.Context()), a)
+	})
 	if err != nil {
 		s.logger().Error("Unary operation GET failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", a, "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		s.respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	s.logger().Info("Unary operation GET successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "a", a, "result", result)
-	c.JSON(http.StatusOK, Response{Result: result})
-}
-
-// Core operation implementations
-func (s *Service) add(a, b float64) (float64, error) {
-	s.logger().Debug("Performing addition", "a", a, "b", b)
-	result := a + b
-	s.logger().Debug("Addition result", "result", result)
-	return result, nil
-}
-
-func (s *Service) subtract(a, b float64) (float64, error) {
-	s.logger().Debug("Performing subtraction", "a", a, "b", b)
-	result := a - b
-	s.logger().Debug("Subtraction result", "result", result)
-	return result, nil
-}
-
-func (s *Service) multiply(a, b float64) (float64, error) {
-	s.logger().Debug("Performing multiplication", "a", a, "b", b)
-	result := a * b
-	s.logger().Debug("Multiplication result", "result", result)
-	return result, nil
-}
-
-func (s *Service) divide(a, b float64) (float64, error) {
-	s.logger().Debug("Performing division", "a", a, "b", b)
-	if b == 0 {
-		s.logger().Error("Division by zero attempted", "a", a, "b", b)
-		return 0, errors.New("cannot divide by zero")
+	if s.respondResult(c, Response{Result: result}) {
+		s.recordRequestHistory(c, []float64{a}, result, "")
 	}
-	result := a / b
-	s.logger().Debug("Division result", "result", result)
-	return result, nil
-}
-
-func (s *Service) percentage(a, b float64) (float64, error) {
-	s.logger().Debug("Performing percentage", "a", a, "b", b)
-	result := a * (b / 100)
-	s.logger().Debug("Percentage result", "result", result)
-	return result, nil
-}
-
-func (s *Service) power(a, b float64) (float64, error) {
-	s.logger().Debug("Performing power operation", "a", a, "b", b)
-	result := math.Pow(a, b)
-	s.logger().Debug("Power result", "result", result)
-	return result, nil
-}
-
-func (s *Service) sqrt(a, b float64) (float64, error) {
-	s.logger().Debug("Performing square root", "a", a)
-	if a < 0 {
-		s.logger().Error("Square root of negative number attempted", "a", a)
-		return 0, errors.New("cannot calculate square root of negative number")
-	}
-	result := math.Sqrt(a)
-	s.logger().Debug("Square root result", "result", result)
-	return result, nil
-}
-
-func (s *Service) root(a, b float64) (float64, error) {
-	s.logger().Debug("Performing nth root", "a", a, "b", b)
-	if b == 0 {
-		s.logger().Error("Zeroth root attempted", "a", a, "b", b)
-		return 0, errors.New("cannot calculate 0th root")
-	}
-	if a < 0 {
-		// Check if the root is odd (can handle negative numbers)
-		if math.Mod(b, 2) == 1 {
-			// Odd root of negative number
-			s.logger().Debug("Performing odd root of negative number", "a", a, "b", b)
-			result := -math.Pow(-a, 1/b)
-			s.logger().Debug("Odd root of negative result", "result", result)
-			return result, nil
-		}
-		s.logger().Error("Even root of negative number attempted", "a", a, "b", b)
-		return 0, errors.New("cannot calculate even root of negative number")
-	}
-	result := math.Pow(a, 1/b)
-	s.logger().Debug("Nth root result", "result", result)
-	return result, nil
-}
-
-func (s *Service) inverse(a float64) (float64, error) {
-	s.logger().Debug("Performing inverse", "a", a)
-	if a == 0 {
-		s.logger().Error("Inverse of zero attempted", "a", a)
-		return 0, errors.New("cannot calculate inverse of zero")
-	}
-	result := 1 / a
-	s.logger().Debug("Inverse result", "result", result)
-	return result, nil
-}
-
-func (s *Service) negative(a float64) (float64, error) {
-	s.logger().Debug("Performing negation", "a", a)
-	result := -a
-	s.logger().Debug("Negation result", "result", result)
-	return result, nil
 }
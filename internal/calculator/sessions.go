@@ -0,0 +1,391 @@
+package calculator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"calculator/core"
+	"calculator/internal/access"
+	"calculator/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultRegister is the memory register used when a memory request names none
+const defaultRegister = "M"
+
+// SessionOperationRequest represents an operation applied to a session.
+// A defaults to the session accumulator; B is required by binary operations other than sqrt.
+type SessionOperationRequest struct {
+	A *Operand `json:"a"`
+	B *Operand `json:"b"`
+}
+
+// SessionOperationResponse represents the result of a session operation and the updated session
+type SessionOperationResponse struct {
+	Result  float64         `json:"result"`
+	Session storage.Session `json:"session"`
+}
+
+// MemoryRequest represents a memory register action: MC, MR, M+, M- or MS.
+// Value defaults to the session accumulator for M+, M- and MS.
+type MemoryRequest struct {
+	Action   string   `json:"action" binding:"required"`
+	Register string   `json:"register"`
+	Value    *Operand `json:"value"`
+}
+
+// AccumulatorRequest represents a request to set the session accumulator
+type AccumulatorRequest struct {
+	Value Operand `json:"value"`
+}
+
+// newSessionID returns a random, URL-safe session id
+func newSessionID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// sessionNotFound returns the error reported for unknown session ids
+func sessionNotFound(id string) *Error {
+	return newError(CodeNotFound, fmt.Sprintf("session '%s' not found", id)).WithDetail("session", id)
+}
+
+// tenantSession returns the session with the given id if it belongs to tenantID
+func (s *Service) tenantSession(tenantID, id string) (storage.Session, bool) {
+	session, ok := s.store().Session(id)
+	if !ok || !sameTenant(session.Tenant, tenantID) {
+		return storage.Session{}, false
+	}
+	return session, true
+}
+
+// updateTenantSession applies update to the session with the given id, returning storage.ErrNotFound
+// unless it belongs to tenantID
+func (s *Service) updateTenantSession(tenantID, id string, update func(*storage.Session) error) (storage.Session, error) {
+	return s.store().UpdateSession(id, func(session *storage.Session) error {
+		if !sameTenant(session.Tenant, tenantID) {
+			return storage.ErrNotFound
+		}
+		return update(session)
+	})
+}
+
+// resolveAns returns the last answer of the session in sc
+func (s *Service) resolveAns(sc scope, param string) (float64, error) {
+	if sc.session != "" {
+		if session, ok := s.tenantSession(sc.tenant, sc.session); ok {
+			return session.Ans, nil
+		}
+	}
+	s.logger().Error("Last answer referenced without session", "param", param, "session", sc.session)
+	return 0, newError(CodeUndefinedVariable, fmt.Sprintf("'ans' requires a valid %s header", SessionHeader)).
+		WithDetail("param", param).
+		WithDetail("name", "ans")
+}
+
+// resolveOptionalOperand resolves o within sc, returning nil when o was not supplied
+func (s *Service) resolveOptionalOperand(sc scope, param string, o *Operand) (*float64, error) {
+	if o == nil {
+		return nil, nil
+	}
+	value, err := s.resolveOperand(sc, param, *o)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// recordAnswer stores result as the last answer of the caller's session, if any
+func (s *Service) recordAnswer(c *gin.Context, result float64) {
+	s.recordSessionAnswer(tenantFromContext(c), c.GetHeader(SessionHeader), result)
+}
+
+// recordSessionAnswer stores result as the last answer of session id of tenantID; an empty id and
+// results that are not finite, like those left out of the history, are ignored
+func (s *Service) recordSessionAnswer(tenantID, id string, result float64) {
+	if id == "" || math.IsNaN(result) || math.IsInf(result, 0) {
+		return
+	}
+	_, err := s.updateTenantSession(tenantID, id, func(session *storage.Session) error {
+		session.Ans = result
+		session.AnsExact = ""
+		return nil
+	})
+	if err != nil {
+		s.logger().Debug("Answer not recorded", "session", id, "error", err)
+	}
+}
+
+// sessionScope returns the scope for operands of a request targeting session id
+func sessionScope(c *gin.Context, id string) scope {
+	sc := scopeFromContext(c)
+	sc.session = id
+	if sc.owner == "" {
+		sc.owner = qualify(sc.tenant, "session:"+id)
+	}
+	return sc
+}
+
+// CreateSession handles creating a new calculator session
+func (s *Service) CreateSession(c *gin.Context) {
+	now := time.Now()
+	session := storage.Session{
+		ID:        newSessionID(),
+		Tenant:    tenantFromContext(c),
+		Memory:    make(map[string]float64),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.store().CreateSession(session)
+
+	s.logger().Info("Session created", "operation", c.Request.URL.Path, "method", c.Request.Method, "session", session.ID)
+	c.JSON(http.StatusCreated, session)
+}
+
+// GetSession handles reading a session's state
+func (s *Service) GetSession(c *gin.Context) {
+	id := c.Param("id")
+	session, ok := s.tenantSession(tenantFromContext(c), id)
+	if !ok {
+		s.logger().Error("Session not found", "operation", c.Request.URL.Path, "method", c.Request.Method, "session", id)
+		s.respondError(c, http.StatusNotFound, sessionNotFound(id))
+		return
+	}
+	s.respondJSON(c, session)
+}
+
+// DeleteSession handles deleting a session
+func (s *Service) DeleteSession(c *gin.Context) {
+	id := c.Param("id")
+	if _, ok := s.tenantSession(tenantFromContext(c), id); !ok || !s.store().DeleteSession(id) {
+		s.logger().Error("Session not found", "operation", c.Request.URL.Path, "method", c.Request.Method, "session", id)
+		s.respondError(c, http.StatusNotFound, sessionNotFound(id))
+		return
+	}
+	s.logger().Info("Session deleted", "operation", c.Request.URL.Path, "method", c.Request.Method, "session", id)
+	c.Status(http.StatusNoContent)
+}
+
+// ClearSession handles clearing the session accumulator (AC); memory and ANS are kept
+func (s *Service) ClearSession(c *gin.Context) {
+	s.updateSession(c, func(session *storage.Session) error {
+		session.Accumulator = 0
+		return nil
+	})
+}
+
+// SetAccumulator handles setting the session accumulator to a value
+func (s *Service) SetAccumulator(c *gin.Context) {
+	id := c.Param("id")
+	var req AccumulatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind accumulator JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	value, err := s.resolveOperand(sessionScope(c, id), "value", req.Value)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	s.updateSession(c, func(session *storage.Session) error {
+		session.Accumulator = value
+		return nil
+	})
+}
+
+// SessionMemory handles the memory register actions MC, MR, M+, M- and MS
+func (s *Service) SessionMemory(c *gin.Context) {
+	id := c.Param("id")
+	var req MemoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind memory JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	register := req.Register
+	if register == "" {
+		register = defaultRegister
+	}
+	if !identifierPattern.MatchString(register) {
+		s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, fmt.Sprintf("invalid memory register '%s'", register)).WithDetail("register", register))
+		return
+	}
+
+	value, err := s.resolveOptionalOperand(sessionScope(c, id), "value", req.Value)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing memory request", "operation", c.Request.URL.Path, "method", c.Request.Method, "session", id, "action", req.Action, "register", register)
+
+	s.updateSession(c, func(session *storage.Session) error {
+		operand := session.Accumulator
+		if value != nil {
+			operand = *value
+		}
+		switch strings.ToUpper(req.Action) {
+		case "MC":
+			delete(session.Memory, register)
+		case "MR":
+			session.Accumulator = session.Memory[register]
+		case "M+", "M-":
+			if strings.ToUpper(req.Action) == "M-" {
+				operand = -operand
+			}
+			// An overflowing register would leave the session unreadable as JSON
+			sum := session.Memory[register] + operand
+			if err := checkFinite(sum); err != nil {
+				return err
+			}
+			session.Memory[register] = sum
+		case "MS":
+			session.Memory[register] = operand
+		default:
+			return newError(CodeInvalidInput, fmt.Sprintf("unknown memory action '%s'", req.Action)).
+				WithDetail("action", req.Action).
+				WithDetail("allowed", []string{"MC", "MR", "M+", "M-", "MS"})
+		}
+		return nil
+	})
+}
+
+// SessionOperation handles applying an operation to the session accumulator.
+// The result becomes both the new accumulator and the session's ANS.
+func (s *Service) SessionOperation(c *gin.Context) {
+	id := c.Param("id")
+	name := c.Param("operation")
+
+	// An empty body applies the operation to the accumulator alone
+	var req SessionOperationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.logger().Error("Failed to bind session operation JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing session operation request", "operation", c.Request.URL.Path, "method", c.Request.Method, "session", id, "name", name)
+
+	result, session, err := s.applySessionOperation(c.Request.Context(), sessionScope(c, id), name, req)
+	if err != nil {
+		s.logger().Error("Session operation failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "session", id, "name", name, "error", err)
+		status := http.StatusBadRequest
+		switch calcErr := toError(err); calcErr.Code {
+		case CodeNotFound, CodeUnknownOperation:
+			status = http.StatusNotFound
+		case CodeUnauthorized, CodeForbidden:
+			status = deniedStatus(calcErr)
+		}
+		s.respondError(c, status, err)
+		return
+	}
+
+	s.logger().Info("Session operation successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "session", id, "name", name, "result", result)
+	s.respondJSON(c, SessionOperationResponse{Result: result, Session: session})
+}
+
+// applySessionOperation applies the named operation to the accumulator of the session in sc, making the
+// result both the new accumulator and the session's ANS. It returns the result and the updated session.
+func (s *Service) applySessionOperation(ctx context.Context, sc scope, name string, req SessionOperationRequest) (float64, storage.Session, error) {
+	id := sc.session
+	binary, isBinary := core.BinaryOperation(name)
+	unary, isUnary := core.UnaryOperation(name)
+	if !isBinary && !isUnary {
+		// User-defined functions of one or two parameters act as unary or binary operations; calling
+		// them needs the compute permission, as on /functions/:name/call
+		binary, unary = s.functionOperations(sc.owner, name)
+		isBinary, isUnary = binary != nil, unary != nil
+		if isBinary || isUnary {
+			if err := s.authorize(sc.principal, access.PermissionCompute); err != nil {
+				s.logger().Error("Permission denied", "session", id, "name", name, "permission", access.PermissionCompute, "code", err.Code)
+				s.auditDenied(sc.owner, "sessions/"+id+"/operations/"+name, err)
+				return 0, storage.Session{}, err
+			}
+		}
+	}
+	if !isBinary && !isUnary {
+		return 0, storage.Session{}, core.UnknownOperationError(name)
+	}
+
+	a, err := s.resolveOptionalOperand(sc, "a", req.A)
+	if err != nil {
+		return 0, storage.Session{}, err
+	}
+	b, err := s.resolveOptionalOperand(sc, "b", req.B)
+	if err != nil {
+		return 0, storage.Session{}, err
+	}
+	if isBinary && b == nil && name != "sqrt" {
+		return 0, storage.Session{}, newError(CodeInvalidInput, "invalid value for parameter 'b'").WithDetail("param", "b")
+	}
+
+	ctx = s.withLogger(ctx)
+	var result float64
+	session, err := s.updateTenantSession(sc.tenant, id, func(session *storage.Session) error {
+		left := session.Accumulator
+		if a != nil {
+			left = *a
+		}
+		var err error
+		if isBinary {
+			right := 0.0
+			if b != nil {
+				right = *b
+			}
+			result, err = binary(ctx, left, right)
+		} else {
+			result, err = unary(ctx, left)
+		}
+		if err == nil {
+			// Checked before the session changes, which would otherwise be unreadable as JSON
+			err = checkFinite(result)
+		}
+		if err != nil {
+			return err
+		}
+		session.Accumulator = result
+		session.Ans = result
+		session.AnsExact = ""
+		return nil
+	})
+	if errors.Is(err, storage.ErrNotFound) {
+		return 0, storage.Session{}, sessionNotFound(id)
+	}
+	if err != nil {
+		return 0, storage.Session{}, err
+	}
+	return result, session, nil
+}
+
+// updateSession applies update to the session named in the path and writes the updated session
+func (s *Service) updateSession(c *gin.Context, update func(*storage.Session) error) {
+	id := c.Param("id")
+	session, err := s.updateTenantSession(tenantFromContext(c), id, update)
+	if err != nil {
+		s.respondSessionError(c, id, err)
+		return
+	}
+	s.logger().Info("Session updated", "operation", c.Request.URL.Path, "method", c.Request.Method, "session", id)
+	s.respondJSON(c, session)
+}
+
+// respondSessionError writes a session update error, mapping storage.ErrNotFound to 404
+func (s *Service) respondSessionError(c *gin.Context, id string, err error) {
+	s.logger().Error("Session update failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "session", id, "error", err)
+	if errors.Is(err, storage.ErrNotFound) {
+		s.respondError(c, http.StatusNotFound, sessionNotFound(id))
+		return
+	}
+	s.respondError(c, http.StatusBadRequest, err)
+}
@@ -0,0 +1,191 @@
+package calculator
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"calculator/internal/storage"
+	"calculator/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeSession(t *testing.T, w *httptest.ResponseRecorder) storage.Session {
+	var session storage.Session
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+	return session
+}
+
+// TestSessionLifecycle tests creating, reading and deleting sessions
+func TestSessionLifecycle(t *testing.T) {
+	s := &Service{}
+
+	c, w := setupTestContext("POST", "/sessions", nil)
+	s.CreateSession(c)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	created := decodeSession(t, w)
+	assert.Len(t, created.ID, 32)
+
+	c, w = setupTestContext("GET", "/sessions/"+created.ID, nil)
+	s.GetSession(withParam(c, "id", created.ID))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, created.ID, decodeSession(t, w).ID)
+
+	c, _ = setupTestContext("DELETE", "/sessions/"+created.ID, nil)
+	s.DeleteSession(withParam(c, "id", created.ID))
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+
+	c, w = setupTestContext("GET", "/sessions/"+created.ID, nil)
+	s.GetSession(withParam(c, "id", created.ID))
+	assertErrorCode(t, w, http.StatusNotFound, CodeNotFound)
+}
+
+// TestSessionOperations tests chaining operations on the session accumulator
+func TestSessionOperations(t *testing.T) {
+	s := &Service{}
+	c, w := setupTestContext("POST", "/sessions", nil)
+	s.CreateSession(c)
+	id := decodeSession(t, w).ID
+
+	operate := func(operation string, body map[string]any) *httptest.ResponseRecorder {
+		c, w := setupTestContext("POST", "/sessions/"+id+"/operations/"+operation, body)
+		s.SessionOperation(withParam(withParam(c, "id", id), "operation", operation))
+		return w
+	}
+
+	c, w = setupTestContext("PUT", "/sessions/"+id+"/accumulator", map[string]any{"value": 5})
+	s.SetAccumulator(withParam(c, "id", id))
+	assert.Equal(t, 5.0, decodeSession(t, w).Accumulator)
+
+	w = operate("add", map[string]any{"b": 3})
+	assertResponse(t, w, http.StatusOK, 8, "")
+
+	w = operate("multiply", map[string]any{"b": "ans"})
+	assertResponse(t, w, http.StatusOK, 64, "")
+
+	w = operate("sqrt", nil)
+	assertResponse(t, w, http.StatusOK, 8, "")
+
+	w = operate("negative", nil)
+	var response SessionOperationResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, -8.0, response.Session.Accumulator)
+	assert.Equal(t, -8.0, response.Session.Ans)
+
+	w = operate("divide", map[string]any{"b": 0})
+	assertErrorCode(t, w, http.StatusBadRequest, CodeDivisionByZero)
+
+	w = operate("add", nil)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+
+	w = operate("modulo", map[string]any{"b": 1})
+	assertErrorCode(t, w, http.StatusNotFound, CodeUnknownOperation)
+
+	// The accumulator is unchanged by the failed operations
+	c, w = setupTestContext("GET", "/sessions/"+id, nil)
+	s.GetSession(withParam(c, "id", id))
+	assert.Equal(t, -8.0, decodeSession(t, w).Accumulator)
+
+	// Plain operation endpoints record ANS for the session in the header
+	c, w = setupTestContext("POST", "/add", map[string]any{"a": "ans", "b": 10})
+	c.Request.Header.Set(SessionHeader, id)
+	s.Add(c)
+	assertResponse(t, w, http.StatusOK, 2, "")
+
+	c, w = setupTestContext("GET", "/sessions/"+id, nil)
+	s.GetSession(withParam(c, "id", id))
+	assert.Equal(t, 2.0, decodeSession(t, w).Ans)
+}
+
+// TestSessionOverflow tests that a session operation overflowing float64 is a DOMAIN_ERROR
+func TestSessionOverflow(t *testing.T) {
+	s := &Service{}
+	c, w := setupTestContext("POST", "/sessions", nil)
+	s.CreateSession(c)
+	id := decodeSession(t, w).ID
+
+	c, w = setupTestContext("PUT", "/sessions/"+id+"/accumulator", map[string]any{"value": 1e308})
+	s.SetAccumulator(withParam(c, "id", id))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	c, w = setupTestContext("POST", "/sessions/"+id+"/operations/multiply", map[string]any{"b": 10})
+	s.SessionOperation(withParam(withParam(c, "id", id), "operation", "multiply"))
+	assertErrorCode(t, w, http.StatusBadRequest, CodeDomainError)
+
+	for _, body := range []map[string]any{{"action": "M+"}, {"action": "M+"}, {"action": "M-", "value": -1e308}} {
+		c, w = setupTestContext("POST", "/sessions/"+id+"/memory", body)
+		s.SessionMemory(withParam(c, "id", id))
+	}
+	assertErrorCode(t, w, http.StatusBadRequest, CodeDomainError)
+
+	// Plain operations naming the session leave its ANS unchanged
+	c, w = setupTestContext("POST", "/add", map[string]any{"a": 1, "b": 2})
+	c.Request.Header.Set(SessionHeader, id)
+	s.Add(c)
+	c, w = setupTestContext("POST", "/multiply", map[string]any{"a": "ans", "b": 1e308})
+	c.Request.Header.Set(SessionHeader, id)
+	s.Multiply(c)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeDomainError)
+	s.recordSessionAnswer(tenant.DefaultID, id, math.Inf(1))
+
+	// The session is unchanged by the failed operations and stays readable
+	c, w = setupTestContext("GET", "/sessions/"+id, nil)
+	s.GetSession(withParam(c, "id", id))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	session := decodeSession(t, w)
+	assert.Equal(t, 1e308, session.Accumulator)
+	assert.Equal(t, 3.0, session.Ans)
+	assert.Equal(t, 1e308, session.Memory["M"])
+}
+
+// TestSessionMemory tests the memory register actions
+func TestSessionMemory(t *testing.T) {
+	s := &Service{}
+	c, w := setupTestContext("POST", "/sessions", nil)
+	s.CreateSession(c)
+	id := decodeSession(t, w).ID
+
+	memory := func(body map[string]any) *httptest.ResponseRecorder {
+		c, w := setupTestContext("POST", "/sessions/"+id+"/memory", body)
+		s.SessionMemory(withParam(c, "id", id))
+		return w
+	}
+
+	c, _ = setupTestContext("PUT", "/sessions/"+id+"/accumulator", map[string]any{"value": 10})
+	s.SetAccumulator(withParam(c, "id", id))
+
+	w = memory(map[string]any{"action": "M+"})
+	assert.Equal(t, 10.0, decodeSession(t, w).Memory["M"])
+
+	w = memory(map[string]any{"action": "m+", "value": 5})
+	assert.Equal(t, 15.0, decodeSession(t, w).Memory["M"])
+
+	w = memory(map[string]any{"action": "M-", "value": "pi"})
+	assert.InDelta(t, 11.8584, decodeSession(t, w).Memory["M"], 0.0001)
+
+	w = memory(map[string]any{"action": "MS", "register": "R1", "value": 42})
+	assert.Equal(t, 42.0, decodeSession(t, w).Memory["R1"])
+
+	w = memory(map[string]any{"action": "MR", "register": "R1"})
+	assert.Equal(t, 42.0, decodeSession(t, w).Accumulator)
+
+	w = memory(map[string]any{"action": "MC"})
+	session := decodeSession(t, w)
+	assert.NotContains(t, session.Memory, "M")
+	assert.Contains(t, session.Memory, "R1")
+
+	w = memory(map[string]any{"action": "M*"})
+	assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+
+	c, w = setupTestContext("POST", "/sessions/missing/memory", map[string]any{"action": "MC"})
+	s.SessionMemory(withParam(c, "id", "missing"))
+	assertErrorCode(t, w, http.StatusNotFound, CodeNotFound)
+
+	c, w = setupTestContext("POST", "/sessions/"+id+"/clear", nil)
+	s.ClearSession(withParam(c, "id", id))
+	assert.Equal(t, 0.0, decodeSession(t, w).Accumulator)
+}
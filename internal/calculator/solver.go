@@ -0,0 +1,92 @@
+package calculator
+
+import (
+	"fmt"
+	"net/http"
+
+	"calculator/core"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Solution counts reported by the solvers
+const (
+	SolutionsUnique   = core.SolutionsUnique
+	SolutionsFinite   = core.SolutionsFinite
+	SolutionsNone     = core.SolutionsNone
+	SolutionsInfinite = core.SolutionsInfinite
+)
+
+// PolynomialRoot is a root of a polynomial with its multiplicity
+type PolynomialRoot = core.PolynomialRoot
+
+// PolynomialRequest represents a polynomial given by its coefficients from the highest degree down,
+// e.g. [1, -3, 2] for x^2 - 3x + 2
+type PolynomialRequest struct {
+	Coefficients []Operand `json:"coefficients" binding:"required"`
+}
+
+// PolynomialResponse represents the distinct roots of a polynomial with their multiplicities
+type PolynomialResponse = core.PolynomialSolution
+
+// LinearSystemRequest represents a system of linear equations such as ["2x + y = 3", "x - y = 0"]
+type LinearSystemRequest struct {
+	Equations []string `json:"equations" binding:"required"`
+}
+
+// LinearSystemResponse represents the solution of a linear system
+type LinearSystemResponse = core.LinearSystemSolution
+
+// SolvePolynomial handles finding all roots of a polynomial
+func (s *Service) SolvePolynomial(c *gin.Context) {
+	var req PolynomialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind polynomial JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	sc := scopeFromContext(c)
+	var coefficients []float64
+	for i, o := range req.Coefficients {
+		value, err := s.resolveOperand(sc, fmt.Sprintf("coefficients[%d]", i), o)
+		if err != nil {
+			s.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		coefficients = append(coefficients, value)
+	}
+
+	s.logger().Info("Processing polynomial request", "operation", c.Request.URL.Path, "method", c.Request.Method, "coefficients", coefficients)
+
+	response, err := core.SolvePolynomial(s.withLogger(c.Request.Context()), coefficients)
+	if err != nil {
+		s.logger().Error("Polynomial roots failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Polynomial solved", "operation", c.Request.URL.Path, "method", c.Request.Method, "degree", response.Degree, "solutions", response.Solutions)
+	s.respondJSON(c, response)
+}
+
+// SolveLinearSystem handles solving a system of linear equations in named variables
+func (s *Service) SolveLinearSystem(c *gin.Context) {
+	var req LinearSystemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind linear system JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing linear system request", "operation", c.Request.URL.Path, "method", c.Request.Method, "equations", req.Equations)
+
+	response, err := core.SolveLinearSystem(s.withLogger(c.Request.Context()), req.Equations)
+	if err != nil {
+		s.logger().Error("Linear system failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Linear system solved", "operation", c.Request.URL.Path, "method", c.Request.Method, "solutions", response.Solutions, "rank", response.Rank)
+	s.respondJSON(c, response)
+}
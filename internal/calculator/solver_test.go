@@ -0,0 +1,122 @@
+package calculator
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSolvePolynomial tests the polynomial solver handler
+func TestSolvePolynomial(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      any
+		solutions string
+		degree    int
+		roots     int
+	}{
+		{"quadratic", map[string]any{"coefficients": []any{1, 0, -2}}, SolutionsFinite, 2, 2},
+		{"leading zeros", map[string]any{"coefficients": []any{0, 0, 1, -1}}, SolutionsFinite, 1, 1},
+		{"constant operand", map[string]any{"coefficients": []any{"pi", 1}}, SolutionsFinite, 1, 1},
+		{"nonzero constant", map[string]any{"coefficients": []any{0, 5}}, SolutionsNone, 0, 0},
+		{"zero polynomial", map[string]any{"coefficients": []any{0, 0}}, SolutionsInfinite, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestContext("POST", "/solve/polynomial", tt.body)
+			(&Service{}).SolvePolynomial(c)
+			require.Equal(t, http.StatusOK, w.Code)
+			var response PolynomialResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.solutions, response.Solutions)
+			assert.Equal(t, tt.degree, response.Degree)
+			assert.Len(t, response.Roots, tt.roots)
+		})
+	}
+
+	t.Run("missing coefficients", func(t *testing.T) {
+		c, w := setupTestContext("POST", "/solve/polynomial", map[string]any{})
+		(&Service{}).SolvePolynomial(c)
+		assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+	})
+
+	t.Run("roots overflow", func(t *testing.T) {
+		c, w := setupTestContext("POST", "/solve/polynomial", map[string]any{"coefficients": []any{1e-300, 0, 1e300}})
+		(&Service{}).SolvePolynomial(c)
+		assertErrorCode(t, w, http.StatusBadRequest, CodeDomainError)
+	})
+}
+
+// TestSolveLinearSystem tests unique, inconsistent, underdetermined and nonlinear systems
+func TestSolveLinearSystem(t *testing.T) {
+	solve := func(equations ...string) (*LinearSystemResponse, int, []byte) {
+		c, w := setupTestContext("POST", "/solve/linear", map[string]any{"equations": equations})
+		(&Service{}).SolveLinearSystem(c)
+		var response LinearSystemResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return &response, w.Code, w.Body.Bytes()
+	}
+
+	t.Run("unique", func(t *testing.T) {
+		response, code, _ := solve("2x + y = 3", "x - y = 0")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, SolutionsUnique, response.Solutions)
+		assert.Equal(t, []string{"x", "y"}, response.Variables)
+		assert.Equal(t, 2, response.Rank)
+		assert.InDelta(t, 1, response.Solution["x"], 1e-12)
+		assert.InDelta(t, 1, response.Solution["y"], 1e-12)
+	})
+
+	t.Run("three variables with constants", func(t *testing.T) {
+		response, code, _ := solve("u + v + w = 6", "2*u - v = 0", "w = 2*pi/pi + 1", "(u + v)/2 = 1.5")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, SolutionsUnique, response.Solutions)
+		assert.InDelta(t, 1, response.Solution["u"], 1e-12)
+		assert.InDelta(t, 2, response.Solution["v"], 1e-12)
+		assert.InDelta(t, 3, response.Solution["w"], 1e-12)
+	})
+
+	t.Run("inconsistent", func(t *testing.T) {
+		response, code, _ := solve("x + y = 1", "2x + 2y = 3")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, SolutionsNone, response.Solutions)
+		assert.Equal(t, 1, response.Rank)
+		assert.Nil(t, response.Solution)
+	})
+
+	t.Run("infinite", func(t *testing.T) {
+		response, code, _ := solve("x + y + z = 3", "x - y = 1")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, SolutionsInfinite, response.Solutions)
+		assert.Equal(t, 2, response.Rank)
+		assert.Equal(t, []string{"z"}, response.FreeVariables)
+		assert.Equal(t, "2 - 0.5*z", response.General["x"])
+		assert.Equal(t, "1 - 0.5*z", response.General["y"])
+		assert.InDelta(t, 2, response.Solution["x"], 1e-12)
+		assert.InDelta(t, 0, response.Solution["z"], 1e-12)
+	})
+
+	tests := []struct {
+		name      string
+		equations []string
+		code      string
+	}{
+		{"product of variables", []string{"x*y = 1"}, CodeInvalidInput},
+		{"division by variable", []string{"1/x = 2"}, CodeInvalidInput},
+		{"function of variable", []string{"sin(x) = 0"}, CodeInvalidInput},
+		{"power of variable", []string{"x^2 = 4"}, CodeInvalidInput},
+		{"not an equation", []string{"x + 1"}, CodeInvalidInput},
+		{"division by zero", []string{"x/0 = 1"}, CodeDivisionByZero},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestContext("POST", "/solve/linear", map[string]any{"equations": tt.equations})
+			(&Service{}).SolveLinearSystem(c)
+			assertErrorCode(t, w, http.StatusBadRequest, tt.code)
+		})
+	}
+}
@@ -0,0 +1,384 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"calculator/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// AuthorizationHeader carries bearer tokens whose tenant claim selects the caller's tenant
+const AuthorizationHeader = "Authorization"
+
+// Keys of the caller's tenant and token subject in the Gin context, set by ScopeTenant
+const (
+	tenantContextKey  = "calculator.tenant"
+	subjectContextKey = "calculator.subject"
+)
+
+// tenantCallKey is the context key of the tenantCall of a gRPC call
+type tenantCallKey struct{}
+
+// tenantCall is the tenant and token subject of a gRPC call
+type tenantCall struct {
+	tenant  string
+	subject string
+}
+
+// TenantRequest represents a request to create a tenant
+type TenantRequest struct {
+	ID      string       `json:"id" binding:"required"`
+	Name    string       `json:"name"`
+	APIKeys []string     `json:"api_keys"`
+	Quota   tenant.Quota `json:"quota"`
+}
+
+// TenantsResponse represents the list of tenants
+type TenantsResponse struct {
+	Tenants []tenant.Tenant `json:"tenants"`
+}
+
+// tenantRegistry returns a safe tenant registry (never nil). If Tenants is nil, a registry holding only
+// the default tenant is created on first use.
+func (s *Service) tenantRegistry() *tenant.Registry {
+	s.tenantsOnce.Do(func() {
+		if s.Tenants == nil {
+			s.Tenants = tenant.NewRegistry()
+		}
+	})
+	return s.Tenants
+}
+
+// qualify returns owner qualified by tenantID, so that equal API keys, token subjects or session ids of
+// different tenants never share data. Owners of the default tenant are left unqualified.
+func qualify(tenantID, owner string) string {
+	if owner == "" || tenantID == "" || tenantID == tenant.DefaultID {
+		return owner
+	}
+	return tenantID + "/" + owner
+}
+
+// sameTenant reports whether two tenant IDs name the same tenant, an empty ID naming the default tenant
+func sameTenant(a, b string) bool {
+	if a == "" {
+		a = tenant.DefaultID
+	}
+	if b == "" {
+		b = tenant.DefaultID
+	}
+	return a == b
+}
+
+// tenantFromContext returns the caller's tenant resolved by ScopeTenant
+func tenantFromContext(c *gin.Context) string {
+	if id := c.GetString(tenantContextKey); id != "" {
+		return id
+	}
+	return tenant.DefaultID
+}
+
+// bearerToken returns the token of an Authorization header value using the Bearer scheme, or ""
+func bearerToken(authorization string) string {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// resolveTenant returns the tenant and token subject of a caller presenting apiKey and authorization,
+// an Authorization header value; both may be empty. A bearer token names the tenant in its tenant claim
+// and an API key registered to a tenant selects that tenant; other callers belong to the default tenant.
+func (s *Service) resolveTenant(apiKey, authorization string) (string, string, *Error) {
+	id, subject := "", ""
+	if token := bearerToken(authorization); token != "" {
+		if len(s.TokenSecret) == 0 {
+			return "", "", newError(CodeUnauthorized, "bearer tokens are not accepted by this server")
+		}
+		claims, err := tenant.ParseToken(token, s.TokenSecret, time.Now())
+		if err != nil {
+			return "", "", newError(CodeUnauthorized, err.Error())
+		}
+		if claims.Tenant == "" {
+			return "", "", newError(CodeUnauthorized, "the bearer token has no tenant claim")
+		}
+		id, subject = claims.Tenant, claims.Subject
+	}
+	if apiKey != "" {
+		if keyTenant, ok := s.tenantRegistry().ForKey(apiKey); ok {
+			if id != "" && id != keyTenant {
+				return "", "", newError(CodeUnauthorized, "the API key and the bearer token belong to different tenants")
+			}
+			id = keyTenant
+		}
+	}
+	if id == "" {
+		id = tenant.DefaultID
+	}
+	return id, subject, nil
+}
+
+// tenantError converts an error of tenant.Registry.Admit into a calculator error and its HTTP status
+func tenantError(id string, err error) (int, *Error) {
+	var quotaErr *tenant.QuotaError
+	switch {
+	case errors.Is(err, tenant.ErrNotFound):
+		return http.StatusUnauthorized, newError(CodeUnauthorized, fmt.Sprintf("tenant '%s' does not exist", id)).WithDetail("tenant", id)
+	case errors.Is(err, tenant.ErrDisabled):
+		return http.StatusForbidden, newError(CodeForbidden, fmt.Sprintf("tenant '%s' is disabled", id)).WithDetail("tenant", id)
+	case errors.As(err, &quotaErr):
+		return http.StatusTooManyRequests, newError(CodeRateLimited, fmt.Sprintf("tenant '%s' exceeded its request quota", id)).
+			WithDetail("tenant", id).
+			WithDetail("retry_after_ms", quotaErr.RetryAfter.Milliseconds())
+	}
+	return http.StatusInternalServerError, newError(CodeInternal, err.Error())
+}
+
+// ScopeTenant is middleware resolving the caller's tenant from its bearer token or API key (for WebSocket
+// upgrades also the api_key query parameter). Requests of unknown or disabled tenants, and requests over
+// the tenant's quota, are refused; failed requests are counted in the tenant's usage.
+func (s *Service) ScopeTenant(c *gin.Context) {
+	apiKey := c.GetHeader(APIKeyHeader)
+	if apiKey == "" && c.IsWebsocket() {
+		apiKey = c.Query("api_key")
+	}
+	id, subject, calcErr := s.resolveTenant(apiKey, c.GetHeader(AuthorizationHeader))
+	status := http.StatusUnauthorized
+	if calcErr == nil {
+		if err := s.tenantRegistry().Admit(id); err != nil {
+			var quotaErr *tenant.QuotaError
+			if errors.As(err, &quotaErr) {
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(quotaErr.RetryAfter.Seconds()))))
+			}
+			status, calcErr = tenantError(id, err)
+		}
+	}
+	if calcErr != nil {
+		s.logger().Error("Tenant request refused", "operation", c.Request.URL.Path, "method", c.Request.Method, "tenant", id, "code", calcErr.Code, "error", calcErr.Message)
+		s.respondError(c, status, calcErr)
+		c.Abort()
+		return
+	}
+
+	c.Set(tenantContextKey, id)
+	if subject != "" {
+		c.Set(subjectContextKey, subject)
+	}
+	c.Next()
+	if c.Writer.Status() >= http.StatusBadRequest {
+		s.tenantRegistry().RecordFailure(id)
+	}
+}
+
+// tenantContext resolves the tenant of a gRPC call from its x-api-key and authorization metadata and
+// admits the call, returning ctx with the tenant attached
+func (s *Service) tenantContext(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	authorization := ""
+	if values := md.Get(strings.ToLower(AuthorizationHeader)); len(values) > 0 {
+		authorization = values[0]
+	}
+	id, subject, calcErr := s.resolveTenant(apiKeyFromMetadata(ctx), authorization)
+	if calcErr != nil {
+		return nil, grpcStatus(calcErr)
+	}
+	if err := s.tenantRegistry().Admit(id); err != nil {
+		_, calcErr = tenantError(id, err)
+		return nil, grpcStatus(calcErr)
+	}
+	return context.WithValue(ctx, tenantCallKey{}, tenantCall{tenant: id, subject: subject}), nil
+}
+
+// tenantFromCall returns the tenant and token subject attached to ctx by tenantContext
+func tenantFromCall(ctx context.Context) tenantCall {
+	call, ok := ctx.Value(tenantCallKey{}).(tenantCall)
+	if !ok {
+		call.tenant = tenant.DefaultID
+	}
+	return call
+}
+
+// scopeTenantUnary admits each unary RPC for its tenant and counts its failure
+func (s *Service) scopeTenantUnary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.tenantContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := handler(ctx, req)
+	if err != nil {
+		s.tenantRegistry().RecordFailure(tenantFromCall(ctx).tenant)
+	}
+	return resp, err
+}
+
+// admitMessage admits a message within a connection or request already admitted for tenant id: a
+// WebSocket or gRPC stream message or one GraphQL operation, returning the error refusing it
+func (s *Service) admitMessage(id string) *Error {
+	if err := s.tenantRegistry().Admit(id); err != nil {
+		_, calcErr := tenantError(id, err)
+		return calcErr
+	}
+	return nil
+}
+
+// tenantRefused reports whether err refuses every further message of the tenant, because the tenant
+// was disabled or removed, rather than one message over its quota
+func tenantRefused(err *Error) bool {
+	return err.Code == CodeForbidden || err.Code == CodeUnauthorized
+}
+
+// tenantClosed returns the error ending the open connections of tenant id once it is disabled or
+// removed, or nil while it is enabled
+func (s *Service) tenantClosed(id string) *Error {
+	t, ok := s.tenantRegistry().Get(id)
+	switch {
+	case !ok:
+		_, err := tenantError(id, tenant.ErrNotFound)
+		return err
+	case t.Disabled:
+		_, err := tenantError(id, tenant.ErrDisabled)
+		return err
+	}
+	return nil
+}
+
+// tenantServerStream is a server stream whose context carries the call's tenant. Every received
+// message is admitted for the tenant.
+type tenantServerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	s      *Service
+	tenant string
+}
+
+func (ss *tenantServerStream) Context() context.Context {
+	return ss.ctx
+}
+
+// RecvMsg receives a message and admits it, ending the stream when the tenant is disabled or over quota
+func (ss *tenantServerStream) RecvMsg(m any) error {
+	if err := ss.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if err := ss.s.admitMessage(ss.tenant); err != nil {
+		ss.s.logger().Error("gRPC stream message refused", "tenant", ss.tenant, "code", err.Code)
+		return grpcStatus(err)
+	}
+	return nil
+}
+
+// scopeTenantStream admits each streaming RPC and each of its messages for its tenant and counts its failure
+func (s *Service) scopeTenantStream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.tenantContext(ss.Context())
+	if err != nil {
+		return err
+	}
+	if err := handler(srv, &tenantServerStream{ServerStream: ss, ctx: ctx, s: s, tenant: tenantFromCall(ctx).tenant}); err != nil {
+		s.tenantRegistry().RecordFailure(tenantFromCall(ctx).tenant)
+		return err
+	}
+	return nil
+}
+
+// tenantNotFound returns the error reported for unknown tenant IDs
+func tenantNotFound(id string) *Error {
+	return newError(CodeNotFound, fmt.Sprintf("tenant '%s' not found", id)).WithDetail("tenant", id)
+}
+
+// CreateTenant handles creating a tenant with its API keys and quota
+func (s *Service) CreateTenant(c *gin.Context) {
+	var req TenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind tenant JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	created, err := s.tenantRegistry().Create(tenant.Tenant{ID: req.ID, Name: req.Name, APIKeys: req.APIKeys, Quota: req.Quota})
+	switch {
+	case errors.Is(err, tenant.ErrExists):
+		s.respondError(c, http.StatusConflict, newError(CodeConflict, fmt.Sprintf("tenant '%s' already exists", req.ID)).WithDetail("tenant", req.ID))
+		return
+	case errors.Is(err, tenant.ErrKeyInUse):
+		s.respondError(c, http.StatusConflict, newError(CodeConflict, "an API key is already registered to a tenant").WithDetail("tenant", req.ID))
+		return
+	case err != nil:
+		s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, err.Error()).WithDetail("tenant", req.ID))
+		return
+	}
+	s.logger().Info("Tenant created", "operation", c.Request.URL.Path, "method", c.Request.Method, "tenant", created.ID, "api_keys", len(created.APIKeys))
+	c.JSON(http.StatusCreated, created)
+}
+
+// ListTenants handles listing all tenants with their usage
+func (s *Service) ListTenants(c *gin.Context) {
+	c.JSON(http.StatusOK, TenantsResponse{Tenants: s.tenantRegistry().List()})
+}
+
+// GetTenant handles reading a tenant with its usage
+func (s *Service) GetTenant(c *gin.Context) {
+	id := c.Param("id")
+	t, ok := s.tenantRegistry().Get(id)
+	if !ok {
+		s.respondError(c, http.StatusNotFound, tenantNotFound(id))
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// DisableTenant handles refusing further requests of a tenant; its data is kept
+func (s *Service) DisableTenant(c *gin.Context) {
+	s.setTenantDisabled(c, true)
+}
+
+// EnableTenant handles admitting the requests of a disabled tenant again
+func (s *Service) EnableTenant(c *gin.Context) {
+	s.setTenantDisabled(c, false)
+}
+
+// setTenantDisabled disables or enables the tenant named in the path and writes it
+func (s *Service) setTenantDisabled(c *gin.Context, disabled bool) {
+	id := c.Param("id")
+	t, err := s.tenantRegistry().SetDisabled(id, disabled)
+	switch {
+	case errors.Is(err, tenant.ErrNotFound):
+		s.respondError(c, http.StatusNotFound, tenantNotFound(id))
+		return
+	case err != nil:
+		s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, err.Error()).WithDetail("tenant", id))
+		return
+	}
+	s.logger().Info("Tenant updated", "operation", c.Request.URL.Path, "method", c.Request.Method, "tenant", id, "disabled", disabled)
+	c.JSON(http.StatusOK, t)
+}
+
+// TenantMetrics handles exposing each tenant's usage in the Prometheus text format, labelled by tenant
+func (s *Service) TenantMetrics(c *gin.Context) {
+	tenants := s.tenantRegistry().List()
+	var b strings.Builder
+	metric := func(name, kind, help string, value func(tenant.Tenant) uint64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, t := range tenants {
+			fmt.Fprintf(&b, "%s{tenant=%q} %d\n", name, t.ID, value(t))
+		}
+	}
+	metric("calculator_tenant_requests_total", "counter", "Requests admitted per tenant.", func(t tenant.Tenant) uint64 { return t.Usage.Requests })
+	metric("calculator_tenant_errors_total", "counter", "Admitted requests that failed per tenant.", func(t tenant.Tenant) uint64 { return t.Usage.Errors })
+	metric("calculator_tenant_throttled_total", "counter", "Requests refused by the tenant's quota.", func(t tenant.Tenant) uint64 { return t.Usage.Throttled })
+	metric("calculator_tenant_disabled", "gauge", "Whether the tenant is disabled.", func(t tenant.Tenant) uint64 {
+		if t.Disabled {
+			return 1
+		}
+		return 0
+	})
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
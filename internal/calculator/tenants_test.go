@@ -0,0 +1,396 @@
+package calculator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator/internal/calculator/calculatorpb"
+	"calculator/internal/storage"
+	"calculator/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// testTokenSecret signs the bearer tokens of the tenant tests
+var testTokenSecret = []byte("tenant-test-secret")
+
+// setupTenants returns a service with the tenants acme (API key "acme-key") and globex (API key
+// "globex-key") besides the default tenant, whose API key "root" is an admin, and its routes
+func setupTenants(t *testing.T) (*Service, *gin.Engine) {
+	t.Helper()
+	s := &Service{AdminKeys: []string{"root"}, TokenSecret: testTokenSecret}
+	for _, id := range []string{"acme", "globex"} {
+		_, err := s.tenantRegistry().Create(tenant.Tenant{ID: id, APIKeys: []string{id + "-key"}})
+		require.NoError(t, err)
+	}
+	r := gin.New()
+	SetupRoutes(r, s)
+	return s, r
+}
+
+// tenantRequest sends a request through r with an optional JSON body and headers
+func tenantRequest(r http.Handler, method, url string, body any, header map[string]string) *httptest.ResponseRecorder {
+	c, w := setupTestContext(method, url, body)
+	for key, value := range header {
+		c.Request.Header.Set(key, value)
+	}
+	r.ServeHTTP(w, c.Request)
+	return w
+}
+
+// bearer returns an Authorization header with a token for subject in tenantID
+func bearer(t *testing.T, tenantID, subject string) map[string]string {
+	t.Helper()
+	token, err := tenant.SignToken(tenant.Claims{Subject: subject, Tenant: tenantID, ExpiresAt: time.Now().Add(time.Hour).Unix()}, testTokenSecret)
+	require.NoError(t, err)
+	return map[string]string{AuthorizationHeader: "Bearer " + token}
+}
+
+// TestTenantIsolation tests that one tenant cannot read or change another tenant's variables,
+// functions, history, sessions or events
+func TestTenantIsolation(t *testing.T) {
+	_, r := setupTenants(t)
+	acme := map[string]string{APIKeyHeader: "acme-key", ClientHeader: "acme-app"}
+	globex := map[string]string{APIKeyHeader: "globex-key", ClientHeader: "globex-app"}
+
+	w := tenantRequest(r, "POST", "/api/v1/variables", map[string]any{"name": "x", "value": 7}, acme)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = tenantRequest(r, "POST", "/api/v1/functions", map[string]any{"definition": "sq(x) = x^2"}, acme)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = tenantRequest(r, "POST", "/api/v1/add", map[string]any{"a": "$x", "b": 1}, acme)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = tenantRequest(r, "POST", "/api/v1/sessions", nil, acme)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var session storage.Session
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+	assert.Equal(t, "acme", session.Tenant)
+	w = tenantRequest(r, "POST", "/api/v1/sessions/"+session.ID+"/operations/add", map[string]any{"a": 2, "b": 3}, acme)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	t.Run("variables", func(t *testing.T) {
+		w := tenantRequest(r, "GET", "/api/v1/variables/x", nil, globex)
+		assertErrorCode(t, w, http.StatusNotFound, CodeNotFound)
+		w = tenantRequest(r, "GET", "/api/v1/variables", nil, globex)
+		assert.JSONEq(t, `{"variables": []}`, w.Body.String())
+		w = tenantRequest(r, "POST", "/api/v1/add", map[string]any{"a": "$x", "b": 1}, globex)
+		assertErrorCode(t, w, http.StatusBadRequest, CodeUndefinedVariable)
+		w = tenantRequest(r, "DELETE", "/api/v1/variables/x", nil, globex)
+		assertErrorCode(t, w, http.StatusNotFound, CodeNotFound)
+		w = tenantRequest(r, "GET", "/api/v1/variables/x", nil, acme)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("functions", func(t *testing.T) {
+		w := tenantRequest(r, "GET", "/api/v1/functions/sq", nil, globex)
+		assertErrorCode(t, w, http.StatusNotFound, CodeNotFound)
+		w = tenantRequest(r, "POST", "/api/v1/functions/sq/call", map[string]any{"args": []float64{3}}, globex)
+		assertErrorCode(t, w, http.StatusNotFound, CodeNotFound)
+		w = tenantRequest(r, "POST", "/api/v1/functions/sq/call", map[string]any{"args": []float64{3}}, acme)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("history", func(t *testing.T) {
+		var history HistoryResponse
+		w := tenantRequest(r, "GET", "/api/v1/history", nil, globex)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+		assert.Empty(t, history.History)
+		w = tenantRequest(r, "GET", "/api/v1/history", nil, acme)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+		assert.NotEmpty(t, history.History)
+	})
+
+	t.Run("sessions", func(t *testing.T) {
+		path := "/api/v1/sessions/" + session.ID
+		w := tenantRequest(r, "GET", path, nil, globex)
+		assertErrorCode(t, w, http.StatusNotFound, CodeNotFound)
+		w = tenantRequest(r, "POST", path+"/operations/add", map[string]any{"b": 1}, globex)
+		assertErrorCode(t, w, http.StatusNotFound, CodeNotFound)
+		w = tenantRequest(r, "POST", path+"/memory", map[string]any{"action": "MS", "value": 1}, globex)
+		assertErrorCode(t, w, http.StatusNotFound, CodeNotFound)
+		w = tenantRequest(r, "DELETE", path, nil, globex)
+		assertErrorCode(t, w, http.StatusNotFound, CodeNotFound)
+
+		// The session's ANS can neither be read nor overwritten from another tenant
+		header := map[string]string{APIKeyHeader: "globex-key", SessionHeader: session.ID}
+		w = tenantRequest(r, "POST", "/api/v1/add", map[string]any{"a": "ans", "b": 1}, header)
+		assertErrorCode(t, w, http.StatusBadRequest, CodeUndefinedVariable)
+		w = tenantRequest(r, "POST", "/api/v1/add", map[string]any{"a": 100, "b": 1}, header)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = tenantRequest(r, "GET", path, nil, acme)
+		require.Equal(t, http.StatusOK, w.Code)
+		var current storage.Session
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &current))
+		assert.Equal(t, 5.0, current.Ans)
+	})
+
+	t.Run("bearer tokens", func(t *testing.T) {
+		// The same subject in two tenants owns separate data
+		w := tenantRequest(r, "POST", "/api/v1/variables", map[string]any{"name": "y", "value": 1}, bearer(t, "acme", "ops"))
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		w = tenantRequest(r, "GET", "/api/v1/variables/y", nil, bearer(t, "globex", "ops"))
+		assertErrorCode(t, w, http.StatusNotFound, CodeNotFound)
+		w = tenantRequest(r, "GET", "/api/v1/variables/y", nil, bearer(t, "acme", "ops"))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("events", func(t *testing.T) {
+		server := httptest.NewServer(r)
+		t.Cleanup(server.Close)
+		events := readEvents(t, server.URL+"/api/v1/events?last_event_id=0", http.Header{APIKeyHeader: {"globex-key"}}, 2, nil)
+		for _, event := range events {
+			assert.Equal(t, "globex", event.data["tenant"])
+			assert.Equal(t, "globex-app", event.data["client"])
+		}
+	})
+
+	t.Run("audit actors", func(t *testing.T) {
+		w := tenantRequest(r, "GET", "/api/v1/admin/audit?actor=acme/key:acme-key&action=variable.create", nil, map[string]string{APIKeyHeader: "root"})
+		var response AuditResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Records, 1)
+	})
+}
+
+// TestTenantAdmission tests refusing callers of unknown or disabled tenants and requests over a quota
+func TestTenantAdmission(t *testing.T) {
+	s, r := setupTenants(t)
+	root := map[string]string{APIKeyHeader: "root"}
+	add := map[string]any{"a": 1, "b": 2}
+
+	w := tenantRequest(r, "POST", "/api/v1/admin/tenants/acme/disable", nil, root)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = tenantRequest(r, "POST", "/api/v1/add", add, map[string]string{APIKeyHeader: "acme-key"})
+	assertErrorCode(t, w, http.StatusForbidden, CodeForbidden)
+	w = tenantRequest(r, "POST", "/api/v1/add", add, bearer(t, "acme", "ops"))
+	assertErrorCode(t, w, http.StatusForbidden, CodeForbidden)
+	w = tenantRequest(r, "POST", "/api/v1/add", add, map[string]string{APIKeyHeader: "globex-key"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = tenantRequest(r, "POST", "/api/v1/admin/tenants/acme/enable", nil, root)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = tenantRequest(r, "POST", "/api/v1/add", add, map[string]string{APIKeyHeader: "acme-key"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Tokens must be valid and name an existing tenant matching the API key's
+	w = tenantRequest(r, "POST", "/api/v1/add", add, bearer(t, "initech", "ops"))
+	assertErrorCode(t, w, http.StatusUnauthorized, CodeUnauthorized)
+	w = tenantRequest(r, "POST", "/api/v1/add", add, map[string]string{AuthorizationHeader: "Bearer not-a-token"})
+	assertErrorCode(t, w, http.StatusUnauthorized, CodeUnauthorized)
+	header := bearer(t, "acme", "ops")
+	header[APIKeyHeader] = "globex-key"
+	w = tenantRequest(r, "POST", "/api/v1/add", add, header)
+	assertErrorCode(t, w, http.StatusUnauthorized, CodeUnauthorized)
+	noSecret := &Service{}
+	rNoSecret := gin.New()
+	SetupRoutes(rNoSecret, noSecret)
+	w = tenantRequest(rNoSecret, "POST", "/api/v1/add", add, bearer(t, tenant.DefaultID, "ops"))
+	assertErrorCode(t, w, http.StatusUnauthorized, CodeUnauthorized)
+
+	_, err := s.tenantRegistry().Create(tenant.Tenant{ID: "initech", APIKeys: []string{"initech-key"}, Quota: tenant.Quota{RequestsPerMinute: 1}})
+	require.NoError(t, err)
+	initech := map[string]string{APIKeyHeader: "initech-key"}
+	w = tenantRequest(r, "POST", "/api/v1/add", add, initech)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = tenantRequest(r, "POST", "/api/v1/add", add, initech)
+	assertErrorCode(t, w, http.StatusTooManyRequests, CodeRateLimited)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	w = tenantRequest(r, "POST", "/api/v1/add", add, map[string]string{APIKeyHeader: "acme-key"})
+	assert.Equal(t, http.StatusOK, w.Code, "quotas are per tenant")
+
+	usage, _ := s.tenantRegistry().Get("initech")
+	assert.Equal(t, tenant.Usage{Requests: 1, Throttled: 1}, usage.Usage)
+}
+
+// TestTenantAdmin tests the admin endpoints creating, listing and disabling tenants and their metrics
+func TestTenantAdmin(t *testing.T) {
+	_, r := setupTenants(t)
+	root := map[string]string{APIKeyHeader: "root"}
+
+	w := tenantRequest(r, "POST", "/api/v1/admin/tenants", map[string]any{
+		"id": "initech", "name": "Initech", "api_keys": []string{"initech-key"}, "quota": map[string]any{"requests_per_minute": 100},
+	}, root)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created tenant.Tenant
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "Initech", created.Name)
+	assert.Equal(t, 100, created.Quota.RequestsPerMinute)
+
+	w = tenantRequest(r, "POST", "/api/v1/admin/tenants", map[string]any{"id": "initech"}, root)
+	assertErrorCode(t, w, http.StatusConflict, CodeConflict)
+	w = tenantRequest(r, "POST", "/api/v1/admin/tenants", map[string]any{"id": "hooli", "api_keys": []string{"acme-key"}}, root)
+	assertErrorCode(t, w, http.StatusConflict, CodeConflict)
+	w = tenantRequest(r, "POST", "/api/v1/admin/tenants", map[string]any{"id": "Not Valid"}, root)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+
+	w = tenantRequest(r, "POST", "/api/v1/add", map[string]any{"a": 1, "b": 2}, map[string]string{APIKeyHeader: "initech-key"})
+	require.Equal(t, http.StatusOK, w.Code)
+	w = tenantRequest(r, "POST", "/api/v1/divide", map[string]any{"a": 1, "b": 0}, map[string]string{APIKeyHeader: "initech-key"})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = tenantRequest(r, "GET", "/api/v1/admin/tenants", nil, root)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list TenantsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	ids := make([]string, len(list.Tenants))
+	for i, t := range list.Tenants {
+		ids[i] = t.ID
+	}
+	assert.Equal(t, []string{"acme", tenant.DefaultID, "globex", "initech"}, ids)
+	assert.Equal(t, tenant.Usage{Requests: 2, Errors: 1}, list.Tenants[3].Usage)
+
+	w = tenantRequest(r, "GET", "/api/v1/admin/tenants/missing", nil, root)
+	assertErrorCode(t, w, http.StatusNotFound, CodeNotFound)
+	w = tenantRequest(r, "POST", "/api/v1/admin/tenants/missing/disable", nil, root)
+	assertErrorCode(t, w, http.StatusNotFound, CodeNotFound)
+	w = tenantRequest(r, "POST", "/api/v1/admin/tenants/default/disable", nil, root)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+	w = tenantRequest(r, "POST", "/api/v1/admin/tenants/initech/disable", nil, root)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = tenantRequest(r, "GET", "/api/v1/admin/tenants/initech", nil, root)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.True(t, created.Disabled)
+
+	w = tenantRequest(r, "GET", "/api/v1/admin/metrics", nil, root)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain"))
+	metrics := w.Body.String()
+	assert.Contains(t, metrics, "# TYPE calculator_tenant_requests_total counter\n")
+	assert.Contains(t, metrics, `calculator_tenant_requests_total{tenant="initech"} 2`+"\n")
+	assert.Contains(t, metrics, `calculator_tenant_errors_total{tenant="initech"} 1`+"\n")
+	assert.Contains(t, metrics, `calculator_tenant_disabled{tenant="initech"} 1`+"\n")
+
+	// Tenant administration needs an admin key
+	w = tenantRequest(r, "GET", "/api/v1/admin/tenants", nil, map[string]string{APIKeyHeader: "acme-key"})
+	assertErrorCode(t, w, http.StatusUnauthorized, CodeUnauthorized)
+}
+
+// TestGRPCTenants tests that gRPC calls are scoped to and admitted for the caller's tenant
+func TestGRPCTenants(t *testing.T) {
+	s, r := setupTenants(t)
+	client := setupGRPC(t, s)
+	w := tenantRequest(r, "POST", "/api/v1/variables", map[string]any{"name": "x", "value": 7}, map[string]string{APIKeyHeader: "acme-key"})
+	require.Equal(t, http.StatusCreated, w.Code)
+	req := &calculatorpb.CalculateRequest{Operation: "multiply", A: ref("$x"), B: value(2)}
+
+	acme := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "acme-key")
+	resp, err := client.Calculate(acme, req)
+	require.NoError(t, err)
+	assert.Equal(t, 14.0, resp.GetResult())
+
+	globex := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "globex-key")
+	_, err = client.Calculate(globex, req)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	token := bearer(t, "acme", "ops")[AuthorizationHeader]
+	_, err = client.Calculate(metadata.AppendToOutgoingContext(context.Background(), "authorization", token), &calculatorpb.CalculateRequest{Operation: "add", A: value(1), B: value(2)})
+	assert.NoError(t, err)
+
+	_, err = s.tenantRegistry().SetDisabled("acme", true)
+	require.NoError(t, err)
+	_, err = client.Calculate(acme, req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+// dialTenantWebSocket serves r on a test server and opens a WebSocket connection with apiKey
+func dialTenantWebSocket(t *testing.T, r http.Handler, apiKey string) *websocket.Conn {
+	t.Helper()
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws?api_key="+apiKey, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+// TestTenantMessages tests that each WebSocket message, gRPC stream message and GraphQL operation is
+// admitted for the caller's tenant, and that connections close once the tenant is disabled
+func TestTenantMessages(t *testing.T) {
+	add := WSRequest{ID: "1", Operation: "add", A: &Operand{Value: 1}, B: &Operand{Value: 2}}
+
+	t.Run("websocket quota", func(t *testing.T) {
+		s, r := setupTenants(t)
+		// The upgrade and each message count against the quota
+		_, err := s.tenantRegistry().Create(tenant.Tenant{ID: "initech", APIKeys: []string{"initech-key"}, Quota: tenant.Quota{RequestsPerMinute: 3}})
+		require.NoError(t, err)
+		conn := dialTenantWebSocket(t, r, "initech-key")
+		for _, code := range []string{"", "", CodeRateLimited, CodeRateLimited} {
+			reply := exchange(t, conn, add, 1)[0]
+			assert.Equal(t, code, reply.Code)
+			assert.Equal(t, "1", reply.ID)
+		}
+		usage, _ := s.tenantRegistry().Get("initech")
+		assert.Equal(t, tenant.Usage{Requests: 3, Throttled: 2}, usage.Usage)
+	})
+
+	t.Run("websocket disabled", func(t *testing.T) {
+		s, r := setupTenants(t)
+		conn := dialTenantWebSocket(t, r, "acme-key")
+		assert.Equal(t, 3.0, *exchange(t, conn, add, 1)[0].Result)
+		_, err := s.tenantRegistry().SetDisabled("acme", true)
+		require.NoError(t, err)
+		reply := exchange(t, conn, add, 1)[0]
+		assert.Equal(t, CodeForbidden, reply.Code)
+		_, _, err = conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "%v", err)
+	})
+
+	t.Run("websocket disabled while idle", func(t *testing.T) {
+		s, r := setupTenants(t)
+		s.WSLimits = &WebSocketLimits{PingPeriod: 10 * time.Millisecond}
+		conn := dialTenantWebSocket(t, r, "acme-key")
+		_, err := s.tenantRegistry().SetDisabled("acme", true)
+		require.NoError(t, err)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		_, _, err = conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "%v", err)
+	})
+
+	t.Run("grpc stream", func(t *testing.T) {
+		s, _ := setupTenants(t)
+		client := setupGRPC(t, s)
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "acme-key")
+		stream, err := client.CalculateStream(ctx)
+		require.NoError(t, err)
+		req := &calculatorpb.CalculateRequest{Id: "a", Operation: "add", A: value(1), B: value(2)}
+		require.NoError(t, stream.Send(req))
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, 3.0, resp.GetResult())
+
+		_, err = s.tenantRegistry().SetDisabled("acme", true)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(req))
+		_, err = stream.Recv()
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("graphql", func(t *testing.T) {
+		s, r := setupTenants(t)
+		// The request and each operation count against the quota
+		_, err := s.tenantRegistry().Create(tenant.Tenant{ID: "initech", APIKeys: []string{"initech-key"}, Quota: tenant.Quota{RequestsPerMinute: 2}})
+		require.NoError(t, err)
+		w := tenantRequest(r, "POST", "/graphql", GraphQLRequest{Query: `mutation { a: add(a: 1, b: 2) b: add(a: 3, b: 4) }`},
+			map[string]string{APIKeyHeader: "initech-key"})
+		require.Equal(t, http.StatusOK, w.Code)
+		var result graphQLResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.JSONEq(t, "3", string(result.Data["a"]))
+		assert.JSONEq(t, "null", string(result.Data["b"]))
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, []any{"b"}, result.Errors[0].Path)
+		assert.Equal(t, CodeRateLimited, result.Errors[0].Extensions["code"])
+	})
+}
@@ -0,0 +1,75 @@
+package calculator
+
+import (
+	"net/http"
+
+	"calculator/core"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Unit describes a unit of measure relative to the coherent base units
+type Unit = core.Unit
+
+// Quantity is a value with a unit
+type Quantity = core.Quantity
+
+// QuantityOperationFunc defines the signature for operations on dimensioned values
+type QuantityOperationFunc = core.QuantityFunc
+
+// ConvertRequest represents a unit conversion request
+type ConvertRequest struct {
+	Value float64 `json:"value"`
+	From  string  `json:"from" binding:"required"`
+	To    string  `json:"to" binding:"required"`
+}
+
+// ConvertResponse represents a unit conversion response
+type ConvertResponse struct {
+	Result   float64 `json:"result"`
+	Unit     string  `json:"unit"`
+	Category string  `json:"category"`
+}
+
+// UnitsResponse represents the unit catalogue response
+type UnitsResponse struct {
+	Units []Unit `json:"units"`
+}
+
+// Convert handles unit conversion
+func (s *Service) Convert(c *gin.Context) {
+	var req ConvertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind convert JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Processing convert request", "operation", c.Request.URL.Path, "method", c.Request.Method, "value", req.Value, "from", req.From, "to", req.To)
+
+	result, err := core.Convert(s.withLogger(c.Request.Context()), req.Value, req.From, req.To)
+	if err != nil {
+		s.logger().Error("Conversion failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "value", req.Value, "from", req.From, "to", req.To, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.logger().Info("Conversion successful", "operation", c.Request.URL.Path, "method", c.Request.Method, "value", req.Value, "from", req.From, "to", req.To, "result", result.Value)
+	s.respondJSON(c, ConvertResponse{Result: result.Value, Unit: result.Unit.Symbol, Category: result.Unit.Category})
+}
+
+// Units handles listing the unit catalogue, optionally filtered by ?category=
+func (s *Service) Units(c *gin.Context) {
+	c.JSON(http.StatusOK, UnitsResponse{Units: core.Units(c.Query("category"))})
+}
+
+// quantityOperands resolves the optional units of a binary request into quantities.
+// An empty unit is dimensionless.
+func quantityOperands(a float64, aUnit string, b float64, bUnit string) (Quantity, Quantity, error) {
+	qa, err := core.NewQuantity(a, aUnit)
+	if err != nil {
+		return Quantity{}, Quantity{}, err
+	}
+	qb, err := core.NewQuantity(b, bUnit)
+	return qa, qb, err
+}
@@ -0,0 +1,124 @@
+package calculator
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConvert tests the Convert handler
+func TestConvert(t *testing.T) {
+	s := &Service{}
+
+	tests := []struct {
+		name           string
+		value          float64
+		from           string
+		to             string
+		expectedStatus int
+		expectedResult float64
+		expectedCode   string
+	}{
+		{"km to mi", 10, "km", "mi", http.StatusOK, 6.213712, ""},
+		{"lb to kg", 1, "lb", "kg", http.StatusOK, 0.453592, ""},
+		{"celsius to fahrenheit", 100, "C", "F", http.StatusOK, 212, ""},
+		{"fahrenheit to celsius", -40, "F", "C", http.StatusOK, -40, ""},
+		{"celsius to kelvin", 0, "°C", "K", http.StatusOK, 273.15, ""},
+		{"hours to seconds", 1.5, "h", "s", http.StatusOK, 5400, ""},
+		{"litres to gallons", 3.785411784, "L", "gal", http.StatusOK, 1, ""},
+		{"hectare to m2", 1, "ha", "m2", http.StatusOK, 10000, ""},
+		{"km/h to m/s", 36, "km/h", "m/s", http.StatusOK, 10, ""},
+		{"SI vs IEC", 1, "GiB", "GB", http.StatusOK, 1.073742, ""},
+		{"bytes to bits", 1, "B", "bit", http.StatusOK, 8, ""},
+		{"kWh to MJ", 1, "kWh", "MJ", http.StatusOK, 3.6, ""},
+		{"unit by name", 1, "Kilometres", "metre", http.StatusOK, 1000, ""},
+		{"incompatible", 1, "m", "kg", http.StatusBadRequest, 0, CodeIncompatibleUnits},
+		{"unknown unit", 1, "furlong", "m", http.StatusBadRequest, 0, CodeUnknownUnit},
+		{"overflow", 1e308, "km", "mm", http.StatusBadRequest, 0, CodeDomainError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestContext("POST", "/convert", map[string]any{"value": tt.value, "from": tt.from, "to": tt.to})
+			s.Convert(c)
+
+			if tt.expectedStatus != http.StatusOK {
+				assertErrorCode(t, w, tt.expectedStatus, tt.expectedCode)
+				return
+			}
+			var response ConvertResponse
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.InDelta(t, tt.expectedResult, response.Result, 0.0001)
+		})
+	}
+}
+
+// TestDimensionedOperations tests binary operations on operands with units
+func TestDimensionedOperations(t *testing.T) {
+	s := &Service{}
+
+	tests := []struct {
+		name           string
+		op             string
+		body           map[string]any
+		expectedStatus int
+		expectedResult float64
+		expectedUnit   string
+		expectedCode   string
+	}{
+		{"add same unit", "add", map[string]any{"a": 1, "a_unit": "m", "b": 2, "b_unit": "m"}, http.StatusOK, 3, "m", ""},
+		{"add mixed units", "add", map[string]any{"a": 1, "a_unit": "km", "b": 500, "b_unit": "m"}, http.StatusOK, 1.5, "km", ""},
+		{"subtract mixed units", "subtract", map[string]any{"a": 1, "a_unit": "h", "b": 30, "b_unit": "min"}, http.StatusOK, 0.5, "h", ""},
+		{"add incompatible", "add", map[string]any{"a": 1, "a_unit": "m", "b": 1, "b_unit": "kg"}, http.StatusBadRequest, 0, "", CodeIncompatibleUnits},
+		{"add offset temperatures", "add", map[string]any{"a": 1, "a_unit": "C", "b": 1, "b_unit": "F"}, http.StatusBadRequest, 0, "", CodeIncompatibleUnits},
+		{"multiply to area", "multiply", map[string]any{"a": 2, "a_unit": "m", "b": 300, "b_unit": "cm"}, http.StatusOK, 6, "m2", ""},
+		{"multiply by scalar", "multiply", map[string]any{"a": 3, "a_unit": "km", "b": 2}, http.StatusOK, 6, "km", ""},
+		{"divide to speed", "divide", map[string]any{"a": 100, "a_unit": "m", "b": 10, "b_unit": "s"}, http.StatusOK, 10, "m/s", ""},
+		{"divide derived", "divide", map[string]any{"a": 1, "b": 2, "b_unit": "s"}, http.StatusOK, 0.5, "1/s", ""},
+		{"divide by zero", "divide", map[string]any{"a": 1, "a_unit": "m", "b": 0, "b_unit": "s"}, http.StatusBadRequest, 0, "", CodeDivisionByZero},
+		{"unknown unit", "add", map[string]any{"a": 1, "a_unit": "parsec", "b": 1}, http.StatusBadRequest, 0, "", CodeUnknownUnit},
+		{"unsupported operation", "power", map[string]any{"a": 2, "a_unit": "m", "b": 2}, http.StatusBadRequest, 0, "", CodeInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestContext("POST", "/"+tt.op, tt.body)
+			switch tt.op {
+			case "add":
+				s.Add(c)
+			case "subtract":
+				s.Subtract(c)
+			case "multiply":
+				s.Multiply(c)
+			case "divide":
+				s.Divide(c)
+			case "power":
+				s.Power(c)
+			}
+
+			if tt.expectedStatus != http.StatusOK {
+				assertErrorCode(t, w, tt.expectedStatus, tt.expectedCode)
+				return
+			}
+			var response Response
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.InDelta(t, tt.expectedResult, response.Result, 0.0001)
+			assert.Equal(t, tt.expectedUnit, response.Unit)
+		})
+	}
+
+	t.Run("GET with units", func(t *testing.T) {
+		c, w := setupTestContext("GET", "/add?a=1&a_unit=kg&b=500&b_unit=g", nil)
+		s.AddGET(c)
+
+		var response Response
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.InDelta(t, 1.5, response.Result, 0.0001)
+		assert.Equal(t, "kg", response.Unit)
+	})
+}
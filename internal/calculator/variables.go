@@ -0,0 +1,279 @@
+package calculator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"calculator/core"
+	"calculator/internal/access"
+	"calculator/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// APIKeyHeader identifies the caller's API key, which scopes variables
+	APIKeyHeader = "X-API-Key"
+	// SessionHeader identifies the caller's session, which scopes variables when no API key is sent
+	SessionHeader = "X-Session-ID"
+)
+
+// identifierPattern matches valid variable and constant names
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Constant is a built-in named value
+type Constant = core.Constant
+
+// Operand is a numeric request input. Besides a JSON number it accepts a string naming
+// a built-in constant ("pi"), a variable ("$x") or a numeric literal ("2.5").
+type Operand struct {
+	Value float64
+	Ref   string
+}
+
+// UnmarshalJSON accepts either a JSON number or a string reference
+func (o *Operand) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		*o = Operand{}
+		return json.Unmarshal(data, &o.Ref)
+	}
+	*o = Operand{}
+	return json.Unmarshal(data, &o.Value)
+}
+
+// MarshalJSON writes references as strings and literals as numbers
+func (o Operand) MarshalJSON() ([]byte, error) {
+	if o.Ref != "" {
+		return json.Marshal(o.Ref)
+	}
+	return json.Marshal(o.Value)
+}
+
+// String returns the reference or the formatted literal value
+func (o Operand) String() string {
+	if o.Ref != "" {
+		return o.Ref
+	}
+	return strconv.FormatFloat(o.Value, 'g', -1, 64)
+}
+
+// LogValue logs references as strings and literals as numbers
+func (o Operand) LogValue() slog.Value {
+	if o.Ref != "" {
+		return slog.StringValue(o.Ref)
+	}
+	return slog.Float64Value(o.Value)
+}
+
+// VariableRequest represents a variable create or update request
+type VariableRequest struct {
+	Name  string  `json:"name"`
+	Value Operand `json:"value"`
+}
+
+// VariablesResponse represents the list of variables for the caller
+type VariablesResponse struct {
+	Variables []storage.Variable `json:"variables"`
+}
+
+// ConstantsResponse represents the list of built-in constants
+type ConstantsResponse struct {
+	Constants []Constant `json:"constants"`
+}
+
+// scope identifies whose variables and which session an operand may reference, within a tenant, and
+// the principal whose permissions apply
+type scope struct {
+	owner     string
+	session   string
+	tenant    string
+	principal access.Principal
+}
+
+// scopeFromContext returns the caller's scope from its tenant and the API key and session headers
+func scopeFromContext(c *gin.Context) scope {
+	owner, _ := ownerFromContext(c)
+	return scope{owner: owner, session: c.GetHeader(SessionHeader), tenant: tenantFromContext(c), principal: principalFromContext(c)}
+}
+
+// ownerFromContext returns the key scoping the caller's data within its tenant, preferring the API key
+// over the bearer token's subject and the session
+func ownerFromContext(c *gin.Context) (string, bool) {
+	tenantID := tenantFromContext(c)
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		return qualify(tenantID, "key:"+key), true
+	}
+	if subject := c.GetString(subjectContextKey); subject != "" {
+		return qualify(tenantID, "user:"+subject), true
+	}
+	if session := c.GetHeader(SessionHeader); session != "" {
+		return qualify(tenantID, "session:"+session), true
+	}
+	return "", false
+}
+
+// requireOwner returns the caller's owner key or writes an error response
+func (s *Service) requireOwner(c *gin.Context) (string, bool) {
+	owner, ok := ownerFromContext(c)
+	if !ok {
+		s.logger().Error("Request without owner scope", "operation", c.Request.URL.Path, "method", c.Request.Method)
+		s.respondError(c, http.StatusBadRequest, newError(CodeInvalidInput, fmt.Sprintf("%s or %s header is required", APIKeyHeader, SessionHeader)))
+		return "", false
+	}
+	return owner, true
+}
+
+// resolveOperand returns the numeric value of o within sc. param names the request field for error messages.
+// "ans" and "$ans" refer to the last answer of the caller's session.
+func (s *Service) resolveOperand(sc scope, param string, o Operand) (float64, error) {
+	if o.Ref == "" {
+		return o.Value, nil
+	}
+	ref := strings.TrimSpace(o.Ref)
+	if ref == "ans" || ref == "$ans" {
+		return s.resolveAns(sc, param)
+	}
+	if name, ok := strings.CutPrefix(ref, "$"); ok {
+		if sc.owner != "" {
+			if value, ok := s.store().Variable(sc.owner, name); ok {
+				s.logger().Debug("Resolved variable", "param", param, "name", name, "value", value)
+				return value, nil
+			}
+		}
+		if constant, ok := core.LookupConstant(name); ok {
+			return constant.Value, nil
+		}
+		s.logger().Error("Undefined variable referenced", "param", param, "name", name)
+		return 0, newError(CodeUndefinedVariable, fmt.Sprintf("undefined variable '%s'", name)).
+			WithDetail("param", param).
+			WithDetail("name", name)
+	}
+	if constant, ok := core.LookupConstant(ref); ok {
+		s.logger().Debug("Resolved constant", "param", param, "name", ref, "value", constant.Value)
+		return constant.Value, nil
+	}
+	if value, err := strconv.ParseFloat(ref, 64); err == nil {
+		return value, nil
+	}
+	return 0, newError(CodeInvalidInput, fmt.Sprintf("invalid value for parameter '%s'", param)).
+		WithDetail("param", param).
+		WithDetail("value", ref)
+}
+
+// parseQueryOperand parses a GET query parameter as a number, constant or variable reference
+func (s *Service) parseQueryOperand(c *gin.Context, param string) (float64, error) {
+	str := c.Query(param)
+	if value, err := strconv.ParseFloat(str, 64); err == nil {
+		return value, nil
+	}
+	if str == "" {
+		return 0, newError(CodeInvalidInput, fmt.Sprintf("invalid value for parameter '%s'", param)).WithDetail("param", param)
+	}
+	return s.resolveOperand(scopeFromContext(c), param, Operand{Ref: str})
+}
+
+// Constants handles listing the built-in constants
+func (s *Service) Constants(c *gin.Context) {
+	c.JSON(http.StatusOK, ConstantsResponse{Constants: core.Constants()})
+}
+
+// ListVariables handles listing the caller's variables
+func (s *Service) ListVariables(c *gin.Context) {
+	owner, ok := s.requireOwner(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, VariablesResponse{Variables: s.store().Variables(owner)})
+}
+
+// GetVariable handles reading one of the caller's variables
+func (s *Service) GetVariable(c *gin.Context) {
+	owner, ok := s.requireOwner(c)
+	if !ok {
+		return
+	}
+	name := c.Param("name")
+	value, found := s.store().Variable(owner, name)
+	if !found {
+		s.logger().Error("Variable not found", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", name)
+		s.respondError(c, http.StatusNotFound, newError(CodeNotFound, fmt.Sprintf("variable '%s' not found", name)).WithDetail("name", name))
+		return
+	}
+	c.JSON(http.StatusOK, storage.Variable{Name: name, Value: value})
+}
+
+// CreateVariable handles creating a new variable, failing if it already exists
+func (s *Service) CreateVariable(c *gin.Context) {
+	s.handleSetVariable(c, "", false)
+}
+
+// PutVariable handles creating or replacing the variable named in the path
+func (s *Service) PutVariable(c *gin.Context) {
+	s.handleSetVariable(c, c.Param("name"), true)
+}
+
+// DeleteVariable handles deleting one of the caller's variables
+func (s *Service) DeleteVariable(c *gin.Context) {
+	owner, ok := s.requireOwner(c)
+	if !ok {
+		return
+	}
+	name := c.Param("name")
+	if !s.store().DeleteVariable(owner, name) {
+		s.logger().Error("Variable not found", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", name)
+		s.respondError(c, http.StatusNotFound, newError(CodeNotFound, fmt.Sprintf("variable '%s' not found", name)).WithDetail("name", name))
+		return
+	}
+	s.logger().Info("Variable deleted", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", name)
+	c.Status(http.StatusNoContent)
+}
+
+// handleSetVariable handles the common logic for creating and replacing variables.
+// A non-empty name overrides the name in the body.
+func (s *Service) handleSetVariable(c *gin.Context, name string, replace bool) {
+	owner, ok := s.requireOwner(c)
+	if !ok {
+		return
+	}
+	var req VariableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger().Error("Failed to bind variable JSON request", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if name != "" {
+		req.Name = name
+	}
+	if err := core.ValidateVariableName(req.Name); err != nil {
+		s.logger().Error("Invalid variable name", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", req.Name, "error", err)
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	value, err := s.resolveOperand(scopeFromContext(c), "value", req.Value)
+	if err != nil {
+		s.respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	_, exists := s.store().Variable(owner, req.Name)
+	if exists && !replace {
+		s.logger().Error("Variable already exists", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", req.Name)
+		s.respondError(c, http.StatusConflict, newError(CodeConflict, fmt.Sprintf("variable '%s' already exists", req.Name)).WithDetail("name", req.Name))
+		return
+	}
+	s.store().SetVariable(owner, req.Name, value)
+
+	s.logger().Info("Variable stored", "operation", c.Request.URL.Path, "method", c.Request.Method, "name", req.Name, "value", value)
+	status := http.StatusOK
+	if !exists {
+		status = http.StatusCreated
+	}
+	c.JSON(status, storage.Variable{Name: req.Name, Value: value})
+}
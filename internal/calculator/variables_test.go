@@ -0,0 +1,126 @@
+package calculator
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+
+	"calculator/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func withParam(c *gin.Context, key, value string) *gin.Context {
+	c.Params = append(c.Params, gin.Param{Key: key, Value: value})
+	return c
+}
+
+// TestConstantsAsOperands tests that constants can be used wherever a number is accepted
+func TestConstantsAsOperands(t *testing.T) {
+	s := &Service{}
+
+	c, w := setupTestContext("POST", "/multiply", map[string]any{"a": "pi", "b": 2})
+	s.Multiply(c)
+	assertResponse(t, w, http.StatusOK, 2*math.Pi, "")
+
+	c, w = setupTestContext("GET", "/power?a=e&b=2", nil)
+	s.PowerGET(c)
+	assertResponse(t, w, http.StatusOK, math.E*math.E, "")
+
+	c, w = setupTestContext("POST", "/negative", map[string]any{"a": "phi"})
+	s.Negative(c)
+	assertResponse(t, w, http.StatusOK, -math.Phi, "")
+
+	c, w = setupTestContext("POST", "/add", map[string]any{"a": "2.5", "b": "$sqrt2"})
+	s.Add(c)
+	assertResponse(t, w, http.StatusOK, 2.5+math.Sqrt2, "")
+
+	c, w = setupTestContext("POST", "/add", map[string]any{"a": "unknown", "b": 1})
+	s.Add(c)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeInvalidInput)
+}
+
+// TestVariables tests variable CRUD and use of variables as operands
+func TestVariables(t *testing.T) {
+	s := &Service{Store: storage.NewMemory()}
+
+	c, w := setupTestContext("POST", "/variables", map[string]any{"name": "x", "value": 4})
+	c.Request.Header.Set(APIKeyHeader, "alice")
+	s.CreateVariable(c)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	c, w = setupTestContext("POST", "/variables", map[string]any{"name": "x", "value": 5})
+	c.Request.Header.Set(APIKeyHeader, "alice")
+	s.CreateVariable(c)
+	assertErrorCode(t, w, http.StatusConflict, CodeConflict)
+
+	c, w = setupTestContext("PUT", "/variables/y", map[string]any{"value": "$x"})
+	c.Request.Header.Set(APIKeyHeader, "alice")
+	s.PutVariable(withParam(c, "name", "y"))
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	c, w = setupTestContext("POST", "/add", map[string]any{"a": "$x", "b": "$y"})
+	c.Request.Header.Set(APIKeyHeader, "alice")
+	s.Add(c)
+	assertResponse(t, w, http.StatusOK, 8, "")
+
+	c, w = setupTestContext("GET", "/sqrt?a=$x&b=0", nil)
+	c.Request.Header.Set(APIKeyHeader, "alice")
+	s.SqrtGET(c)
+	assertResponse(t, w, http.StatusOK, 2, "")
+
+	// Variables are scoped per API key
+	c, w = setupTestContext("POST", "/add", map[string]any{"a": "$x", "b": 1})
+	c.Request.Header.Set(APIKeyHeader, "bob")
+	s.Add(c)
+	assertErrorCode(t, w, http.StatusBadRequest, CodeUndefinedVariable)
+
+	c, w = setupTestContext("GET", "/variables", nil)
+	c.Request.Header.Set(APIKeyHeader, "alice")
+	s.ListVariables(c)
+	var list VariablesResponse
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, []storage.Variable{{Name: "x", Value: 4}, {Name: "y", Value: 4}}, list.Variables)
+
+	c, _ = setupTestContext("DELETE", "/variables/x", nil)
+	c.Request.Header.Set(APIKeyHeader, "alice")
+	s.DeleteVariable(withParam(c, "name", "x"))
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+
+	c, w = setupTestContext("GET", "/variables/x", nil)
+	c.Request.Header.Set(APIKeyHeader, "alice")
+	s.GetVariable(withParam(c, "name", "x"))
+	assertErrorCode(t, w, http.StatusNotFound, CodeNotFound)
+}
+
+// TestVariableValidation tests variable name and scope validation
+func TestVariableValidation(t *testing.T) {
+	s := &Service{}
+
+	tests := []struct {
+		name           string
+		session        string
+		body           map[string]any
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"missing scope", "", map[string]any{"name": "x", "value": 1}, http.StatusBadRequest, CodeInvalidInput},
+		{"invalid name", "s1", map[string]any{"name": "1x", "value": 1}, http.StatusBadRequest, CodeInvalidInput},
+		{"constant name", "s1", map[string]any{"name": "pi", "value": 3}, http.StatusBadRequest, CodeInvalidInput},
+		{"undefined value reference", "s1", map[string]any{"name": "x", "value": "$nope"}, http.StatusBadRequest, CodeUndefinedVariable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestContext("POST", "/variables", tt.body)
+			if tt.session != "" {
+				c.Request.Header.Set(SessionHeader, tt.session)
+			}
+			s.CreateVariable(c)
+			assertErrorCode(t, w, tt.expectedStatus, tt.expectedCode)
+		})
+	}
+}
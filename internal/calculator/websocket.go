@@ -0,0 +1,335 @@
+package calculator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"calculator/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket message types
+const (
+	WSTypeCalculate        = "calculate"
+	WSTypeSessionOperation = "session_operation"
+	WSTypeSession          = "session"
+	WSTypePing             = "ping"
+	WSTypePong             = "pong"
+	WSTypeResult           = "result"
+	WSTypeError            = "error"
+)
+
+// WebSocketLimits bounds each WebSocket connection. Zero fields take their defaults.
+type WebSocketLimits struct {
+	// MaxMessageBytes is the largest message a client may send; larger messages close the connection
+	MaxMessageBytes int64
+	// MessagesPerSecond and Burst rate-limit client messages; excess messages fail with RATE_LIMITED
+	MessagesPerSecond float64
+	Burst             int
+	// PingPeriod is how often the server pings; the connection closes when no pong arrives within PongWait
+	PingPeriod time.Duration
+	PongWait   time.Duration
+	// WriteWait bounds each write to the client
+	WriteWait time.Duration
+}
+
+// Default WebSocket connection limits
+var defaultWebSocketLimits = WebSocketLimits{
+	MaxMessageBytes:   4096,
+	MessagesPerSecond: 20,
+	Burst:             40,
+	PingPeriod:        30 * time.Second,
+	PongWait:          60 * time.Second,
+	WriteWait:         10 * time.Second,
+}
+
+// WSRequest is a message sent by a WebSocket client. ID is echoed in every reply so clients can
+// correlate results with requests. Type defaults to calculate.
+type WSRequest struct {
+	ID        string   `json:"id"`
+	Type      string   `json:"type"`
+	Operation string   `json:"operation"`
+	A         *Operand `json:"a"`
+	B         *Operand `json:"b"`
+	Session   string   `json:"session"`
+}
+
+// WSResponse is a message sent to a WebSocket client: a result, an error, a session state update or a pong
+type WSResponse struct {
+	ID      string           `json:"id,omitempty"`
+	Type    string           `json:"type"`
+	Result  *float64         `json:"result,omitempty"`
+	Error   string           `json:"error,omitempty"`
+	Code    string           `json:"code,omitempty"`
+	Details map[string]any   `json:"details,omitempty"`
+	Session *storage.Session `json:"session,omitempty"`
+}
+
+// webSocketLimits returns the connection limits with defaults for unset fields
+func (s *Service) webSocketLimits() WebSocketLimits {
+	limits := defaultWebSocketLimits
+	if s.WSLimits == nil {
+		return limits
+	}
+	if s.WSLimits.MaxMessageBytes > 0 {
+		limits.MaxMessageBytes = s.WSLimits.MaxMessageBytes
+	}
+	if s.WSLimits.MessagesPerSecond > 0 {
+		limits.MessagesPerSecond = s.WSLimits.MessagesPerSecond
+	}
+	if s.WSLimits.Burst > 0 {
+		limits.Burst = s.WSLimits.Burst
+	}
+	if s.WSLimits.PingPeriod > 0 {
+		limits.PingPeriod = s.WSLimits.PingPeriod
+	}
+	if s.WSLimits.PongWait > 0 {
+		limits.PongWait = s.WSLimits.PongWait
+	}
+	if s.WSLimits.WriteWait > 0 {
+		limits.WriteWait = s.WSLimits.WriteWait
+	}
+	return limits
+}
+
+// checkOrigin allows WebSocket connections from non-browser clients, the server's own host and Origins
+func (s *Service) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if slices.Contains(s.Origins, origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// tokenBucket is a simple rate limiter refilled at rate tokens per second up to burst
+type tokenBucket struct {
+	tokens float64
+	rate   float64
+	burst  float64
+	last   time.Time
+}
+
+// allow takes a token at now, reporting whether one was available
+func (b *tokenBucket) allow(now time.Time) bool {
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// encodeWSReply encodes reply, replacing a reply holding NaN or an infinity, which JSON cannot represent,
+// with a DOMAIN_ERROR reply so that the request fails rather than the connection
+func (s *Service) encodeWSReply(reply WSResponse) ([]byte, error) {
+	data, err := json.Marshal(reply)
+	var unsupported *json.UnsupportedValueError
+	if errors.As(err, &unsupported) {
+		s.logger().Error("WebSocket result is not finite", "id", reply.ID, "value", unsupported.Str)
+		return json.Marshal(wsError(reply.ID, newError(CodeDomainError, "result is not a finite number").
+			WithDetail("result", unsupported.Str)))
+	}
+	return data, err
+}
+
+// wsError returns the error reply to the request with the given id
+func wsError(id string, err error) WSResponse {
+	calcErr := toError(err)
+	return WSResponse{ID: id, Type: WSTypeError, Error: calcErr.Message, Code: calcErr.Code, Details: calcErr.Details}
+}
+
+// WebSocket handles a WebSocket connection on which the client sends operations as JSON messages and
+// receives results, errors and session state updates. The connection's scope comes from the API key and
+// session headers, or the api_key and session_id query parameters for browsers, which cannot set headers.
+func (s *Service) WebSocket(c *gin.Context) {
+	limits := s.webSocketLimits()
+	upgrader := websocket.Upgrader{CheckOrigin: s.checkOrigin}
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written an error response
+		s.logger().Error("WebSocket upgrade failed", "operation", c.Request.URL.Path, "method", c.Request.Method, "error", err)
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	sc := scopeFromContext(c)
+	if sc.owner == "" {
+		if key := c.Query("api_key"); key != "" {
+			sc.owner = qualify(sc.tenant, "key:"+key)
+			sc.principal.APIKey = key
+		}
+	}
+	if sc.session == "" {
+		sc.session = c.Query("session_id")
+		if sc.owner == "" && sc.session != "" {
+			sc.owner = qualify(sc.tenant, "session:"+sc.session)
+		}
+	}
+
+	client := clientFromContext(c)
+	s.logger().Info("WebSocket connection opened", "operation", c.Request.URL.Path, "remote", c.ClientIP())
+	start := time.Now()
+
+	conn.SetReadLimit(limits.MaxMessageBytes)
+	_ = conn.SetReadDeadline(time.Now().Add(limits.PongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(limits.PongWait))
+	})
+
+	// closeRefused ends the connection once its tenant is disabled or removed
+	closeRefused := func(err *Error) {
+		s.logger().Info("WebSocket connection closed for tenant", "operation", c.Request.URL.Path, "tenant", sc.tenant, "code", err.Code)
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Message), time.Now().Add(limits.WriteWait))
+		_ = conn.Close()
+	}
+
+	// Ping the client until the connection closes, closing it when the tenant is disabled while idle;
+	// WriteControl may be called concurrently with writes
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(limits.PingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.tenantClosed(sc.tenant); err != nil {
+					closeRefused(err)
+					return
+				}
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(limits.WriteWait)); err != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	bucket := &tokenBucket{tokens: float64(limits.Burst), rate: limits.MessagesPerSecond, burst: float64(limits.Burst), last: time.Now()}
+	messages := 0
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				s.logger().Error("WebSocket connection failed", "operation", c.Request.URL.Path, "error", err)
+			}
+			break
+		}
+		messages++
+
+		var replies []WSResponse
+		var req WSRequest
+		allowed := bucket.allow(time.Now())
+		var refused *Error
+		if allowed {
+			refused = s.admitMessage(sc.tenant)
+		}
+		switch {
+		case !allowed:
+			// Correlate the rejection when the id can be read
+			_ = json.Unmarshal(data, &req)
+			s.logger().Error("WebSocket message rate limited", "operation", c.Request.URL.Path, "id", req.ID)
+			replies = []WSResponse{wsError(req.ID, newError(CodeRateLimited, "too many messages").
+				WithDetail("messages_per_second", limits.MessagesPerSecond).
+				WithDetail("burst", limits.Burst))}
+		case refused != nil:
+			_ = json.Unmarshal(data, &req)
+			s.logger().Error("WebSocket message refused for tenant", "operation", c.Request.URL.Path, "id", req.ID, "tenant", sc.tenant, "code", refused.Code)
+			replies = []WSResponse{wsError(req.ID, refused)}
+		case json.Unmarshal(data, &req) != nil:
+			s.logger().Error("Invalid WebSocket message", "operation", c.Request.URL.Path)
+			replies = []WSResponse{wsError("", newError(CodeInvalidInput, "message must be a JSON object"))}
+		default:
+			replies = s.handleWSRequest(c.Request.Context(), sc, client, req)
+		}
+
+		for _, reply := range replies {
+			data, err := s.encodeWSReply(reply)
+			if err == nil {
+				_ = conn.SetWriteDeadline(time.Now().Add(limits.WriteWait))
+				err = conn.WriteMessage(websocket.TextMessage, data)
+			}
+			if err != nil {
+				s.logger().Error("WebSocket write failed", "operation", c.Request.URL.Path, "error", err)
+				return
+			}
+		}
+		if refused != nil && tenantRefused(refused) {
+			closeRefused(refused)
+			break
+		}
+	}
+
+	s.logger().Info("WebSocket connection closed", "operation", c.Request.URL.Path, "messages", messages, "duration", time.Since(start))
+}
+
+// handleWSRequest processes a client message within the connection's scope and returns the replies.
+// Calculations are published to the events feed as made by client.
+func (s *Service) handleWSRequest(ctx context.Context, sc scope, client string, req WSRequest) []WSResponse {
+	s.logger().Debug("Processing WebSocket message", "id", req.ID, "type", req.Type, "name", req.Operation)
+
+	if req.Session != "" {
+		sc.session = req.Session
+		if sc.owner == "" {
+			sc.owner = qualify(sc.tenant, "session:"+req.Session)
+		}
+	}
+
+	switch req.Type {
+	case WSTypeCalculate, "":
+		start := time.Now()
+		result, err := s.evaluate(ctx, sc, req.Operation, req.A, req.B)
+		s.publishEvaluation(TransportWebSocket, sc.tenant, client, req.Operation, req.A, req.B, start, result, err)
+		if err != nil {
+			return []WSResponse{wsError(req.ID, err)}
+		}
+		replies := []WSResponse{{ID: req.ID, Type: WSTypeResult, Result: &result}}
+		// The result became the session's ANS
+		if session, ok := s.tenantSession(sc.tenant, sc.session); ok && sc.session != "" {
+			replies = append(replies, WSResponse{ID: req.ID, Type: WSTypeSession, Session: &session})
+		}
+		return replies
+	case WSTypeSessionOperation:
+		if req.Session == "" {
+			return []WSResponse{wsError(req.ID, newError(CodeInvalidInput, "session is required").WithDetail("param", "session"))}
+		}
+		start := time.Now()
+		result, session, err := s.applySessionOperation(ctx, sc, req.Operation, SessionOperationRequest{A: req.A, B: req.B})
+		s.publishEvaluation(TransportWebSocket, sc.tenant, client, "sessions/:id/operations/"+req.Operation, req.A, req.B, start, result, err)
+		if err != nil {
+			return []WSResponse{wsError(req.ID, err)}
+		}
+		return []WSResponse{
+			{ID: req.ID, Type: WSTypeResult, Result: &result},
+			{ID: req.ID, Type: WSTypeSession, Session: &session},
+		}
+	case WSTypeSession:
+		session, ok := s.tenantSession(sc.tenant, req.Session)
+		if !ok {
+			return []WSResponse{wsError(req.ID, sessionNotFound(req.Session))}
+		}
+		return []WSResponse{{ID: req.ID, Type: WSTypeSession, Session: &session}}
+	case WSTypePing:
+		return []WSResponse{{ID: req.ID, Type: WSTypePong}}
+	}
+	return []WSResponse{wsError(req.ID, newError(CodeInvalidInput, fmt.Sprintf("unknown message type '%s'", req.Type)).
+		WithDetail("type", req.Type).
+		WithDetail("available", []string{WSTypeCalculate, WSTypeSessionOperation, WSTypeSession, WSTypePing}))}
+}
@@ -0,0 +1,197 @@
+package calculator
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialWebSocket serves s on a test server and opens a WebSocket connection with the given headers
+func dialWebSocket(t *testing.T, s *Service, query string, header http.Header) *websocket.Conn {
+	t.Helper()
+	r := gin.New()
+	r.GET("/ws", s.WebSocket)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws"+query, header)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+// exchange sends req and reads n replies
+func exchange(t *testing.T, conn *websocket.Conn, req any, n int) []WSResponse {
+	t.Helper()
+	require.NoError(t, conn.WriteJSON(req))
+	replies := make([]WSResponse, n)
+	for i := range replies {
+		require.NoError(t, conn.ReadJSON(&replies[i]))
+	}
+	return replies
+}
+
+// TestWebSocketCalculate tests results and errors correlated with their request ids
+func TestWebSocketCalculate(t *testing.T) {
+	conn := dialWebSocket(t, &Service{}, "", nil)
+
+	tests := []struct {
+		name     string
+		req      WSRequest
+		expected WSResponse
+	}{
+		{"binary", WSRequest{ID: "1", Operation: "add", A: &Operand{Value: 2}, B: &Operand{Value: 3}}, WSResponse{ID: "1", Type: WSTypeResult, Result: ptr(5.0)}},
+		{"unary", WSRequest{ID: "2", Type: WSTypeCalculate, Operation: "negative", A: &Operand{Value: 1}}, WSResponse{ID: "2", Type: WSTypeResult, Result: ptr(-1.0)}},
+		{"zero result", WSRequest{ID: "3", Operation: "subtract", A: &Operand{Value: 1}, B: &Operand{Value: 1}}, WSResponse{ID: "3", Type: WSTypeResult, Result: ptr(0.0)}},
+		{"ping", WSRequest{ID: "4", Type: WSTypePing}, WSResponse{ID: "4", Type: WSTypePong}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, []WSResponse{tt.expected}, exchange(t, conn, tt.req, 1))
+		})
+	}
+
+	errorTests := []struct {
+		name string
+		req  any
+		id   string
+		code string
+	}{
+		{"division by zero", WSRequest{ID: "5", Operation: "divide", A: &Operand{Value: 1}, B: &Operand{Value: 0}}, "5", CodeDivisionByZero},
+		{"unknown operation", WSRequest{ID: "6", Operation: "modulo", A: &Operand{Value: 1}}, "6", CodeUnknownOperation},
+		{"missing operand", WSRequest{ID: "7", Operation: "add", A: &Operand{Value: 1}}, "7", CodeInvalidInput},
+		{"unknown type", WSRequest{ID: "8", Type: "subscribe"}, "8", CodeInvalidInput},
+		{"invalid message", "not an object", "", CodeInvalidInput},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply := exchange(t, conn, tt.req, 1)[0]
+			assert.Equal(t, WSTypeError, reply.Type)
+			assert.Equal(t, tt.id, reply.ID)
+			assert.Equal(t, tt.code, reply.Code)
+			assert.NotEmpty(t, reply.Error)
+		})
+	}
+}
+
+// TestWebSocketSessions tests session operations and state updates on one connection
+func TestWebSocketSessions(t *testing.T) {
+	s := &Service{}
+	s.store().CreateSession(storage.Session{ID: "sess", Accumulator: 10})
+	s.store().SetVariable("key:alice", "x", 4)
+	conn := dialWebSocket(t, s, "?api_key=alice", nil)
+
+	replies := exchange(t, conn, WSRequest{ID: "1", Type: WSTypeSessionOperation, Session: "sess", Operation: "multiply", B: &Operand{Ref: "$x"}}, 2)
+	assert.Equal(t, WSResponse{ID: "1", Type: WSTypeResult, Result: ptr(40.0)}, replies[0])
+	assert.Equal(t, WSTypeSession, replies[1].Type)
+	assert.Equal(t, 40.0, replies[1].Session.Accumulator)
+	assert.Equal(t, 40.0, replies[1].Session.Ans)
+
+	// Calculations naming a session update its ANS
+	replies = exchange(t, conn, WSRequest{ID: "2", Session: "sess", Operation: "add", A: &Operand{Ref: "ans"}, B: &Operand{Value: 2}}, 2)
+	assert.Equal(t, 42.0, *replies[0].Result)
+	assert.Equal(t, 42.0, replies[1].Session.Ans)
+	assert.Equal(t, 40.0, replies[1].Session.Accumulator)
+
+	replies = exchange(t, conn, WSRequest{ID: "3", Type: WSTypeSession, Session: "sess"}, 1)
+	assert.Equal(t, "sess", replies[0].Session.ID)
+
+	replies = exchange(t, conn, WSRequest{ID: "4", Type: WSTypeSession, Session: "missing"}, 1)
+	assert.Equal(t, CodeNotFound, replies[0].Code)
+
+	replies = exchange(t, conn, WSRequest{ID: "5", Type: WSTypeSessionOperation, Operation: "add"}, 1)
+	assert.Equal(t, CodeInvalidInput, replies[0].Code)
+
+	// Calculations are recorded in the connection owner's history
+	assert.Len(t, s.store().History("key:alice", 0), 1)
+}
+
+// TestWebSocketOverflow tests that results beyond float64 fail the request with a DOMAIN_ERROR frame and
+// leave the connection open
+func TestWebSocketOverflow(t *testing.T) {
+	s := &Service{}
+	s.store().CreateSession(storage.Session{ID: "inf", Accumulator: math.Inf(1)})
+	conn := dialWebSocket(t, s, "", nil)
+
+	reply := exchange(t, conn, WSRequest{ID: "1", Operation: "multiply", A: &Operand{Value: 1e308}, B: &Operand{Value: 10}}, 1)[0]
+	assert.Equal(t, WSTypeError, reply.Type)
+	assert.Equal(t, "1", reply.ID)
+	assert.Equal(t, CodeDomainError, reply.Code)
+
+	// A reply JSON cannot encode is replaced by an error frame
+	reply = exchange(t, conn, WSRequest{ID: "2", Type: WSTypeSession, Session: "inf"}, 1)[0]
+	assert.Equal(t, WSTypeError, reply.Type)
+	assert.Equal(t, "2", reply.ID)
+	assert.Equal(t, CodeDomainError, reply.Code)
+
+	assert.Equal(t, []WSResponse{{ID: "3", Type: WSTypePong}}, exchange(t, conn, WSRequest{ID: "3", Type: WSTypePing}, 1))
+}
+
+// TestWebSocketLimits tests rate limiting, the message size limit and server pings
+func TestWebSocketLimits(t *testing.T) {
+	t.Run("rate limit", func(t *testing.T) {
+		conn := dialWebSocket(t, &Service{WSLimits: &WebSocketLimits{MessagesPerSecond: 0.001, Burst: 2}}, "", nil)
+		for i, code := range []string{"", "", CodeRateLimited} {
+			reply := exchange(t, conn, WSRequest{ID: string(rune('a' + i)), Operation: "add", A: &Operand{Value: 1}, B: &Operand{Value: 1}}, 1)[0]
+			assert.Equal(t, code, reply.Code)
+			assert.Equal(t, string(rune('a'+i)), reply.ID)
+		}
+	})
+
+	t.Run("message size", func(t *testing.T) {
+		conn := dialWebSocket(t, &Service{WSLimits: &WebSocketLimits{MaxMessageBytes: 64}}, "", nil)
+		require.NoError(t, conn.WriteJSON(WSRequest{ID: strings.Repeat("x", 100), Operation: "add"}))
+		_, _, err := conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseMessageTooBig))
+	})
+
+	t.Run("ping", func(t *testing.T) {
+		conn := dialWebSocket(t, &Service{WSLimits: &WebSocketLimits{PingPeriod: 10 * time.Millisecond}}, "", nil)
+		pinged := make(chan struct{}, 1)
+		conn.SetPingHandler(func(string) error {
+			select {
+			case pinged <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+		go func() {
+			// Reading processes control frames
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+		select {
+		case <-pinged:
+		case <-time.After(2 * time.Second):
+			t.Fatal("no ping received")
+		}
+	})
+
+	t.Run("origin", func(t *testing.T) {
+		s := &Service{Origins: []string{"http://localhost:3000"}}
+		dialWebSocket(t, s, "", http.Header{"Origin": {"http://localhost:3000"}})
+
+		r := gin.New()
+		r.GET("/ws", s.WebSocket)
+		server := httptest.NewServer(r)
+		defer server.Close()
+		_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", http.Header{"Origin": {"http://evil.example"}})
+		require.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
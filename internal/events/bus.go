@@ -0,0 +1,113 @@
+// Package events provides a concurrency-safe publish/subscribe bus that keeps the most recent events in
+// a bounded ring buffer, so that subscribers can resume after the last event they received.
+package events
+
+import "sync"
+
+// Event is a published value with its sequence number. IDs start at 1 and increase by one per event.
+type Event[T any] struct {
+	ID   uint64
+	Data T
+}
+
+// Subscription receives events published after it was created. C is closed when the subscription is
+// closed or when the subscriber falls too far behind, in which case it may resubscribe from its last ID.
+type Subscription[T any] struct {
+	C <-chan Event[T]
+
+	bus *Bus[T]
+	ch  chan Event[T]
+}
+
+// Close stops the subscription and closes C
+func (s *Subscription[T]) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.remove(s)
+}
+
+// Bus broadcasts events to subscribers. The zero value is not usable; create one with New.
+type Bus[T any] struct {
+	mu          sync.Mutex
+	ring        []Event[T]
+	next        int // index of the ring slot written next
+	lastID      uint64
+	subscribers map[*Subscription[T]]struct{}
+}
+
+// New returns a bus remembering the last size events
+func New[T any](size int) *Bus[T] {
+	return &Bus[T]{
+		ring:        make([]Event[T], 0, max(size, 1)),
+		subscribers: make(map[*Subscription[T]]struct{}),
+	}
+}
+
+// Publish records data as the next event and delivers it to every subscriber, returning its ID.
+// Subscribers whose buffer is full are dropped rather than blocking the publisher.
+func (b *Bus[T]) Publish(data T) uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	event := Event[T]{ID: b.lastID, Data: data}
+	if len(b.ring) < cap(b.ring) {
+		b.ring = append(b.ring, event)
+	} else {
+		b.ring[b.next] = event
+	}
+	b.next = (b.next + 1) % cap(b.ring)
+
+	for sub := range b.subscribers {
+		select {
+		case sub.ch <- event:
+		default:
+			b.remove(sub)
+		}
+	}
+	return event.ID
+}
+
+// Subscribe returns a subscription with the given buffer size together with the buffered events published
+// after the event with ID after. missed reports whether events after it have already left the buffer.
+func (b *Bus[T]) Subscribe(after uint64, buffer int) (sub *Subscription[T], backlog []Event[T], missed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, event := range b.ordered() {
+		if event.ID > after {
+			backlog = append(backlog, event)
+		}
+	}
+	oldest := b.lastID + 1
+	if len(backlog) > 0 {
+		oldest = backlog[0].ID
+	}
+	missed = after+1 < oldest && after < b.lastID
+
+	ch := make(chan Event[T], max(buffer, 1))
+	sub = &Subscription[T]{C: ch, bus: b, ch: ch}
+	b.subscribers[sub] = struct{}{}
+	return sub, backlog, missed
+}
+
+// LastID returns the ID of the most recent event, or 0 if none was published
+func (b *Bus[T]) LastID() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastID
+}
+
+// ordered returns the buffered events from oldest to newest; the caller must hold mu
+func (b *Bus[T]) ordered() []Event[T] {
+	if len(b.ring) < cap(b.ring) {
+		return b.ring
+	}
+	return append(append([]Event[T]{}, b.ring[b.next:]...), b.ring[:b.next]...)
+}
+
+// remove unregisters sub and closes its channel once; the caller must hold mu
+func (b *Bus[T]) remove(sub *Subscription[T]) {
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.ch)
+	}
+}
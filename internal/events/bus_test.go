@@ -0,0 +1,84 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ids returns the IDs of events
+func ids[T any](events []Event[T]) []uint64 {
+	var result []uint64
+	for _, event := range events {
+		result = append(result, event.ID)
+	}
+	return result
+}
+
+// TestBusResume tests replaying buffered events after a last-seen ID
+func TestBusResume(t *testing.T) {
+	b := New[string](3)
+	_, backlog, missed := b.Subscribe(0, 1)
+	assert.Empty(t, backlog)
+	assert.False(t, missed)
+
+	for _, data := range []string{"a", "b", "c", "d", "e"} {
+		b.Publish(data)
+	}
+	assert.Equal(t, uint64(5), b.LastID())
+
+	tests := []struct {
+		name    string
+		after   uint64
+		backlog []uint64
+		missed  bool
+	}{
+		{"from the start", 0, []uint64{3, 4, 5}, true},
+		{"evicted", 1, []uint64{3, 4, 5}, true},
+		{"oldest buffered", 2, []uint64{3, 4, 5}, false},
+		{"partial", 3, []uint64{4, 5}, false},
+		{"up to date", 5, nil, false},
+		{"ahead", 9, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, backlog, missed := b.Subscribe(tt.after, 1)
+			defer sub.Close()
+			assert.Equal(t, tt.backlog, ids(backlog))
+			assert.Equal(t, tt.missed, missed)
+		})
+	}
+
+	_, backlog, _ = b.Subscribe(3, 1)
+	assert.Equal(t, "d", backlog[0].Data)
+}
+
+// TestBusDelivery tests live delivery, closing and dropping slow subscribers
+func TestBusDelivery(t *testing.T) {
+	b := New[int](10)
+	fast, _, _ := b.Subscribe(0, 10)
+	slow, _, _ := b.Subscribe(0, 1)
+	closed, _, _ := b.Subscribe(0, 10)
+	closed.Close()
+	closed.Close()
+
+	b.Publish(1)
+	b.Publish(2)
+
+	event := <-fast.C
+	assert.Equal(t, Event[int]{ID: 1, Data: 1}, event)
+	event = <-fast.C
+	assert.Equal(t, uint64(2), event.ID)
+
+	// The slow subscriber received what fitted in its buffer and was then dropped
+	event, ok := <-slow.C
+	require.True(t, ok)
+	assert.Equal(t, 1, event.Data)
+	_, ok = <-slow.C
+	assert.False(t, ok)
+	slow.Close()
+
+	_, ok = <-closed.C
+	assert.False(t, ok)
+}
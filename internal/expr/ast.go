@@ -0,0 +1,191 @@
+// Package expr parses and evaluates arithmetic expressions such as "x^2 + y*pi".
+//
+// Expressions support numbers, identifiers, the operators + - * / ^ (with ^ right-associative and
+// binding tighter than unary minus), implicit multiplication ("2x", "3(x+1)") and function calls.
+package expr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Node is a node of an expression tree
+type Node interface {
+	// String formats the node as a parseable expression with minimal parentheses
+	String() string
+	// precedence returns the binding strength used when formatting
+	precedence() int
+}
+
+// Operator precedences, higher binds tighter
+const (
+	precSum = iota + 1
+	precProduct
+	precUnary
+	precPower
+	precAtom
+)
+
+// Num is a numeric literal
+type Num struct {
+	Value float64
+}
+
+// Var is a reference to a variable, parameter or constant
+type Var struct {
+	Name string
+}
+
+// Unary is a negation
+type Unary struct {
+	Op byte
+	X  Node
+}
+
+// Binary is an arithmetic operation: one of + - * / ^
+type Binary struct {
+	Op   byte
+	L, R Node
+}
+
+// Call is a function call
+type Call struct {
+	Name string
+	Args []Node
+}
+
+func (n *Num) String() string {
+	return strconv.FormatFloat(n.Value, 'g', -1, 64)
+}
+
+func (n *Num) precedence() int {
+	if n.Value < 0 {
+		return precUnary
+	}
+	return precAtom
+}
+
+func (n *Var) String() string { return n.Name }
+
+func (n *Var) precedence() int { return precAtom }
+
+func (n *Unary) String() string {
+	return string(n.Op) + wrap(n.X, precUnary, true)
+}
+
+func (n *Unary) precedence() int { return precUnary }
+
+func (n *Binary) String() string {
+	prec := n.precedence()
+	// ^ is right-associative; - and / are left-associative and need parentheses on the right
+	left := wrap(n.L, prec, n.Op == '^')
+	right := wrap(n.R, prec, n.Op == '-' || n.Op == '/')
+	if n.Op == '^' {
+		return left + "^" + right
+	}
+	return left + " " + string(n.Op) + " " + right
+}
+
+func (n *Binary) precedence() int {
+	switch n.Op {
+	case '+', '-':
+		return precSum
+	case '*', '/':
+		return precProduct
+	default:
+		return precPower
+	}
+}
+
+func (n *Call) String() string {
+	args := make([]string, len(n.Args))
+	for i, arg := range n.Args {
+		args[i] = arg.String()
+	}
+	return n.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func (n *Call) precedence() int { return precAtom }
+
+// wrap formats n, parenthesizing it when it binds looser than prec (or equally, when strict)
+func wrap(n Node, prec int, strict bool) string {
+	p := n.precedence()
+	if p < prec || (strict && p == prec) {
+		return "(" + n.String() + ")"
+	}
+	return n.String()
+}
+
+// Identifiers returns the distinct variable names referenced by n in order of first appearance
+func Identifiers(n Node) []string {
+	var names []string
+	seen := make(map[string]bool)
+	Walk(n, func(node Node) {
+		if v, ok := node.(*Var); ok && !seen[v.Name] {
+			seen[v.Name] = true
+			names = append(names, v.Name)
+		}
+	})
+	return names
+}
+
+// Calls returns the function calls made by n in order of appearance
+func Calls(n Node) []*Call {
+	var calls []*Call
+	Walk(n, func(node Node) {
+		if call, ok := node.(*Call); ok {
+			calls = append(calls, call)
+		}
+	})
+	return calls
+}
+
+// Walk calls fn for n and every node below it, parents before children
+func Walk(n Node, fn func(Node)) {
+	fn(n)
+	switch n := n.(type) {
+	case *Unary:
+		Walk(n.X, fn)
+	case *Binary:
+		Walk(n.L, fn)
+		Walk(n.R, fn)
+	case *Call:
+		for _, arg := range n.Args {
+			Walk(arg, fn)
+		}
+	}
+}
+
+// Substitute returns a copy of n with every variable named in bindings replaced by its node
+func Substitute(n Node, bindings map[string]Node) Node {
+	switch n := n.(type) {
+	case *Var:
+		if replacement, ok := bindings[n.Name]; ok {
+			return replacement
+		}
+		return n
+	case *Unary:
+		return &Unary{Op: n.Op, X: Substitute(n.X, bindings)}
+	case *Binary:
+		return &Binary{Op: n.Op, L: Substitute(n.L, bindings), R: Substitute(n.R, bindings)}
+	case *Call:
+		args := make([]Node, len(n.Args))
+		for i, arg := range n.Args {
+			args[i] = Substitute(arg, bindings)
+		}
+		return &Call{Name: n.Name, Args: args}
+	default:
+		return n
+	}
+}
+
+// DependsOn reports whether n references the variable name
+func DependsOn(n Node, name string) bool {
+	found := false
+	Walk(n, func(node Node) {
+		if v, ok := node.(*Var); ok && v.Name == name {
+			found = true
+		}
+	})
+	return found
+}
@@ -0,0 +1,138 @@
+package expr
+
+// Derive returns the simplified derivative of n with respect to the variable v.
+// Identifiers other than v are treated as constants. Calls to functions other than the builtins
+// report ErrUndefined; floor and ceil are treated as piecewise constant.
+func Derive(n Node, v string) (Node, error) {
+	d, err := derive(n, v)
+	if err != nil {
+		return nil, err
+	}
+	return Simplify(d), nil
+}
+
+// Node constructors used to keep the differentiation rules readable
+func num(value float64) Node              { return &Num{Value: value} }
+func add(l, r Node) Node                  { return &Binary{Op: '+', L: l, R: r} }
+func sub(l, r Node) Node                  { return &Binary{Op: '-', L: l, R: r} }
+func mul(l, r Node) Node                  { return &Binary{Op: '*', L: l, R: r} }
+func div(l, r Node) Node                  { return &Binary{Op: '/', L: l, R: r} }
+func pow(l, r Node) Node                  { return &Binary{Op: '^', L: l, R: r} }
+func call(name string, args ...Node) Node { return &Call{Name: name, Args: args} }
+
+func derive(n Node, v string) (Node, error) {
+	if !DependsOn(n, v) {
+		return num(0), nil
+	}
+	switch n := n.(type) {
+	case *Var:
+		return num(1), nil
+	case *Unary:
+		dx, err := derive(n.X, v)
+		if err != nil {
+			return nil, err
+		}
+		return neg(dx), nil
+	case *Binary:
+		return deriveBinary(n, v)
+	case *Call:
+		return deriveCall(n, v)
+	default:
+		return num(0), nil
+	}
+}
+
+func deriveBinary(n *Binary, v string) (Node, error) {
+	u, w := n.L, n.R
+	du, err := derive(u, v)
+	if err != nil {
+		return nil, err
+	}
+	dw, err := derive(w, v)
+	if err != nil {
+		return nil, err
+	}
+	switch n.Op {
+	case '+':
+		return add(du, dw), nil
+	case '-':
+		return sub(du, dw), nil
+	case '*':
+		// (uw)' = u'w + uw'
+		return add(mul(du, w), mul(u, dw)), nil
+	case '/':
+		// (u/w)' = (u'w - uw') / w^2
+		return div(sub(mul(du, w), mul(u, dw)), pow(w, num(2))), nil
+	default:
+		switch {
+		case !DependsOn(w, v):
+			// (u^c)' = c u^(c-1) u'
+			return mul(mul(w, pow(u, sub(w, num(1)))), du), nil
+		case !DependsOn(u, v):
+			// (c^w)' = c^w ln(c) w'
+			return mul(mul(n, call("ln", u)), dw), nil
+		default:
+			// (u^w)' = u^w (w' ln(u) + w u' / u)
+			return mul(n, add(mul(dw, call("ln", u)), div(mul(w, du), u))), nil
+		}
+	}
+}
+
+func deriveCall(n *Call, v string) (Node, error) {
+	b, ok := builtins[n.Name]
+	if !ok {
+		return nil, errorf(ErrUndefined, "cannot differentiate unknown function '%s'", n.Name)
+	}
+	if len(n.Args) != b.Arity {
+		return nil, errorf(ErrArity, "%s expects %d argument(s), got %d", n.Name, b.Arity, len(n.Args))
+	}
+	u := n.Args[0]
+	du, err := derive(u, v)
+	if err != nil {
+		return nil, err
+	}
+
+	var outer Node
+	switch n.Name {
+	case "sqrt":
+		outer = div(num(1), mul(num(2), n))
+	case "root":
+		if DependsOn(n.Args[1], v) {
+			return nil, errorf(ErrDomain, "cannot differentiate root with a variable degree")
+		}
+		// root(u, k)' = root(u, k) / (k u) u'
+		outer = div(n, mul(n.Args[1], u))
+	case "abs":
+		outer = div(u, n)
+	case "exp":
+		outer = n
+	case "ln":
+		outer = div(num(1), u)
+	case "log":
+		outer = div(num(1), mul(u, call("ln", num(10))))
+	case "log2":
+		outer = div(num(1), mul(u, call("ln", num(2))))
+	case "sin":
+		outer = call("cos", u)
+	case "cos":
+		outer = neg(call("sin", u))
+	case "tan":
+		outer = div(num(1), pow(call("cos", u), num(2)))
+	case "asin":
+		outer = div(num(1), call("sqrt", sub(num(1), pow(u, num(2)))))
+	case "acos":
+		outer = neg(div(num(1), call("sqrt", sub(num(1), pow(u, num(2))))))
+	case "atan":
+		outer = div(num(1), add(num(1), pow(u, num(2))))
+	case "sinh":
+		outer = call("cosh", u)
+	case "cosh":
+		outer = call("sinh", u)
+	case "tanh":
+		outer = div(num(1), pow(call("cosh", u), num(2)))
+	default:
+		// floor and ceil are constant between their discontinuities
+		return num(0), nil
+	}
+	return mul(outer, du), nil
+}
@@ -0,0 +1,105 @@
+package expr
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSimplify tests constant folding and identity elimination
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2 * 3 + 4", "10"},
+		{"x + 0", "x"},
+		{"0 * x + 1 * x", "x"},
+		{"x * 2", "2 * x"},
+		{"2 * (3 * x)", "6 * x"},
+		{"x + x", "2 * x"},
+		{"x * x", "x^2"},
+		{"x - x", "0"},
+		{"x - -y", "x + y"},
+		{"-(-x)", "x"},
+		{"x^1 + y^0", "x + 1"},
+		{"(x^2)^3", "x^6"},
+		{"(x^2)^0.5", "(x^2)^0.5"},
+		{"1 / 0", "1 / 0"},
+		{"sqrt(4 * 4)", "sqrt(16)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			node, err := Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, Simplify(node).String())
+		})
+	}
+}
+
+// TestDerive tests symbolic differentiation against known derivatives
+func TestDerive(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x^2", "2 * x"},
+		{"3x^3 + 2x - 7", "9 * x^2 + 2"},
+		{"a*x^2 + b*x + c", "2 * a * x + b"},
+		{"-x^2", "-2 * x"},
+		{"1/x", "-1 / x^2"},
+		{"ln(x)/x", "(1 - ln(x)) / x^2"},
+		{"x*exp(x)", "exp(x) + x * exp(x)"},
+		{"sin(x^2)", "2 * cos(x^2) * x"},
+		{"cos(2x)", "-2 * sin(2 * x)"},
+		{"sqrt(x)", "1 / (2 * sqrt(x))"},
+		{"root(x, 3)", "root(x, 3) / (3 * x)"},
+		{"2^x", "2^x * ln(2)"},
+		{"x^x", "x^x * (ln(x) + 1)"},
+		{"log(x)", "1 / (x * ln(10))"},
+		{"y^2", "0"},
+		{"floor(x)", "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			node, err := Parse(tt.input)
+			assert.NoError(t, err)
+			d, err := Derive(node, "x")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, d.String())
+		})
+	}
+}
+
+// TestDeriveNumerically checks derivatives against central differences
+func TestDeriveNumerically(t *testing.T) {
+	for _, input := range []string{"tan(x)", "asin(x)", "acos(x)", "atan(x^2)", "tanh(x)", "abs(x - 2)", "log2(x)", "(x + 1)/(x - 1)", "sinh(x) * cosh(x)"} {
+		t.Run(input, func(t *testing.T) {
+			node, err := Parse(input)
+			assert.NoError(t, err)
+			d, err := Derive(node, "x")
+			assert.NoError(t, err)
+
+			const x, h = 0.3, 1e-6
+			hi, _ := Eval(node, Vars{"x": x + h})
+			lo, _ := Eval(node, Vars{"x": x - h})
+			actual, err := Eval(d, Vars{"x": x})
+			assert.NoError(t, err)
+			assert.InDelta(t, (hi-lo)/(2*h), actual, 1e-6)
+			assert.False(t, math.IsNaN(actual))
+		})
+	}
+}
+
+// TestDeriveErrors tests expressions that cannot be differentiated
+func TestDeriveErrors(t *testing.T) {
+	for _, input := range []string{"f(x)", "root(8, x)"} {
+		node, err := Parse(input)
+		assert.NoError(t, err)
+		_, err = Derive(node, "x")
+		var exprErr *Error
+		assert.True(t, errors.As(err, &exprErr), input)
+	}
+}
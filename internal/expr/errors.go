@@ -0,0 +1,48 @@
+package expr
+
+import "fmt"
+
+// ErrorKind classifies expression errors so callers can map them to their own error codes
+type ErrorKind int
+
+// Error kinds
+const (
+	// ErrSyntax reports malformed input
+	ErrSyntax ErrorKind = iota + 1
+	// ErrUndefined reports an unknown identifier or function
+	ErrUndefined
+	// ErrArity reports a function called with the wrong number of arguments
+	ErrArity
+	// ErrDivisionByZero reports a division by zero
+	ErrDivisionByZero
+	// ErrDomain reports an argument outside a function's domain
+	ErrDomain
+	// ErrRecursion reports recursive or too deeply nested function calls
+	ErrRecursion
+)
+
+// Error is an expression parsing or evaluation error
+type Error struct {
+	Kind ErrorKind
+	// Pos is the byte offset in the source for syntax errors, -1 otherwise
+	Pos int
+	Msg string
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Pos >= 0 {
+		return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+	}
+	return e.Msg
+}
+
+// errorf creates an evaluation error of the given kind
+func errorf(kind ErrorKind, format string, args ...any) *Error {
+	return &Error{Kind: kind, Pos: -1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// syntaxError creates a syntax error at pos
+func syntaxError(pos int, format string, args ...any) *Error {
+	return &Error{Kind: ErrSyntax, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,185 @@
+package expr
+
+import (
+	"math"
+	"sort"
+)
+
+// Env resolves the identifiers and non-builtin functions referenced by an expression
+type Env interface {
+	// Lookup returns the value of the named variable
+	Lookup(name string) (float64, bool)
+	// Call invokes a function that is not a builtin; it reports ErrUndefined for unknown names
+	Call(name string, args []float64) (float64, error)
+}
+
+// Vars is an Env of plain variables without user-defined functions
+type Vars map[string]float64
+
+// Lookup returns the value of the named variable
+func (v Vars) Lookup(name string) (float64, bool) {
+	value, ok := v[name]
+	return value, ok
+}
+
+// Call reports every non-builtin function as undefined
+func (v Vars) Call(name string, args []float64) (float64, error) {
+	return 0, errorf(ErrUndefined, "undefined function '%s'", name)
+}
+
+// Builtin is a function available in every expression
+type Builtin struct {
+	Arity int
+	Fn    func(args []float64) (float64, error)
+}
+
+// unary wraps a single-argument math function, reporting NaN results as domain errors
+func unary(name string, fn func(float64) float64) Builtin {
+	return Builtin{Arity: 1, Fn: func(args []float64) (float64, error) {
+		result := fn(args[0])
+		if math.IsNaN(result) {
+			return 0, errorf(ErrDomain, "%s(%g) is undefined", name, args[0])
+		}
+		return result, nil
+	}}
+}
+
+// positive wraps a logarithm, which is only defined for positive arguments
+func positive(name string, fn func(float64) float64) Builtin {
+	return Builtin{Arity: 1, Fn: func(args []float64) (float64, error) {
+		if args[0] <= 0 {
+			return 0, errorf(ErrDomain, "%s is only defined for positive numbers", name)
+		}
+		return fn(args[0]), nil
+	}}
+}
+
+var builtins = map[string]Builtin{
+	"sqrt": {Arity: 1, Fn: func(args []float64) (float64, error) {
+		if args[0] < 0 {
+			return 0, errorf(ErrDomain, "cannot calculate square root of negative number")
+		}
+		return math.Sqrt(args[0]), nil
+	}},
+	"root":  {Arity: 2, Fn: func(args []float64) (float64, error) { return Root(args[0], args[1]) }},
+	"abs":   unary("abs", math.Abs),
+	"exp":   unary("exp", math.Exp),
+	"ln":    positive("ln", math.Log),
+	"log":   positive("log", math.Log10),
+	"log2":  positive("log2", math.Log2),
+	"sin":   unary("sin", math.Sin),
+	"cos":   unary("cos", math.Cos),
+	"tan":   unary("tan", math.Tan),
+	"asin":  unary("asin", math.Asin),
+	"acos":  unary("acos", math.Acos),
+	"atan":  unary("atan", math.Atan),
+	"sinh":  unary("sinh", math.Sinh),
+	"cosh":  unary("cosh", math.Cosh),
+	"tanh":  unary("tanh", math.Tanh),
+	"floor": unary("floor", math.Floor),
+	"ceil":  unary("ceil", math.Ceil),
+}
+
+// LookupBuiltin returns the named builtin function
+func LookupBuiltin(name string) (Builtin, bool) {
+	b, ok := builtins[name]
+	return b, ok
+}
+
+// BuiltinNames returns the sorted names of the builtin functions
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Root returns the nth root of x; odd integer roots of negative numbers are real
+func Root(x, n float64) (float64, error) {
+	if n == 0 {
+		return 0, errorf(ErrDomain, "root degree cannot be zero")
+	}
+	if x < 0 {
+		if n != math.Trunc(n) || math.Mod(n, 2) == 0 {
+			return 0, errorf(ErrDomain, "cannot calculate even root of negative number")
+		}
+		return -math.Pow(-x, 1/n), nil
+	}
+	return math.Pow(x, 1/n), nil
+}
+
+// Eval evaluates n, resolving identifiers and non-builtin functions through env
+func Eval(n Node, env Env) (float64, error) {
+	switch n := n.(type) {
+	case *Num:
+		return n.Value, nil
+	case *Var:
+		if value, ok := env.Lookup(n.Name); ok {
+			return value, nil
+		}
+		return 0, errorf(ErrUndefined, "undefined variable '%s'", n.Name)
+	case *Unary:
+		x, err := Eval(n.X, env)
+		if err != nil {
+			return 0, err
+		}
+		return -x, nil
+	case *Binary:
+		return evalBinary(n, env)
+	case *Call:
+		args := make([]float64, len(n.Args))
+		for i, arg := range n.Args {
+			value, err := Eval(arg, env)
+			if err != nil {
+				return 0, err
+			}
+			args[i] = value
+		}
+		if b, ok := builtins[n.Name]; ok {
+			if len(args) != b.Arity {
+				return 0, errorf(ErrArity, "%s expects %d argument(s), got %d", n.Name, b.Arity, len(args))
+			}
+			return b.Fn(args)
+		}
+		return env.Call(n.Name, args)
+	default:
+		return 0, errorf(ErrSyntax, "unsupported expression node %T", n)
+	}
+}
+
+func evalBinary(n *Binary, env Env) (float64, error) {
+	l, err := Eval(n.L, env)
+	if err != nil {
+		return 0, err
+	}
+	r, err := Eval(n.R, env)
+	if err != nil {
+		return 0, err
+	}
+	switch n.Op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	case '/':
+		if r == 0 {
+			return 0, errorf(ErrDivisionByZero, "division by zero")
+		}
+		return l / r, nil
+	case '^':
+		result := math.Pow(l, r)
+		if math.IsNaN(result) {
+			return 0, errorf(ErrDomain, "%g^%g is undefined", l, r)
+		}
+		if l == 0 && r < 0 {
+			return 0, errorf(ErrDivisionByZero, "division by zero")
+		}
+		return result, nil
+	default:
+		return 0, errorf(ErrSyntax, "unknown operator '%c'", n.Op)
+	}
+}
@@ -0,0 +1,52 @@
+package expr
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEval tests evaluating expressions
+func TestEval(t *testing.T) {
+	env := Vars{"x": 3, "y": 4, "pi": math.Pi}
+	tests := []struct {
+		input    string
+		expected float64
+		kind     ErrorKind
+	}{
+		{"1 + 2 * 3", 7, 0},
+		{"2^3^2", 512, 0},
+		{"-x^2", -9, 0},
+		{"2x + y", 10, 0},
+		{"sqrt(x^2 + y^2)", 5, 0},
+		{"root(-27, 3)", -3, 0},
+		{"sin(pi / 2)", 1, 0},
+		{"log(1000) + ln(1)", 3, 0},
+		{"x / (y - 4)", 0, ErrDivisionByZero},
+		{"0^-1", 0, ErrDivisionByZero},
+		{"sqrt(-1)", 0, ErrDomain},
+		{"ln(0)", 0, ErrDomain},
+		{"(-8)^0.5", 0, ErrDomain},
+		{"root(-16, 2)", 0, ErrDomain},
+		{"z + 1", 0, ErrUndefined},
+		{"f(1)", 0, ErrUndefined},
+		{"sqrt(1, 2)", 0, ErrArity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			node, err := Parse(tt.input)
+			assert.NoError(t, err)
+			result, err := Eval(node, env)
+			if tt.kind != 0 {
+				var exprErr *Error
+				assert.True(t, errors.As(err, &exprErr))
+				assert.Equal(t, tt.kind, exprErr.Kind)
+				return
+			}
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.expected, result, 1e-9)
+		})
+	}
+}
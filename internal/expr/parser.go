@@ -0,0 +1,337 @@
+package expr
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind identifies the kind of a lexical token
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNum
+	tokIdent
+	tokOp
+)
+
+// token is a lexical token with its byte offset in the source
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+// lex splits src into tokens
+func lex(src string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		ch := rune(src[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case unicode.IsDigit(ch) || ch == '.':
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			// Only consume an exponent when digits follow, so "2e" stays 2 * e
+			if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				j := i + 1
+				if j < len(src) && (src[j] == '+' || src[j] == '-') {
+					j++
+				}
+				if j < len(src) && unicode.IsDigit(rune(src[j])) {
+					for j < len(src) && unicode.IsDigit(rune(src[j])) {
+						j++
+					}
+					i = j
+				}
+			}
+			value, err := strconv.ParseFloat(src[start:i], 64)
+			if err != nil {
+				return nil, syntaxError(start, "invalid number '%s'", src[start:i])
+			}
+			tokens = append(tokens, token{kind: tokNum, text: src[start:i], num: value, pos: start})
+		case unicode.IsLetter(ch) || ch == '_':
+			start := i
+			for i < len(src) && (unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i])) || src[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:i], pos: start})
+		case strings.ContainsRune("+-*/^(),=", ch):
+			tokens = append(tokens, token{kind: tokOp, text: string(ch), pos: i})
+			i++
+		default:
+			return nil, syntaxError(i, "unexpected character '%c'", ch)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// parser is a recursive-descent parser over a token stream
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the operator op
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		t := p.peek()
+		if t.kind == tokEOF {
+			return syntaxError(t.pos, "expected '%s' but reached end of input", op)
+		}
+		return syntaxError(t.pos, "expected '%s' but found '%s'", op, t.text)
+	}
+	return nil
+}
+
+// expression := term (('+' | '-') term)*
+func (p *parser) expression() (Node, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokOp || (t.text != "+" && t.text != "-") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: t.text[0], L: left, R: right}
+	}
+}
+
+// term := unary (('*' | '/') unary | implicit-multiplication)*
+func (p *parser) term() (Node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		var op byte
+		switch {
+		case t.kind == tokOp && (t.text == "*" || t.text == "/"):
+			p.next()
+			op = t.text[0]
+		case t.kind == tokIdent || t.kind == tokNum || (t.kind == tokOp && t.text == "("):
+			// Implicit multiplication: "2x", "3(x+1)", "x y"
+			op = '*'
+		default:
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: op, L: left, R: right}
+	}
+}
+
+// unary := ('-' | '+') unary | power
+func (p *parser) unary() (Node, error) {
+	if p.accept("-") {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Op: '-', X: x}, nil
+	}
+	if p.accept("+") {
+		return p.unary()
+	}
+	return p.power()
+}
+
+// power := primary ('^' unary)?
+func (p *parser) power() (Node, error) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if p.accept("^") {
+		exponent, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Binary{Op: '^', L: base, R: exponent}, nil
+	}
+	return base, nil
+}
+
+// primary := number | identifier | identifier '(' arguments ')' | '(' expression ')'
+func (p *parser) primary() (Node, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokNum:
+		return &Num{Value: t.num}, nil
+	case t.kind == tokIdent:
+		if !p.accept("(") {
+			return &Var{Name: t.text}, nil
+		}
+		call := &Call{Name: t.text}
+		if p.accept(")") {
+			return call, nil
+		}
+		for {
+			arg, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			call.Args = append(call.Args, arg)
+			if p.accept(")") {
+				return call, nil
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+	case t.kind == tokOp && t.text == "(":
+		inner, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	case t.kind == tokEOF:
+		return nil, syntaxError(t.pos, "unexpected end of input")
+	default:
+		return nil, syntaxError(t.pos, "unexpected '%s'", t.text)
+	}
+}
+
+// newParser lexes src and returns a parser positioned at its first token
+func newParser(src string) (*parser, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	return &parser{tokens: tokens}, nil
+}
+
+// end returns a syntax error unless all input has been consumed
+func (p *parser) end() error {
+	if t := p.peek(); t.kind != tokEOF {
+		return syntaxError(t.pos, "unexpected '%s'", t.text)
+	}
+	return nil
+}
+
+// Parse parses a single expression
+func Parse(src string) (Node, error) {
+	p, err := newParser(src)
+	if err != nil {
+		return nil, err
+	}
+	node, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.end(); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// Definition is a parsed function definition such as "f(x, y) = x^2 + y"
+type Definition struct {
+	Name   string
+	Params []string
+	Body   Node
+}
+
+// ParseDefinition parses a function definition of the form "name(p1, p2, ...) = body"
+func ParseDefinition(src string) (*Definition, error) {
+	p, err := newParser(src)
+	if err != nil {
+		return nil, err
+	}
+	name := p.next()
+	if name.kind != tokIdent {
+		return nil, syntaxError(name.pos, "expected function name")
+	}
+	def := &Definition{Name: name.text}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for !p.accept(")") {
+		if len(def.Params) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		param := p.next()
+		if param.kind != tokIdent {
+			return nil, syntaxError(param.pos, "expected parameter name")
+		}
+		if seen[param.text] {
+			return nil, syntaxError(param.pos, "duplicate parameter '%s'", param.text)
+		}
+		seen[param.text] = true
+		def.Params = append(def.Params, param.text)
+	}
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	def.Body, err = p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.end(); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+// ParseEquation parses an equation of the form "lhs = rhs"
+func ParseEquation(src string) (lhs, rhs Node, err error) {
+	p, err := newParser(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	if lhs, err = p.expression(); err != nil {
+		return nil, nil, err
+	}
+	if err := p.expect("="); err != nil {
+		return nil, nil, err
+	}
+	if rhs, err = p.expression(); err != nil {
+		return nil, nil, err
+	}
+	if err := p.end(); err != nil {
+		return nil, nil, err
+	}
+	return lhs, rhs, nil
+}
@@ -0,0 +1,85 @@
+package expr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseFormatting tests that parsed expressions format with minimal parentheses
+func TestParseFormatting(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + 2 * 3", "1 + 2 * 3"},
+		{"(1 + 2) * 3", "(1 + 2) * 3"},
+		{"a - (b - c)", "a - (b - c)"},
+		{"a / (b * c)", "a / (b * c)"},
+		{"2^3^2", "2^3^2"},
+		{"(2^3)^2", "(2^3)^2"},
+		{"-x^2", "-x^2"},
+		{"(-x)^2", "(-x)^2"},
+		{"2x", "2 * x"},
+		{"3(x + 1)", "3 * (x + 1)"},
+		{"root(x, 3) + sin(pi)", "root(x, 3) + sin(pi)"},
+		{"1.5e3 + 2e", "1500 + 2 * e"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			node, err := Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, node.String())
+		})
+	}
+}
+
+// TestParseErrors tests that malformed input reports syntax errors with positions
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+	}{
+		{"1 +", 3},
+		{"(1 + 2", 6},
+		{"1 $ 2", 2},
+		{"f(1,", 4},
+		{"1 2 )", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var exprErr *Error
+			assert.True(t, errors.As(err, &exprErr))
+			assert.Equal(t, ErrSyntax, exprErr.Kind)
+			assert.Equal(t, tt.pos, exprErr.Pos)
+		})
+	}
+}
+
+// TestParseDefinition tests parsing function definitions
+func TestParseDefinition(t *testing.T) {
+	def, err := ParseDefinition("hyp(a, b) = sqrt(a^2 + b^2)")
+	assert.NoError(t, err)
+	assert.Equal(t, "hyp", def.Name)
+	assert.Equal(t, []string{"a", "b"}, def.Params)
+	assert.Equal(t, "sqrt(a^2 + b^2)", def.Body.String())
+	assert.Equal(t, []string{"a", "b"}, Identifiers(def.Body))
+
+	for _, input := range []string{"f(x, x) = x", "f(1) = 1", "f(x) x", "f = 1", "f(x) = "} {
+		_, err := ParseDefinition(input)
+		assert.Error(t, err, input)
+	}
+}
+
+// TestParseEquation tests parsing equations
+func TestParseEquation(t *testing.T) {
+	lhs, rhs, err := ParseEquation("2x + y = 3")
+	assert.NoError(t, err)
+	assert.Equal(t, "2 * x + y", lhs.String())
+	assert.Equal(t, "3", rhs.String())
+
+	_, _, err = ParseEquation("2x + y")
+	assert.Error(t, err)
+}
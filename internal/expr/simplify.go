@@ -0,0 +1,206 @@
+package expr
+
+import "math"
+
+// maxSimplifyPasses bounds the rewriting passes Simplify makes before giving up on a fixed point
+const maxSimplifyPasses = 16
+
+// Simplify folds constant arithmetic and removes identities such as x + 0, 1 * x and x^1.
+// Division by zero and other undefined constant operations are left unfolded.
+func Simplify(n Node) Node {
+	for range maxSimplifyPasses {
+		next := simplify(n)
+		if next.String() == n.String() {
+			return next
+		}
+		n = next
+	}
+	return n
+}
+
+// isNum reports whether n is the numeric literal value
+func isNum(n Node, value float64) bool {
+	num, ok := n.(*Num)
+	return ok && num.Value == value
+}
+
+// neg returns the negation of n, folding literals and double negation
+func neg(n Node) Node {
+	switch n := n.(type) {
+	case *Num:
+		return &Num{Value: -n.Value}
+	case *Unary:
+		return n.X
+	case *Binary:
+		// -(c * x) → -c * x
+		if c, ok := n.L.(*Num); ok && n.Op == '*' {
+			return &Binary{Op: '*', L: &Num{Value: -c.Value}, R: n.R}
+		}
+	}
+	return &Unary{Op: '-', X: n}
+}
+
+func simplify(n Node) Node {
+	switch n := n.(type) {
+	case *Unary:
+		return neg(simplify(n.X))
+	case *Binary:
+		return simplifyBinary(n.Op, simplify(n.L), simplify(n.R))
+	case *Call:
+		args := make([]Node, len(n.Args))
+		for i, arg := range n.Args {
+			args[i] = simplify(arg)
+		}
+		return &Call{Name: n.Name, Args: args}
+	default:
+		return n
+	}
+}
+
+func simplifyBinary(op byte, l, r Node) Node {
+	ln, lok := l.(*Num)
+	rn, rok := r.(*Num)
+	if lok && rok {
+		if folded, ok := fold(op, ln.Value, rn.Value); ok {
+			return &Num{Value: folded}
+		}
+	}
+	same := l.String() == r.String()
+
+	switch op {
+	case '+':
+		switch {
+		case isNum(l, 0):
+			return r
+		case isNum(r, 0):
+			return l
+		case same:
+			return simplifyBinary('*', &Num{Value: 2}, l)
+		}
+		if u, ok := r.(*Unary); ok {
+			return &Binary{Op: '-', L: l, R: u.X}
+		}
+		if rok && rn.Value < 0 {
+			return &Binary{Op: '-', L: l, R: &Num{Value: -rn.Value}}
+		}
+	case '-':
+		switch {
+		case isNum(r, 0):
+			return l
+		case isNum(l, 0):
+			return neg(r)
+		case same:
+			return &Num{Value: 0}
+		}
+		if u, ok := r.(*Unary); ok {
+			return &Binary{Op: '+', L: l, R: u.X}
+		}
+		if rok && rn.Value < 0 {
+			return &Binary{Op: '+', L: l, R: &Num{Value: -rn.Value}}
+		}
+	case '*':
+		switch {
+		case isNum(l, 0) || isNum(r, 0):
+			return &Num{Value: 0}
+		case isNum(l, 1):
+			return r
+		case isNum(r, 1):
+			return l
+		case isNum(l, -1):
+			return neg(r)
+		case isNum(r, -1):
+			return neg(l)
+		case same:
+			return &Binary{Op: '^', L: l, R: &Num{Value: 2}}
+		}
+		// Pull negations outward and literals to the front: x * 2 → 2 * x, (-a) * b → -(a * b)
+		if u, ok := l.(*Unary); ok {
+			return neg(simplifyBinary('*', u.X, r))
+		}
+		if u, ok := r.(*Unary); ok {
+			return neg(simplifyBinary('*', l, u.X))
+		}
+		if rok && !lok {
+			return simplifyBinary('*', r, l)
+		}
+		if inner, ok := r.(*Binary); ok && inner.Op == '*' {
+			if c, ok := inner.L.(*Num); ok {
+				if lok {
+					// c1 * (c2 * x) → (c1 * c2) * x
+					return simplifyBinary('*', &Num{Value: ln.Value * c.Value}, inner.R)
+				}
+				// x * (c * y) → c * (x * y)
+				return simplifyBinary('*', c, simplifyBinary('*', l, inner.R))
+			}
+		}
+		if inner, ok := l.(*Binary); ok && inner.Op == '*' {
+			if c, ok := inner.L.(*Num); ok {
+				// (c * x) * y → c * (x * y)
+				return simplifyBinary('*', c, simplifyBinary('*', inner.R, r))
+			}
+		}
+		// (a / b) * b → a and a * (b / c) → (a * b) / c
+		if inner, ok := l.(*Binary); ok && inner.Op == '/' {
+			if inner.R.String() == r.String() {
+				return inner.L
+			}
+		}
+		if inner, ok := r.(*Binary); ok && inner.Op == '/' {
+			return simplifyBinary('/', simplifyBinary('*', l, inner.L), inner.R)
+		}
+	case '/':
+		switch {
+		case isNum(r, 1):
+			return l
+		case isNum(l, 0) && !isNum(r, 0):
+			return &Num{Value: 0}
+		case same && !isNum(r, 0):
+			return &Num{Value: 1}
+		}
+		if u, ok := l.(*Unary); ok {
+			return neg(simplifyBinary('/', u.X, r))
+		}
+	case '^':
+		switch {
+		case isNum(r, 0):
+			return &Num{Value: 1}
+		case isNum(r, 1):
+			return l
+		case isNum(l, 1):
+			return &Num{Value: 1}
+		}
+		// (x^a)^b → x^(a*b) for a literal integer b
+		if inner, ok := l.(*Binary); ok && inner.Op == '^' && rok && rn.Value == math.Trunc(rn.Value) {
+			if a, ok := inner.R.(*Num); ok {
+				return &Binary{Op: '^', L: inner.L, R: &Num{Value: a.Value * rn.Value}}
+			}
+		}
+	}
+	return &Binary{Op: op, L: l, R: r}
+}
+
+// fold evaluates a binary operation on literals, reporting false when the result is undefined
+func fold(op byte, l, r float64) (float64, bool) {
+	var result float64
+	switch op {
+	case '+':
+		result = l + r
+	case '-':
+		result = l - r
+	case '*':
+		result = l * r
+	case '/':
+		if r == 0 {
+			return 0, false
+		}
+		result = l / r
+	case '^':
+		if l == 0 && r < 0 {
+			return 0, false
+		}
+		result = math.Pow(l, r)
+	default:
+		return 0, false
+	}
+	return result, !math.IsNaN(result) && !math.IsInf(result, 0)
+}
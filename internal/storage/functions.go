@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"calculator/internal/expr"
+)
+
+// Function is a user-defined function stored for an owner
+type Function struct {
+	Name       string    `json:"name"`
+	Params     []string  `json:"params"`
+	Body       string    `json:"body"`
+	Definition string    `json:"definition"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	// Node is Body parsed when the function was defined; it is immutable and shared by every copy
+	Node expr.Node `json:"-"`
+}
+
+// clone returns a copy of the function that shares no slices with stored state
+func (f Function) clone() Function {
+	f.Params = slices.Clone(f.Params)
+	return f
+}
+
+// Function returns the named function for owner
+func (m *Memory) Function(owner, name string) (Function, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	fn, ok := m.functions[owner][name]
+	if !ok {
+		return Function{}, false
+	}
+	return fn.clone(), true
+}
+
+// SetFunction creates or replaces the named function for owner
+func (m *Memory) SetFunction(owner string, fn Function) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.functions[owner] == nil {
+		m.functions[owner] = make(map[string]Function)
+	}
+	m.functions[owner][fn.Name] = fn.clone()
+}
+
+// DeleteFunction removes the named function for owner, reporting whether it existed
+func (m *Memory) DeleteFunction(owner, name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.functions[owner][name]; !ok {
+		return false
+	}
+	delete(m.functions[owner], name)
+	return true
+}
+
+// Functions returns all functions for owner sorted by name
+func (m *Memory) Functions(owner string) []Function {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	functions := make([]Function, 0, len(m.functions[owner]))
+	for _, fn := range m.functions[owner] {
+		functions = append(functions, fn.clone())
+	}
+	sort.Slice(functions, func(i, j int) bool { return functions[i].Name < functions[j].Name })
+	return functions
+}
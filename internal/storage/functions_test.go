@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemoryFunctions tests function storage isolation between owners
+func TestMemoryFunctions(t *testing.T) {
+	m := NewMemory()
+
+	m.SetFunction("alice", Function{Name: "sq", Params: []string{"x"}, Body: "x^2"})
+	m.SetFunction("alice", Function{Name: "add", Params: []string{"a", "b"}, Body: "a + b"})
+	m.SetFunction("bob", Function{Name: "sq", Params: []string{"y"}, Body: "y * y"})
+
+	fn, ok := m.Function("alice", "sq")
+	assert.True(t, ok)
+	assert.Equal(t, "x^2", fn.Body)
+
+	// Returned functions are copies
+	fn.Params[0] = "z"
+	fn, _ = m.Function("alice", "sq")
+	assert.Equal(t, []string{"x"}, fn.Params)
+
+	fn, ok = m.Function("bob", "sq")
+	assert.True(t, ok)
+	assert.Equal(t, "y * y", fn.Body)
+
+	_, ok = m.Function("carol", "sq")
+	assert.False(t, ok)
+
+	functions := m.Functions("alice")
+	assert.Len(t, functions, 2)
+	assert.Equal(t, "add", functions[0].Name)
+	assert.Empty(t, m.Functions("carol"))
+
+	assert.True(t, m.DeleteFunction("alice", "sq"))
+	assert.False(t, m.DeleteFunction("alice", "sq"))
+	_, ok = m.Function("alice", "sq")
+	assert.False(t, ok)
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"slices"
+	"time"
+)
+
+// MaxHistoryEntries bounds the calculations kept per owner; the oldest are dropped first
+const MaxHistoryEntries = 1000
+
+// HistoryEntry is a completed calculation recorded for an owner
+type HistoryEntry struct {
+	Operation string    `json:"operation"`
+	Operands  []float64 `json:"operands"`
+	Result    float64   `json:"result"`
+	Unit      string    `json:"unit,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// clone returns a copy of the entry that shares no slices with stored state
+func (e HistoryEntry) clone() HistoryEntry {
+	e.Operands = slices.Clone(e.Operands)
+	return e
+}
+
+// AppendHistory records a calculation for owner
+func (m *Memory) AppendHistory(owner string, entry HistoryEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entries := append(m.history[owner], entry.clone())
+	if len(entries) > MaxHistoryEntries {
+		entries = slices.Clone(entries[len(entries)-MaxHistoryEntries:])
+	}
+	m.history[owner] = entries
+}
+
+// History returns up to limit of owner's calculations, newest first. A limit of zero or less returns all.
+func (m *Memory) History(owner string, limit int) []HistoryEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries := m.history[owner]
+	if limit <= 0 || limit > len(entries) {
+		limit = len(entries)
+	}
+	history := make([]HistoryEntry, 0, limit)
+	for i := len(entries) - 1; i >= len(entries)-limit; i-- {
+		history = append(history, entries[i].clone())
+	}
+	return history
+}
+
+// ClearHistory removes all of owner's calculations
+func (m *Memory) ClearHistory(owner string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.history, owner)
+}
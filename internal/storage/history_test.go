@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemoryHistory tests history ordering, limits, bounds and isolation between owners
+func TestMemoryHistory(t *testing.T) {
+	m := NewMemory()
+
+	m.AppendHistory("alice", HistoryEntry{Operation: "add", Operands: []float64{1, 2}, Result: 3})
+	m.AppendHistory("alice", HistoryEntry{Operation: "negative", Operands: []float64{3}, Result: -3})
+	m.AppendHistory("bob", HistoryEntry{Operation: "multiply", Operands: []float64{2, 2}, Result: 4})
+
+	history := m.History("alice", 0)
+	assert.Len(t, history, 2)
+	assert.Equal(t, "negative", history[0].Operation)
+	assert.Equal(t, "add", history[1].Operation)
+
+	history = m.History("alice", 1)
+	assert.Len(t, history, 1)
+	assert.Equal(t, -3.0, history[0].Result)
+
+	// Returned entries are copies
+	history[0].Operands[0] = 99
+	assert.Equal(t, []float64{3}, m.History("alice", 1)[0].Operands)
+
+	assert.Len(t, m.History("bob", 10), 1)
+	assert.Empty(t, m.History("carol", 0))
+
+	m.ClearHistory("alice")
+	assert.Empty(t, m.History("alice", 0))
+	assert.Len(t, m.History("bob", 0), 1)
+
+	// The oldest entries are dropped beyond the bound
+	for i := range MaxHistoryEntries + 5 {
+		m.AppendHistory("dave", HistoryEntry{Operation: "add", Result: float64(i)})
+	}
+	history = m.History("dave", 0)
+	assert.Len(t, history, MaxHistoryEntries)
+	assert.Equal(t, float64(MaxHistoryEntries+4), history[0].Result)
+	assert.Equal(t, 5.0, history[len(history)-1].Result)
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"maps"
+	"time"
+)
+
+// Session is server-side calculator state shared by every client of its tenant that knows its id.
+// AnsExact holds ANS as an exact fraction such as "1/3" when it was produced by a rational operation.
+type Session struct {
+	ID          string             `json:"id"`
+	Tenant      string             `json:"tenant,omitempty"`
+	Accumulator float64            `json:"accumulator"`
+	Ans         float64            `json:"ans"`
+	AnsExact    string             `json:"ans_exact,omitempty"`
+	Memory      map[string]float64 `json:"memory"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// clone returns a deep copy of the session so callers cannot mutate stored state
+func (s Session) clone() Session {
+	s.Memory = maps.Clone(s.Memory)
+	if s.Memory == nil {
+		s.Memory = make(map[string]float64)
+	}
+	return s
+}
+
+// CreateSession stores a new session
+func (m *Memory) CreateSession(session Session) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = session.clone()
+}
+
+// Session returns a copy of the session with the given id
+func (m *Memory) Session(id string) (Session, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	session, ok := m.sessions[id]
+	if !ok {
+		return Session{}, false
+	}
+	return session.clone(), true
+}
+
+// UpdateSession atomically applies update to the session with the given id and returns the result
+func (m *Memory) UpdateSession(id string, update func(*Session) error) (Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.sessions[id]
+	if !ok {
+		return Session{}, ErrNotFound
+	}
+	session := stored.clone()
+	if err := update(&session); err != nil {
+		return Session{}, err
+	}
+	session.UpdatedAt = time.Now()
+	m.sessions[id] = session.clone()
+	return session, nil
+}
+
+// DeleteSession removes the session with the given id, reporting whether it existed
+func (m *Memory) DeleteSession(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[id]; !ok {
+		return false
+	}
+	delete(m.sessions, id)
+	return true
+}
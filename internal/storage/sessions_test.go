@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemorySessions tests session storage, atomic updates and copy semantics
+func TestMemorySessions(t *testing.T) {
+	m := NewMemory()
+	m.CreateSession(Session{ID: "s1"})
+
+	session, err := m.UpdateSession("s1", func(s *Session) error {
+		s.Accumulator = 5
+		s.Memory["M"] = 1
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, session.Accumulator)
+
+	// Mutating a returned copy does not change stored state
+	session.Memory["M"] = 99
+	stored, ok := m.Session("s1")
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, stored.Memory["M"])
+
+	// A failed update leaves the session unchanged
+	_, err = m.UpdateSession("s1", func(s *Session) error {
+		s.Accumulator = 7
+		return errors.New("boom")
+	})
+	assert.Error(t, err)
+	stored, _ = m.Session("s1")
+	assert.Equal(t, 5.0, stored.Accumulator)
+
+	_, err = m.UpdateSession("missing", func(*Session) error { return nil })
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.True(t, m.DeleteSession("s1"))
+	assert.False(t, m.DeleteSession("s1"))
+}
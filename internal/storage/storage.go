@@ -0,0 +1,119 @@
+// Package storage holds per-owner calculator state such as user-defined variables, functions,
+// sessions and calculation history.
+//
+// An owner is an opaque key identifying who the data belongs to (for example an API key or a session id).
+// Data stored for one owner is never visible to another.
+package storage
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrNotFound is returned when a requested record does not exist
+var ErrNotFound = errors.New("not found")
+
+// Store defines the persistence operations used by the calculator service
+type Store interface {
+	// Variable returns the value of the named variable for owner
+	Variable(owner, name string) (float64, bool)
+	// SetVariable creates or replaces the named variable for owner
+	SetVariable(owner, name string, value float64)
+	// DeleteVariable removes the named variable for owner, reporting whether it existed
+	DeleteVariable(owner, name string) bool
+	// Variables returns all variables for owner sorted by name
+	Variables(owner string) []Variable
+
+	// CreateSession stores a new session
+	CreateSession(session Session)
+	// Session returns a copy of the session with the given id
+	Session(id string) (Session, bool)
+	// UpdateSession atomically applies update to the session with the given id and returns the result.
+	// If update returns an error the session is left unchanged. Returns ErrNotFound for unknown ids.
+	UpdateSession(id string, update func(*Session) error) (Session, error)
+	// DeleteSession removes the session with the given id, reporting whether it existed
+	DeleteSession(id string) bool
+
+	// Function returns the named user-defined function for owner
+	Function(owner, name string) (Function, bool)
+	// SetFunction creates or replaces the named function for owner
+	SetFunction(owner string, fn Function)
+	// DeleteFunction removes the named function for owner, reporting whether it existed
+	DeleteFunction(owner, name string) bool
+	// Functions returns all functions for owner sorted by name
+	Functions(owner string) []Function
+
+	// AppendHistory records a calculation for owner, dropping the oldest beyond MaxHistoryEntries
+	AppendHistory(owner string, entry HistoryEntry)
+	// History returns up to limit of owner's calculations, newest first; a limit of zero or less returns all
+	History(owner string, limit int) []HistoryEntry
+	// ClearHistory removes all of owner's calculations
+	ClearHistory(owner string)
+}
+
+// Variable is a named value stored for an owner
+type Variable struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+// Memory is an in-memory, concurrency-safe Store
+type Memory struct {
+	mu        sync.RWMutex
+	variables map[string]map[string]float64
+	sessions  map[string]Session
+	functions map[string]map[string]Function
+	history   map[string][]HistoryEntry
+}
+
+// NewMemory creates an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		variables: make(map[string]map[string]float64),
+		sessions:  make(map[string]Session),
+		functions: make(map[string]map[string]Function),
+		history:   make(map[string][]HistoryEntry),
+	}
+}
+
+// Variable returns the value of the named variable for owner
+func (m *Memory) Variable(owner, name string) (float64, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, ok := m.variables[owner][name]
+	return value, ok
+}
+
+// SetVariable creates or replaces the named variable for owner
+func (m *Memory) SetVariable(owner, name string, value float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.variables[owner] == nil {
+		m.variables[owner] = make(map[string]float64)
+	}
+	m.variables[owner][name] = value
+}
+
+// DeleteVariable removes the named variable for owner, reporting whether it existed
+func (m *Memory) DeleteVariable(owner, name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.variables[owner][name]; !ok {
+		return false
+	}
+	delete(m.variables[owner], name)
+	return true
+}
+
+// Variables returns all variables for owner sorted by name
+func (m *Memory) Variables(owner string) []Variable {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	variables := make([]Variable, 0, len(m.variables[owner]))
+	for name, value := range m.variables[owner] {
+		variables = append(variables, Variable{Name: name, Value: value})
+	}
+	sort.Slice(variables, func(i, j int) bool { return variables[i].Name < variables[j].Name })
+	return variables
+}
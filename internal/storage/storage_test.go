@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemoryVariables tests variable storage isolation between owners
+func TestMemoryVariables(t *testing.T) {
+	m := NewMemory()
+
+	m.SetVariable("alice", "x", 1)
+	m.SetVariable("alice", "a", 2)
+	m.SetVariable("bob", "x", 3)
+
+	value, ok := m.Variable("alice", "x")
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, value)
+
+	value, ok = m.Variable("bob", "x")
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, value)
+
+	_, ok = m.Variable("carol", "x")
+	assert.False(t, ok)
+
+	assert.Equal(t, []Variable{{"a", 2}, {"x", 1}}, m.Variables("alice"))
+	assert.Empty(t, m.Variables("carol"))
+
+	assert.True(t, m.DeleteVariable("alice", "x"))
+	assert.False(t, m.DeleteVariable("alice", "x"))
+	_, ok = m.Variable("alice", "x")
+	assert.False(t, ok)
+}
@@ -0,0 +1,224 @@
+// Package tenant keeps the teams sharing one deployment apart.
+//
+// Every caller belongs to exactly one tenant: the tenant its API key was registered with, the tenant named
+// by its bearer token, or the default tenant. A Registry holds the tenants, enforces their request quotas
+// and counts their usage for metrics.
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultID is the tenant of callers that no registered API key or token assigns to another tenant.
+// It always exists and cannot be disabled.
+const DefaultID = "default"
+
+// quotaWindow is the period a Quota's request budget applies to
+const quotaWindow = time.Minute
+
+var (
+	// ErrNotFound is returned for tenants that do not exist
+	ErrNotFound = errors.New("tenant not found")
+	// ErrExists is returned when creating a tenant whose ID is taken
+	ErrExists = errors.New("tenant already exists")
+	// ErrKeyInUse is returned when creating a tenant with an API key registered to another tenant
+	ErrKeyInUse = errors.New("API key already registered")
+	// ErrDisabled is returned when admitting a request of a disabled tenant
+	ErrDisabled = errors.New("tenant is disabled")
+	// ErrDefaultTenant is returned when disabling the default tenant
+	ErrDefaultTenant = errors.New("the default tenant cannot be disabled")
+)
+
+// idPattern matches valid tenant IDs, which appear in owner keys and metric labels
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// QuotaError reports a request refused because its tenant used up its quota
+type QuotaError struct {
+	Tenant string
+	// RetryAfter is how long until the quota admits requests again
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("tenant '%s' exceeded its request quota", e.Tenant)
+}
+
+// Quota limits a tenant's requests; zero fields are unlimited
+type Quota struct {
+	RequestsPerMinute int `json:"requests_per_minute"`
+}
+
+// Usage counts a tenant's requests
+type Usage struct {
+	// Requests counts admitted requests
+	Requests uint64 `json:"requests"`
+	// Errors counts admitted requests that failed
+	Errors uint64 `json:"errors"`
+	// Throttled counts requests refused by the quota
+	Throttled uint64 `json:"throttled"`
+}
+
+// Tenant is a team sharing the deployment
+type Tenant struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	APIKeys   []string  `json:"api_keys"`
+	Quota     Quota     `json:"quota"`
+	Disabled  bool      `json:"disabled"`
+	CreatedAt time.Time `json:"created_at"`
+	Usage     Usage     `json:"usage"`
+}
+
+// entry is a tenant with its quota window
+type entry struct {
+	tenant      Tenant
+	windowStart time.Time
+	windowCount int
+}
+
+// snapshot returns a copy of the tenant that callers cannot use to mutate the registry
+func (e *entry) snapshot() Tenant {
+	t := e.tenant
+	t.APIKeys = append([]string{}, t.APIKeys...)
+	return t
+}
+
+// Registry holds the tenants of a deployment. It is safe for concurrent use.
+type Registry struct {
+	mu      sync.Mutex
+	tenants map[string]*entry
+	keys    map[string]string // API key to tenant ID
+	now     func() time.Time
+}
+
+// NewRegistry returns a registry holding only the default tenant
+func NewRegistry() *Registry {
+	r := &Registry{
+		tenants: make(map[string]*entry),
+		keys:    make(map[string]string),
+		now:     time.Now,
+	}
+	r.tenants[DefaultID] = &entry{tenant: Tenant{ID: DefaultID, Name: "Default", APIKeys: []string{}, CreatedAt: r.now().UTC()}}
+	return r
+}
+
+// Create adds t, whose ID must be a lowercase slug and whose API keys must not belong to another tenant.
+// Usage, Disabled and CreatedAt are ignored.
+func (r *Registry) Create(t Tenant) (Tenant, error) {
+	if !idPattern.MatchString(t.ID) {
+		return Tenant{}, fmt.Errorf("invalid tenant ID '%s': use up to 63 lowercase letters, digits and hyphens", t.ID)
+	}
+	if t.Quota.RequestsPerMinute < 0 {
+		return Tenant{}, errors.New("requests_per_minute cannot be negative")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tenants[t.ID]; ok {
+		return Tenant{}, ErrExists
+	}
+	keys := make([]string, 0, len(t.APIKeys))
+	for _, key := range t.APIKeys {
+		if key == "" {
+			return Tenant{}, errors.New("API keys cannot be empty")
+		}
+		if _, ok := r.keys[key]; ok {
+			return Tenant{}, ErrKeyInUse
+		}
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		r.keys[key] = t.ID
+	}
+	e := &entry{tenant: Tenant{ID: t.ID, Name: t.Name, APIKeys: keys, Quota: t.Quota, CreatedAt: r.now().UTC()}}
+	r.tenants[t.ID] = e
+	return e.snapshot(), nil
+}
+
+// Get returns the tenant with the given ID
+func (r *Registry) Get(id string) (Tenant, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.tenants[id]
+	if !ok {
+		return Tenant{}, false
+	}
+	return e.snapshot(), true
+}
+
+// List returns all tenants sorted by ID
+func (r *Registry) List() []Tenant {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tenants := make([]Tenant, 0, len(r.tenants))
+	for _, e := range r.tenants {
+		tenants = append(tenants, e.snapshot())
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].ID < tenants[j].ID })
+	return tenants
+}
+
+// SetDisabled disables or re-enables the tenant with the given ID. Its data is kept either way.
+func (r *Registry) SetDisabled(id string, disabled bool) (Tenant, error) {
+	if id == DefaultID && disabled {
+		return Tenant{}, ErrDefaultTenant
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.tenants[id]
+	if !ok {
+		return Tenant{}, ErrNotFound
+	}
+	e.tenant.Disabled = disabled
+	return e.snapshot(), nil
+}
+
+// ForKey returns the ID of the tenant an API key is registered to
+func (r *Registry) ForKey(key string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id, ok := r.keys[key]
+	return id, ok
+}
+
+// Admit counts a request of the tenant with the given ID against its quota. It returns ErrNotFound,
+// ErrDisabled, or a *QuotaError when the tenant has used up its requests for the current minute.
+func (r *Registry) Admit(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.tenants[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if e.tenant.Disabled {
+		return ErrDisabled
+	}
+	if limit := e.tenant.Quota.RequestsPerMinute; limit > 0 {
+		now := r.now()
+		if now.Sub(e.windowStart) >= quotaWindow {
+			e.windowStart, e.windowCount = now, 0
+		}
+		if e.windowCount >= limit {
+			e.tenant.Usage.Throttled++
+			return &QuotaError{Tenant: id, RetryAfter: e.windowStart.Add(quotaWindow).Sub(now)}
+		}
+		e.windowCount++
+	}
+	e.tenant.Usage.Requests++
+	return nil
+}
+
+// RecordFailure counts an admitted request of the tenant with the given ID that failed
+func (r *Registry) RecordFailure(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.tenants[id]; ok {
+		e.tenant.Usage.Errors++
+	}
+}
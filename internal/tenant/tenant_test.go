@@ -0,0 +1,136 @@
+package tenant
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRegistry tests creating, listing and disabling tenants
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	acme, err := r.Create(Tenant{ID: "acme", Name: "Acme", APIKeys: []string{"a1", "a2"}, Disabled: true})
+	require.NoError(t, err)
+	assert.False(t, acme.Disabled, "tenants are created enabled")
+	assert.False(t, acme.CreatedAt.IsZero())
+
+	_, err = r.Create(Tenant{ID: "acme"})
+	assert.ErrorIs(t, err, ErrExists)
+	_, err = r.Create(Tenant{ID: "globex", APIKeys: []string{"a2"}})
+	assert.ErrorIs(t, err, ErrKeyInUse)
+	_, ok := r.Get("globex")
+	assert.False(t, ok, "a failed create leaves no tenant")
+	for _, id := range []string{"", "Acme", "a/b", "-acme", strings.Repeat("a", 64)} {
+		_, err = r.Create(Tenant{ID: id})
+		assert.Error(t, err, id)
+	}
+	_, err = r.Create(Tenant{ID: "initech", Quota: Quota{RequestsPerMinute: -1}})
+	assert.Error(t, err)
+
+	id, ok := r.ForKey("a2")
+	assert.True(t, ok)
+	assert.Equal(t, "acme", id)
+	_, ok = r.ForKey("unknown")
+	assert.False(t, ok)
+
+	tenants := r.List()
+	require.Len(t, tenants, 2)
+	assert.Equal(t, "acme", tenants[0].ID)
+	assert.Equal(t, DefaultID, tenants[1].ID)
+
+	// Snapshots do not share state with the registry
+	tenants[0].APIKeys[0] = "changed"
+	got, _ := r.Get("acme")
+	assert.Equal(t, []string{"a1", "a2"}, got.APIKeys)
+
+	disabled, err := r.SetDisabled("acme", true)
+	require.NoError(t, err)
+	assert.True(t, disabled.Disabled)
+	assert.ErrorIs(t, r.Admit("acme"), ErrDisabled)
+	_, err = r.SetDisabled("acme", false)
+	require.NoError(t, err)
+	assert.NoError(t, r.Admit("acme"))
+
+	_, err = r.SetDisabled(DefaultID, true)
+	assert.ErrorIs(t, err, ErrDefaultTenant)
+	_, err = r.SetDisabled("missing", true)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, r.Admit("missing"), ErrNotFound)
+}
+
+// TestQuota tests that a tenant's requests are limited per minute and counted
+func TestQuota(t *testing.T) {
+	r := NewRegistry()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	_, err := r.Create(Tenant{ID: "acme", Quota: Quota{RequestsPerMinute: 2}})
+	require.NoError(t, err)
+
+	require.NoError(t, r.Admit("acme"))
+	require.NoError(t, r.Admit("acme"))
+	now = now.Add(20 * time.Second)
+	err = r.Admit("acme")
+	var quotaErr *QuotaError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, "acme", quotaErr.Tenant)
+	assert.Equal(t, 40*time.Second, quotaErr.RetryAfter)
+
+	now = now.Add(40 * time.Second)
+	assert.NoError(t, r.Admit("acme"), "the quota resets every minute")
+	r.RecordFailure("acme")
+
+	// The default tenant is unlimited
+	for range 10 {
+		require.NoError(t, r.Admit(DefaultID))
+	}
+
+	acme, _ := r.Get("acme")
+	assert.Equal(t, Usage{Requests: 3, Errors: 1, Throttled: 1}, acme.Usage)
+}
+
+// TestToken tests signing and verifying bearer tokens
+func TestToken(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Unix(1_800_000_000, 0)
+	token, err := SignToken(Claims{Subject: "alice", Tenant: "acme", ExpiresAt: now.Add(time.Hour).Unix()}, secret)
+	require.NoError(t, err)
+
+	claims, err := ParseToken(token, secret, now)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims.Subject)
+	assert.Equal(t, "acme", claims.Tenant)
+
+	parts := strings.Split(token, ".")
+	forged, err := SignToken(Claims{Subject: "alice", Tenant: "globex"}, []byte("other"))
+	require.NoError(t, err)
+	unsigned := "eyJhbGciOiJub25lIn0." + parts[1] + "."
+
+	tests := []struct {
+		name  string
+		token string
+		now   time.Time
+		err   string
+	}{
+		{"expired", token, now.Add(time.Hour), "invalid token: expired"},
+		{"wrong secret", forged, now, "invalid token: bad signature"},
+		{"swapped claims", parts[0] + "." + strings.Split(forged, ".")[1] + "." + parts[2], now, "invalid token: bad signature"},
+		{"algorithm none", unsigned, now, "invalid token: unsupported algorithm 'none'"},
+		{"malformed", "not-a-token", now, "invalid token: malformed JWT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseToken(tt.token, secret, tt.now)
+			assert.EqualError(t, err, tt.err)
+			assert.True(t, errors.Is(err, ErrInvalidToken))
+		})
+	}
+
+	early, err := SignToken(Claims{Tenant: "acme", NotBefore: now.Add(time.Minute).Unix()}, secret)
+	require.NoError(t, err)
+	_, err = ParseToken(early, secret, now)
+	assert.EqualError(t, err, "invalid token: not valid yet")
+}
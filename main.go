@@ -3,10 +3,14 @@ package main
 import (
 	"log"
 	"log/slog"
-	"net/http"
+	"net"
 	"os"
+	"strings"
+	"time"
 
+	"calculator/internal/access"
 	"calculator/internal/calculator"
+	"calculator/internal/storage"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -30,6 +34,33 @@ func main() {
 	// Set the default logger to also write to file (optional - for other packages)
 	slog.SetDefault(fileLogger)
 
+	// Open the audit trail, continuing the hash chain of the records already written
+	auditFile, err := os.OpenFile("audit.log", os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		log.Fatalf("Failed to open audit log: %v", err)
+	}
+	defer func() {
+		_ = auditFile.Close()
+	}()
+	auditLog, err := calculator.NewAuditLog(auditFile)
+	if err != nil {
+		log.Fatalf("Failed to verify audit log: %v", err)
+	}
+
+	// Load the roles and permissions of API keys from the file named by CALCULATOR_ACCESS_CONFIG, if set
+	var accessPolicy *access.Policy
+	if path := os.Getenv("CALCULATOR_ACCESS_CONFIG"); path != "" {
+		accessFile, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("Failed to open access configuration: %v", err)
+		}
+		accessPolicy, err = access.Load(accessFile)
+		_ = accessFile.Close()
+		if err != nil {
+			log.Fatalf("Failed to load access configuration: %v", err)
+		}
+	}
+
 	// Create a new Gin router
 	r := gin.Default()
 
@@ -37,16 +68,44 @@ func main() {
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"http://localhost:3000"}
 	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "If-None-Match", calculator.APIKeyHeader, calculator.SessionHeader, calculator.ClientHeader, calculator.IdempotencyKeyHeader}
+	config.ExposeHeaders = []string{"ETag", calculator.CacheHeader, calculator.IdempotentReplayHeader}
 	r.Use(cors.New(config))
 
-	// Create calculator service with file logger
+	// Create calculator service with file logger, in-memory storage and a result cache
+	// of up to 10000 entries and 16 MiB, each kept for 10 minutes. Responses to POST requests with an
+	// Idempotency-Key are replayed to retries for 24 hours. Without an access configuration every
+	// caller may use the expensive endpoints and the comma-separated API keys in CALCULATOR_ADMIN_KEYS
+	// may also use the admin endpoints. Bearer tokens naming a tenant are verified with CALCULATOR_TOKEN_SECRET
+	// and refused without it. Browsers on the CORS origins may also open WebSocket connections.
 	calculatorService := &calculator.Service{
-		Logger: fileLogger,
+		Logger:      fileLogger,
+		Store:       storage.NewMemory(),
+		Cache:       calculator.NewCache(10000, 16<<20, 10*time.Minute),
+		Idempotency: calculator.NewIdempotencyStore(10000, 16<<20, 24*time.Hour),
+		Audit:       auditLog,
+		Access:      accessPolicy,
+		AdminKeys:   adminKeys(os.Getenv("CALCULATOR_ADMIN_KEYS")),
+		TokenSecret: []byte(os.Getenv("CALCULATOR_TOKEN_SECRET")),
+		Origins:     config.AllowOrigins,
 	}
 
 	// Setup routes
-	setupRoutes(r, calculatorService)
+	calculator.SetupRoutes(r, calculatorService)
+
+	// Start the gRPC server on its own port, sharing the calculator service
+	grpcPort := ":9090"
+	listener, err := net.Listen("tcp", grpcPort)
+	if err != nil {
+		log.Fatalf("Failed to listen on gRPC port: %v", err)
+	}
+	grpcServer := calculatorService.NewGRPCServer()
+	go func() {
+		log.Printf("gRPC server starting on port %s\n", grpcPort)
+		if err := grpcServer.Serve(listener); err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+	}()
 
 	// Start the server
 	port := ":8080"
@@ -57,37 +116,13 @@ func main() {
 	}
 }
 
-func setupRoutes(r *gin.Engine, s *calculator.Service) {
-	// Health check endpoint
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
-	})
-
-	// Calculator endpoints
-	api := r.Group("/api/v1")
-	{
-		// POST endpoints
-		api.POST("/add", s.Add)
-		api.POST("/subtract", s.Subtract)
-		api.POST("/multiply", s.Multiply)
-		api.POST("/divide", s.Divide)
-		api.POST("/percentage", s.Percentage)
-		api.POST("/power", s.Power)
-		api.POST("/sqrt", s.Sqrt)
-		api.POST("/root", s.Root)
-		api.POST("/inverse", s.Inverse)
-		api.POST("/negative", s.Negative)
-
-		// GET endpoints
-		api.GET("/add", s.AddGET)
-		api.GET("/subtract", s.SubtractGET)
-		api.GET("/multiply", s.MultiplyGET)
-		api.GET("/divide", s.DivideGET)
-		api.GET("/percentage", s.PercentageGET)
-		api.GET("/power", s.PowerGET)
-		api.GET("/sqrt", s.SqrtGET)
-		api.GET("/root", s.RootGET)
-		api.GET("/inverse", s.InverseGET)
-		api.GET("/negative", s.NegativeGET)
+// adminKeys returns the API keys listed in value, separated by commas
+func adminKeys(value string) []string {
+	var keys []string
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
 	}
+	return keys
 }